@@ -0,0 +1,73 @@
+// aad.go: Canonical encoding of structured context for use as additional data.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// canonicalAADLabel prefixes every CanonicalAAD encoding, so it cannot coincide with
+// hand-built AAD of another shape.
+const canonicalAADLabel = "go-crypto/v1/aad"
+
+// KV is a named context value for CanonicalAAD.
+type KV struct {
+	Key   string
+	Value string
+}
+
+// CanonicalAAD encodes key-value pairs into a deterministic byte string for use as AAD.
+//
+// The pairs are sorted by key (then by value, for repeated keys) and each key and
+// value is prefixed with its big-endian 32-bit length, after a fixed label and the
+// pair count. The encoding is injective: different sets of pairs always produce
+// different bytes, so {a: "bc"} never collides with {ab: "c"}, which plain string
+// concatenation cannot guarantee. Because the pairs are sorted, the order in which
+// they are passed does not matter.
+//
+// Parameters:
+//   - fields: The context pairs to bind (may be empty)
+//
+// Returns:
+//   - The canonical encoding
+//
+// Example:
+//
+//	aad := crypto.CanonicalAAD(
+//		crypto.KV{Key: "table", Value: "users"},
+//		crypto.KV{Key: "owner", Value: userID},
+//	)
+//	ciphertext, err := crypto.EncryptWithAAD(plaintext, key, aad)
+func CanonicalAAD(fields ...KV) []byte {
+	sorted := append([]KV(nil), fields...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Key != sorted[j].Key {
+			return sorted[i].Key < sorted[j].Key
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+
+	size := len(canonicalAADLabel) + 4
+	for _, f := range sorted {
+		size += 8 + len(f.Key) + len(f.Value)
+	}
+	out := make([]byte, 0, size)
+	out = append(out, canonicalAADLabel...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(sorted)))
+	for _, f := range sorted {
+		out = appendLengthPrefixed(out, f.Key)
+		out = appendLengthPrefixed(out, f.Value)
+	}
+	return out
+}
+
+// appendLengthPrefixed appends a big-endian 32-bit length followed by s to dst.
+func appendLengthPrefixed[T string | []byte](dst []byte, s T) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(s)))
+	return append(dst, s...)
+}
@@ -0,0 +1,67 @@
+// aad_test.go: Test cases for canonical AAD encoding.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestCanonicalAAD_Injective(t *testing.T) {
+	sets := [][]crypto.KV{
+		nil,
+		{{Key: "a", Value: "bc"}},
+		{{Key: "ab", Value: "c"}},
+		{{Key: "abc", Value: ""}},
+		{{Key: "", Value: "abc"}},
+		{{Key: "a", Value: "b"}, {Key: "c", Value: ""}},
+		{{Key: "a", Value: ""}, {Key: "b", Value: "c"}},
+		{{Key: "a", Value: "bc"}, {Key: "a", Value: "bc"}},
+	}
+	seen := make(map[string]int)
+	for i, set := range sets {
+		enc := string(crypto.CanonicalAAD(set...))
+		if j, dup := seen[enc]; dup {
+			t.Errorf("sets %d and %d produce the same encoding", j, i)
+		}
+		seen[enc] = i
+	}
+}
+
+func TestCanonicalAAD_OrderIndependent(t *testing.T) {
+	a := crypto.CanonicalAAD(crypto.KV{Key: "owner", Value: "alice"}, crypto.KV{Key: "table", Value: "users"}, crypto.KV{Key: "tag", Value: "x"}, crypto.KV{Key: "tag", Value: "y"})
+	b := crypto.CanonicalAAD(crypto.KV{Key: "tag", Value: "y"}, crypto.KV{Key: "table", Value: "users"}, crypto.KV{Key: "tag", Value: "x"}, crypto.KV{Key: "owner", Value: "alice"})
+	if !bytes.Equal(a, b) {
+		t.Error("CanonicalAAD() depends on argument order")
+	}
+}
+
+func TestCanonicalAAD_DoesNotModifyInput(t *testing.T) {
+	fields := []crypto.KV{{Key: "z", Value: "1"}, {Key: "a", Value: "2"}}
+	_ = crypto.CanonicalAAD(fields...)
+	if fields[0].Key != "z" {
+		t.Error("CanonicalAAD() reordered the caller's slice")
+	}
+}
+
+func TestCanonicalAAD_WithEncryptWithAAD(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	aad := crypto.CanonicalAAD(crypto.KV{Key: "owner", Value: "alice"})
+	ciphertext, err := crypto.EncryptWithAAD([]byte("record"), key, aad)
+	if err != nil {
+		t.Fatalf("EncryptWithAAD() error: %v", err)
+	}
+	if _, err := crypto.DecryptWithAAD(ciphertext, key, crypto.CanonicalAAD(crypto.KV{Key: "owner", Value: "alice"})); err != nil {
+		t.Errorf("DecryptWithAAD() with rebuilt AAD error: %v", err)
+	}
+	if _, err := crypto.DecryptWithAAD(ciphertext, key, crypto.CanonicalAAD(crypto.KV{Key: "owner", Value: "bob"})); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptWithAAD() with other owner: got %v, want ErrDecrypt", err)
+	}
+}
@@ -0,0 +1,174 @@
+// aadchain.go: Ciphertexts whose authenticated context can be extended after encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// aadChainLabel domain-separates AAD chain envelopes from other authenticated headers.
+const aadChainLabel = "go-crypto/v1/aad-chain"
+
+// aadChainDigestSize is the size of the chain digest carried in front of the envelope.
+const aadChainDigestSize = MACSize
+
+// EncryptWithAADChain encrypts plaintext bound to a sequence of additional data values
+// that can later be extended with AppendAAD.
+//
+// It behaves like EncryptWithAAD, except that the binding is a keyed digest chained
+// over each AAD value in order, carried in front of the envelope. Only the digest is
+// stored, keyed so that the context cannot be guessed from it without the key; the AAD
+// values themselves must be supplied again, in the same order, to decrypt.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - aads: The initial AAD values, in order (may be none)
+//
+// Returns:
+//   - A base64-encoded string containing the chain digest, nonce, ciphertext and tag
+//   - An error if the key is invalid or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptWithAADChain(document, key, []byte("doc:42"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptWithAADChain(plaintext, key []byte, aads ...[]byte) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	digest, err := aadChainDigest(key, nil, aads)
+	if err != nil {
+		return "", err
+	}
+	out, err := sealWithHeader(key, aadChainLabel, digest, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// DecryptWithAADChain decrypts a ciphertext produced by EncryptWithAADChain and
+// possibly extended by AppendAAD.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded ciphertext
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//   - aads: Every AAD value bound so far, in the order they were added
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error wrapping ErrDecrypt if the ciphertext was tampered with, the key is
+//     wrong, or the AAD values or their order do not match
+//
+// Example:
+//
+//	document, err := crypto.DecryptWithAADChain(ciphertext, key, []byte("doc:42"), []byte("owner:alice"))
+//	if errors.Is(err, crypto.ErrDecrypt) {
+//		// tampered ciphertext, wrong key, or wrong context
+//	}
+func DecryptWithAADChain(encryptedText string, key []byte, aads ...[]byte) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	data, err := decodeCiphertext(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+	header, plaintext, err := openWithHeader(key, aadChainLabel, data, aadChainDigestSize)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := aadChainDigest(key, nil, aads)
+	if err != nil {
+		Zeroize(plaintext)
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(header, digest) != 1 {
+		Zeroize(plaintext)
+		richErr := goerrors.New(ErrCodeDecrypt, "additional data does not match the ciphertext")
+		return nil, inputError(ErrDecrypt, richErr)
+	}
+	return plaintext, nil
+}
+
+// AppendAAD extends the authenticated context of a ciphertext produced by
+// EncryptWithAADChain, for context that only becomes known during a record's lifecycle,
+// such as an owner assigned after creation.
+//
+// The ciphertext is decrypted under its current binding and sealed again, with a fresh
+// nonce, bound to the existing AAD values followed by extraAAD. The existing values do
+// not need to be known: the chain digest stored in the ciphertext is authenticated and
+// extended in place. After the call, DecryptWithAADChain requires extraAAD as the last
+// value. Each call costs a full decryption and re-encryption of the plaintext, so it
+// suits occasional lifecycle events rather than per-request use. The intermediate
+// plaintext is zeroized.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext to extend
+//   - key: The 32-byte key (must be exactly KeySize bytes)
+//   - extraAAD: The AAD value to add to the binding (may be empty)
+//
+// Returns:
+//   - The re-sealed ciphertext; the old one remains valid under the old binding
+//   - An error if the key is invalid or the ciphertext fails to decrypt
+//
+// Example:
+//
+//	ciphertext, err = crypto.AppendAAD(ciphertext, key, []byte("owner:alice"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func AppendAAD(ciphertext string, key, extraAAD []byte) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	header, plaintext, err := openWithHeader(key, aadChainLabel, data, aadChainDigestSize)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(plaintext)
+	digest, err := aadChainDigest(key, header, [][]byte{extraAAD})
+	if err != nil {
+		return "", err
+	}
+	out, err := sealWithHeader(key, aadChainLabel, digest, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// aadChainDigest extends the chain digest prev (nil for a new chain) with aads:
+// d' = HMAC(k, d || len(aad) || aad), with k derived from key and d initially HMAC(k, "").
+func aadChainDigest(key, prev []byte, aads [][]byte) ([]byte, error) {
+	chainKey, err := deriveSubkey(key, nil, aadChainLabel, KeySize)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to derive AAD chain key")
+	}
+	defer Zeroize(chainKey)
+	digest := prev
+	if digest == nil {
+		digest = newMAC(chainKey).Sum(nil)
+	}
+	for _, aad := range aads {
+		mac := newMAC(chainKey)
+		mac.Write(digest)
+		mac.Write(appendLengthPrefixed(nil, aad))
+		digest = mac.Sum(nil)
+	}
+	return digest, nil
+}
@@ -0,0 +1,84 @@
+// aadchain_test.go: Test cases for extensible AAD bindings.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestAppendAAD_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := []byte("quarterly report")
+	doc, owner := []byte("doc:42"), []byte("owner:alice")
+
+	ciphertext, err := crypto.EncryptWithAADChain(plaintext, key, doc)
+	if err != nil {
+		t.Fatalf("EncryptWithAADChain() error: %v", err)
+	}
+	if got, err := crypto.DecryptWithAADChain(ciphertext, key, doc); err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("DecryptWithAADChain() = %q, %v", got, err)
+	}
+
+	extended, err := crypto.AppendAAD(ciphertext, key, owner)
+	if err != nil {
+		t.Fatalf("AppendAAD() error: %v", err)
+	}
+	if got, err := crypto.DecryptWithAADChain(extended, key, doc, owner); err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("DecryptWithAADChain() after AppendAAD = %q, %v", got, err)
+	}
+	for name, aads := range map[string][][]byte{
+		"old binding":    {doc},
+		"reversed order": {owner, doc},
+		"concatenated":   {append(append([]byte(nil), doc...), owner...)},
+		"none":           nil,
+	} {
+		if _, err := crypto.DecryptWithAADChain(extended, key, aads...); !errors.Is(err, crypto.ErrDecrypt) {
+			t.Errorf("%s: expected ErrDecrypt, got %v", name, err)
+		}
+	}
+	if _, err := crypto.DecryptWithAADChain(ciphertext, key, doc); err != nil {
+		t.Errorf("Expected the original ciphertext to stay valid, got %v", err)
+	}
+
+	// Appending from an empty chain matches encrypting with the value up front.
+	empty, _ := crypto.EncryptWithAADChain(plaintext, key)
+	empty, _ = crypto.AppendAAD(empty, key, doc)
+	if _, err := crypto.DecryptWithAADChain(empty, key, doc); err != nil {
+		t.Errorf("Expected an appended empty chain to decrypt, got %v", err)
+	}
+}
+
+func TestAppendAAD_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptWithAADChain([]byte("data"), key, []byte("ctx"))
+
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	data[0] ^= 1
+	tampered := base64.StdEncoding.EncodeToString(data)
+	if _, err := crypto.AppendAAD(tampered, key, []byte("x")); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for a tampered chain digest, got %v", err)
+	}
+	other, _ := crypto.GenerateKey()
+	if _, err := crypto.AppendAAD(ciphertext, other, []byte("x")); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for the wrong key, got %v", err)
+	}
+	plain, _ := crypto.EncryptWithAAD([]byte("data"), key, []byte("ctx"))
+	if _, err := crypto.AppendAAD(plain, key, []byte("x")); err == nil {
+		t.Error("Expected an EncryptWithAAD ciphertext to be rejected")
+	}
+	if _, err := crypto.AppendAAD(ciphertext, key[:16], []byte("x")); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	if _, err := crypto.EncryptWithAADChain([]byte("data"), nil); err == nil {
+		t.Error("Expected error for a nil key")
+	}
+}
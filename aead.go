@@ -0,0 +1,81 @@
+// aead.go: Envelope framing over caller-supplied AEAD implementations.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// EncryptWith encrypts plaintext with a caller-supplied AEAD using the package's envelope format.
+//
+// The output is base64(nonce || ciphertext || tag), the same framing used by EncryptBytes,
+// with a random nonce of aead.NonceSize() bytes. This lets advanced users plug in any
+// cipher.AEAD, such as ChaCha20-Poly1305 or a hardware-backed implementation, without
+// re-implementing the framing. With an AES-256-GCM AEAD the output is interchangeable
+// with EncryptBytes (when aad is empty).
+//
+// Parameters:
+//   - aead: The AEAD to encrypt with (cannot be nil)
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - aad: Additional authenticated data (can be empty)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if the AEAD is nil or nonce generation fails
+//
+// Example:
+//
+//	aead, _ := chacha20poly1305.NewX(key)
+//	ciphertext, err := crypto.EncryptWith(aead, []byte("payload"), nil)
+func EncryptWith(aead cipher.AEAD, plaintext, aad []byte) (string, error) {
+	if err := checkAEAD(aead); err != nil {
+		return "", err
+	}
+	sealed, err := sealAEAD(aead, nil, plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptWith decrypts a ciphertext produced by EncryptWith using the same AEAD.
+//
+// Parameters:
+//   - aead: The AEAD to decrypt with (cannot be nil)
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - aad: The additional authenticated data supplied at encryption time
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if the AEAD is nil, or decoding or authentication fails
+//
+// Example:
+//
+//	plaintext, err := crypto.DecryptWith(aead, ciphertext, nil)
+func DecryptWith(aead cipher.AEAD, encryptedText string, aad []byte) ([]byte, error) {
+	if err := checkAEAD(aead); err != nil {
+		return nil, err
+	}
+	data, err := decodeCiphertext(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+	return openAEAD(aead, data, aad)
+}
+
+// checkAEAD rejects a nil AEAD.
+func checkAEAD(aead cipher.AEAD) error {
+	if aead == nil {
+		richErr := goerrors.New(ErrCodeCipherInit, "AEAD cannot be nil")
+		return fmt.Errorf("%w: %w", ErrCipherInit, richErr)
+	}
+	return nil
+}
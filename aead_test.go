@@ -0,0 +1,78 @@
+// aead_test.go: Test cases for AAD support and caller-supplied AEADs.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func TestEncryptWithAAD(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, err := crypto.EncryptWithAAD([]byte("card"), key, []byte("user:42"))
+	if err != nil {
+		t.Fatalf("EncryptWithAAD() error: %v", err)
+	}
+	plaintext, err := crypto.DecryptWithAAD(ciphertext, key, []byte("user:42"))
+	if err != nil || string(plaintext) != "card" {
+		t.Errorf("Expected round trip, got %q err=%v", plaintext, err)
+	}
+	if _, err := crypto.DecryptWithAAD(ciphertext, key, []byte("user:43")); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for mismatched AAD, got %v", err)
+	}
+	if _, err := crypto.DecryptBytes(ciphertext, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt when AAD is omitted, got %v", err)
+	}
+
+	// Empty AAD is equivalent to EncryptBytes.
+	plain, _ := crypto.EncryptWithAAD([]byte("x"), key, nil)
+	if _, err := crypto.DecryptBytes(plain, key); err != nil {
+		t.Errorf("Expected empty AAD to interoperate with DecryptBytes, got %v", err)
+	}
+	if _, err := crypto.EncryptWithAAD(nil, make([]byte, 8), nil); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
+
+func TestEncryptWith_CustomAEAD(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	xchacha, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		t.Fatalf("NewX() error: %v", err)
+	}
+	ciphertext, err := crypto.EncryptWith(xchacha, []byte("ported"), []byte("ctx"))
+	if err != nil {
+		t.Fatalf("EncryptWith() error: %v", err)
+	}
+	plaintext, err := crypto.DecryptWith(xchacha, ciphertext, []byte("ctx"))
+	if err != nil || string(plaintext) != "ported" {
+		t.Errorf("Expected round trip, got %q err=%v", plaintext, err)
+	}
+	if _, err := crypto.DecryptWith(xchacha, ciphertext, nil); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for missing AAD, got %v", err)
+	}
+
+	// An AES-256-GCM AEAD produces the standard envelope.
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	standard, _ := crypto.EncryptWith(gcm, []byte("std"), nil)
+	if plaintext, err := crypto.DecryptBytes(standard, key); err != nil || string(plaintext) != "std" {
+		t.Errorf("Expected GCM output to match EncryptBytes format, got %q err=%v", plaintext, err)
+	}
+
+	if _, err := crypto.EncryptWith(nil, []byte("x"), nil); !errors.Is(err, crypto.ErrCipherInit) {
+		t.Errorf("Expected ErrCipherInit for nil AEAD, got %v", err)
+	}
+	if _, err := crypto.DecryptWith(nil, standard, nil); !errors.Is(err, crypto.ErrCipherInit) {
+		t.Errorf("Expected ErrCipherInit for nil AEAD, got %v", err)
+	}
+}
@@ -0,0 +1,599 @@
+// age.go: Interoperability with the age file encryption format (X25519 recipients).
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	goerrors "github.com/agilira/go-errors"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Constants of the age v1 format (https://age-encryption.org/v1).
+const (
+	ageVersionLine     = "age-encryption.org/v1"
+	ageX25519Label     = "age-encryption.org/v1/X25519"
+	ageRecipientHRP    = "age"
+	ageIdentityHRP     = "age-secret-key-"
+	ageFileKeySize     = 16
+	ageStreamNonceSize = 16
+	ageChunkSize       = 64 * 1024
+	ageColumns         = 64
+)
+
+// ageB64 is the canonical unpadded base64 used throughout the age header.
+var ageB64 = base64.RawStdEncoding.Strict()
+
+// ErrInvalidAge is returned when data is not a well-formed age file.
+var ErrInvalidAge = errors.New("crypto: invalid age file")
+
+// ErrCodeInvalidAge is the error code for malformed age files.
+const ErrCodeInvalidAge = "CRYPTO_INVALID_AGE"
+
+// GenerateAgeIdentity generates an X25519 key pair in age's text encoding.
+//
+// The identity ("AGE-SECRET-KEY-1...") is the secret half and must be protected like
+// any private key; the recipient ("age1...") can be shared freely. Both are
+// interchangeable with keys produced by age-keygen.
+//
+// Returns:
+//   - identity: The secret key, for DecryptAge
+//   - recipient: The public key, for EncryptAge
+//   - err: An error if key generation fails
+//
+// Example:
+//
+//	identity, recipient, err := crypto.GenerateAgeIdentity()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println("Public key:", recipient)
+func GenerateAgeIdentity() (identity, recipient string, err error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", goerrors.Wrap(err, "KEY_GEN_ERROR", "failed to generate X25519 key")
+	}
+	secret := priv.Bytes()
+	defer Zeroize(secret)
+	identity = strings.ToUpper(bech32Encode(ageIdentityHRP, secret))
+	recipient = bech32Encode(ageRecipientHRP, priv.PublicKey().Bytes())
+	return identity, recipient, nil
+}
+
+// EncryptAge encrypts plaintext to one or more age X25519 recipients.
+//
+// The output is a binary age v1 file: any holder of a matching identity can decrypt
+// it with DecryptAge or with the age command-line tool ("age -d -i key.txt"), and
+// files produced by "age -r age1..." decrypt with DecryptAge. A fresh 16-byte file
+// key is wrapped for each recipient; the payload is encrypted with ChaCha20-Poly1305
+// in 64 KiB chunks. ASCII armor is not produced.
+//
+// Parameters:
+//   - plaintext: The data to encrypt (can be empty)
+//   - recipients: The recipients as "age1..." strings (at least one)
+//
+// Returns:
+//   - The age file
+//   - An error if a recipient is invalid or encryption fails
+//
+// Example:
+//
+//	file, err := crypto.EncryptAge(report, []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = os.WriteFile("report.age", file, 0o600)
+func EncryptAge(plaintext []byte, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, goerrors.New("INVALID_RECIPIENT", "at least one recipient is required")
+	}
+	keys := make([]*ecdh.PublicKey, len(recipients))
+	for i, r := range recipients {
+		key, err := parseAgeRecipient(r)
+		if err != nil {
+			return nil, goerrors.Wrap(err, "INVALID_RECIPIENT", fmt.Sprintf("invalid recipient %d", i))
+		}
+		keys[i] = key
+	}
+
+	fileKey := make([]byte, ageFileKeySize)
+	if _, err := io.ReadFull(rand.Reader, fileKey); err != nil {
+		return nil, goerrors.Wrap(err, "KEY_GEN_ERROR", "failed to generate file key")
+	}
+	defer Zeroize(fileKey)
+
+	var buf bytes.Buffer
+	buf.WriteString(ageVersionLine + "\n")
+	for _, key := range keys {
+		share, body, err := ageWrapX25519(fileKey, key)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("-> X25519 " + ageB64.EncodeToString(share) + "\n")
+		writeAgeBody(&buf, body)
+	}
+	buf.WriteString("---")
+	mac, err := ageHeaderMAC(fileKey, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(" " + ageB64.EncodeToString(mac) + "\n")
+
+	nonce := make([]byte, ageStreamNonceSize)
+	if _, err := io.ReadFull(nonceReader(), nonce); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate nonce")
+		return nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	buf.Write(nonce)
+	aead, err := agePayloadAEAD(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+	var counter uint64
+	for {
+		n := min(len(plaintext), ageChunkSize)
+		final := n == len(plaintext)
+		out = aead.Seal(out, ageChunkNonce(counter, final), plaintext[:n], nil)
+		plaintext = plaintext[n:]
+		if final {
+			return out, nil
+		}
+		counter++
+	}
+}
+
+// DecryptAge decrypts an age v1 file with an X25519 identity.
+//
+// Every X25519 stanza is tried with the identity; stanzas of other recipient types
+// are skipped. The header MAC and every payload chunk are authenticated, and a
+// truncated or extended payload is rejected. ASCII-armored files must be dearmored
+// first.
+//
+// Parameters:
+//   - ciphertext: The binary age file
+//   - identity: The secret key as an "AGE-SECRET-KEY-1..." string
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error wrapping ErrInvalidAge if the file is malformed, ErrDecrypt if no
+//     stanza matches the identity or authentication fails, or another error if the
+//     identity is invalid
+//
+// Example:
+//
+//	file, _ := os.ReadFile("report.age")
+//	report, err := crypto.DecryptAge(file, identity)
+func DecryptAge(ciphertext []byte, identity string) ([]byte, error) {
+	priv, err := parseAgeIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := parseAgeHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileKey []byte
+	for _, s := range hdr.stanzas {
+		if s.args[0] != "X25519" {
+			continue
+		}
+		if fileKey, err = ageUnwrapX25519(s, priv); err != nil {
+			return nil, err
+		}
+		if fileKey != nil {
+			break
+		}
+	}
+	if fileKey == nil {
+		richErr := goerrors.New(ErrCodeDecrypt, "no recipient stanza matches the identity")
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, richErr)
+	}
+	defer Zeroize(fileKey)
+
+	mac, err := ageHeaderMAC(fileKey, hdr.macInput)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, hdr.mac) {
+		richErr := goerrors.New(ErrCodeDecrypt, "header MAC mismatch")
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, richErr)
+	}
+
+	payload := ciphertext[hdr.size:]
+	if len(payload) < ageStreamNonceSize+chacha20poly1305.Overhead {
+		return nil, ageError("payload too short")
+	}
+	aead, err := agePayloadAEAD(fileKey, payload[:ageStreamNonceSize])
+	if err != nil {
+		return nil, err
+	}
+	payload = payload[ageStreamNonceSize:]
+	sealedChunk := ageChunkSize + aead.Overhead()
+	plaintext := make([]byte, 0, len(payload)/sealedChunk*ageChunkSize+ageChunkSize)
+	for counter := uint64(0); ; counter++ {
+		n := min(len(payload), sealedChunk)
+		final := n == len(payload)
+		if final && counter > 0 && n == aead.Overhead() {
+			Zeroize(plaintext)
+			return nil, ageError("last payload chunk is empty")
+		}
+		out, err := aead.Open(plaintext, ageChunkNonce(counter, final), payload[:n], nil)
+		if err != nil {
+			Zeroize(plaintext)
+			richErr := goerrors.Wrap(err, ErrCodeDecrypt, fmt.Sprintf("failed to decrypt payload chunk %d", counter))
+			return nil, fmt.Errorf("%w: %w", ErrDecrypt, richErr)
+		}
+		plaintext = out
+		payload = payload[n:]
+		if final {
+			return plaintext, nil
+		}
+	}
+}
+
+// ageStanza is a recipient stanza of an age header.
+type ageStanza struct {
+	args []string
+	body []byte
+}
+
+// ageHeader is a parsed age header.
+type ageHeader struct {
+	stanzas  []ageStanza
+	macInput []byte // the header up to and including "---"
+	mac      []byte
+	size     int // header length including the MAC line
+}
+
+// parseAgeHeader parses the textual header at the start of data.
+func parseAgeHeader(data []byte) (*ageHeader, error) {
+	pos := 0
+	nextLine := func() (string, bool) {
+		i := bytes.IndexByte(data[pos:], '\n')
+		if i < 0 {
+			return "", false
+		}
+		line := string(data[pos : pos+i])
+		pos += i + 1
+		return line, true
+	}
+
+	if line, ok := nextLine(); !ok || line != ageVersionLine {
+		return nil, ageError("missing age-encryption.org/v1 version line")
+	}
+	hdr := &ageHeader{}
+	for {
+		start := pos
+		line, ok := nextLine()
+		if !ok {
+			return nil, ageError("header is not terminated")
+		}
+		if strings.HasPrefix(line, "---") {
+			encoded, ok := strings.CutPrefix(line, "--- ")
+			mac, err := ageB64.DecodeString(encoded)
+			if !ok || err != nil || len(mac) != sha256.Size {
+				return nil, ageError("malformed header MAC line")
+			}
+			if len(hdr.stanzas) == 0 {
+				return nil, ageError("header has no recipient stanzas")
+			}
+			hdr.macInput = data[:start+3]
+			hdr.mac = mac
+			hdr.size = pos
+			return hdr, nil
+		}
+
+		rest, ok := strings.CutPrefix(line, "-> ")
+		if !ok {
+			return nil, ageError("malformed stanza line")
+		}
+		args := strings.Split(rest, " ")
+		for _, arg := range args {
+			if !isAgeArg(arg) {
+				return nil, ageError("malformed stanza argument")
+			}
+		}
+		var encoded strings.Builder
+		for {
+			bodyLine, ok := nextLine()
+			if !ok || len(bodyLine) > ageColumns {
+				return nil, ageError("malformed stanza body")
+			}
+			encoded.WriteString(bodyLine)
+			if len(bodyLine) < ageColumns {
+				break
+			}
+		}
+		body, err := ageB64.DecodeString(encoded.String())
+		if err != nil {
+			return nil, ageError("stanza body is not canonical base64")
+		}
+		hdr.stanzas = append(hdr.stanzas, ageStanza{args: args, body: body})
+	}
+}
+
+// isAgeArg reports whether s is a valid stanza argument: one or more visible ASCII characters.
+func isAgeArg(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+// writeAgeBody writes base64 of body wrapped at 64 columns. The final line is always
+// shorter than 64 characters, possibly empty, which is how readers find the end.
+func writeAgeBody(buf *bytes.Buffer, body []byte) {
+	encoded := ageB64.EncodeToString(body)
+	for len(encoded) >= ageColumns {
+		buf.WriteString(encoded[:ageColumns] + "\n")
+		encoded = encoded[ageColumns:]
+	}
+	buf.WriteString(encoded + "\n")
+}
+
+// ageWrapX25519 wraps fileKey for recipient, returning the ephemeral share and stanza body.
+func ageWrapX25519(fileKey []byte, recipient *ecdh.PublicKey) ([]byte, []byte, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, goerrors.Wrap(err, "KEY_GEN_ERROR", "failed to generate ephemeral X25519 key")
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, nil, goerrors.Wrap(err, "INVALID_RECIPIENT", "X25519 key agreement failed")
+	}
+	defer Zeroize(shared)
+	share := ephemeral.PublicKey().Bytes()
+	aead, err := ageX25519AEAD(shared, share, recipient.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return share, aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil), nil
+}
+
+// ageUnwrapX25519 tries to unwrap the file key from an X25519 stanza. It returns a nil
+// key without error if the stanza is for a different recipient.
+func ageUnwrapX25519(s ageStanza, identity *ecdh.PrivateKey) ([]byte, error) {
+	if len(s.args) != 2 || len(s.body) != ageFileKeySize+chacha20poly1305.Overhead {
+		return nil, ageError("malformed X25519 stanza")
+	}
+	share, err := ageB64.DecodeString(s.args[1])
+	if err != nil {
+		return nil, ageError("malformed X25519 ephemeral share")
+	}
+	sharePub, err := ecdh.X25519().NewPublicKey(share)
+	if err != nil {
+		return nil, ageError("malformed X25519 ephemeral share")
+	}
+	shared, err := identity.ECDH(sharePub)
+	if err != nil {
+		return nil, ageError("X25519 ephemeral share is a low-order point")
+	}
+	defer Zeroize(shared)
+	aead, err := ageX25519AEAD(shared, share, identity.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), s.body, nil)
+	if err != nil {
+		return nil, nil
+	}
+	return fileKey, nil
+}
+
+// ageX25519AEAD derives the stanza wrapping AEAD from an X25519 shared secret.
+func ageX25519AEAD(shared, share, recipient []byte) (cipher.AEAD, error) {
+	salt := append(append(make([]byte, 0, len(share)+len(recipient)), share...), recipient...)
+	return ageAEAD(shared, salt, ageX25519Label)
+}
+
+// agePayloadAEAD derives the payload AEAD from the file key and payload nonce.
+func agePayloadAEAD(fileKey, nonce []byte) (cipher.AEAD, error) {
+	return ageAEAD(fileKey, nonce, "payload")
+}
+
+// ageAEAD derives a ChaCha20-Poly1305 key with HKDF-SHA256.
+func ageAEAD(secret, salt []byte, info string) (cipher.AEAD, error) {
+	key, err := deriveSubkey(secret, salt, info, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to derive age key")
+	}
+	defer Zeroize(key)
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeCipherInit, "failed to create ChaCha20-Poly1305")
+		return nil, fmt.Errorf("%w: %w", ErrCipherInit, richErr)
+	}
+	return aead, nil
+}
+
+// ageHeaderMAC computes the header MAC over the header up to and including "---".
+func ageHeaderMAC(fileKey, header []byte) ([]byte, error) {
+	key, err := deriveSubkey(fileKey, nil, "header", sha256.Size)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to derive age header key")
+	}
+	defer Zeroize(key)
+	h := hmac.New(sha256.New, key)
+	h.Write(header)
+	return h.Sum(nil), nil
+}
+
+// ageChunkNonce returns the STREAM nonce of a payload chunk:
+// an 11-byte big-endian counter followed by the final-chunk flag.
+func ageChunkNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// parseAgeRecipient decodes an "age1..." recipient.
+func parseAgeRecipient(s string) (*ecdh.PublicKey, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != ageRecipientHRP {
+		return nil, fmt.Errorf("not an age X25519 recipient")
+	}
+	return ecdh.X25519().NewPublicKey(data)
+}
+
+// parseAgeIdentity decodes an "AGE-SECRET-KEY-1..." identity.
+func parseAgeIdentity(s string) (*ecdh.PrivateKey, error) {
+	hrp, data, err := bech32Decode(s)
+	if err == nil && hrp != ageIdentityHRP {
+		err = fmt.Errorf("not an age X25519 identity")
+	}
+	if err == nil && len(data) != 32 {
+		err = fmt.Errorf("identity must encode 32 bytes (got %d)", len(data))
+	}
+	if err != nil {
+		return nil, goerrors.Wrap(err, "INVALID_IDENTITY", "invalid age identity")
+	}
+	defer Zeroize(data)
+	key, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "INVALID_IDENTITY", "invalid age identity")
+	}
+	return key, nil
+}
+
+// ageError builds an error wrapping ErrInvalidAge.
+func ageError(msg string) error {
+	richErr := goerrors.New(ErrCodeInvalidAge, msg)
+	return fmt.Errorf("%w: %w", ErrInvalidAge, richErr)
+}
+
+// bech32Charset is the alphabet of Bech32 (BIP 173), used by age key encodings.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Polymod computes the Bech32 checksum polynomial over values.
+func bech32Polymod(values []byte) uint32 {
+	generators := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range generators {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand expands the human-readable part for checksum computation.
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// bech32Encode encodes data with the lowercase human-readable part hrp. Unlike BIP 173
+// it imposes no length limit, matching age's encoding of keys.
+func bech32Encode(hrp string, data []byte) string {
+	values := convertBits(data, 8, 5, true)
+	checksumInput := append(append(bech32HRPExpand(hrp), values...), 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(checksumInput) ^ 1
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range values {
+		sb.WriteByte(bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(mod>>uint(5*(5-i)))&31])
+	}
+	return sb.String()
+}
+
+// bech32Decode decodes a Bech32 string, returning its lowercase human-readable part and data.
+func bech32Decode(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("mixed-case Bech32 string")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, fmt.Errorf("malformed Bech32 string")
+	}
+	hrp := s[:sep]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, fmt.Errorf("invalid Bech32 prefix character")
+		}
+	}
+	values := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		v := strings.IndexByte(bech32Charset, s[i])
+		if v < 0 {
+			return "", nil, fmt.Errorf("invalid Bech32 character %q", s[i])
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), values...)) != 1 {
+		return "", nil, fmt.Errorf("invalid Bech32 checksum")
+	}
+	data := convertBits(values[:len(values)-6], 5, 8, false)
+	if data == nil {
+		return "", nil, fmt.Errorf("invalid Bech32 padding")
+	}
+	return hrp, data, nil
+}
+
+// convertBits regroups data from fromBits-bit to toBits-bit groups. Without pad, it
+// returns nil if the input leaves more than fromBits-1 or any non-zero padding bits.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, b := range data {
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil
+	}
+	return out
+}
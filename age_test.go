@@ -0,0 +1,182 @@
+// age_test.go: Test cases for age file format interoperability.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+// ageTestIdentity and ageTestRecipient are the key pair of testdata/example_keys.txt in
+// filippo.io/age v1.2.1.
+const (
+	ageTestIdentity  = "AGE-SECRET-KEY-184JMZMVQH3E6U0PSL869004Y3U2NYV7R30EU99CSEDNPH02YUVFSZW44VU"
+	ageTestRecipient = "age1cy0su9fwf3gf9mw868g5yut09p6nytfmmnktexz2ya5uqg9vl9sss4euqm"
+)
+
+// ageTestVectors were produced by filippo.io/age v1.2.1 for ageTestRecipient: the first
+// is its testdata/example.age, the second is encrypted to a random recipient followed by
+// ageTestRecipient.
+var ageTestVectors = []struct {
+	file      string
+	plaintext string
+}{
+	{
+		"YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSA4aHJsTStaQkczRGQ0ZkYyK2E1ODN6ZFRJV0RrOC9SNDFrQ1lac3Z3VFc0CnlPNFBZZGxNV0RKK0N4Z1VOUnFZNVowVC9tK2czRkNoNWpJeEdMYkNWWGMKLS0tIEkvaW1ldlp6eTgxMjBKU3ptSm5tbi9LTWszcDVBMTFWODNOazQxbTlOUEUKcMXlNiShUgdT+Sxa0Q7KsnO6TWEXgHcT6DggQXod8soIGCJyyPhchXc0oTEaO3XpjQ6v",
+		"Black lives matter.",
+	},
+	{
+		"YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSB6T01ESVhSZUYzQUxFQ1ZzMzhBRTRib0txL1g4MUNjUXNJbnhGMnJJakhVCkRNYjd3bTJ1ZlBydDE4eWtxaDdPdk9YMjhTN2VsL2NHSUNMN3VKL05CYmcKLT4gWDI1NTE5IDh6eE5sSUtubVM2NW1VL1NKYnNUcXpOTjZMZ1RTalRZaUdsQjlxWGNqWEEKMGdGdEhGUUZMVVp6RnhaZklGN0Z4NXNDRG5XWkZZdWNnK0NUcVd5WW4rdwotLS0ga0U2eExOajFob0NoY1NZZk9IZmxjTzAyOE1LbHY0N0tKWTNqSEZFZFA3TQopHVskduHKrRSSLtZUlJSzmOMI0oSb1m/2uPMET3jSornXlrbq24tPwWMEdQwOH8+qE6zvC8/BjNFHuwtPgo8GOfCmWlfnGApTOA==",
+		"Encrypted by age v1.2.1 to two recipients.",
+	},
+}
+
+func TestDecryptAge_Vectors(t *testing.T) {
+	for i, v := range ageTestVectors {
+		file, _ := base64.StdEncoding.DecodeString(v.file)
+		got, err := crypto.DecryptAge(file, ageTestIdentity)
+		if err != nil {
+			t.Fatalf("vector %d: DecryptAge() error: %v", i, err)
+		}
+		if string(got) != v.plaintext {
+			t.Errorf("vector %d: DecryptAge() = %q, want %q", i, got, v.plaintext)
+		}
+		tampered := append([]byte(nil), file...)
+		tampered[len(tampered)-1] ^= 1
+		if _, err := crypto.DecryptAge(tampered, ageTestIdentity); err == nil {
+			t.Errorf("vector %d: expected an error for a tampered payload", i)
+		}
+	}
+	other, _, _ := crypto.GenerateAgeIdentity()
+	file, _ := base64.StdEncoding.DecodeString(ageTestVectors[0].file)
+	if _, err := crypto.DecryptAge(file, other); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong identity: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestEncryptAge_KnownRecipient(t *testing.T) {
+	// The recipient age derives from ageTestIdentity must open files for that identity.
+	file, err := crypto.EncryptAge([]byte("written by go-crypto"), []string{ageTestRecipient})
+	if err != nil {
+		t.Fatalf("EncryptAge() error: %v", err)
+	}
+	got, err := crypto.DecryptAge(file, ageTestIdentity)
+	if err != nil || string(got) != "written by go-crypto" {
+		t.Errorf("DecryptAge() = %q, %v", got, err)
+	}
+	if _, err := crypto.DecryptAge(file, strings.ToLower(ageTestIdentity)); err != nil {
+		t.Errorf("lowercase identity: %v", err)
+	}
+}
+
+func TestGenerateAgeIdentity_Format(t *testing.T) {
+	identity, recipient, err := crypto.GenerateAgeIdentity()
+	if err != nil {
+		t.Fatalf("GenerateAgeIdentity() error: %v", err)
+	}
+	if !strings.HasPrefix(identity, "AGE-SECRET-KEY-1") || len(identity) != 74 {
+		t.Errorf("identity %q does not look like an age secret key", identity)
+	}
+	if !strings.HasPrefix(recipient, "age1") || len(recipient) != 62 {
+		t.Errorf("recipient %q does not look like an age recipient", recipient)
+	}
+}
+
+func TestEncryptAge_RoundTrip(t *testing.T) {
+	id1, r1, _ := crypto.GenerateAgeIdentity()
+	id2, r2, _ := crypto.GenerateAgeIdentity()
+
+	sizes := []int{0, 1, 64 * 1024, 2 * 64 * 1024, 150000}
+	for _, size := range sizes {
+		plaintext := bytes.Repeat([]byte{0xA5}, size)
+		file, err := crypto.EncryptAge(plaintext, []string{r1, r2})
+		if err != nil {
+			t.Fatalf("EncryptAge(%d bytes) error: %v", size, err)
+		}
+		if !bytes.HasPrefix(file, []byte("age-encryption.org/v1\n-> X25519 ")) {
+			t.Fatalf("unexpected header: %q", file[:40])
+		}
+		for _, id := range []string{id1, id2} {
+			got, err := crypto.DecryptAge(file, id)
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Errorf("DecryptAge(%d bytes) = %d bytes, %v", size, len(got), err)
+			}
+		}
+	}
+
+	// Identities are case-insensitive, like age-keygen output pasted in lowercase.
+	file, _ := crypto.EncryptAge([]byte("x"), []string{r1})
+	if _, err := crypto.DecryptAge(file, strings.ToLower(id1)); err != nil {
+		t.Errorf("lowercase identity: %v", err)
+	}
+}
+
+func TestDecryptAge_Errors(t *testing.T) {
+	id, recipient, _ := crypto.GenerateAgeIdentity()
+	otherID, _, _ := crypto.GenerateAgeIdentity()
+	plaintext := bytes.Repeat([]byte("chunked "), 20000) // spans several chunks
+	file, _ := crypto.EncryptAge(plaintext, []string{recipient})
+	headerEnd := bytes.Index(file, []byte("\n--- ")) + 1
+
+	if _, err := crypto.DecryptAge(file, otherID); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong identity: got %v, want ErrDecrypt", err)
+	}
+
+	tamperedMAC := append([]byte(nil), file...)
+	tamperedMAC[headerEnd+5] ^= 1
+	if _, err := crypto.DecryptAge(tamperedMAC, id); !errors.Is(err, crypto.ErrDecrypt) && !errors.Is(err, crypto.ErrInvalidAge) {
+		t.Errorf("tampered MAC: got %v", err)
+	}
+
+	grease := append([]byte("age-encryption.org/v1\n-> grease x\n\n"), file[len("age-encryption.org/v1\n"):]...)
+	if _, err := crypto.DecryptAge(grease, id); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("stanza added after encryption: got %v, want ErrDecrypt", err)
+	}
+
+	truncated := file[:len(file)-(len(file)-headerEnd)%(64*1024+16)]
+	if _, err := crypto.DecryptAge(truncated, id); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("truncated at chunk boundary: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.DecryptAge(append(append([]byte(nil), file...), 0), id); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("extended payload: got %v, want ErrDecrypt", err)
+	}
+
+	for _, bad := range [][]byte{nil, []byte("not an age file\n"), []byte("age-encryption.org/v1\n---\n")} {
+		if _, err := crypto.DecryptAge(bad, id); !errors.Is(err, crypto.ErrInvalidAge) {
+			t.Errorf("DecryptAge(%q): got %v, want ErrInvalidAge", bad, err)
+		}
+	}
+	if _, err := crypto.DecryptAge(file, "AGE-SECRET-KEY-1INVALID"); err == nil {
+		t.Error("invalid identity: expected error")
+	}
+}
+
+func TestEncryptAge_Recipients(t *testing.T) {
+	// Public key from the age README; parsing it checks the Bech32 implementation.
+	if _, err := crypto.EncryptAge([]byte("x"), []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"}); err != nil {
+		t.Errorf("EncryptAge() to a known recipient: %v", err)
+	}
+	invalid := []string{
+		"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8q", // checksum
+		"age1Ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p", // mixed case
+		"ssh-ed25519 AAAA",
+		"",
+	}
+	for _, r := range invalid {
+		if _, err := crypto.EncryptAge([]byte("x"), []string{r}); err == nil {
+			t.Errorf("EncryptAge() to %q: expected error", r)
+		}
+	}
+	if _, err := crypto.EncryptAge([]byte("x"), nil); err == nil {
+		t.Error("EncryptAge() without recipients: expected error")
+	}
+}
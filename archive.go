@@ -0,0 +1,397 @@
+// archive.go: Self-describing containers for long-term archival of encrypted data.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	goerrors "github.com/agilira/go-errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// Algorithm identifies an authenticated encryption algorithm in self-describing formats.
+//
+// Identifiers are stable and are never reused, so that data written today can still be
+// dispatched to the right implementation by future versions of the library.
+type Algorithm uint16
+
+const (
+	// AlgorithmAES256GCM is AES-256 in GCM mode with a 12-byte nonce and 16-byte tag.
+	AlgorithmAES256GCM Algorithm = 1
+
+	// AlgorithmXChaCha20Poly1305 is XChaCha20-Poly1305 with a 24-byte nonce and 16-byte tag.
+	//
+	// Reserved: only the identifier and nonce size are fixed. No function of the package
+	// encrypts or decrypts with it yet, and DecryptArchive rejects it with
+	// ErrUnsupportedAlgorithm.
+	AlgorithmXChaCha20Poly1305 Algorithm = 2
+)
+
+// String returns a human-readable name for the algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmAES256GCM:
+		return "AES-256-GCM"
+	case AlgorithmXChaCha20Poly1305:
+		return "XChaCha20-Poly1305"
+	default:
+		return fmt.Sprintf("Algorithm(%d)", uint16(a))
+	}
+}
+
+// Archive container layout:
+//
+//	magic "AGCA" || version (1 byte) || record* || payload record
+//
+// Each record is tag (1 byte) || length (uint32 big-endian) || value. The payload
+// record must be last; every byte preceding its value is authenticated as additional
+// data. Unknown record tags are skipped (but still authenticated), so later versions
+// can add metadata without breaking older readers.
+const (
+	archiveMagic   = "AGCA"
+	archiveVersion = 1
+
+	archiveTagAlgorithm byte = 0x01
+	archiveTagCreated   byte = 0x02
+	archiveTagKDF       byte = 0x03
+	archiveTagPayload   byte = 0x7f
+
+	archiveRecordHeaderSize = 5
+
+	// archiveKDFArgon2id identifies Argon2id inside the KDF record:
+	// id (1) || time (4) || memory KiB (4) || threads (1) || salt.
+	archiveKDFArgon2id  byte = 1
+	archiveKDFFixedSize      = 10
+)
+
+var (
+	// ErrInvalidArchive is returned when an archive container is malformed.
+	ErrInvalidArchive = errors.New("crypto: invalid archive container")
+
+	// ErrUnsupportedAlgorithm is returned when data names an algorithm this version does not implement.
+	ErrUnsupportedAlgorithm = errors.New("crypto: unsupported algorithm")
+)
+
+// Error codes for archive containers
+const (
+	ErrCodeInvalidArchive       = "CRYPTO_INVALID_ARCHIVE"
+	ErrCodeUnsupportedAlgorithm = "CRYPTO_UNSUPPORTED_ALGORITHM"
+)
+
+// ArchiveInfo describes the metadata stored in an archive container.
+type ArchiveInfo struct {
+	// Algorithm is the encryption algorithm of the payload.
+	Algorithm Algorithm
+
+	// Created is the time the archive was written, with second precision.
+	Created time.Time
+
+	// KDF holds the Argon2id parameters for password-based archives, or nil when the
+	// archive was encrypted with a raw key. Memory is reported in MB, as in DeriveKey.
+	KDF *KDFParams
+
+	kdfMemoryKiB uint32
+	salt         []byte
+	aad          []byte
+	payload      []byte
+}
+
+// ArchiveEncrypt encrypts plaintext into a self-describing archive container.
+//
+// The container records the algorithm identifier and creation timestamp alongside the
+// ciphertext, authenticated as additional data. It is meant for data that must stay
+// decryptable for years: ArchiveDecrypt dispatches on the stored metadata instead of
+// assuming the library's current defaults.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The binary archive container
+//   - An error if encryption fails
+//
+// Example:
+//
+//	archive, err := crypto.ArchiveEncrypt(record, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = os.WriteFile("record.agca", archive, 0o600)
+func ArchiveEncrypt(plaintext []byte, key []byte) ([]byte, error) {
+	return sealArchive(plaintext, key, nil)
+}
+
+// ArchiveDecrypt decrypts an archive container produced by ArchiveEncrypt.
+//
+// Parameters:
+//   - data: The binary archive container
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error wrapping ErrInvalidArchive for malformed or password-based containers,
+//     ErrUnsupportedAlgorithm for unknown algorithm ids, or ErrDecrypt on authentication failure
+//
+// Example:
+//
+//	plaintext, err := crypto.ArchiveDecrypt(archive, key)
+//	if errors.Is(err, crypto.ErrUnsupportedAlgorithm) {
+//		// written by a newer library version
+//	}
+func ArchiveDecrypt(data []byte, key []byte) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	info, err := parseArchive(data)
+	if err != nil {
+		return nil, err
+	}
+	if info.KDF != nil {
+		return nil, archiveError("archive is password-protected; use ArchiveDecryptWithPassword")
+	}
+	return openArchive(info, key)
+}
+
+// ArchiveEncryptWithPassword encrypts plaintext into an archive container keyed by a password.
+//
+// A random salt is generated and the key is derived with Argon2id. The salt and the
+// effective Argon2id parameters are stored in the container, so the archive remains
+// decryptable even if the library defaults change later.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - password: The password to derive the key from (cannot be empty)
+//   - params: Custom Argon2id parameters (nil to use secure defaults)
+//
+// Returns:
+//   - The binary archive container
+//   - An error if key derivation or encryption fails
+//
+// Example:
+//
+//	archive, err := crypto.ArchiveEncryptWithPassword(record, []byte(passphrase), nil)
+func ArchiveEncryptWithPassword(plaintext, password []byte, params *KDFParams) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, goerrors.New("EMPTY_PASSWORD", "password cannot be empty")
+	}
+	salt := make([]byte, PasswordSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, goerrors.Wrap(err, "SALT_GEN_ERROR", "failed to generate salt")
+	}
+	t, memoryKiB, threads := params.resolve()
+	key := argon2.IDKey(password, salt, t, memoryKiB, threads, KeySize)
+	defer Zeroize(key)
+
+	kdf := make([]byte, archiveKDFFixedSize, archiveKDFFixedSize+len(salt))
+	kdf[0] = archiveKDFArgon2id
+	binary.BigEndian.PutUint32(kdf[1:5], t)
+	binary.BigEndian.PutUint32(kdf[5:9], memoryKiB)
+	kdf[9] = threads
+	kdf = append(kdf, salt...)
+	return sealArchive(plaintext, key, kdf)
+}
+
+// ArchiveDecryptWithPassword decrypts a password-based archive container.
+//
+// The Argon2id parameters stored in the container are used, within the same sanity
+// bounds as VerifyPassword, so a crafted container cannot demand unbounded resources.
+//
+// Parameters:
+//   - data: The binary archive container
+//   - password: The password used at encryption time
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error if the container is malformed, uses an unknown algorithm, or the password is wrong
+//
+// Example:
+//
+//	plaintext, err := crypto.ArchiveDecryptWithPassword(archive, []byte(passphrase))
+func ArchiveDecryptWithPassword(data, password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, goerrors.New("EMPTY_PASSWORD", "password cannot be empty")
+	}
+	info, err := parseArchive(data)
+	if err != nil {
+		return nil, err
+	}
+	if info.KDF == nil {
+		return nil, archiveError("archive is not password-protected; use ArchiveDecrypt")
+	}
+	key := argon2.IDKey(password, info.salt, info.KDF.Time, info.kdfMemoryKiB, info.KDF.Threads, KeySize)
+	defer Zeroize(key)
+	return openArchive(info, key)
+}
+
+// InspectArchive returns the metadata of an archive container without decrypting it.
+//
+// The metadata is authenticated only when the archive is decrypted; InspectArchive is
+// intended for inventory and retention tooling and must not be used for security decisions.
+//
+// Parameters:
+//   - data: The binary archive container
+//
+// Returns:
+//   - The archive metadata
+//   - An error wrapping ErrInvalidArchive if the container is malformed
+//
+// Example:
+//
+//	info, err := crypto.InspectArchive(archive)
+//	if err == nil && time.Since(info.Created) > retention {
+//		// eligible for deletion
+//	}
+func InspectArchive(data []byte) (*ArchiveInfo, error) {
+	return parseArchive(data)
+}
+
+// sealArchive builds the container metadata and encrypts plaintext under key.
+func sealArchive(plaintext, key, kdf []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(archiveMagic)
+	buf.WriteByte(archiveVersion)
+
+	var alg [2]byte
+	binary.BigEndian.PutUint16(alg[:], uint16(AlgorithmAES256GCM))
+	writeArchiveRecord(&buf, archiveTagAlgorithm, alg[:])
+
+	var created [8]byte
+	binary.BigEndian.PutUint64(created[:], uint64(time.Now().Unix()))
+	writeArchiveRecord(&buf, archiveTagCreated, created[:])
+
+	if kdf != nil {
+		writeArchiveRecord(&buf, archiveTagKDF, kdf)
+	}
+
+	payloadLen := gcm.NonceSize() + len(plaintext) + gcm.Overhead()
+	var header [archiveRecordHeaderSize]byte
+	header[0] = archiveTagPayload
+	binary.BigEndian.PutUint32(header[1:], uint32(payloadLen))
+	buf.Write(header[:])
+
+	aad := buf.Bytes()
+	out := make([]byte, len(aad), len(aad)+payloadLen)
+	copy(out, aad)
+	return sealAEAD(gcm, out, plaintext, aad)
+}
+
+// writeArchiveRecord appends a single TLV record to buf.
+func writeArchiveRecord(buf *bytes.Buffer, tag byte, value []byte) {
+	var header [archiveRecordHeaderSize]byte
+	header[0] = tag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(value)))
+	buf.Write(header[:])
+	buf.Write(value)
+}
+
+// parseArchive walks the TLV records of a container and validates the known ones.
+func parseArchive(data []byte) (*ArchiveInfo, error) {
+	if len(data) < len(archiveMagic)+1 || string(data[:len(archiveMagic)]) != archiveMagic {
+		return nil, archiveError("missing archive magic")
+	}
+	if v := data[len(archiveMagic)]; v != archiveVersion {
+		return nil, archiveError(fmt.Sprintf("unsupported archive version %d", v))
+	}
+
+	info := &ArchiveInfo{}
+	seen := make(map[byte]bool)
+	off := len(archiveMagic) + 1
+	for {
+		if len(data)-off < archiveRecordHeaderSize {
+			return nil, archiveError("truncated archive record")
+		}
+		tag := data[off]
+		length := binary.BigEndian.Uint32(data[off+1 : off+archiveRecordHeaderSize])
+		start := off + archiveRecordHeaderSize
+		if uint64(length) > uint64(len(data)-start) {
+			return nil, archiveError(fmt.Sprintf("record 0x%02x exceeds container size", tag))
+		}
+		if seen[tag] {
+			return nil, archiveError(fmt.Sprintf("duplicate record 0x%02x", tag))
+		}
+		seen[tag] = true
+		value := data[start : start+int(length)]
+
+		switch tag {
+		case archiveTagAlgorithm:
+			if len(value) != 2 {
+				return nil, archiveError("invalid algorithm record")
+			}
+			info.Algorithm = Algorithm(binary.BigEndian.Uint16(value))
+		case archiveTagCreated:
+			if len(value) != 8 {
+				return nil, archiveError("invalid timestamp record")
+			}
+			info.Created = time.Unix(int64(binary.BigEndian.Uint64(value)), 0).UTC()
+		case archiveTagKDF:
+			if err := info.parseKDF(value); err != nil {
+				return nil, err
+			}
+		case archiveTagPayload:
+			if start+int(length) != len(data) {
+				return nil, archiveError("trailing data after payload")
+			}
+			if !seen[archiveTagAlgorithm] {
+				return nil, archiveError("missing algorithm record")
+			}
+			info.aad = data[:start]
+			info.payload = value
+			return info, nil
+		}
+		off = start + int(length)
+	}
+}
+
+// parseKDF decodes the KDF record, enforcing the same bounds as PHC password hashes.
+func (info *ArchiveInfo) parseKDF(value []byte) error {
+	if len(value) < archiveKDFFixedSize || value[0] != archiveKDFArgon2id {
+		return archiveError("invalid or unsupported KDF record")
+	}
+	t := binary.BigEndian.Uint32(value[1:5])
+	memoryKiB := binary.BigEndian.Uint32(value[5:9])
+	threads := value[9]
+	salt := value[archiveKDFFixedSize:]
+	if t == 0 || t > maxPHCTime || memoryKiB == 0 || uint64(memoryKiB) > maxPHCMemoryKiB || threads == 0 || len(salt) == 0 {
+		return archiveError("KDF parameters out of range")
+	}
+	info.KDF = &KDFParams{Time: t, Memory: memoryKiB / 1024, Threads: threads}
+	info.kdfMemoryKiB = memoryKiB
+	info.salt = salt
+	return nil
+}
+
+// openArchive dispatches decryption on the archive's algorithm identifier.
+func openArchive(info *ArchiveInfo, key []byte) ([]byte, error) {
+	switch info.Algorithm {
+	case AlgorithmAES256GCM:
+		gcm, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		return openAEAD(gcm, info.payload, info.aad)
+	default:
+		richErr := goerrors.New(ErrCodeUnsupportedAlgorithm, fmt.Sprintf("archive uses unknown algorithm id %d", uint16(info.Algorithm)))
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedAlgorithm, richErr)
+	}
+}
+
+// archiveError builds an error wrapping ErrInvalidArchive.
+func archiveError(msg string) error {
+	richErr := goerrors.New(ErrCodeInvalidArchive, msg)
+	return fmt.Errorf("%w: %w", ErrInvalidArchive, richErr)
+}
@@ -0,0 +1,119 @@
+// archive_test.go: Test cases for self-describing archive containers.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestArchiveEncrypt_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := []byte("retained for seven years")
+
+	before := time.Now().Add(-time.Second)
+	archive, err := crypto.ArchiveEncrypt(plaintext, key)
+	if err != nil {
+		t.Fatalf("ArchiveEncrypt() error: %v", err)
+	}
+	decrypted, err := crypto.ArchiveDecrypt(archive, key)
+	if err != nil {
+		t.Fatalf("ArchiveDecrypt() error: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("Round-trip mismatch")
+	}
+
+	info, err := crypto.InspectArchive(archive)
+	if err != nil {
+		t.Fatalf("InspectArchive() error: %v", err)
+	}
+	if info.Algorithm != crypto.AlgorithmAES256GCM || info.KDF != nil {
+		t.Errorf("Unexpected metadata: %+v", info)
+	}
+	if info.Created.Before(before) || info.Created.After(time.Now().Add(time.Second)) {
+		t.Errorf("Unexpected creation time: %v", info.Created)
+	}
+	if info.Algorithm.String() != "AES-256-GCM" {
+		t.Errorf("Unexpected algorithm name: %s", info.Algorithm)
+	}
+}
+
+func TestArchiveEncryptWithPassword_RoundTrip(t *testing.T) {
+	archive, err := crypto.ArchiveEncryptWithPassword([]byte("ledger"), []byte("passphrase"), fastParams)
+	if err != nil {
+		t.Fatalf("ArchiveEncryptWithPassword() error: %v", err)
+	}
+	info, err := crypto.InspectArchive(archive)
+	if err != nil {
+		t.Fatalf("InspectArchive() error: %v", err)
+	}
+	if info.KDF == nil || *info.KDF != *fastParams {
+		t.Errorf("Expected stored KDF params %+v, got %+v", fastParams, info.KDF)
+	}
+
+	decrypted, err := crypto.ArchiveDecryptWithPassword(archive, []byte("passphrase"))
+	if err != nil || string(decrypted) != "ledger" {
+		t.Errorf("Expected round trip, got %q err=%v", decrypted, err)
+	}
+	if _, err := crypto.ArchiveDecryptWithPassword(archive, []byte("wrong")); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for wrong password, got %v", err)
+	}
+
+	key, _ := crypto.GenerateKey()
+	if _, err := crypto.ArchiveDecrypt(archive, key); !errors.Is(err, crypto.ErrInvalidArchive) {
+		t.Errorf("Expected ErrInvalidArchive for password archive with key, got %v", err)
+	}
+	keyed, _ := crypto.ArchiveEncrypt([]byte("x"), key)
+	if _, err := crypto.ArchiveDecryptWithPassword(keyed, []byte("passphrase")); !errors.Is(err, crypto.ErrInvalidArchive) {
+		t.Errorf("Expected ErrInvalidArchive for keyed archive with password, got %v", err)
+	}
+}
+
+func TestArchiveDecrypt_UnknownAlgorithm(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	archive, _ := crypto.ArchiveEncrypt([]byte("future"), key)
+
+	// The algorithm record immediately follows magic and version: tag, length, id.
+	future := append([]byte{}, archive...)
+	future[5+5+1] = 0x99
+	if _, err := crypto.ArchiveDecrypt(future, key); !errors.Is(err, crypto.ErrUnsupportedAlgorithm) {
+		t.Errorf("Expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
+
+func TestArchiveDecrypt_Tampering(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	archive, _ := crypto.ArchiveEncrypt([]byte("immutable"), key)
+
+	// Altering the timestamp must break authentication.
+	modified := append([]byte{}, archive...)
+	modified[5+7+5+7] ^= 0x01
+	if _, err := crypto.ArchiveDecrypt(modified, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for modified metadata, got %v", err)
+	}
+
+	malformed := map[string][]byte{
+		"empty":      nil,
+		"bad magic":  append([]byte("XXXX"), archive[4:]...),
+		"truncated":  archive[:len(archive)-1],
+		"trailing":   append(append([]byte{}, archive...), 0),
+		"no records": archive[:5],
+	}
+	for name, data := range malformed {
+		if _, err := crypto.ArchiveDecrypt(data, key); !errors.Is(err, crypto.ErrInvalidArchive) {
+			t.Errorf("%s: expected ErrInvalidArchive, got %v", name, err)
+		}
+	}
+	if _, err := crypto.ArchiveDecrypt(archive, make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
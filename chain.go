@@ -0,0 +1,163 @@
+// chain.go: Encrypted records chained by their authentication tags.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// chainLabel domain-separates chained records from other envelopes.
+const chainLabel = "go-crypto/v1/chain"
+
+// ChainedEncryptor encrypts a sequence of records, each authenticated together with
+// the tag of the record before it.
+//
+// Every record is an AES-256-GCM envelope (nonce || ciphertext || tag) whose additional
+// data is the previous record's 16-byte tag (nothing for the first record). Since the
+// tag authenticates the whole record, the records form a hash chain: inserting,
+// deleting, reordering or modifying a record breaks verification of the record after
+// it. This makes an encrypted audit log tamper-evident with VerifyChain.
+//
+// Truncation at the tail cannot be detected from the records alone, because a
+// shortened chain is itself valid. Anchor the chain by storing the latest record, or
+// the record count, somewhere the attacker cannot write. A ChainedEncryptor is safe for
+// concurrent use; concurrent Appends are serialized and chained in call order.
+type ChainedEncryptor struct {
+	mu   sync.Mutex
+	aead cipher.AEAD
+	prev []byte
+}
+
+// NewChainedEncryptor creates a ChainedEncryptor that starts a new chain under key.
+//
+// Parameters:
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A ChainedEncryptor positioned at the start of a chain
+//   - An error if the key size is invalid or cipher initialization fails
+//
+// Example:
+//
+//	log, err := crypto.NewChainedEncryptor(key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	record, err := log.Append([]byte(`{"user":"alice","action":"login"}`))
+func NewChainedEncryptor(key []byte) (*ChainedEncryptor, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainedEncryptor{aead: aead}, nil
+}
+
+// Append encrypts plaintext as the next record of the chain.
+//
+// Parameters:
+//   - plaintext: The record to encrypt (can be empty)
+//
+// Returns:
+//   - The base64-encoded record
+//   - An error if nonce generation fails; the chain is not advanced in that case
+func (c *ChainedEncryptor) Append(plaintext []byte) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out, err := sealAEAD(c.aead, nil, plaintext, chainAAD(c.prev))
+	if err != nil {
+		return "", err
+	}
+	c.prev = append(c.prev[:0], out[len(out)-c.aead.Overhead():]...)
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// VerifyChain checks that records form an unbroken chain produced by ChainedEncryptor.
+//
+// Parameters:
+//   - records: The base64-encoded records, in order from the start of the chain
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - nil if every record is authentic and in place
+//   - An error naming the first record that fails, wrapping the underlying error
+//     (e.g. ErrDecrypt for a modified, inserted or missing record)
+//
+// Example:
+//
+//	if err := crypto.VerifyChain(records, key); err != nil {
+//		alert("audit log tampered: ", err)
+//	}
+func VerifyChain(records []string, key []byte) error {
+	return walkChain(records, key, func(_ int, plaintext []byte) {
+		Zeroize(plaintext)
+	})
+}
+
+// DecryptChain verifies records like VerifyChain and returns their plaintexts.
+//
+// No plaintext is returned unless the whole chain verifies.
+//
+// Parameters:
+//   - records: The base64-encoded records, in order from the start of the chain
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - One plaintext per record, at the same index
+//   - An error naming the first record that fails, as for VerifyChain
+//
+// Example:
+//
+//	entries, err := crypto.DecryptChain(records, key)
+func DecryptChain(records []string, key []byte) ([][]byte, error) {
+	out := make([][]byte, len(records))
+	err := walkChain(records, key, func(i int, plaintext []byte) {
+		out[i] = plaintext
+	})
+	if err != nil {
+		for _, plaintext := range out {
+			Zeroize(plaintext)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// walkChain authenticates records in order and passes each plaintext to visit.
+func walkChain(records []string, key []byte, visit func(i int, plaintext []byte)) error {
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	var prev []byte
+	for i, record := range records {
+		data, err := decodeCiphertext(record)
+		if err == nil && len(data) < aead.NonceSize()+aead.Overhead() {
+			richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
+			err = inputError(ErrCiphertextShort, richErr)
+		}
+		var plaintext []byte
+		if err == nil {
+			plaintext, err = openAEAD(aead, data, chainAAD(prev))
+		}
+		if err != nil {
+			return goerrors.Wrap(err, ErrCodeDecrypt, fmt.Sprintf("failed to verify record %d", i))
+		}
+		visit(i, plaintext)
+		prev = data[len(data)-aead.Overhead():]
+	}
+	return nil
+}
+
+// chainAAD returns the additional data of the record following the one tagged prev.
+func chainAAD(prev []byte) []byte {
+	return append([]byte(chainLabel), prev...)
+}
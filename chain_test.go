@@ -0,0 +1,101 @@
+// chain_test.go: Test cases for chained encrypted records.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func buildChain(t *testing.T, key []byte, n int) []string {
+	t.Helper()
+	c, err := crypto.NewChainedEncryptor(key)
+	if err != nil {
+		t.Fatalf("NewChainedEncryptor() error: %v", err)
+	}
+	records := make([]string, n)
+	for i := range records {
+		if records[i], err = c.Append([]byte(fmt.Sprintf("event %d", i))); err != nil {
+			t.Fatalf("Append() error: %v", err)
+		}
+	}
+	return records
+}
+
+func TestChainedEncryptor_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	records := buildChain(t, key, 5)
+	if err := crypto.VerifyChain(records, key); err != nil {
+		t.Fatalf("VerifyChain() error: %v", err)
+	}
+	entries, err := crypto.DecryptChain(records, key)
+	if err != nil {
+		t.Fatalf("DecryptChain() error: %v", err)
+	}
+	for i, entry := range entries {
+		if want := fmt.Sprintf("event %d", i); string(entry) != want {
+			t.Errorf("entry %d = %q, want %q", i, entry, want)
+		}
+	}
+	if err := crypto.VerifyChain(nil, key); err != nil {
+		t.Errorf("VerifyChain(nil) error: %v", err)
+	}
+	// Records are not plain envelopes.
+	if _, err := crypto.DecryptBytes(records[0], key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptBytes() of chained record: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestVerifyChain_Tampering(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	records := buildChain(t, key, 4)
+	other := buildChain(t, key, 4)
+	forged, _ := crypto.EncryptBytes([]byte("forged"), key)
+
+	tests := []struct {
+		name    string
+		records []string
+	}{
+		{"deleted middle", []string{records[0], records[2], records[3]}},
+		{"deleted first", records[1:]},
+		{"swapped", []string{records[0], records[2], records[1], records[3]}},
+		{"inserted", []string{records[0], records[1], forged, records[2], records[3]}},
+		{"from another chain", []string{records[0], other[1], records[2], records[3]}},
+		{"duplicated", []string{records[0], records[1], records[1], records[2]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := crypto.VerifyChain(tt.records, key); !errors.Is(err, crypto.ErrDecrypt) {
+				t.Errorf("VerifyChain() = %v, want ErrDecrypt", err)
+			}
+			if entries, err := crypto.DecryptChain(tt.records, key); err == nil || entries != nil {
+				t.Errorf("DecryptChain() = %v, %v, want error", entries, err)
+			}
+		})
+	}
+
+	wrongKey, _ := crypto.GenerateKey()
+	if err := crypto.VerifyChain(records, wrongKey); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong key: got %v, want ErrDecrypt", err)
+	}
+	if err := crypto.VerifyChain([]string{"AAAA"}, key); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("short record: got %v, want ErrCiphertextShort", err)
+	}
+	// A truncated chain is still valid; callers must anchor the head.
+	if err := crypto.VerifyChain(records[:2], key); err != nil {
+		t.Errorf("truncated chain: VerifyChain() error: %v", err)
+	}
+}
+
+func TestNewChainedEncryptor_InvalidKey(t *testing.T) {
+	if _, err := crypto.NewChainedEncryptor(make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+}
@@ -0,0 +1,371 @@
+// cipher.go: Reusable AES-256-GCM handle with pluggable nonce generation.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// NonceStrategy produces the nonces used by a Cipher.
+//
+// Implementations must never return the same nonce twice for the same key and must be
+// safe for concurrent use, since a Cipher may be shared between goroutines.
+type NonceStrategy interface {
+	// Next returns a fresh nonce of exactly size bytes.
+	Next(size int) ([]byte, error)
+}
+
+// randomNonces draws every nonce from crypto/rand.
+type randomNonces struct{}
+
+// RandomNonces returns the default NonceStrategy, which draws every nonce from crypto/rand.
+//
+// Random 96-bit nonces are safe for any number of concurrent or distributed writers,
+// up to about 2^32 messages per key.
+func RandomNonces() NonceStrategy {
+	return randomNonces{}
+}
+
+// Next implements NonceStrategy.
+func (randomNonces) Next(size int) ([]byte, error) {
+	nonce := make([]byte, size)
+	if _, err := io.ReadFull(nonceReader(), nonce); err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// counterNonces builds nonces as prefix || big-endian counter.
+type counterNonces struct {
+	mu      sync.Mutex
+	prefix  []byte
+	counter uint64
+	spent   bool
+}
+
+// NewCounterNonces returns a NonceStrategy producing nonces of the form
+// prefix || counter, where counter is a big-endian integer filling the remaining bytes
+// and starting at zero.
+//
+// Counter nonces never collide and allow far more than 2^32 messages per key, but only
+// if a single strategy instance is the sole writer for its key (or every writer uses a
+// distinct prefix). Restarting the process with the same key and prefix reuses nonces;
+// use RandomNonces unless that can be ruled out. For AES-GCM the prefix can be at most
+// 4 bytes, leaving 8 bytes for the counter.
+//
+// Parameters:
+//   - prefix: A fixed per-writer field (may be empty)
+//
+// Returns:
+//   - A concurrency-safe counter NonceStrategy
+//
+// Example:
+//
+//	c, err := crypto.NewCipherWithNonceStrategy(key, crypto.NewCounterNonces([]byte{0, 0, 0, 7}))
+func NewCounterNonces(prefix []byte) NonceStrategy {
+	return &counterNonces{prefix: append([]byte(nil), prefix...)}
+}
+
+// Next implements NonceStrategy.
+func (c *counterNonces) Next(size int) ([]byte, error) {
+	if size-len(c.prefix) < 8 {
+		return nil, goerrors.New(ErrCodeNonceGen, fmt.Sprintf("counter nonce prefix of %d bytes leaves less than 8 bytes for the counter", len(c.prefix)))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.spent {
+		return nil, goerrors.New(ErrCodeNonceGen, "nonce counter exhausted")
+	}
+	nonce := make([]byte, size)
+	copy(nonce, c.prefix)
+	binary.BigEndian.PutUint64(nonce[size-8:], c.counter)
+	c.counter++
+	c.spent = c.counter == 0
+	return nonce, nil
+}
+
+// Cipher is a reusable AES-256-GCM handle.
+//
+// It performs the key schedule once, so repeated operations with the same key avoid
+// the per-call setup cost of EncryptBytes and DecryptBytes. Ciphertexts use the same
+// format as EncryptBytes, so both APIs interoperate. A Cipher is safe for concurrent use.
+type Cipher struct {
+	aead   cipher.AEAD
+	nonces NonceStrategy
+	meta   *KeyMetadata
+}
+
+// NewCipher creates a Cipher for key using random nonces.
+//
+// Parameters:
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A ready-to-use Cipher
+//   - An error if the key size is invalid or cipher initialization fails
+//
+// Example:
+//
+//	c, err := crypto.NewCipher(key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	ciphertext, err := c.Encrypt([]byte("message"))
+func NewCipher(key []byte) (*Cipher, error) {
+	return NewCipherWithNonceStrategy(key, nil)
+}
+
+// NewCipherWithNonceStrategy creates a Cipher for key that takes its nonces from strategy.
+//
+// This lets each instance choose the nonce scheme that suits its deployment, random
+// nonces for distributed writers or counter nonces for a single long-lived writer,
+// while keeping the cached GCM handle.
+//
+// Parameters:
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - strategy: The nonce source (nil to use RandomNonces)
+//
+// Returns:
+//   - A ready-to-use Cipher
+//   - An error if the key size is invalid or cipher initialization fails
+//
+// Example:
+//
+//	c, err := crypto.NewCipherWithNonceStrategy(key, crypto.NewCounterNonces(nil))
+func NewCipherWithNonceStrategy(key []byte, strategy NonceStrategy) (*Cipher, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if strategy == nil {
+		strategy = RandomNonces()
+	}
+	return &Cipher{aead: aead, nonces: strategy}, nil
+}
+
+// NewCipherWithMetadata creates a Cipher like NewCipherWithNonceStrategy that records
+// each encryption (Encrypt and EncryptFixed) in meta.
+//
+// Parameters:
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - strategy: The nonce source (nil to use RandomNonces)
+//   - meta: The key's metadata (nil to track a key created now)
+//
+// Returns:
+//   - A ready-to-use Cipher; Metadata returns the tracked metadata
+//   - An error if the key size is invalid or cipher initialization fails
+//
+// Example:
+//
+//	c, err := crypto.NewCipherWithMetadata(key, nil, crypto.NewKeyMetadata(createdAt, ops))
+//	// ...
+//	if c.Metadata().NeedsRotation(30*24*time.Hour, 1<<32) {
+//		rotateKey()
+//	}
+func NewCipherWithMetadata(key []byte, strategy NonceStrategy, meta *KeyMetadata) (*Cipher, error) {
+	c, err := NewCipherWithNonceStrategy(key, strategy)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		meta = NewKeyMetadata(time.Now(), 0)
+	}
+	c.meta = meta
+	return c, nil
+}
+
+// Metadata returns the key metadata the Cipher records its encryptions in, or nil if it
+// was not created with NewCipherWithMetadata.
+func (c *Cipher) Metadata() *KeyMetadata {
+	return c.meta
+}
+
+// Encrypt encrypts plaintext and returns the same base64 format as EncryptBytes.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if nonce generation fails
+func (c *Cipher) Encrypt(plaintext []byte) (string, error) {
+	obs := loadObserver()
+	if obs == nil {
+		return c.encrypt(plaintext)
+	}
+	start := time.Now()
+	ciphertext, err := c.encrypt(plaintext)
+	obs.ObserveEncrypt(time.Since(start), err)
+	return ciphertext, err
+}
+
+// Decrypt authenticates and decrypts a ciphertext produced by Encrypt or EncryptBytes.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error if decoding or authentication fails
+func (c *Cipher) Decrypt(ciphertext string) ([]byte, error) {
+	obs := loadObserver()
+	if obs == nil {
+		return c.decrypt(ciphertext)
+	}
+	start := time.Now()
+	plaintext, err := c.decrypt(ciphertext)
+	observeDecrypt(obs, start, plaintext, err)
+	return plaintext, err
+}
+
+// EncryptFixed appends the binary envelope nonce || ciphertext || tag of plaintext to dst
+// and returns the extended slice.
+//
+// It is the allocation-free counterpart of Encrypt for hot paths that encrypt many
+// small records: no base64 is produced, and when dst has enough spare capacity
+// (len(plaintext) + CiphertextOverhead bytes) the envelope is written in place. With
+// the default random nonces the nonce is generated directly into dst, so no scratch
+// buffer or lock is needed and the method remains safe for concurrent use as long as
+// each goroutine passes its own dst. Custom nonce strategies allocate their nonce as
+// usual. The output is the base64-decoded form of Encrypt's output.
+//
+// Parameters:
+//   - dst: The buffer to append to (may be nil; reuse dst[:0] to avoid allocations)
+//   - plaintext: The byte slice to encrypt (can be empty; must not overlap dst's spare capacity)
+//
+// Returns:
+//   - dst extended with the envelope
+//   - An error if nonce generation fails
+//
+// Example:
+//
+//	buf := make([]byte, 0, len(record)+crypto.CiphertextOverhead)
+//	for _, record := range records {
+//		sealed, err := c.EncryptFixed(buf[:0], record)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		store(sealed) // copy if stored beyond the next iteration
+//	}
+func (c *Cipher) EncryptFixed(dst, plaintext []byte) ([]byte, error) {
+	obs := loadObserver()
+	if obs == nil {
+		return c.encryptFixed(dst, plaintext)
+	}
+	start := time.Now()
+	out, err := c.encryptFixed(dst, plaintext)
+	obs.ObserveEncrypt(time.Since(start), err)
+	return out, err
+}
+
+// DecryptFixed authenticates a binary envelope produced by EncryptFixed and appends the
+// plaintext to dst.
+//
+// Parameters:
+//   - dst: The buffer to append to (may be nil)
+//   - sealed: The binary envelope (nonce || ciphertext || tag)
+//
+// Returns:
+//   - dst extended with the plaintext
+//   - An error if the envelope is too short or authentication fails
+//
+// Example:
+//
+//	plaintext, err := c.DecryptFixed(buf[:0], sealed)
+func (c *Cipher) DecryptFixed(dst, sealed []byte) ([]byte, error) {
+	obs := loadObserver()
+	if obs == nil {
+		return c.decryptFixed(dst, sealed)
+	}
+	start := time.Now()
+	out, err := c.decryptFixed(dst, sealed)
+	var plaintext []byte
+	if err == nil {
+		plaintext = out[len(dst):] // only the plaintext, not the caller's prefix
+	}
+	observeDecrypt(obs, start, plaintext, err)
+	return out, err
+}
+
+// decryptFixed implements DecryptFixed without instrumentation.
+func (c *Cipher) decryptFixed(dst, sealed []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(sealed) < size+c.aead.Overhead() {
+		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
+		return nil, inputError(ErrCiphertextShort, richErr)
+	}
+	out, err := c.aead.Open(dst, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeDecrypt, "failed to decrypt")
+		return nil, inputError(ErrDecrypt, richErr)
+	}
+	return out, nil
+}
+
+// encryptFixed implements EncryptFixed without instrumentation.
+func (c *Cipher) encryptFixed(dst, plaintext []byte) ([]byte, error) {
+	if c.meta != nil {
+		c.meta.RecordOperation()
+	}
+	if _, ok := c.nonces.(randomNonces); !ok {
+		return c.seal(dst, plaintext, nil)
+	}
+	size := c.aead.NonceSize()
+	n := len(dst)
+	dst = slices.Grow(dst, size+len(plaintext)+c.aead.Overhead())[:n+size]
+	nonce := dst[n:]
+	if _, err := io.ReadFull(nonceReader(), nonce); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate nonce")
+		return nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	return c.aead.Seal(dst, nonce, plaintext, nil), nil
+}
+
+// encrypt implements Encrypt without instrumentation.
+func (c *Cipher) encrypt(plaintext []byte) (string, error) {
+	if c.meta != nil {
+		c.meta.RecordOperation()
+	}
+	sealed, err := c.seal(nil, plaintext, nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt implements Decrypt without instrumentation.
+func (c *Cipher) decrypt(ciphertext string) ([]byte, error) {
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return openAEAD(c.aead, data, nil)
+}
+
+// seal appends nonce || ciphertext || tag to dst using a nonce from the strategy.
+func (c *Cipher) seal(dst, plaintext, aad []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	nonce, err := c.nonces.Next(size)
+	if err == nil && len(nonce) != size {
+		err = goerrors.New(ErrCodeNonceGen, fmt.Sprintf("nonce strategy returned %d bytes, want %d", len(nonce), size))
+	}
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate nonce")
+		return nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	dst = append(dst, nonce...)
+	return c.aead.Seal(dst, nonce, plaintext, aad), nil
+}
@@ -0,0 +1,189 @@
+// cipher_test.go: Test cases for the reusable Cipher handle and nonce strategies.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestCipher_RoundTripAndInterop(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	c, err := crypto.NewCipher(key)
+	if err != nil {
+		t.Fatalf("NewCipher() error: %v", err)
+	}
+	ciphertext, err := c.Encrypt([]byte("handle"))
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if plaintext, err := crypto.DecryptBytes(ciphertext, key); err != nil || string(plaintext) != "handle" {
+		t.Errorf("Expected DecryptBytes to open Cipher output, got %q err=%v", plaintext, err)
+	}
+	legacy, _ := crypto.EncryptBytes([]byte("legacy"), key)
+	if plaintext, err := c.Decrypt(legacy); err != nil || string(plaintext) != "legacy" {
+		t.Errorf("Expected Cipher to open EncryptBytes output, got %q err=%v", plaintext, err)
+	}
+	if _, err := c.Decrypt(""); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("Expected ErrEmptyPlaintext, got %v", err)
+	}
+	if _, err := crypto.NewCipher(make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
+
+func TestCipher_CounterNonces(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	prefix := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+	c, err := crypto.NewCipherWithNonceStrategy(key, crypto.NewCounterNonces(prefix))
+	if err != nil {
+		t.Fatalf("NewCipherWithNonceStrategy() error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				ciphertext, err := c.Encrypt([]byte("x"))
+				if err != nil {
+					t.Errorf("Encrypt() error: %v", err)
+					return
+				}
+				raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+				mu.Lock()
+				seen[string(raw[:12])] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 400 {
+		t.Errorf("Expected 400 unique nonces, got %d", len(seen))
+	}
+	for nonce := range seen {
+		if !bytes.HasPrefix([]byte(nonce), prefix) {
+			t.Fatalf("Expected nonce prefix %x, got %x", prefix, nonce)
+		}
+	}
+
+	first, _ := crypto.NewCounterNonces(nil).Next(12)
+	if !bytes.Equal(first, make([]byte, 12)) {
+		t.Errorf("Expected counter to start at zero, got %x", first)
+	}
+}
+
+type badStrategy struct{ size int }
+
+func (b badStrategy) Next(int) ([]byte, error) { return make([]byte, b.size), nil }
+
+func TestCipher_InvalidStrategy(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	c, _ := crypto.NewCipherWithNonceStrategy(key, badStrategy{size: 8})
+	if _, err := c.Encrypt([]byte("x")); !errors.Is(err, crypto.ErrNonceGen) {
+		t.Errorf("Expected ErrNonceGen for wrong nonce size, got %v", err)
+	}
+	c, _ = crypto.NewCipherWithNonceStrategy(key, crypto.NewCounterNonces(make([]byte, 5)))
+	if _, err := c.Encrypt([]byte("x")); !errors.Is(err, crypto.ErrNonceGen) {
+		t.Errorf("Expected ErrNonceGen for oversized prefix, got %v", err)
+	}
+}
+
+func TestCipher_EncryptFixed(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	c, _ := crypto.NewCipher(key)
+	record := []byte("fixed-size record")
+
+	buf := make([]byte, 0, len(record)+crypto.CiphertextOverhead)
+	sealed, err := c.EncryptFixed(buf, record)
+	if err != nil {
+		t.Fatalf("EncryptFixed() error: %v", err)
+	}
+	if len(sealed) != len(record)+crypto.CiphertextOverhead || &sealed[0] != &buf[:1][0] {
+		t.Errorf("EncryptFixed() did not seal in place: %d bytes", len(sealed))
+	}
+	plaintext, err := c.DecryptFixed(nil, sealed)
+	if err != nil || !bytes.Equal(plaintext, record) {
+		t.Fatalf("DecryptFixed() = %q, %v", plaintext, err)
+	}
+
+	// The binary envelope is the decoded form of Encrypt's output.
+	if plaintext, err := c.Decrypt(base64.StdEncoding.EncodeToString(sealed)); err != nil || !bytes.Equal(plaintext, record) {
+		t.Errorf("Decrypt() of encoded EncryptFixed output = %q, %v", plaintext, err)
+	}
+	// Appending preserves the existing prefix.
+	out, _ := c.EncryptFixed([]byte("hdr:"), record)
+	if !bytes.HasPrefix(out, []byte("hdr:")) {
+		t.Errorf("EncryptFixed() overwrote dst prefix: %q", out[:4])
+	}
+	if p, err := c.DecryptFixed(nil, out[4:]); err != nil || !bytes.Equal(p, record) {
+		t.Errorf("DecryptFixed() after prefix = %q, %v", p, err)
+	}
+
+	// Custom strategies go through the strategy.
+	cc, _ := crypto.NewCipherWithNonceStrategy(key, crypto.NewCounterNonces(nil))
+	first, _ := cc.EncryptFixed(nil, record)
+	if !bytes.Equal(first[:12], make([]byte, 12)) {
+		t.Errorf("counter strategy nonce = %x, want zero", first[:12])
+	}
+
+	sealed[len(sealed)-1] ^= 1
+	if _, err := c.DecryptFixed(nil, sealed); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("tampered: got %v, want ErrDecrypt", err)
+	}
+	if _, err := c.DecryptFixed(nil, sealed[:10]); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("short: got %v, want ErrCiphertextShort", err)
+	}
+}
+
+func TestCipher_EncryptFixedAllocs(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	c, _ := crypto.NewCipher(key)
+	record := make([]byte, 64)
+	buf := make([]byte, 0, len(record)+crypto.CiphertextOverhead)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := c.EncryptFixed(buf[:0], record); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("EncryptFixed() allocates %.1f times per call, want 0", allocs)
+	}
+}
+
+func BenchmarkCipher_Encrypt(b *testing.B) {
+	key, _ := crypto.GenerateKey()
+	c, _ := crypto.NewCipher(key)
+	record := make([]byte, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Encrypt(record); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCipher_EncryptFixed(b *testing.B) {
+	key, _ := crypto.GenerateKey()
+	c, _ := crypto.NewCipher(key)
+	record := make([]byte, 64)
+	buf := make([]byte, 0, len(record)+crypto.CiphertextOverhead)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.EncryptFixed(buf[:0], record); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
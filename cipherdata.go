@@ -0,0 +1,212 @@
+// cipherdata.go: Structured ciphertext for binary serialization frameworks.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// CipherData holds the components of an AES-256-GCM ciphertext as separate fields.
+//
+// It is intended for binary protocols (protobuf, msgpack, CBOR, ...) where the
+// base64 string returned by EncryptBytes would add size and require manual slicing.
+// The fields carry the same bytes as the EncryptBytes format, which is simply
+// Nonce || Ciphertext || Tag.
+type CipherData struct {
+	// Nonce is the 12-byte GCM nonce.
+	Nonce []byte `json:"nonce"`
+
+	// Ciphertext is the encrypted data, the same length as the plaintext.
+	Ciphertext []byte `json:"ciphertext"`
+
+	// Tag is the 16-byte GCM authentication tag.
+	Tag []byte `json:"tag"`
+}
+
+// EncryptToStruct encrypts a plaintext using AES-256-GCM and returns the result as a CipherData.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A CipherData with the nonce, ciphertext and tag in separate fields
+//   - An error if encryption fails
+//
+// Example:
+//
+//	cd, err := crypto.EncryptToStruct([]byte("payload"), key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	msg := &pb.Encrypted{Nonce: cd.Nonce, Ciphertext: cd.Ciphertext, Tag: cd.Tag}
+func EncryptToStruct(plaintext, key []byte) (*CipherData, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealAEAD(gcm, nil, plaintext, nil)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize, tagStart := gcm.NonceSize(), len(sealed)-gcm.Overhead()
+	return &CipherData{
+		Nonce:      sealed[:nonceSize:nonceSize],
+		Ciphertext: sealed[nonceSize:tagStart:tagStart],
+		Tag:        sealed[tagStart:],
+	}, nil
+}
+
+// DecryptFromStruct authenticates and decrypts a CipherData produced by EncryptToStruct.
+//
+// Parameters:
+//   - data: The structured ciphertext
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if the fields have invalid sizes or authentication fails
+//
+// Example:
+//
+//	cd := &crypto.CipherData{Nonce: msg.Nonce, Ciphertext: msg.Ciphertext, Tag: msg.Tag}
+//	plaintext, err := crypto.DecryptFromStruct(cd, key)
+func DecryptFromStruct(data *CipherData, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		richErr := goerrors.New(ErrCodeEmptyPlain, "cipher data cannot be nil")
+		return nil, fmt.Errorf("%w: %w", ErrEmptyPlaintext, richErr)
+	}
+	if len(data.Nonce) != gcm.NonceSize() || len(data.Tag) != gcm.Overhead() {
+		richErr := goerrors.New(ErrCodeCipherShort, fmt.Sprintf("invalid nonce or tag size (nonce %d, tag %d)", len(data.Nonce), len(data.Tag)))
+		return nil, inputError(ErrCiphertextShort, richErr)
+	}
+	sealed := make([]byte, 0, len(data.Nonce)+len(data.Ciphertext)+len(data.Tag))
+	sealed = append(sealed, data.Nonce...)
+	sealed = append(sealed, data.Ciphertext...)
+	sealed = append(sealed, data.Tag...)
+	return openAEAD(gcm, sealed, nil)
+}
+
+// MaxCipherDataSize is the largest ciphertext accepted by CipherData.ReadFrom.
+// It bounds the memory a malicious length prefix can make the reader allocate.
+const MaxCipherDataSize = 64 << 20
+
+// cipherDataHeaderSize is the size of the binary header written by CipherData.WriteTo:
+// nonce length (1 byte) || tag length (1 byte) || ciphertext length (uint32 big-endian).
+const cipherDataHeaderSize = 6
+
+// cipherDataReadChunk caps how much memory is committed ahead of data actually arriving.
+const cipherDataReadChunk = 64 << 10
+
+// ErrCipherDataTooLarge is returned when a CipherData field exceeds the binary format limits.
+var ErrCipherDataTooLarge = errors.New("crypto: cipher data too large")
+
+// ErrCodeCipherDataTooLarge is the error code for oversized CipherData fields.
+const ErrCodeCipherDataTooLarge = "CRYPTO_CIPHER_DATA_TOO_LARGE"
+
+// WriteTo writes the CipherData to w in a compact length-prefixed binary layout.
+//
+// The layout is nonce length (1 byte) || tag length (1 byte) || ciphertext length
+// (uint32 big-endian) || nonce || ciphertext || tag. The fields are handed to w without
+// being copied into an intermediate buffer; on network connections they are sent
+// with a single vectored write where the platform supports it.
+//
+// WriteTo implements io.WriterTo.
+//
+// Example:
+//
+//	cd, _ := crypto.EncryptToStruct(payload, key)
+//	if _, err := cd.WriteTo(conn); err != nil {
+//		log.Fatal(err)
+//	}
+func (c *CipherData) WriteTo(w io.Writer) (int64, error) {
+	if len(c.Nonce) > 0xff || len(c.Tag) > 0xff || len(c.Ciphertext) > MaxCipherDataSize {
+		richErr := goerrors.New(ErrCodeCipherDataTooLarge, fmt.Sprintf("cipher data exceeds format limits (nonce %d, tag %d, ciphertext %d)", len(c.Nonce), len(c.Tag), len(c.Ciphertext)))
+		return 0, fmt.Errorf("%w: %w", ErrCipherDataTooLarge, richErr)
+	}
+	var header [cipherDataHeaderSize]byte
+	header[0] = byte(len(c.Nonce))
+	header[1] = byte(len(c.Tag))
+	binary.BigEndian.PutUint32(header[2:], uint32(len(c.Ciphertext)))
+	bufs := net.Buffers{header[:], c.Nonce, c.Ciphertext, c.Tag}
+	return bufs.WriteTo(w)
+}
+
+// ReadFrom reads exactly one CipherData written by WriteTo from r, replacing the
+// receiver's fields.
+//
+// Unlike most io.ReaderFrom implementations it does not read until EOF, so several
+// records can be read back to back from a connection. It returns io.EOF if r is
+// exhausted before the first byte, io.ErrUnexpectedEOF if a record is cut short, and
+// an error wrapping ErrCipherDataTooLarge if the ciphertext length exceeds
+// MaxCipherDataSize. Memory is committed incrementally as data arrives, so a forged
+// length prefix cannot trigger a large allocation up front.
+//
+// ReadFrom implements io.ReaderFrom.
+//
+// Example:
+//
+//	var cd crypto.CipherData
+//	if _, err := cd.ReadFrom(conn); err != nil {
+//		log.Fatal(err)
+//	}
+//	plaintext, err := crypto.DecryptFromStruct(&cd, key)
+func (c *CipherData) ReadFrom(r io.Reader) (int64, error) {
+	var header [cipherDataHeaderSize]byte
+	n, err := io.ReadFull(r, header[:])
+	read := int64(n)
+	if err != nil {
+		return read, err
+	}
+	nonceLen, tagLen := int(header[0]), int(header[1])
+	ctLen := binary.BigEndian.Uint32(header[2:])
+	if ctLen > MaxCipherDataSize {
+		richErr := goerrors.New(ErrCodeCipherDataTooLarge, fmt.Sprintf("ciphertext length %d exceeds limit %d", ctLen, MaxCipherDataSize))
+		return read, fmt.Errorf("%w: %w", ErrCipherDataTooLarge, richErr)
+	}
+
+	body, n, err := readBounded(r, nonceLen+int(ctLen)+tagLen)
+	read += int64(n)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return read, err
+	}
+	ctEnd := nonceLen + int(ctLen)
+	c.Nonce = body[:nonceLen:nonceLen]
+	c.Ciphertext = body[nonceLen:ctEnd:ctEnd]
+	c.Tag = body[ctEnd:]
+	return read, nil
+}
+
+// readBounded reads exactly size bytes from r, growing the buffer in steps of at most
+// cipherDataReadChunk so the allocation tracks the bytes actually received.
+func readBounded(r io.Reader, size int) ([]byte, int, error) {
+	buf := make([]byte, 0, min(size, cipherDataReadChunk))
+	for len(buf) < size {
+		step := min(size-len(buf), cipherDataReadChunk)
+		buf = slices.Grow(buf, step)
+		n, err := io.ReadFull(r, buf[len(buf):len(buf)+step])
+		buf = buf[:len(buf)+n]
+		if err != nil {
+			return nil, len(buf), err
+		}
+	}
+	return buf, len(buf), nil
+}
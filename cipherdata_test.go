@@ -0,0 +1,124 @@
+// cipherdata_test.go: Test cases for structured ciphertext.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptToStruct_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := []byte("structured payload")
+
+	cd, err := crypto.EncryptToStruct(plaintext, key)
+	if err != nil {
+		t.Fatalf("EncryptToStruct() error: %v", err)
+	}
+	if len(cd.Nonce) != 12 || len(cd.Tag) != 16 || len(cd.Ciphertext) != len(plaintext) {
+		t.Errorf("Unexpected field sizes: nonce %d, ciphertext %d, tag %d", len(cd.Nonce), len(cd.Ciphertext), len(cd.Tag))
+	}
+	decrypted, err := crypto.DecryptFromStruct(cd, key)
+	if err != nil {
+		t.Fatalf("DecryptFromStruct() error: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("Round-trip mismatch")
+	}
+
+	// The fields are compatible with the base64 envelope format.
+	joined := append(append(append([]byte{}, cd.Nonce...), cd.Ciphertext...), cd.Tag...)
+	viaString, err := crypto.DecryptBytes(base64.StdEncoding.EncodeToString(joined), key)
+	if err != nil || !bytes.Equal(viaString, plaintext) {
+		t.Errorf("Expected struct fields to match the EncryptBytes layout, got err=%v", err)
+	}
+}
+
+func TestDecryptFromStruct_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	cd, _ := crypto.EncryptToStruct([]byte("payload"), key)
+
+	if _, err := crypto.DecryptFromStruct(nil, key); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("Expected ErrEmptyPlaintext for nil data, got %v", err)
+	}
+	if _, err := crypto.DecryptFromStruct(&crypto.CipherData{Nonce: cd.Nonce[:8], Ciphertext: cd.Ciphertext, Tag: cd.Tag}, key); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort for short nonce, got %v", err)
+	}
+	if _, err := crypto.DecryptFromStruct(&crypto.CipherData{Nonce: cd.Nonce, Ciphertext: cd.Ciphertext}, key); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort for missing tag, got %v", err)
+	}
+	tampered := &crypto.CipherData{Nonce: cd.Nonce, Ciphertext: append([]byte{}, cd.Ciphertext...), Tag: cd.Tag}
+	tampered.Ciphertext[0] ^= 1
+	if _, err := crypto.DecryptFromStruct(tampered, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for tampered ciphertext, got %v", err)
+	}
+	if _, err := crypto.EncryptToStruct([]byte("x"), nil); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
+
+func TestCipherData_WriteToReadFrom(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	var wire bytes.Buffer
+	messages := [][]byte{[]byte("first"), {}, bytes.Repeat([]byte("z"), 100000)}
+	for _, msg := range messages {
+		cd, _ := crypto.EncryptToStruct(msg, key)
+		n, err := cd.WriteTo(&wire)
+		if err != nil {
+			t.Fatalf("WriteTo() error: %v", err)
+		}
+		if n != int64(6+12+len(msg)+16) {
+			t.Errorf("Unexpected WriteTo count %d for %d-byte message", n, len(msg))
+		}
+	}
+
+	for _, msg := range messages {
+		var cd crypto.CipherData
+		if _, err := cd.ReadFrom(&wire); err != nil {
+			t.Fatalf("ReadFrom() error: %v", err)
+		}
+		decrypted, err := crypto.DecryptFromStruct(&cd, key)
+		if err != nil || !bytes.Equal(decrypted, msg) {
+			t.Errorf("Expected round trip for %d-byte message, got err=%v", len(msg), err)
+		}
+	}
+	var cd crypto.CipherData
+	if _, err := cd.ReadFrom(&wire); err != io.EOF {
+		t.Errorf("Expected io.EOF after the last record, got %v", err)
+	}
+}
+
+func TestCipherData_ReadFromMalicious(t *testing.T) {
+	// A forged length prefix larger than the limit is rejected before reading.
+	header := []byte{12, 16, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[2:], crypto.MaxCipherDataSize+1)
+	var cd crypto.CipherData
+	if _, err := cd.ReadFrom(bytes.NewReader(header)); !errors.Is(err, crypto.ErrCipherDataTooLarge) {
+		t.Errorf("Expected ErrCipherDataTooLarge, got %v", err)
+	}
+
+	// A large but allowed prefix with little data fails without hanging or panicking.
+	binary.BigEndian.PutUint32(header[2:], crypto.MaxCipherDataSize)
+	stream := append(append([]byte{}, header...), make([]byte, 100)...)
+	if _, err := cd.ReadFrom(bytes.NewReader(stream)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF for truncated body, got %v", err)
+	}
+	if _, err := cd.ReadFrom(bytes.NewReader(header[:3])); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF for truncated header, got %v", err)
+	}
+
+	oversized := &crypto.CipherData{Nonce: make([]byte, 256)}
+	if _, err := oversized.WriteTo(io.Discard); !errors.Is(err, crypto.ErrCipherDataTooLarge) {
+		t.Errorf("Expected ErrCipherDataTooLarge from WriteTo, got %v", err)
+	}
+}
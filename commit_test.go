@@ -0,0 +1,92 @@
+// commit_test.go: Test cases for key-committing encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptCommitting_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, plaintext := range [][]byte{nil, []byte("attack at dawn"), bytes.Repeat([]byte{7}, 5000)} {
+		ciphertext, err := crypto.EncryptCommitting(plaintext, key)
+		if err != nil {
+			t.Fatalf("EncryptCommitting() error: %v", err)
+		}
+		data, _ := base64.StdEncoding.DecodeString(ciphertext)
+		if want := len(plaintext) + 48 + crypto.CiphertextOverhead; len(data) != want {
+			t.Errorf("Ciphertext is %d bytes, want %d", len(data), want)
+		}
+		got, err := crypto.DecryptCommitting(ciphertext, key)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("DecryptCommitting() = %q, %v", got, err)
+		}
+	}
+
+	c1, _ := crypto.EncryptCommitting([]byte("x"), key)
+	c2, _ := crypto.EncryptCommitting([]byte("x"), key)
+	d1, _ := base64.StdEncoding.DecodeString(c1)
+	d2, _ := base64.StdEncoding.DecodeString(c2)
+	if bytes.Equal(d1[:48], d2[:48]) {
+		t.Error("Expected per-message salts and commitments")
+	}
+}
+
+func TestDecryptCommitting_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptCommitting([]byte("attack at dawn"), key)
+
+	other, _ := crypto.GenerateKey()
+	if _, err := crypto.DecryptCommitting(ciphertext, other); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong key: got %v, want ErrDecrypt", err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	for _, i := range []int{0, 20, len(data) - 1} {
+		tampered := append([]byte(nil), data...)
+		tampered[i] ^= 1
+		if _, err := crypto.DecryptCommitting(base64.StdEncoding.EncodeToString(tampered), key); !errors.Is(err, crypto.ErrDecrypt) {
+			t.Errorf("byte %d tampered: got %v, want ErrDecrypt", i, err)
+		}
+	}
+	if _, err := crypto.DecryptCommitting(base64.StdEncoding.EncodeToString(data[:40]), key); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("short ciphertext: got %v, want ErrCiphertextShort", err)
+	}
+	plain, _ := crypto.EncryptBytes(bytes.Repeat([]byte{1}, 64), key)
+	if _, err := crypto.DecryptCommitting(plain, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("EncryptBytes ciphertext: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.DecryptBytes(ciphertext, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptBytes of committing ciphertext: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.EncryptCommitting([]byte("x"), key[:16]); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+}
+
+func TestDecryptCommitting_StrictErrors(t *testing.T) {
+	crypto.SetStrictErrors(true)
+	t.Cleanup(func() { crypto.SetStrictErrors(false) })
+
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptCommitting([]byte("attack at dawn"), key)
+	other, _ := crypto.GenerateKey()
+	_, wrongKey := crypto.DecryptCommitting(ciphertext, other)
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	data[len(data)-1] ^= 1
+	_, tampered := crypto.DecryptCommitting(base64.StdEncoding.EncodeToString(data), key)
+	if !errors.Is(wrongKey, crypto.ErrDecrypt) || !errors.Is(tampered, crypto.ErrDecrypt) {
+		t.Fatalf("got %v and %v, want ErrDecrypt", wrongKey, tampered)
+	}
+	if wrongKey.Error() != tampered.Error() {
+		t.Errorf("strict errors distinguish a wrong key (%q) from a tampered tag (%q)", wrongKey, tampered)
+	}
+}
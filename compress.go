@@ -0,0 +1,269 @@
+// compress.go: Compress-then-encrypt with bounded decompression.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// DefaultMaxDecompressedSize is the decompressed size limit applied by DecryptCompressed (64 MiB).
+const DefaultMaxDecompressedSize = 64 << 20
+
+// compressedLabel domain-separates compressed envelopes from other authenticated headers.
+const compressedLabel = "go-crypto/v1/gzip"
+
+// compressedPaddedLabel domain-separates padded compressed envelopes.
+const compressedPaddedLabel = "go-crypto/v1/gzip-padded"
+
+// MaxPaddingBucketSize is the largest bucket size accepted by EncryptCompressedPadded (1 MiB).
+const MaxPaddingBucketSize = 1 << 20
+
+// ErrDecompressedTooLarge is returned when a compressed plaintext inflates beyond the allowed size.
+var ErrDecompressedTooLarge = errors.New("crypto: decompressed data exceeds size limit")
+
+// ErrCodeDecompressedTooLarge is the error code for oversized decompressed data.
+const ErrCodeDecompressedTooLarge = "CRYPTO_DECOMPRESSED_TOO_LARGE"
+
+// EncryptCompressed gzip-compresses plaintext and encrypts the result with AES-256-GCM.
+//
+// Compression before encryption saves space for text-like data, but the ciphertext
+// length then depends on the content. If an attacker can mix their own input with a
+// secret in the same plaintext, the length leaks information about the secret (as in
+// the CRIME and BREACH attacks). Do not use it for such data.
+//
+// The output is only accepted by DecryptCompressed and DecryptCompressedLimited.
+//
+// Parameters:
+//   - plaintext: The byte slice to compress and encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if compression or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptCompressed(jsonDocument, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptCompressed(plaintext, key []byte) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	compressed, err := compress(plaintext)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(compressed)
+
+	out, err := sealWithHeader(key, compressedLabel, nil, compressed)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// EncryptCompressedPadded gzip-compresses plaintext, pads the compressed data to a
+// multiple of bucketSize and encrypts the result with AES-256-GCM.
+//
+// With EncryptCompressed the ciphertext length reveals the compressed size, and with it
+// the compression ratio, which is what CRIME and BREACH style attacks measure. Padding
+// rounds the size up to the next bucket, so ciphertexts of plaintexts that compress to
+// sizes within the same bucket cannot be told apart. This narrows the leak rather than
+// removing it: an attacker who can inject input next to a secret can still push the
+// compressed size across bucket boundaries and observe the step, given enough queries.
+// Larger buckets make that costlier at the price of space. The padding is the ISO/IEC
+// 7816-4 scheme (0x80 then zeros), always at least one byte, and is authenticated and
+// checked on decryption.
+//
+// The output is only accepted by DecryptCompressedPadded.
+//
+// Parameters:
+//   - plaintext: The byte slice to compress and encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - bucketSize: The padding granularity in bytes (between 1 and MaxPaddingBucketSize)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if bucketSize is out of range or compression or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptCompressedPadded(jsonDocument, key, 256)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptCompressedPadded(plaintext, key []byte, bucketSize int) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	if bucketSize < 1 || bucketSize > MaxPaddingBucketSize {
+		return "", goerrors.New("INVALID_BUCKET_SIZE", fmt.Sprintf("bucket size must be between 1 and %d (got %d)", MaxPaddingBucketSize, bucketSize))
+	}
+	compressed, err := compress(plaintext)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(compressed)
+
+	padded := make([]byte, (len(compressed)/bucketSize+1)*bucketSize)
+	defer Zeroize(padded)
+	n := copy(padded, compressed)
+	padded[n] = 0x80
+
+	out, err := sealWithHeader(key, compressedPaddedLabel, nil, padded)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// DecryptCompressedPadded decrypts a ciphertext produced by EncryptCompressedPadded,
+// strips the padding and decompresses the result, limiting the decompressed size to
+// DefaultMaxDecompressedSize.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decompressed plaintext
+//   - An error if decryption fails, the padding is malformed, decompression fails or the
+//     size limit is exceeded
+//
+// Example:
+//
+//	document, err := crypto.DecryptCompressedPadded(ciphertext, key)
+func DecryptCompressedPadded(encryptedText string, key []byte) ([]byte, error) {
+	data, err := decodeCiphertext(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+	_, padded, err := openWithHeader(key, compressedPaddedLabel, data, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(padded)
+
+	end := len(padded) - 1
+	for end >= 0 && padded[end] == 0 {
+		end--
+	}
+	if end < 0 || padded[end] != 0x80 {
+		return nil, goerrors.New("DECOMPRESS_ERROR", "invalid padding")
+	}
+	return decompressLimited(padded[:end], DefaultMaxDecompressedSize)
+}
+
+// DecryptCompressed decrypts and decompresses a ciphertext produced by EncryptCompressed,
+// limiting the decompressed size to DefaultMaxDecompressedSize.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decompressed plaintext
+//   - An error if decryption or decompression fails or the size limit is exceeded
+//
+// Example:
+//
+//	document, err := crypto.DecryptCompressed(ciphertext, key)
+func DecryptCompressed(encryptedText string, key []byte) ([]byte, error) {
+	return DecryptCompressedLimited(encryptedText, key, DefaultMaxDecompressedSize)
+}
+
+// DecryptCompressedLimited decrypts and decompresses a ciphertext produced by
+// EncryptCompressed, refusing to inflate more than maxSize bytes.
+//
+// Decompression stops as soon as the output would exceed maxSize, so a small crafted
+// blob cannot expand into gigabytes of memory. The ciphertext is authenticated before
+// decompression, so only a holder of the key can produce such a blob; the limit matters
+// where keys are chosen by untrusted parties, e.g. per-tenant keys in a multi-tenant
+// system.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//   - maxSize: The maximum decompressed size in bytes (must not be negative)
+//
+// Returns:
+//   - The decompressed plaintext
+//   - An error wrapping ErrDecompressedTooLarge if the limit is exceeded, or another
+//     error if decryption or decompression fails
+//
+// Example:
+//
+//	document, err := crypto.DecryptCompressedLimited(ciphertext, tenantKey, 1<<20)
+//	if errors.Is(err, crypto.ErrDecompressedTooLarge) {
+//		// reject the upload
+//	}
+func DecryptCompressedLimited(encryptedText string, key []byte, maxSize int64) ([]byte, error) {
+	if maxSize < 0 {
+		return nil, goerrors.New("INVALID_LIMIT", "maximum decompressed size cannot be negative")
+	}
+	data, err := decodeCiphertext(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+	_, compressed, err := openWithHeader(key, compressedLabel, data, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(compressed)
+
+	return decompressLimited(compressed, maxSize)
+}
+
+// decompressLimited inflates gzip data, refusing to produce more than maxSize bytes.
+func decompressLimited(compressed []byte, maxSize int64) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, goerrors.Wrap(err, "DECOMPRESS_ERROR", "failed to decompress plaintext")
+	}
+	// Read one byte past the limit to tell "exactly maxSize" from "more".
+	limit := maxSize
+	if limit < math.MaxInt64 {
+		limit++
+	}
+	var out wipingBuffer
+	n, err := io.Copy(&out, io.LimitReader(zr, limit))
+	if err != nil {
+		Zeroize(out.Bytes())
+		return nil, goerrors.Wrap(err, "DECOMPRESS_ERROR", "failed to decompress plaintext")
+	}
+	if n > maxSize {
+		Zeroize(out.Bytes())
+		richErr := goerrors.New(ErrCodeDecompressedTooLarge, fmt.Sprintf("decompressed data exceeds %d bytes", maxSize))
+		return nil, fmt.Errorf("%w: %w", ErrDecompressedTooLarge, richErr)
+	}
+	return out.Bytes(), nil
+}
+
+// compress gzip-compresses plaintext.
+func compress(plaintext []byte) ([]byte, error) {
+	var buf wipingBuffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(plaintext); err != nil {
+		Zeroize(buf.Bytes())
+		return nil, goerrors.Wrap(err, "COMPRESS_ERROR", "failed to compress plaintext")
+	}
+	if err := zw.Close(); err != nil {
+		Zeroize(buf.Bytes())
+		return nil, goerrors.Wrap(err, "COMPRESS_ERROR", "failed to compress plaintext")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,126 @@
+// compress_test.go: Test cases for compress-then-encrypt.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptCompressed_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, plaintext := range [][]byte{nil, []byte("x"), bytes.Repeat([]byte("compressible "), 10000)} {
+		ciphertext, err := crypto.EncryptCompressed(plaintext, key)
+		if err != nil {
+			t.Fatalf("EncryptCompressed() error: %v", err)
+		}
+		got, err := crypto.DecryptCompressed(ciphertext, key)
+		if err != nil {
+			t.Fatalf("DecryptCompressed() error: %v", err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("round trip of %d bytes returned %d bytes", len(plaintext), len(got))
+		}
+	}
+}
+
+func TestEncryptCompressed_Compresses(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("a"), 100000)
+	ciphertext, _ := crypto.EncryptCompressed(plaintext, key)
+	if len(ciphertext) > 1000 {
+		t.Errorf("ciphertext of 100000 identical bytes is %d bytes, want compression", len(ciphertext))
+	}
+}
+
+func TestDecryptCompressedLimited(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	// A small ciphertext that inflates to 10 MiB.
+	bomb, _ := crypto.EncryptCompressed(make([]byte, 10<<20), key)
+
+	if _, err := crypto.DecryptCompressedLimited(bomb, key, 1<<20); !errors.Is(err, crypto.ErrDecompressedTooLarge) {
+		t.Errorf("over limit: got %v, want ErrDecompressedTooLarge", err)
+	}
+	if got, err := crypto.DecryptCompressedLimited(bomb, key, 10<<20); err != nil || len(got) != 10<<20 {
+		t.Errorf("exactly at limit: got %d bytes, %v", len(got), err)
+	}
+	if _, err := crypto.DecryptCompressedLimited(bomb, key, -1); err == nil {
+		t.Error("negative limit succeeded")
+	}
+}
+
+func TestDecryptCompressed_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptCompressed([]byte("data"), key)
+
+	if _, err := crypto.DecryptCompressed(ciphertext, other); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong key: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.DecryptBytes(ciphertext, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptBytes() of compressed envelope: got %v, want ErrDecrypt", err)
+	}
+	plain, _ := crypto.EncryptBytes([]byte("data"), key)
+	if _, err := crypto.DecryptCompressed(plain, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptCompressed() of plain envelope: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.EncryptCompressed([]byte("data"), make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+}
+
+func TestEncryptCompressedPadded_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, plaintext := range [][]byte{nil, []byte("x"), bytes.Repeat([]byte("compressible "), 10000)} {
+		ciphertext, err := crypto.EncryptCompressedPadded(plaintext, key, 64)
+		if err != nil {
+			t.Fatalf("EncryptCompressedPadded() error: %v", err)
+		}
+		decrypted, err := crypto.DecryptCompressedPadded(ciphertext, key)
+		if err != nil {
+			t.Fatalf("DecryptCompressedPadded() error: %v", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Round-trip mismatch for %d bytes", len(plaintext))
+		}
+		if _, err := crypto.DecryptCompressed(ciphertext, key); !errors.Is(err, crypto.ErrDecrypt) {
+			t.Errorf("Expected DecryptCompressed to reject a padded ciphertext, got %v", err)
+		}
+	}
+}
+
+func TestEncryptCompressedPadded_HidesRatio(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	a, _ := crypto.EncryptCompressedPadded([]byte("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"), key, 256)
+	b, _ := crypto.EncryptCompressedPadded([]byte("q8Zr1xLk0Pw3nT6vB9yHs2dF5gJ7mC4e"), key, 256)
+	if len(a) != len(b) {
+		t.Errorf("Ciphertext lengths differ within one bucket: %d and %d", len(a), len(b))
+	}
+	if want := len(base64.StdEncoding.EncodeToString(make([]byte, 256+crypto.CiphertextOverhead))); len(a) != want {
+		t.Errorf("Ciphertext length %d, want %d", len(a), want)
+	}
+}
+
+func TestEncryptCompressedPadded_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, size := range []int{0, -1, crypto.MaxPaddingBucketSize + 1} {
+		if _, err := crypto.EncryptCompressedPadded([]byte("x"), key, size); err == nil {
+			t.Errorf("Expected an error for bucket size %d", size)
+		}
+	}
+	if _, err := crypto.EncryptCompressedPadded([]byte("x"), key[:16], 64); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	unpadded, _ := crypto.EncryptCompressed([]byte("x"), key)
+	if _, err := crypto.DecryptCompressedPadded(unpadded, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for an unpadded ciphertext, got %v", err)
+	}
+}
@@ -8,6 +8,10 @@ package crypto_test
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/agilira/go-crypto"
@@ -218,3 +222,297 @@ func TestGetKeyFingerprint_Unit(t *testing.T) {
 		t.Error("Expected empty fingerprint for nil key")
 	}
 }
+
+func TestEncryptBytesWithNonce_Unit(t *testing.T) {
+	key := make([]byte, crypto.KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	nonce := []byte("go-crypto-v1")
+	encrypted, err := crypto.EncryptBytesWithNonce([]byte("go-crypto known answer"), key, nonce)
+	if err != nil {
+		t.Fatalf("Failed to encrypt with nonce: %v", err)
+	}
+	// Matches the pinned DecryptBytes vector.
+	if encrypted != "Z28tY3J5cHRvLXYxRf0DsjFPOpK/zwx2z5km5uxoYs6lWgxOSGHC50VEicbOChWxqcE=" {
+		t.Errorf("Unexpected deterministic ciphertext: %s", encrypted)
+	}
+	again, _ := crypto.EncryptBytesWithNonce([]byte("go-crypto known answer"), key, nonce)
+	if again != encrypted {
+		t.Error("Expected identical output for identical inputs")
+	}
+	decrypted, err := crypto.DecryptBytes(encrypted, key)
+	if err != nil || !bytes.Equal(decrypted, []byte("go-crypto known answer")) {
+		t.Errorf("Expected DecryptBytes round trip, got %q err=%v", decrypted, err)
+	}
+
+	for _, size := range []int{0, 8, 16} {
+		if _, err := crypto.EncryptBytesWithNonce([]byte("x"), key, make([]byte, size)); !errors.Is(err, crypto.ErrInvalidNonce) {
+			t.Errorf("Expected ErrInvalidNonce for %d-byte nonce, got %v", size, err)
+		}
+	}
+	if _, err := crypto.EncryptBytesWithNonce([]byte("x"), nil, nonce); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
+
+func TestPlaintextLen_Unit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, n := range []int{0, 1, 2, 3, 15, 16, 17, 1000} {
+		ciphertext, err := crypto.EncryptBytes(make([]byte, n), key)
+		if err != nil {
+			t.Fatalf("EncryptBytes() error: %v", err)
+		}
+		got, err := crypto.PlaintextLen(ciphertext)
+		if err != nil {
+			t.Fatalf("PlaintextLen() error for %d bytes: %v", n, err)
+		}
+		if got != n {
+			t.Errorf("PlaintextLen() = %d, want %d", got, n)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"empty", "", crypto.ErrEmptyPlaintext},
+		{"bad length", "abc", crypto.ErrBase64Decode},
+		{"bad padding", "AAAA====", crypto.ErrBase64Decode},
+		{"too short", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA==", crypto.ErrCiphertextShort},
+	}
+	for _, tt := range tests {
+		if _, err := crypto.PlaintextLen(tt.input); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRebindAAD_Unit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	oldAAD, newAAD := []byte("owner:alice"), []byte("owner:bob")
+	ciphertext, err := crypto.EncryptWithAAD([]byte("record"), key, oldAAD)
+	if err != nil {
+		t.Fatalf("EncryptWithAAD() error: %v", err)
+	}
+
+	rebound, err := crypto.RebindAAD(ciphertext, key, oldAAD, newAAD)
+	if err != nil {
+		t.Fatalf("RebindAAD() error: %v", err)
+	}
+	if rebound == ciphertext {
+		t.Error("RebindAAD() returned the original ciphertext")
+	}
+	plaintext, err := crypto.DecryptWithAAD(rebound, key, newAAD)
+	if err != nil || string(plaintext) != "record" {
+		t.Fatalf("DecryptWithAAD(newAAD) = %q, %v", plaintext, err)
+	}
+	if _, err := crypto.DecryptWithAAD(rebound, key, oldAAD); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptWithAAD(oldAAD) on rebound ciphertext: got %v, want ErrDecrypt", err)
+	}
+
+	// Rebinding to the same AAD still draws a fresh nonce.
+	again, _ := crypto.RebindAAD(ciphertext, key, oldAAD, oldAAD)
+	if again[:16] == ciphertext[:16] {
+		t.Error("RebindAAD() reused the nonce")
+	}
+
+	if _, err := crypto.RebindAAD(ciphertext, key, []byte("owner:mallory"), newAAD); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("RebindAAD() with wrong old AAD: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.RebindAAD(ciphertext, make([]byte, 16), oldAAD, newAAD); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("RebindAAD() with short key: got %v, want ErrInvalidKeySize", err)
+	}
+}
+
+func TestNilKey_Unit(t *testing.T) {
+	ciphertext, _ := crypto.EncryptBytes([]byte("x"), make([]byte, crypto.KeySize))
+
+	nilKeyErrs := map[string]error{
+		"EncryptBytes": func() error { _, err := crypto.EncryptBytes([]byte("x"), nil); return err }(),
+		"DecryptBytes": func() error { _, err := crypto.DecryptBytes(ciphertext, nil); return err }(),
+		"ValidateKey":  crypto.ValidateKey(nil),
+	}
+	for name, err := range nilKeyErrs {
+		if !errors.Is(err, crypto.ErrNilKey) {
+			t.Errorf("%s(nil key): got %v, want ErrNilKey", name, err)
+		}
+		// Existing callers that check ErrInvalidKeySize keep working.
+		if !errors.Is(err, crypto.ErrInvalidKeySize) {
+			t.Errorf("%s(nil key): got %v, want it to match ErrInvalidKeySize", name, err)
+		}
+	}
+
+	for _, key := range [][]byte{{}, make([]byte, 16)} {
+		wrongSizeErrs := map[string]error{
+			"EncryptBytes": func() error { _, err := crypto.EncryptBytes([]byte("x"), key); return err }(),
+			"DecryptBytes": func() error { _, err := crypto.DecryptBytes(ciphertext, key); return err }(),
+			"ValidateKey":  crypto.ValidateKey(key),
+		}
+		for name, err := range wrongSizeErrs {
+			if err == nil || errors.Is(err, crypto.ErrNilKey) {
+				t.Errorf("%s(%d-byte key): got %v, want a non-nil-key error", name, len(key), err)
+			}
+		}
+		if err := wrongSizeErrs["EncryptBytes"]; !errors.Is(err, crypto.ErrInvalidKeySize) {
+			t.Errorf("EncryptBytes(%d-byte key): got %v, want ErrInvalidKeySize", len(key), err)
+		}
+	}
+}
+
+func TestDecryptLenient_Unit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("wrapped across lines "), 8)
+	ciphertext, _ := crypto.EncryptBytes(plaintext, key)
+
+	var wrapped strings.Builder
+	for i := 0; i < len(ciphertext); i += 64 {
+		wrapped.WriteString(ciphertext[i:min(i+64, len(ciphertext))])
+		wrapped.WriteString("\r\n")
+	}
+	inputs := []string{ciphertext, wrapped.String(), "  \t" + ciphertext + "\n"}
+	for i, in := range inputs {
+		got, err := crypto.DecryptLenient(in, key)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("input %d: DecryptLenient() = %q, %v", i, got, err)
+		}
+	}
+
+	// The strict path still rejects spaces and tabs.
+	if _, err := crypto.DecryptBytes(inputs[2], key); !errors.Is(err, crypto.ErrBase64Decode) {
+		t.Errorf("DecryptBytes() of indented text: got %v, want ErrBase64Decode", err)
+	}
+	if _, err := crypto.DecryptLenient(" \n ", key); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("DecryptLenient() of whitespace: got %v, want ErrEmptyPlaintext", err)
+	}
+}
+
+func TestDecryptAnyEncoding_Unit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for size := 0; size < 3; size++ {
+		plaintext := bytes.Repeat([]byte{0xfb}, size)
+		ciphertext, _ := crypto.EncryptBytes(plaintext, key)
+		raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+		encodings := map[string]*base64.Encoding{
+			"std":     base64.StdEncoding,
+			"raw std": base64.RawStdEncoding,
+			"url":     base64.URLEncoding,
+			"raw url": base64.RawURLEncoding,
+		}
+		for name, enc := range encodings {
+			got, err := crypto.DecryptAnyEncoding(enc.EncodeToString(raw), key)
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Errorf("%d bytes, %s: DecryptAnyEncoding() = %x, %v", size, name, got, err)
+			}
+		}
+	}
+
+	ciphertext, _ := crypto.EncryptBytes([]byte("x"), key)
+	unpadded := strings.TrimRight(ciphertext, "=")
+	if _, err := crypto.DecryptBytes(unpadded, key); !errors.Is(err, crypto.ErrBase64Decode) {
+		t.Errorf("DecryptBytes() of unpadded text: got %v, want ErrBase64Decode", err)
+	}
+	mixed := "-" + ciphertext[1:] + "+"
+	if _, err := crypto.DecryptAnyEncoding(mixed, key); !errors.Is(err, crypto.ErrBase64Decode) {
+		t.Errorf("DecryptAnyEncoding() of mixed alphabets: got %v, want ErrBase64Decode", err)
+	}
+	if _, err := crypto.DecryptAnyEncoding("", key); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("DecryptAnyEncoding() of empty text: got %v, want ErrEmptyPlaintext", err)
+	}
+}
+
+func TestIsValidCiphertextFormat_Unit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytes([]byte("payload"), key)
+	empty, _ := crypto.EncryptBytes(nil, key)
+
+	tests := []struct {
+		name string
+		in   string
+		want bool
+	}{
+		{"ciphertext", ciphertext, true},
+		{"empty plaintext", empty, true},
+		{"empty string", "", false},
+		{"not base64", strings.Repeat("!", 40), false},
+		{"bad length", ciphertext[:len(ciphertext)-1], false},
+		{"too short", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", false},
+		{"plain text", "hello world!", false},
+	}
+	for _, tt := range tests {
+		if got := crypto.IsValidCiphertextFormat(tt.in); got != tt.want {
+			t.Errorf("%s: IsValidCiphertextFormat() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestCiphertextFingerprint_Unit tests ciphertext fingerprints
+func TestCiphertextFingerprint_Unit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	c1, _ := crypto.EncryptBytes([]byte("same plaintext"), key)
+	c2, _ := crypto.EncryptBytes([]byte("same plaintext"), key)
+
+	fp := crypto.CiphertextFingerprint(c1)
+	if len(fp) != 32 {
+		t.Fatalf("Expected a 32-character fingerprint, got %q", fp)
+	}
+	if _, err := hex.DecodeString(fp); err != nil {
+		t.Errorf("Expected a hexadecimal fingerprint, got %q", fp)
+	}
+	if crypto.CiphertextFingerprint(c1) != fp {
+		t.Error("Expected the fingerprint to be stable")
+	}
+	if crypto.CiphertextFingerprint(c2) == fp {
+		t.Error("Expected different ciphertexts of the same plaintext to have different fingerprints")
+	}
+	if strings.HasPrefix(fp, crypto.GetKeyFingerprint([]byte(c1))) {
+		t.Error("Expected ciphertext fingerprints to be distinct from key fingerprints")
+	}
+	if crypto.CiphertextFingerprint("") != "" {
+		t.Error("Expected an empty fingerprint for an empty ciphertext")
+	}
+}
+
+func TestEncryptAscii85_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, plaintext := range [][]byte{nil, []byte("a"), make([]byte, 100), bytes.Repeat([]byte("ascii85 "), 1000)} {
+		ciphertext, err := crypto.EncryptAscii85(plaintext, key)
+		if err != nil {
+			t.Fatalf("EncryptAscii85() error: %v", err)
+		}
+		sealedLen := len(plaintext) + crypto.CiphertextOverhead
+		if limit := (sealedLen + 3) / 4 * 5; len(ciphertext) > limit {
+			t.Errorf("Ascii85 length %d exceeds %d for %d bytes", len(ciphertext), limit, sealedLen)
+		}
+		decrypted, err := crypto.DecryptAscii85(ciphertext, key)
+		if err != nil {
+			t.Fatalf("DecryptAscii85() error: %v", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Round-trip mismatch for %d bytes", len(plaintext))
+		}
+	}
+}
+
+func TestDecryptAscii85_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptAscii85([]byte("payload"), key)
+	if _, err := crypto.DecryptAscii85("", key); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("Expected ErrEmptyPlaintext, got %v", err)
+	}
+	if _, err := crypto.DecryptAscii85(ciphertext+"~", key); !errors.Is(err, crypto.ErrBase64Decode) {
+		t.Errorf("Expected ErrBase64Decode for invalid Ascii85, got %v", err)
+	}
+	tampered := []byte(ciphertext)
+	tampered[len(tampered)/2] ^= 1
+	if _, err := crypto.DecryptAscii85(string(tampered), key); err == nil {
+		t.Error("Expected an error for a tampered ciphertext")
+	}
+	if _, err := crypto.DecryptBytes(ciphertext, key); err == nil {
+		t.Error("DecryptBytes should not accept Ascii85 output")
+	}
+	if _, err := crypto.EncryptAscii85([]byte("x"), key[:16]); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// cryptotest.go: Deterministic fixtures for tests. NOT FOR PRODUCTION USE.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+// Package cryptotest provides deterministic keys for tests of code that uses the crypto package.
+//
+// Everything in this package is INSECURE BY DESIGN: the keys are derived from public
+// names with no secret input, so anyone who knows or guesses a name can reproduce the
+// key. Import it only from _test.go files and never use its output to protect real data.
+package cryptotest
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"github.com/agilira/go-crypto"
+	"golang.org/x/crypto/hkdf"
+)
+
+// testKeySalt domain-separates fixture keys so they cannot coincide with keys derived
+// from the same name elsewhere.
+const testKeySalt = "go-crypto/cryptotest/v1 INSECURE TEST KEY"
+
+// TestKey returns a deterministic crypto.KeySize-byte key derived from name.
+//
+// The same name always yields the same key, across runs and machines, so table-driven
+// tests and golden files can use stable keys without hardcoding random bytes, and
+// failures reproduce exactly. Different names yield unrelated keys.
+//
+// INSECURE: the key is HKDF-SHA256 of the name with a fixed public salt. It provides no
+// secrecy at all and must never be used outside tests.
+//
+// Parameters:
+//   - name: The fixture name, e.g. the test case name
+//
+// Returns:
+//   - A 32-byte key
+//
+// Example:
+//
+//	func TestRoundTrip(t *testing.T) {
+//		key := cryptotest.TestKey(t.Name())
+//		ciphertext, _ := crypto.EncryptBytes([]byte("fixture"), key)
+//		...
+//	}
+func TestKey(name string) []byte {
+	key := make([]byte, crypto.KeySize)
+	// HKDF-SHA256 can produce up to 8160 bytes, so reading 32 bytes cannot fail.
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(name), []byte(testKeySalt), nil), key); err != nil {
+		panic("cryptotest: " + err.Error())
+	}
+	return key
+}
@@ -0,0 +1,53 @@
+// cryptotest_test.go: Test cases for deterministic test fixtures.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package cryptotest_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+	"github.com/agilira/go-crypto/cryptotest"
+)
+
+func TestTestKey_Deterministic(t *testing.T) {
+	a := cryptotest.TestKey("fixture")
+	b := cryptotest.TestKey("fixture")
+	if !bytes.Equal(a, b) {
+		t.Error("TestKey() is not deterministic")
+	}
+	if len(a) != crypto.KeySize {
+		t.Errorf("TestKey() returned %d bytes, want %d", len(a), crypto.KeySize)
+	}
+	if err := crypto.ValidateKey(a); err != nil {
+		t.Errorf("TestKey() is not a valid key: %v", err)
+	}
+}
+
+func TestTestKey_DistinctNames(t *testing.T) {
+	seen := make(map[string]string)
+	for _, name := range []string{"", "a", "b", "fixture", "fixture ", "TestRoundTrip/empty"} {
+		k := hex.EncodeToString(cryptotest.TestKey(name))
+		if prev, dup := seen[k]; dup {
+			t.Errorf("names %q and %q produce the same key", prev, name)
+		}
+		seen[k] = name
+	}
+}
+
+func TestTestKey_UsableWithCrypto(t *testing.T) {
+	key := cryptotest.TestKey(t.Name())
+	ciphertext, err := crypto.EncryptBytes([]byte("fixture"), key)
+	if err != nil {
+		t.Fatalf("EncryptBytes() error: %v", err)
+	}
+	plaintext, err := crypto.DecryptBytes(ciphertext, cryptotest.TestKey(t.Name()))
+	if err != nil || string(plaintext) != "fixture" {
+		t.Errorf("DecryptBytes() = %q, %v", plaintext, err)
+	}
+}
@@ -0,0 +1,209 @@
+// deniable.go: Ciphertexts holding a real and a decoy plaintext.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// Deniable envelope format:
+//
+//	slot || slot, in random order
+//	slot = nonce || AES-256-GCM(length (uint32, big-endian) || plaintext || zero padding) || tag
+//
+// Both slots are padded to the longer plaintext, so they have the same size.
+const (
+	deniableLabel      = "go-crypto/v1/deniable"
+	deniableLengthSize = 4
+)
+
+// EncryptDeniable encrypts a real and a decoy plaintext into one ciphertext that opens
+// to real under realKey and to decoy under decoyKey.
+//
+// The ciphertext consists of two slots of equal size in random order, one encrypted
+// under each key. AES-GCM output is indistinguishable from random bytes without the
+// key, so someone holding only one key cannot tell which slot they opened, whether the
+// other slot holds data, or which key it uses: a user compelled to hand over a key can
+// hand over decoyKey and claim the other slot is filler. That claim is plausible only
+// because EncryptDeniableWithFiller produces the same format with a random second
+// slot; it helps to use it for ciphertexts that really have no second plaintext.
+//
+// The guarantee is narrower than it may seem, and whether it protects anyone depends on
+// the threat:
+//   - The format itself is not hidden: every ciphertext has two slots, so an adversary
+//     who knows this function is in use knows a second plaintext may exist, and may
+//     keep demanding a second key. Deniability rests on the claim being plausible, not
+//     on the adversary being unable to suspect it.
+//   - The decoy must be convincing on its own (plausible content, and a plausible
+//     length, since both slots are padded to the longer plaintext and the ciphertext
+//     size reveals that length).
+//   - Keys, passwords or plaintexts left on the device, in backups or in application
+//     logs and caches defeat it; so does any record of having used two keys.
+//   - Overwriting or re-encrypting only one plaintext is not possible: both keys are
+//     needed to produce a new ciphertext.
+//
+// Parameters:
+//   - real: The real plaintext (can be empty)
+//   - decoy: The decoy plaintext (can be empty)
+//   - realKey: The 32-byte key opening the real plaintext (must be exactly KeySize bytes)
+//   - decoyKey: The 32-byte key opening the decoy (must differ from realKey)
+//
+// Returns:
+//   - A base64-encoded string containing both slots
+//   - An error if a key is invalid, the keys are equal or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptDeniable(sources, shoppingList, realKey, decoyKey)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptDeniable(real, decoy []byte, realKey, decoyKey []byte) (string, error) {
+	if err := checkKey(realKey); err != nil {
+		return "", err
+	}
+	if err := checkKey(decoyKey); err != nil {
+		return "", err
+	}
+	if subtle.ConstantTimeCompare(realKey, decoyKey) == 1 {
+		return "", goerrors.New("DENIABLE_SAME_KEY", "real and decoy keys must differ")
+	}
+	size := max(len(real), len(decoy))
+	if uint64(size) > math.MaxUint32 {
+		return "", goerrors.New("DENIABLE_TOO_LARGE", fmt.Sprintf("plaintext exceeds %d bytes", uint32(math.MaxUint32)))
+	}
+	var order [1]byte
+	if _, err := io.ReadFull(nonceReader(), order[:]); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to choose slot order")
+		return "", fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	first, err := sealDeniableSlot(real, realKey, size)
+	if err != nil {
+		return "", err
+	}
+	second, err := sealDeniableSlot(decoy, decoyKey, size)
+	if err != nil {
+		return "", err
+	}
+	if order[0]&1 == 1 {
+		first, second = second, first
+	}
+	return base64.StdEncoding.EncodeToString(append(first, second...)), nil
+}
+
+// EncryptDeniableWithFiller encrypts plaintext into the two-slot format of
+// EncryptDeniable, with uniformly random bytes in place of the second slot.
+//
+// The filler has the size of the real slot and, like the AES-GCM output of a real
+// slot, is indistinguishable from random bytes, so the ciphertext cannot be told apart
+// from one produced by EncryptDeniable with a decoy of the same length. Using it for
+// ciphertexts without a second plaintext makes "the other slot is filler" a true
+// statement for them, and so a plausible one for the others. No key opens the filler.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A base64-encoded string containing the real and the filler slot in random order
+//   - An error if the key is invalid or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptDeniableWithFiller(notes, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptDeniableWithFiller(plaintext, key []byte) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	if uint64(len(plaintext)) > math.MaxUint32 {
+		return "", goerrors.New("DENIABLE_TOO_LARGE", fmt.Sprintf("plaintext exceeds %d bytes", uint32(math.MaxUint32)))
+	}
+	var order [1]byte
+	if _, err := io.ReadFull(nonceReader(), order[:]); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to choose slot order")
+		return "", fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	first, err := sealDeniableSlot(plaintext, key, len(plaintext))
+	if err != nil {
+		return "", err
+	}
+	second := make([]byte, len(first))
+	if _, err := io.ReadFull(rand.Reader, second); err != nil {
+		return "", goerrors.Wrap(err, "FILLER_GEN_ERROR", "failed to generate filler slot")
+	}
+	if order[0]&1 == 1 {
+		first, second = second, first
+	}
+	return base64.StdEncoding.EncodeToString(append(first, second...)), nil
+}
+
+// DecryptDeniable decrypts a ciphertext produced by EncryptDeniable or
+// EncryptDeniableWithFiller, returning the plaintext of whichever slot key opens.
+//
+// Both slots are always tried, so the time taken does not reveal which slot matched.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext
+//   - key: The 32-byte real or decoy key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The plaintext of the matching slot
+//   - An error wrapping ErrDecrypt if neither slot opens under key
+//
+// Example:
+//
+//	plaintext, err := crypto.DecryptDeniable(ciphertext, key)
+func DecryptDeniable(ciphertext string, key []byte) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	slotSize := len(data) / 2
+	if len(data)%2 != 0 || slotSize < CiphertextOverhead+deniableLengthSize {
+		richErr := goerrors.New(ErrCodeCipherShort, "malformed deniable ciphertext")
+		return nil, inputError(ErrCiphertextShort, richErr)
+	}
+	_, first, err1 := openWithHeader(key, deniableLabel, data[:slotSize], 0)
+	_, second, err2 := openWithHeader(key, deniableLabel, data[slotSize:], 0)
+	padded := first
+	if err1 != nil {
+		if err2 != nil {
+			return nil, err2
+		}
+		padded = second
+	}
+	n := binary.BigEndian.Uint32(padded)
+	if uint64(n) > uint64(len(padded)-deniableLengthSize) {
+		Zeroize(padded)
+		richErr := goerrors.New(ErrCodeDecrypt, "invalid deniable plaintext length")
+		return nil, inputError(ErrDecrypt, richErr)
+	}
+	return padded[deniableLengthSize : deniableLengthSize+int(n) : deniableLengthSize+int(n)], nil
+}
+
+// sealDeniableSlot encrypts plaintext, length-prefixed and zero-padded to size bytes.
+func sealDeniableSlot(plaintext, key []byte, size int) ([]byte, error) {
+	padded := make([]byte, deniableLengthSize+size)
+	defer Zeroize(padded)
+	binary.BigEndian.PutUint32(padded, uint32(len(plaintext)))
+	copy(padded[deniableLengthSize:], plaintext)
+	return sealWithHeader(key, deniableLabel, nil, padded)
+}
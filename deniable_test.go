@@ -0,0 +1,111 @@
+// deniable_test.go: Test cases for deniable encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptDeniable_RoundTrip(t *testing.T) {
+	realKey, _ := crypto.GenerateKey()
+	decoyKey, _ := crypto.GenerateKey()
+	cases := [][2][]byte{
+		{[]byte("the real sources"), []byte("groceries")},
+		{nil, []byte("only a decoy")},
+		{[]byte("short"), []byte("a much longer decoy plaintext")},
+	}
+	for _, tc := range cases {
+		ciphertext, err := crypto.EncryptDeniable(tc[0], tc[1], realKey, decoyKey)
+		if err != nil {
+			t.Fatalf("EncryptDeniable() error: %v", err)
+		}
+		real, err := crypto.DecryptDeniable(ciphertext, realKey)
+		if err != nil || !bytes.Equal(real, tc[0]) {
+			t.Errorf("DecryptDeniable(realKey) = %q, %v; want %q", real, err, tc[0])
+		}
+		decoy, err := crypto.DecryptDeniable(ciphertext, decoyKey)
+		if err != nil || !bytes.Equal(decoy, tc[1]) {
+			t.Errorf("DecryptDeniable(decoyKey) = %q, %v; want %q", decoy, err, tc[1])
+		}
+	}
+}
+
+func TestEncryptDeniable_SlotsIndistinguishable(t *testing.T) {
+	realKey, _ := crypto.GenerateKey()
+	decoyKey, _ := crypto.GenerateKey()
+	a, _ := crypto.EncryptDeniable([]byte("x"), []byte("a longer decoy"), realKey, decoyKey)
+	b, _ := crypto.EncryptDeniable([]byte("a longer real!"), []byte("y"), realKey, decoyKey)
+	if len(a) != len(b) {
+		t.Errorf("Ciphertext lengths %d and %d should depend only on the longer plaintext", len(a), len(b))
+	}
+	// The real slot comes first in roughly half of the ciphertexts; a ciphertext made of
+	// two copies of the first slot opens under realKey exactly when it is the real slot.
+	firstSlotReal := 0
+	for i := 0; i < 64; i++ {
+		ciphertext, _ := crypto.EncryptDeniable([]byte("real"), []byte("decoy"), realKey, decoyKey)
+		data, _ := base64.StdEncoding.DecodeString(ciphertext)
+		half := base64.StdEncoding.EncodeToString(append(data[:len(data)/2:len(data)/2], data[:len(data)/2]...))
+		if _, err := crypto.DecryptDeniable(half, realKey); err == nil {
+			firstSlotReal++
+		}
+	}
+	if firstSlotReal == 0 || firstSlotReal == 64 {
+		t.Errorf("Slot order is not randomized (real slot first %d/64 times)", firstSlotReal)
+	}
+}
+
+func TestEncryptDeniableWithFiller(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, plaintext := range [][]byte{nil, []byte("no second plaintext")} {
+		ciphertext, err := crypto.EncryptDeniableWithFiller(plaintext, key)
+		if err != nil {
+			t.Fatalf("EncryptDeniableWithFiller() error: %v", err)
+		}
+		got, err := crypto.DecryptDeniable(ciphertext, key)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("DecryptDeniable() = %q, %v; want %q", got, err, plaintext)
+		}
+		// Same size as a two-key ciphertext with a decoy of the same length.
+		decoyKey, _ := crypto.GenerateKey()
+		twoKeys, _ := crypto.EncryptDeniable(plaintext, bytes.Repeat([]byte{'d'}, len(plaintext)), key, decoyKey)
+		if len(ciphertext) != len(twoKeys) {
+			t.Errorf("Filler ciphertext is %d bytes, two-key ciphertext %d", len(ciphertext), len(twoKeys))
+		}
+		if _, err := crypto.DecryptDeniable(ciphertext, decoyKey); !errors.Is(err, crypto.ErrDecrypt) {
+			t.Errorf("Expected ErrDecrypt for a key other than the real one, got %v", err)
+		}
+	}
+	if _, err := crypto.EncryptDeniableWithFiller([]byte("x"), make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
+
+func TestDecryptDeniable_Errors(t *testing.T) {
+	realKey, _ := crypto.GenerateKey()
+	decoyKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptDeniable([]byte("real"), []byte("decoy"), realKey, decoyKey)
+
+	if _, err := crypto.DecryptDeniable(ciphertext, otherKey); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for an unrelated key, got %v", err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	if _, err := crypto.DecryptDeniable(base64.StdEncoding.EncodeToString(data[:len(data)-1]), realKey); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort for an odd length, got %v", err)
+	}
+	if _, err := crypto.EncryptDeniable([]byte("a"), []byte("b"), realKey, realKey); err == nil {
+		t.Error("Expected an error for equal keys")
+	}
+	if _, err := crypto.EncryptDeniable([]byte("a"), []byte("b"), realKey, decoyKey[:16]); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
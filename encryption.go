@@ -9,11 +9,15 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
+	"crypto/sha256"
+	"encoding/ascii85"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	goerrors "github.com/agilira/go-errors"
 )
@@ -28,6 +32,10 @@ var (
 	// ErrInvalidKeySize is returned when the provided key is not exactly 32 bytes.
 	ErrInvalidKeySize = errors.New("crypto: invalid key size")
 
+	// ErrNilKey is returned when the provided key is nil, which usually means a key was
+	// never loaded. For compatibility, errors wrapping ErrNilKey also match ErrInvalidKeySize.
+	ErrNilKey = errors.New("crypto: key is nil")
+
 	// ErrEmptyPlaintext is returned when trying to decrypt an empty string.
 	// Note: Empty plaintext is supported for encryption.
 	ErrEmptyPlaintext = errors.New("crypto: plaintext cannot be empty")
@@ -49,11 +57,15 @@ var (
 
 	// ErrDecrypt is returned when decryption fails due to authentication failure or corruption.
 	ErrDecrypt = errors.New("crypto: decryption error")
+
+	// ErrInvalidNonce is returned when a caller-supplied nonce has the wrong size.
+	ErrInvalidNonce = errors.New("crypto: invalid nonce size")
 )
 
 // Error codes for rich error handling
 const (
 	ErrCodeInvalidKey   = "CRYPTO_INVALID_KEY"
+	ErrCodeNilKey       = "CRYPTO_NIL_KEY"
 	ErrCodeEmptyPlain   = "CRYPTO_EMPTY_PLAINTEXT"
 	ErrCodeCipherInit   = "CRYPTO_CIPHER_INIT"
 	ErrCodeGCMInit      = "CRYPTO_GCM_INIT"
@@ -61,13 +73,16 @@ const (
 	ErrCodeBase64Decode = "CRYPTO_BASE64_DECODE"
 	ErrCodeCipherShort  = "CRYPTO_CIPHERTEXT_SHORT"
 	ErrCodeDecrypt      = "CRYPTO_DECRYPT"
+	ErrCodeInvalidNonce = "CRYPTO_INVALID_NONCE"
 )
 
 // EncryptBytes encrypts a plaintext byte slice using AES-256-GCM authenticated encryption.
 //
 // The function uses AES-256 in GCM mode, which provides both confidentiality and authenticity.
 // The returned string is base64-encoded and contains the nonce, ciphertext, and authentication tag.
-// This is the core encryption function that works with binary data.
+// This is the core encryption function that works with binary data. The output always
+// uses standard, padded base64 (RFC 4648, section 4), which DecryptBytes requires;
+// DecryptAnyEncoding also accepts the unpadded and URL-safe variants other tools emit.
 //
 // Parameters:
 //   - plaintext: The byte slice to encrypt (can be empty)
@@ -88,31 +103,68 @@ const (
 //	fmt.Println("Encrypted:", ciphertext)
 //
 // Empty plaintext is supported and will result in a valid ciphertext containing
-// only the nonce and authentication tag.
+// only the nonce and authentication tag. A nil key yields an error wrapping ErrNilKey.
 func EncryptBytes(plaintext []byte, key []byte) (string, error) {
-	if len(key) != KeySize {
-		richErr := goerrors.New(ErrCodeInvalidKey, fmt.Sprintf("invalid key size: must be 32 bytes for AES-256 (got %d)", len(key)))
-		return "", fmt.Errorf("%w: %w", ErrInvalidKeySize, richErr)
+	obs := loadObserver()
+	if obs == nil {
+		return encryptBytes(plaintext, key)
 	}
-	block, err := aes.NewCipher(key)
+	start := time.Now()
+	ciphertext, err := encryptBytes(plaintext, key)
+	obs.ObserveEncrypt(time.Since(start), err)
+	return ciphertext, err
+}
+
+// encryptBytes implements EncryptBytes without instrumentation.
+func encryptBytes(plaintext []byte, key []byte) (string, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
-		richErr := goerrors.Wrap(err, ErrCodeCipherInit, "failed to create cipher")
-		return "", fmt.Errorf("%w: %w", ErrCipherInit, richErr)
+		return "", err
 	}
-	gcm, err := cipher.NewGCM(block)
+	ciphertext, err := sealAEAD(gcm, nil, plaintext, nil)
 	if err != nil {
-		richErr := goerrors.Wrap(err, ErrCodeGCMInit, "failed to create GCM")
-		return "", fmt.Errorf("%w: %w", ErrGCMInit, richErr)
-	}
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate nonce")
-		return "", fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+		return "", err
 	}
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
+// EncryptBytesWithNonce encrypts a plaintext byte slice using AES-256-GCM with a caller-supplied nonce.
+//
+// WARNING: reusing a nonce with the same key is catastrophic for AES-GCM. It reveals the
+// XOR of the two plaintexts and lets an attacker forge messages under that key. This
+// function exists only to reproduce exact ciphertexts in tests and to interoperate with
+// systems that dictate the nonce; use EncryptBytes everywhere else.
+//
+// The output has the same format as EncryptBytes and is decrypted with DecryptBytes.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - nonce: The 12-byte nonce (must never be reused with the same key)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error wrapping ErrInvalidNonce if the nonce is not 12 bytes, or another error if encryption fails
+//
+// Example:
+//
+//	// Golden-file test: the output is fully deterministic.
+//	nonce, _ := hex.DecodeString("000000000000000000000001")
+//	ciphertext, err := crypto.EncryptBytesWithNonce([]byte("fixture"), testKey, nonce)
+func EncryptBytesWithNonce(plaintext, key, nonce []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		richErr := goerrors.New(ErrCodeInvalidNonce, fmt.Sprintf("nonce must be %d bytes (got %d)", gcm.NonceSize(), len(nonce)))
+		return "", fmt.Errorf("%w: %w", ErrInvalidNonce, richErr)
+	}
+	out := make([]byte, 0, len(nonce)+len(plaintext)+gcm.Overhead())
+	out = gcm.Seal(append(out, nonce...), nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
 // DecryptBytes decrypts a base64-encoded ciphertext string using AES-256-GCM authenticated decryption.
 //
 // The function verifies the authenticity of the ciphertext using the embedded authentication tag.
@@ -139,49 +191,431 @@ func EncryptBytes(plaintext []byte, key []byte) (string, error) {
 //	fmt.Println("Decrypted:", string(plaintext)) // Output: sensitive binary data
 //
 // The function will return an error if:
-//   - The key size is incorrect
+//   - The key is nil (ErrNilKey) or its size is incorrect (ErrInvalidKeySize)
 //   - The encrypted text is empty
 //   - The base64 decoding fails
 //   - The ciphertext is too short
 //   - Authentication fails (tampering detected)
 func DecryptBytes(encryptedText string, key []byte) ([]byte, error) {
-	if len(key) != KeySize {
-		richErr := goerrors.New(ErrCodeInvalidKey, fmt.Sprintf("invalid key size: must be 32 bytes for AES-256 (got %d)", len(key)))
-		return nil, fmt.Errorf("%w: %w", ErrInvalidKeySize, richErr)
+	obs := loadObserver()
+	if obs == nil {
+		return decryptBytes(encryptedText, key)
+	}
+	start := time.Now()
+	plaintext, err := decryptBytes(encryptedText, key)
+	observeDecrypt(obs, start, plaintext, err)
+	return plaintext, err
+}
+
+// decryptBytes implements DecryptBytes without instrumentation.
+func decryptBytes(encryptedText string, key []byte) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	ciphertext, err := decodeCiphertext(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return openAEAD(gcm, ciphertext, nil)
+}
+
+// DecryptLenient decrypts a ciphertext like DecryptBytes, ignoring embedded whitespace.
+//
+// Ciphertext that travels through text channels is often wrapped to fixed-width lines
+// (PEM-style 64 columns), indented, or quoted by email clients and editors. The
+// standard base64 decoder skips carriage returns and line feeds but rejects the spaces
+// and tabs such channels introduce. DecryptLenient removes all ASCII whitespace before
+// decoding. Whitespace is not part of the authenticated data, so this is safe;
+// DecryptBytes remains the strict variant for callers that want the encoding validated.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string, possibly containing whitespace
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if decryption fails, as for DecryptBytes
+//
+// Example:
+//
+//	body, _ := io.ReadAll(r) // ciphertext wrapped to 64 columns
+//	plaintext, err := crypto.DecryptLenient(string(body), key)
+func DecryptLenient(encryptedText string, key []byte) ([]byte, error) {
+	return DecryptBytes(stripWhitespace(encryptedText), key)
+}
+
+// DecryptAnyEncoding decrypts a ciphertext like DecryptBytes, accepting any common base64 variant.
+//
+// Some producers strip the '=' padding (RawStdEncoding) or use the URL-safe alphabet
+// with '-' and '_' (URLEncoding, RawURLEncoding), which the strict DecryptBytes rejects.
+// DecryptAnyEncoding detects the alphabet from the characters present and accepts the
+// text with or without padding. Mixing the two alphabets, or padding a string whose
+// length is not a multiple of four, is still rejected. The encoding is not part of the
+// authenticated data, so accepting variants is safe. The encoders of this package
+// always produce padded standard base64, so round-trips stay unambiguous.
+//
+// Parameters:
+//   - encryptedText: The ciphertext in standard or URL-safe base64, padded or not
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if decryption fails, as for DecryptBytes
+//
+// Example:
+//
+//	token := r.URL.Query().Get("t") // RawURLEncoding from another service
+//	plaintext, err := crypto.DecryptAnyEncoding(token, key)
+func DecryptAnyEncoding(encryptedText string, key []byte) ([]byte, error) {
+	return DecryptBytes(normalizeBase64(encryptedText), key)
+}
+
+// EncryptAscii85 encrypts plaintext like EncryptBytes and encodes the envelope with
+// Ascii85 instead of base64.
+//
+// Ascii85 expands the envelope by 25% rather than base64's 33%, a modest saving for
+// ciphertext stored in size-constrained text fields. Its alphabet covers every
+// printable ASCII character from '!' to 'u' (plus 'z' for a group of zero bytes),
+// including quotes, backslash, '<', '>' and '&', so the output needs escaping in JSON,
+// XML, URLs and shell commands; prefer base64 there. The output carries no "<~ ~>"
+// delimiters and is only accepted by DecryptAscii85.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - An Ascii85-encoded string containing the nonce, ciphertext and tag
+//   - An error if the key is invalid or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptAscii85(token, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptAscii85(plaintext, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := sealAEAD(gcm, nil, plaintext, nil)
+	if err != nil {
+		return "", err
+	}
+	out := make([]byte, ascii85.MaxEncodedLen(len(sealed)))
+	return string(out[:ascii85.Encode(out, sealed)]), nil
+}
+
+// DecryptAscii85 decrypts a ciphertext produced by EncryptAscii85.
+//
+// Whitespace inside the text is ignored, as by the Ascii85 decoder.
+//
+// Parameters:
+//   - encryptedText: The Ascii85-encoded ciphertext
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error wrapping ErrBase64Decode if the text is not valid Ascii85 (the package's
+//     text decoding error), or another error as for DecryptBytes
+//
+// Example:
+//
+//	token, err := crypto.DecryptAscii85(ciphertext, key)
+func DecryptAscii85(encryptedText string, key []byte) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
 	}
 	if encryptedText == "" {
 		richErr := goerrors.New(ErrCodeEmptyPlain, "encrypted text cannot be empty")
-		return nil, fmt.Errorf("%w: %w", ErrEmptyPlaintext, richErr)
+		return nil, inputError(ErrEmptyPlaintext, richErr)
 	}
-	ciphertext, err := base64.StdEncoding.DecodeString(encryptedText)
+	// Every 5 characters decode to at most 4 bytes; a 'z' expands to 4 bytes.
+	sealed := make([]byte, 4*len(encryptedText))
+	n, _, err := ascii85.Decode(sealed, []byte(encryptedText), true)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeBase64Decode, "failed to decode Ascii85")
+		return nil, inputError(ErrBase64Decode, richErr)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return openAEAD(gcm, sealed[:n], nil)
+}
+
+// normalizeBase64 converts URL-safe or unpadded base64 to padded standard base64.
+// Text mixing both alphabets is returned unchanged, so that decoding rejects it.
+func normalizeBase64(s string) string {
+	if strings.ContainsAny(s, "-_") {
+		if strings.ContainsAny(s, "+/") {
+			return s
+		}
+		s = strings.NewReplacer("-", "+", "_", "/").Replace(s)
+	}
+	if !strings.HasSuffix(s, "=") && len(s)%4 != 0 {
+		s += strings.Repeat("=", 4-len(s)%4)
+	}
+	return s
+}
+
+// stripWhitespace removes ASCII whitespace from s.
+func stripWhitespace(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Sizes of the AES-256-GCM envelope produced by EncryptBytes.
+const (
+	gcmNonceSize = 12
+	gcmTagSize   = 16
+)
+
+// CiphertextOverhead is the number of bytes the binary AES-256-GCM envelope adds to a
+// plaintext: a 12-byte nonce and a 16-byte tag.
+const CiphertextOverhead = gcmNonceSize + gcmTagSize
+
+// PlaintextLen returns the plaintext length of an EncryptBytes ciphertext without decrypting it.
+//
+// The length is computed from the size of the base64 text: only the final base64
+// quantum is decoded, to validate the padding, so the cost does not grow with the size
+// of the ciphertext. The ciphertext is neither authenticated nor fully validated, so a
+// tampered ciphertext can report a length that DecryptBytes would then reject. The
+// plaintext length is not secret in this format: anyone who sees the ciphertext can
+// compute it the same way.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded ciphertext from EncryptBytes or Encrypt
+//
+// Returns:
+//   - The plaintext length in bytes
+//   - An error if the text is empty, not valid base64, or too short to hold a nonce and tag
+//
+// Example:
+//
+//	n, err := crypto.PlaintextLen(ciphertext)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("encrypted file, %d bytes of content\n", n)
+func PlaintextLen(encryptedText string) (int, error) {
+	if encryptedText == "" {
+		richErr := goerrors.New(ErrCodeEmptyPlain, "encrypted text cannot be empty")
+		return 0, fmt.Errorf("%w: %w", ErrEmptyPlaintext, richErr)
+	}
+	if len(encryptedText)%4 != 0 {
+		richErr := goerrors.New(ErrCodeBase64Decode, "base64 length is not a multiple of 4")
+		return 0, fmt.Errorf("%w: %w", ErrBase64Decode, richErr)
+	}
+	tail, err := base64.StdEncoding.DecodeString(encryptedText[len(encryptedText)-4:])
 	if err != nil {
 		richErr := goerrors.Wrap(err, ErrCodeBase64Decode, "failed to decode base64")
-		return nil, fmt.Errorf("%w: %w", ErrBase64Decode, richErr)
+		return 0, fmt.Errorf("%w: %w", ErrBase64Decode, richErr)
+	}
+	decodedLen := (len(encryptedText)/4-1)*3 + len(tail)
+	if decodedLen < gcmNonceSize+gcmTagSize {
+		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
+		return 0, fmt.Errorf("%w: %w", ErrCiphertextShort, richErr)
+	}
+	return decodedLen - gcmNonceSize - gcmTagSize, nil
+}
+
+// IsValidCiphertextFormat reports whether encryptedText is well-formed EncryptBytes output.
+//
+// It checks, without a key, that the text is valid standard base64 and decodes to at
+// least a nonce and a tag. Use it as a cheap pre-filter to reject input that is not
+// this library's format with a clear message, before spending work on decryption. A
+// true result says nothing about authenticity: only decryption verifies the tag.
+//
+// Parameters:
+//   - encryptedText: The text to check
+//
+// Returns:
+//   - true if the text has the shape of an EncryptBytes ciphertext
+//
+// Example:
+//
+//	if !crypto.IsValidCiphertextFormat(field) {
+//		return fmt.Errorf("field %q is not an encrypted value", name)
+//	}
+func IsValidCiphertextFormat(encryptedText string) bool {
+	if encryptedText == "" || len(encryptedText)%4 != 0 {
+		return false
+	}
+	data, err := base64.StdEncoding.DecodeString(encryptedText)
+	return err == nil && len(data) >= gcmNonceSize+gcmTagSize
+}
+
+// ciphertextFingerprintLabel domain-separates ciphertext fingerprints from key fingerprints.
+const ciphertextFingerprintLabel = "go-crypto/v1/ciphertext-fingerprint"
+
+// CiphertextFingerprint returns a short, stable identifier of a ciphertext, for use as a
+// cache key or deduplication token.
+//
+// The fingerprint is the first 16 bytes of SHA-256 over a domain label and the
+// ciphertext string, in lowercase hexadecimal like GetKeyFingerprint. It identifies the
+// ciphertext, not the plaintext: since every encryption uses a fresh nonce, the same
+// plaintext encrypted twice has two fingerprints, and nothing about the plaintext or
+// key can be learned from it. It is twice as long as a key fingerprint because
+// ciphertexts can be chosen by an attacker: 128 bits keep finding two ciphertexts with
+// the same fingerprint, which could poison a cache, out of reach. The fingerprint is
+// computed over the string as given, so differently encoded copies of one ciphertext
+// have different fingerprints; no key is needed and authenticity is not checked.
+//
+// Parameters:
+//   - ciphertext: The ciphertext, e.g. as returned by EncryptBytes
+//
+// Returns:
+//   - A 32-character hexadecimal string, or an empty string for an empty ciphertext
+//
+// Example:
+//
+//	cacheKey := crypto.CiphertextFingerprint(record.Data)
+//	if plaintext, ok := cache.Get(cacheKey); ok {
+//		return plaintext, nil
+//	}
+func CiphertextFingerprint(ciphertext string) string {
+	if ciphertext == "" {
+		return ""
+	}
+	h := sha256.New()
+	h.Write([]byte(ciphertextFingerprintLabel))
+	h.Write([]byte(ciphertext))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// checkKey validates that key is a usable AES-256 key.
+func checkKey(key []byte) error {
+	if key == nil {
+		return nilKeyError()
+	}
+	if len(key) != KeySize {
+		richErr := goerrors.New(ErrCodeInvalidKey, fmt.Sprintf("invalid key size: must be 32 bytes for AES-256 (got %d)", len(key)))
+		return fmt.Errorf("%w: %w", ErrInvalidKeySize, richErr)
+	}
+	return nil
+}
+
+// nilKeyError builds the error for a nil key, wrapping both ErrNilKey and ErrInvalidKeySize.
+func nilKeyError() error {
+	richErr := goerrors.New(ErrCodeNilKey, "key is nil: no key was provided")
+	return fmt.Errorf("%w: %w: %w", ErrNilKey, ErrInvalidKeySize, richErr)
+}
+
+// newGCM validates key and returns an AES-256-GCM AEAD for it.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	return newGCMWithTagSize(key, gcmTagSize)
+}
+
+// newGCMWithTagSize validates key and returns an AES-256-GCM AEAD with tagSize-byte tags.
+func newGCMWithTagSize(key []byte, tagSize int) (cipher.AEAD, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
 	}
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		richErr := goerrors.Wrap(err, ErrCodeCipherInit, "failed to create cipher")
 		return nil, fmt.Errorf("%w: %w", ErrCipherInit, richErr)
 	}
-	gcm, err := cipher.NewGCM(block)
+	var gcm cipher.AEAD
+	if tagSize == gcmTagSize {
+		gcm, err = cipher.NewGCM(block)
+	} else {
+		gcm, err = cipher.NewGCMWithTagSize(block, tagSize)
+	}
 	if err != nil {
 		richErr := goerrors.Wrap(err, ErrCodeGCMInit, "failed to create GCM")
 		return nil, fmt.Errorf("%w: %w", ErrGCMInit, richErr)
 	}
-	if len(ciphertext) < gcm.NonceSize() {
+	return gcm, nil
+}
+
+// decodeCiphertext rejects empty input and decodes a standard base64 ciphertext.
+func decodeCiphertext(encryptedText string) ([]byte, error) {
+	if encryptedText == "" {
+		richErr := goerrors.New(ErrCodeEmptyPlain, "encrypted text cannot be empty")
+		return nil, inputError(ErrEmptyPlaintext, richErr)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encryptedText)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeBase64Decode, "failed to decode base64")
+		return nil, inputError(ErrBase64Decode, richErr)
+	}
+	return ciphertext, nil
+}
+
+// sealAEAD generates a random nonce and appends nonce || ciphertext || tag to dst.
+// It defines the package's single-shot framing independently of the concrete AEAD.
+func sealAEAD(aead cipher.AEAD, dst, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(nonceReader(), nonce); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate nonce")
+		return nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, aad), nil
+}
+
+// openAEAD splits the leading nonce from data and authenticates and decrypts the rest.
+func openAEAD(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
 		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
-		return nil, fmt.Errorf("%w: %w", ErrCiphertextShort, richErr)
+		return nil, inputError(ErrCiphertextShort, richErr)
 	}
-	nonce := ciphertext[:gcm.NonceSize()]
-	ciphertext = ciphertext[gcm.NonceSize():]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	nonce := data[:aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, data[aead.NonceSize():], aad)
 	if err != nil {
 		richErr := goerrors.Wrap(err, ErrCodeDecrypt, "failed to decrypt")
-		return nil, fmt.Errorf("%w: %w", ErrDecrypt, richErr)
+		return nil, inputError(ErrDecrypt, richErr)
 	}
 	return plaintext, nil
 }
 
+// sealWithHeader encrypts plaintext under key and returns header || nonce || ciphertext.
+// The header travels in clear but is authenticated, together with a purpose label that
+// keeps envelopes produced for different features from being accepted by one another.
+func sealWithHeader(key []byte, label string, header, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	aad := make([]byte, 0, len(label)+len(header))
+	aad = append(append(aad, label...), header...)
+	out := make([]byte, 0, len(header)+gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	return sealAEAD(gcm, append(out, header...), plaintext, aad)
+}
+
+// openWithHeader reverses sealWithHeader for a header of headerLen bytes, returning the
+// authenticated header and the plaintext.
+func openWithHeader(key []byte, label string, data []byte, headerLen int) ([]byte, []byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) < headerLen {
+		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
+		return nil, nil, inputError(ErrCiphertextShort, richErr)
+	}
+	header := data[:headerLen]
+	aad := make([]byte, 0, len(label)+headerLen)
+	aad = append(append(aad, label...), header...)
+	plaintext, err := openAEAD(gcm, data[headerLen:], aad)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, plaintext, nil
+}
+
 // Encrypt encrypts a plaintext string using AES-256-GCM authenticated encryption.
 //
 // This is a convenience wrapper around EncryptBytes that works with strings.
@@ -236,7 +670,7 @@ func Encrypt(plaintext string, key []byte) (string, error) {
 //	fmt.Println("Decrypted:", plaintext) // Output: sensitive data
 //
 // The function will return an error if:
-//   - The key size is incorrect
+//   - The key is nil (ErrNilKey) or its size is incorrect (ErrInvalidKeySize)
 //   - The encrypted text is empty
 //   - The base64 decoding fails
 //   - The ciphertext is too short
@@ -249,4 +683,94 @@ func Decrypt(encryptedText string, key []byte) (string, error) {
 	return string(plaintext), nil
 }
 
-// (EncryptWithAAD, DecryptWithAAD and helpers will be added next)
+// EncryptWithAAD encrypts a plaintext byte slice using AES-256-GCM and binds it to additional authenticated data.
+//
+// The additional data (AAD) is not encrypted or included in the output, but it is
+// authenticated: decryption succeeds only if exactly the same AAD is supplied. Use it to
+// bind a ciphertext to its context, such as a record ID or a database column name, so
+// that it cannot be moved to another context undetected.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - aad: The additional authenticated data (can be empty)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptWithAAD([]byte("4111 1111 1111 1111"), key, []byte("user:42"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptWithAAD(plaintext, key, aad []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	return EncryptWith(gcm, plaintext, aad)
+}
+
+// DecryptWithAAD decrypts a ciphertext produced by EncryptWithAAD.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//   - aad: The additional authenticated data supplied at encryption time
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if decryption fails, including when the AAD does not match
+//
+// Example:
+//
+//	plaintext, err := crypto.DecryptWithAAD(ciphertext, key, []byte("user:42"))
+//	if errors.Is(err, crypto.ErrDecrypt) {
+//		// tampered ciphertext, wrong key, or wrong context
+//	}
+func DecryptWithAAD(encryptedText string, key, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptWith(gcm, encryptedText, aad)
+}
+
+// RebindAAD re-encrypts a ciphertext produced by EncryptWithAAD under new additional data.
+//
+// The ciphertext is authenticated with oldAAD, then the plaintext is sealed again with
+// newAAD under a fresh random nonce, and the intermediate plaintext is zeroized. Use it
+// when a record's binding context changes, e.g. when it is reassigned to a new owner.
+// The plaintext never leaves the function, and the original ciphertext stays valid
+// under oldAAD, so callers must replace it in storage.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded ciphertext bound to oldAAD
+//   - key: The 32-byte key (must be exactly KeySize bytes)
+//   - oldAAD: The additional data the ciphertext is currently bound to
+//   - newAAD: The additional data to bind the new ciphertext to
+//
+// Returns:
+//   - A new base64-encoded ciphertext bound to newAAD
+//   - An error if decryption with oldAAD or re-encryption fails
+//
+// Example:
+//
+//	rebound, err := crypto.RebindAAD(ciphertext, key, []byte("owner:alice"), []byte("owner:bob"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func RebindAAD(encryptedText string, key, oldAAD, newAAD []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := DecryptWith(gcm, encryptedText, oldAAD)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(plaintext)
+	return EncryptWith(gcm, plaintext, newAAD)
+}
@@ -0,0 +1,118 @@
+// entropy.go: Health checks for the random number source.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// Parameters of the entropy health check. The thresholds are chosen so that a healthy
+// source fails with a probability below 1e-12 per check.
+const (
+	// entropySampleSize is the number of bytes sampled by CheckEntropySource.
+	entropySampleSize = 4096
+
+	// entropyBlockSize is the block size of the repetition check.
+	entropyBlockSize = 16
+
+	// entropyChiSquareMin and entropyChiSquareMax bound the chi-square statistic of the
+	// byte distribution (255 degrees of freedom, expected value 255). Values below the
+	// minimum indicate suspiciously uniform output, such as a counter.
+	entropyChiSquareMin = 120
+	entropyChiSquareMax = 450
+
+	// entropyMaxBitBias is the largest accepted deviation of the number of set bits from
+	// half the sample size, about 8 standard deviations.
+	entropyMaxBitBias = 724
+)
+
+// ErrEntropy is returned when the random source fails a health check.
+var ErrEntropy = errors.New("crypto: random source failed health check")
+
+// ErrCodeEntropy is the error code for random source health check failures.
+const ErrCodeEntropy = "CRYPTO_ENTROPY"
+
+// CheckEntropySource reads a sample from the random source and runs basic sanity checks on it.
+//
+// The checks detect a broken or stuck source, not a subtly weak one: all-identical
+// output, a biased bit balance (monobit test), an implausible byte distribution
+// (chi-square test in both tails), and repeated 16-byte blocks. A healthy source passes
+// with overwhelming probability. No statistical test can prove that output is
+// unpredictable, so this complements, and does not replace, ensuring the operating
+// system's RNG is seeded (e.g. getrandom(2) blocking until initialization on Linux).
+//
+// Call it before generating long-lived keys on freshly booted VMs or containers.
+//
+// Returns:
+//   - nil if the sample passes all checks
+//   - An error wrapping ErrEntropy describing the failed check, or the read error
+//
+// Example:
+//
+//	if err := crypto.CheckEntropySource(); err != nil {
+//		log.Fatal("refusing to generate keys: ", err)
+//	}
+//	key, err := crypto.GenerateKey()
+func CheckEntropySource() error {
+	sample := make([]byte, entropySampleSize)
+	if _, err := io.ReadFull(rand.Reader, sample); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeEntropy, "failed to read from random source")
+		return fmt.Errorf("%w: %w", ErrEntropy, richErr)
+	}
+	defer Zeroize(sample)
+	return checkEntropySample(sample)
+}
+
+// checkEntropySample runs the health checks on a sample of entropySampleSize bytes.
+func checkEntropySample(sample []byte) error {
+	var counts [256]int
+	ones := 0
+	for _, b := range sample {
+		counts[b]++
+		ones += bits.OnesCount8(b)
+	}
+	if counts[sample[0]] == len(sample) {
+		return entropyError(fmt.Sprintf("sample consists of a single repeated byte 0x%02x", sample[0]))
+	}
+
+	half := len(sample) * 4
+	if ones-half > entropyMaxBitBias || half-ones > entropyMaxBitBias {
+		return entropyError(fmt.Sprintf("bit balance out of range: %d of %d bits set", ones, len(sample)*8))
+	}
+
+	expected := float64(len(sample)) / 256
+	chiSquare := 0.0
+	for _, c := range counts {
+		d := float64(c) - expected
+		chiSquare += d * d / expected
+	}
+	if chiSquare < entropyChiSquareMin || chiSquare > entropyChiSquareMax {
+		return entropyError(fmt.Sprintf("byte distribution implausible: chi-square %.1f", chiSquare))
+	}
+
+	seen := make(map[[entropyBlockSize]byte]struct{}, len(sample)/entropyBlockSize)
+	for i := 0; i+entropyBlockSize <= len(sample); i += entropyBlockSize {
+		block := [entropyBlockSize]byte(sample[i : i+entropyBlockSize])
+		if _, dup := seen[block]; dup {
+			return entropyError(fmt.Sprintf("repeated %d-byte block at offset %d", entropyBlockSize, i))
+		}
+		seen[block] = struct{}{}
+	}
+	return nil
+}
+
+// entropyError builds an error wrapping ErrEntropy.
+func entropyError(msg string) error {
+	richErr := goerrors.New(ErrCodeEntropy, msg)
+	return fmt.Errorf("%w: %w", ErrEntropy, richErr)
+}
@@ -0,0 +1,81 @@
+// entropy_test.go: Test cases for the random source health check.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+// patternReader endlessly repeats a fixed byte pattern.
+type patternReader struct {
+	pattern []byte
+	pos     int
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.pattern[r.pos%len(r.pattern)]
+		r.pos++
+	}
+	return len(p), nil
+}
+
+// maskReader clears bits of an underlying reader's output.
+type maskReader struct {
+	r    io.Reader
+	mask byte
+}
+
+func (m maskReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	for i := range p[:n] {
+		p[i] &= m.mask
+	}
+	return n, err
+}
+
+func TestCheckEntropySource_Healthy(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		if err := crypto.CheckEntropySource(); err != nil {
+			t.Fatalf("CheckEntropySource() error on the system RNG: %v", err)
+		}
+	}
+}
+
+func TestCheckEntropySource_Broken(t *testing.T) {
+	originalReader := rand.Reader
+	defer func() { rand.Reader = originalReader }()
+
+	counter := make([]byte, 256)
+	for i := range counter {
+		counter[i] = byte(i)
+	}
+	loop := make([]byte, 1024)
+	_, _ = io.ReadFull(originalReader, loop)
+
+	sources := map[string]io.Reader{
+		"all zeros":      &patternReader{pattern: []byte{0}},
+		"stuck byte":     &patternReader{pattern: []byte{0x5a}},
+		"counter":        &patternReader{pattern: counter},
+		"short loop":     &patternReader{pattern: loop},
+		"biased":         maskReader{r: originalReader, mask: 0x7f},
+		"ascii alphabet": &patternReader{pattern: bytes.Repeat([]byte("abcdefghijklmnopqrstuvwxyz"), 3)},
+		"failing":        &failingReader{},
+	}
+	for name, source := range sources {
+		rand.Reader = source
+		if err := crypto.CheckEntropySource(); !errors.Is(err, crypto.ErrEntropy) {
+			t.Errorf("%s: expected ErrEntropy, got %v", name, err)
+		}
+	}
+}
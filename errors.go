@@ -0,0 +1,210 @@
+// errors.go: Helpers for matching and inspecting the errors returned by this package.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"errors"
+	"strings"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// errorCodePrefix is the prefix shared by the package's ErrCode constants.
+const errorCodePrefix = "CRYPTO_"
+
+// ErrorCode returns the error code carried by an error returned from this package.
+//
+// Errors are returned as fmt.Errorf("%w: %w", sentinel, richErr): a sentinel for
+// errors.Is and a go-errors value carrying a code and a message. Because the result
+// wraps two errors, errors.Unwrap and goerrors.HasCode do not see the code; ErrorCode
+// walks the whole chain, including such multi-error wrappers. It returns the first
+// CRYPTO_* code found in a depth-first walk, so an outer utility code such as
+// "REKEY_ERROR" does not hide the underlying CRYPTO_DECRYPT. If the chain has no
+// CRYPTO_* code, the first code found is returned.
+//
+// Parameters:
+//   - err: The error to inspect (may be nil)
+//
+// Returns:
+//   - The code, e.g. ErrCodeDecrypt, or an empty string if err carries no code
+//
+// Example:
+//
+//	if _, err := crypto.DecryptBytes(ciphertext, key); err != nil {
+//		metrics.Inc("decrypt_failures", crypto.ErrorCode(err))
+//	}
+func ErrorCode(err error) string {
+	var first string
+	var walk func(error) string
+	walk = func(err error) string {
+		if err == nil {
+			return ""
+		}
+		var code string
+		if e, ok := err.(goerrors.ErrorCoder); ok {
+			code = string(e.ErrorCode())
+		}
+		if strings.HasPrefix(code, errorCodePrefix) {
+			return code
+		}
+		if first == "" {
+			first = code
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, inner := range u.Unwrap() {
+				if code := walk(inner); code != "" {
+					return code
+				}
+			}
+		case interface{ Unwrap() error }:
+			return walk(u.Unwrap())
+		}
+		return ""
+	}
+	if code := walk(err); code != "" {
+		return code
+	}
+	return first
+}
+
+// IsKeySizeError reports whether err was caused by a key of the wrong size, including
+// a nil key (ErrInvalidKeySize, ErrNilKey).
+//
+// Example:
+//
+//	if crypto.IsKeySizeError(err) {
+//		log.Fatal("misconfigured encryption key")
+//	}
+func IsKeySizeError(err error) bool {
+	return errors.Is(err, ErrInvalidKeySize) || errors.Is(err, ErrNilKey)
+}
+
+// IsDecryptError reports whether err is an authentication failure: a tampered or
+// corrupted ciphertext, the wrong key, or the wrong additional data (ErrDecrypt).
+//
+// Example:
+//
+//	if crypto.IsDecryptError(err) {
+//		http.Error(w, "invalid token", http.StatusUnauthorized)
+//	}
+func IsDecryptError(err error) bool {
+	return errors.Is(err, ErrDecrypt)
+}
+
+// IsFormatError reports whether err was caused by malformed input rather than by an
+// authentication failure: empty, truncated or non-base64 ciphertext (ErrEmptyPlaintext,
+// ErrCiphertextShort, ErrBase64Decode), a malformed stream (ErrInvalidStream), age file
+// (ErrInvalidAge) or archive container (ErrInvalidArchive). With strict errors enabled,
+// malformed ciphertexts are reported as ErrDecrypt instead.
+//
+// Example:
+//
+//	if crypto.IsFormatError(err) {
+//		http.Error(w, "malformed ciphertext", http.StatusBadRequest)
+//	}
+func IsFormatError(err error) bool {
+	return errors.Is(err, ErrEmptyPlaintext) || errors.Is(err, ErrCiphertextShort) ||
+		errors.Is(err, ErrBase64Decode) || errors.Is(err, ErrInvalidStream) ||
+		errors.Is(err, ErrInvalidAge) || errors.Is(err, ErrInvalidArchive)
+}
+
+// IsRandomError reports whether err was caused by a failure of the random source
+// (ErrNonceGen, ErrEntropy). Such errors are environmental and may be transient.
+//
+// Example:
+//
+//	if crypto.IsRandomError(err) {
+//		log.Print("random source unavailable; retrying")
+//	}
+func IsRandomError(err error) bool {
+	return errors.Is(err, ErrNonceGen) || errors.Is(err, ErrEntropy)
+}
+
+// IsVerificationError reports whether err is a failed check of a MAC, plaintext hash,
+// signature or key certificate (ErrInvalidMAC, ErrHashMismatch, ErrInvalidSignature,
+// ErrInvalidCertificate). Like IsDecryptError, it means the data or its origin cannot
+// be trusted.
+//
+// Example:
+//
+//	if crypto.IsVerificationError(err) {
+//		log.Printf("rejected unauthenticated message: %v", err)
+//	}
+func IsVerificationError(err error) bool {
+	return errors.Is(err, ErrInvalidMAC) || errors.Is(err, ErrHashMismatch) ||
+		errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrInvalidCertificate)
+}
+
+// IsValidityError reports whether err was caused by data used outside its validity
+// window: an expired ciphertext or key certificate (ErrExpired) or a ciphertext that is
+// not yet valid (ErrNotYetValid). The data itself authenticated.
+//
+// Example:
+//
+//	if crypto.IsValidityError(err) {
+//		http.Error(w, "link expired", http.StatusGone)
+//	}
+func IsValidityError(err error) bool {
+	return errors.Is(err, ErrExpired) || errors.Is(err, ErrNotYetValid)
+}
+
+// IsReplayError reports whether err was caused by a replayed or out-of-order message
+// (ErrReplay).
+//
+// Example:
+//
+//	if crypto.IsReplayError(err) {
+//		conn.Close()
+//	}
+func IsReplayError(err error) bool {
+	return errors.Is(err, ErrReplay)
+}
+
+// IsLimitError reports whether err was caused by input exceeding a size limit
+// (ErrDecompressedTooLarge, ErrStreamTooLarge, ErrCipherDataTooLarge).
+//
+// Example:
+//
+//	if crypto.IsLimitError(err) {
+//		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+//	}
+func IsLimitError(err error) bool {
+	return errors.Is(err, ErrDecompressedTooLarge) || errors.Is(err, ErrStreamTooLarge) ||
+		errors.Is(err, ErrCipherDataTooLarge)
+}
+
+// IsKeyringError reports whether err was caused by the kernel keyring (ErrKeyring,
+// ErrKeyringUnsupported).
+//
+// Example:
+//
+//	if crypto.IsKeyringError(err) {
+//		key, err = loadKeyFromFile(path) // fall back
+//	}
+func IsKeyringError(err error) bool {
+	return errors.Is(err, ErrKeyring) || errors.Is(err, ErrKeyringUnsupported)
+}
+
+// IsSetupError reports whether err means the package or its configuration cannot be
+// used, rather than that an input was bad: cipher initialization (ErrCipherInit,
+// ErrGCMInit), an invalid nonce or tag size (ErrInvalidNonce, ErrInvalidTagSize), an
+// unsupported algorithm (ErrUnsupportedAlgorithm), a Secret without a key
+// (ErrNoSecretKey) or a failed self-test (ErrSelfTest). Such errors do not go away by
+// retrying.
+//
+// Example:
+//
+//	if crypto.IsSetupError(err) {
+//		log.Fatal(err)
+//	}
+func IsSetupError(err error) bool {
+	return errors.Is(err, ErrCipherInit) || errors.Is(err, ErrGCMInit) ||
+		errors.Is(err, ErrInvalidNonce) || errors.Is(err, ErrInvalidTagSize) ||
+		errors.Is(err, ErrUnsupportedAlgorithm) || errors.Is(err, ErrNoSecretKey) ||
+		errors.Is(err, ErrSelfTest)
+}
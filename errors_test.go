@@ -0,0 +1,146 @@
+// errors_test.go: Test cases for error matching helpers.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestErrorCode(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytes([]byte("data"), key)
+
+	_, decryptErr := crypto.DecryptBytes(ciphertext, other)
+	_, keyErr := crypto.EncryptBytes([]byte("data"), key[:16])
+	_, nilKeyErr := crypto.EncryptBytes([]byte("data"), nil)
+	_, formatErr := crypto.DecryptBytes("not base64!", key)
+	_, kdfErr := crypto.DeriveKey(nil, []byte("salt"), 32, nil)
+	// RekeyStream wraps the CRYPTO_DECRYPT error in a REKEY_ERROR.
+	rekeyErr := crypto.RekeyStream(&bytes.Buffer{}, strings.NewReader(ciphertext+"\n"), other, key)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"decrypt", decryptErr, crypto.ErrCodeDecrypt},
+		{"key size", keyErr, crypto.ErrCodeInvalidKey},
+		{"nil key", nilKeyErr, crypto.ErrCodeNilKey},
+		{"base64", formatErr, crypto.ErrCodeBase64Decode},
+		{"caller wrapped", fmt.Errorf("loading record: %w", decryptErr), crypto.ErrCodeDecrypt},
+		{"outer utility code", rekeyErr, crypto.ErrCodeDecrypt},
+		{"utility code only", kdfErr, "EMPTY_PASSWORD"},
+		{"plain error", errors.New("boom"), ""},
+	}
+	for _, tc := range tests {
+		if got := crypto.ErrorCode(tc.err); got != tc.want {
+			t.Errorf("%s: ErrorCode() = %q, want %q (err: %v)", tc.name, got, tc.want, tc.err)
+		}
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytes([]byte("data"), key)
+
+	_, decryptErr := crypto.DecryptBytes(ciphertext, other)
+	_, keyErr := crypto.EncryptBytes([]byte("data"), nil)
+	_, shortErr := crypto.DecryptBytes("AAAA", key)
+	streamErr := crypto.DecryptStream(&bytes.Buffer{}, strings.NewReader("x"), key)
+	wrapped := fmt.Errorf("handler: %w", decryptErr)
+
+	if !crypto.IsDecryptError(decryptErr) || !crypto.IsDecryptError(wrapped) || crypto.IsDecryptError(keyErr) {
+		t.Error("IsDecryptError mismatch")
+	}
+	if !crypto.IsKeySizeError(keyErr) || crypto.IsKeySizeError(decryptErr) {
+		t.Error("IsKeySizeError mismatch")
+	}
+	if !crypto.IsFormatError(shortErr) || !crypto.IsFormatError(streamErr) || crypto.IsFormatError(decryptErr) {
+		t.Error("IsFormatError mismatch")
+	}
+	if crypto.IsRandomError(decryptErr) || crypto.IsRandomError(nil) {
+		t.Error("IsRandomError mismatch")
+	}
+	for _, is := range []func(error) bool{crypto.IsDecryptError, crypto.IsKeySizeError, crypto.IsFormatError, crypto.IsRandomError} {
+		if is(nil) {
+			t.Error("Expected predicates to be false for nil")
+		}
+	}
+}
+
+func TestErrorPredicates_AllSentinels(t *testing.T) {
+	predicates := map[string]func(error) bool{
+		"IsKeySizeError":      crypto.IsKeySizeError,
+		"IsDecryptError":      crypto.IsDecryptError,
+		"IsFormatError":       crypto.IsFormatError,
+		"IsRandomError":       crypto.IsRandomError,
+		"IsVerificationError": crypto.IsVerificationError,
+		"IsValidityError":     crypto.IsValidityError,
+		"IsReplayError":       crypto.IsReplayError,
+		"IsLimitError":        crypto.IsLimitError,
+		"IsKeyringError":      crypto.IsKeyringError,
+		"IsSetupError":        crypto.IsSetupError,
+	}
+	sentinels := map[error]string{
+		crypto.ErrInvalidKeySize:       "IsKeySizeError",
+		crypto.ErrNilKey:               "IsKeySizeError",
+		crypto.ErrDecrypt:              "IsDecryptError",
+		crypto.ErrEmptyPlaintext:       "IsFormatError",
+		crypto.ErrCiphertextShort:      "IsFormatError",
+		crypto.ErrBase64Decode:         "IsFormatError",
+		crypto.ErrInvalidStream:        "IsFormatError",
+		crypto.ErrInvalidAge:           "IsFormatError",
+		crypto.ErrInvalidArchive:       "IsFormatError",
+		crypto.ErrNonceGen:             "IsRandomError",
+		crypto.ErrEntropy:              "IsRandomError",
+		crypto.ErrInvalidMAC:           "IsVerificationError",
+		crypto.ErrHashMismatch:         "IsVerificationError",
+		crypto.ErrInvalidSignature:     "IsVerificationError",
+		crypto.ErrInvalidCertificate:   "IsVerificationError",
+		crypto.ErrExpired:              "IsValidityError",
+		crypto.ErrNotYetValid:          "IsValidityError",
+		crypto.ErrReplay:               "IsReplayError",
+		crypto.ErrDecompressedTooLarge: "IsLimitError",
+		crypto.ErrStreamTooLarge:       "IsLimitError",
+		crypto.ErrCipherDataTooLarge:   "IsLimitError",
+		crypto.ErrKeyring:              "IsKeyringError",
+		crypto.ErrKeyringUnsupported:   "IsKeyringError",
+		crypto.ErrCipherInit:           "IsSetupError",
+		crypto.ErrGCMInit:              "IsSetupError",
+		crypto.ErrInvalidNonce:         "IsSetupError",
+		crypto.ErrInvalidTagSize:       "IsSetupError",
+		crypto.ErrUnsupportedAlgorithm: "IsSetupError",
+		crypto.ErrNoSecretKey:          "IsSetupError",
+		crypto.ErrSelfTest:             "IsSetupError",
+	}
+	for sentinel, want := range sentinels {
+		err := fmt.Errorf("caller: %w", sentinel)
+		for name, is := range predicates {
+			if got := is(err); got != (name == want) {
+				t.Errorf("%s(%v) = %v", name, sentinel, got)
+			}
+			if is(nil) {
+				t.Errorf("%s(nil) = true", name)
+			}
+		}
+	}
+
+	// Errors returned by the package match too, not just the bare sentinels.
+	_, _, certErr := crypto.VerifyKeyCertificate([]byte("not a certificate"))
+	if !crypto.IsVerificationError(certErr) {
+		t.Errorf("IsVerificationError(%v) = false", certErr)
+	}
+}
@@ -0,0 +1,105 @@
+// expiry.go: Ciphertexts carrying an authenticated expiry time.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// expiryHeaderSize is the size of the expiry header: Unix milliseconds as a big-endian int64.
+const expiryHeaderSize = 8
+
+// expiryLabel domain-separates expiring envelopes from other authenticated headers.
+const expiryLabel = "go-crypto/v1/expiry"
+
+// ErrExpired is returned when an authentic ciphertext is past its expiry time.
+var ErrExpired = errors.New("crypto: ciphertext expired")
+
+// ErrCodeExpired is the error code for expired ciphertexts.
+const ErrCodeExpired = "CRYPTO_EXPIRED"
+
+// EncryptWithExpiry encrypts plaintext and embeds an authenticated expiry time ttl from now.
+//
+// The expiry is stored in clear, with millisecond precision, in front of the envelope
+// and authenticated as additional data, so it cannot be extended without the key. This
+// gives short-lived encrypted tokens (password reset links, session handoffs) without a
+// separate signing layer. Expiry is checked against the decrypting machine's clock, so
+// allow for clock skew between hosts when choosing ttl.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - ttl: How long the ciphertext stays valid (must be positive)
+//
+// Returns:
+//   - A base64-encoded string containing the expiry, nonce, ciphertext and tag
+//   - An error if ttl is not positive or encryption fails
+//
+// Example:
+//
+//	token, err := crypto.EncryptWithExpiry([]byte(userID), key, 15*time.Minute)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptWithExpiry(plaintext, key []byte, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", goerrors.New("INVALID_TTL", "ttl must be positive")
+	}
+	expiry := time.Now().Add(ttl)
+	var header [expiryHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:], uint64(expiry.UnixMilli()))
+	out, err := sealWithHeader(key, expiryLabel, header[:], plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// DecryptWithExpiry decrypts a ciphertext produced by EncryptWithExpiry if it has not expired.
+//
+// Authenticity is verified first: a tampered or foreign ciphertext fails with ErrDecrypt
+// even if its expiry has passed, so ErrExpired always refers to a genuine token.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error wrapping ErrExpired if the expiry time has passed, or another error if
+//     decryption fails
+//
+// Example:
+//
+//	userID, err := crypto.DecryptWithExpiry(token, key)
+//	if errors.Is(err, crypto.ErrExpired) {
+//		http.Error(w, "link expired", http.StatusGone)
+//		return
+//	}
+func DecryptWithExpiry(encryptedText string, key []byte) ([]byte, error) {
+	data, err := decodeCiphertext(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+	header, plaintext, err := openWithHeader(key, expiryLabel, data, expiryHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	expiry := time.UnixMilli(int64(binary.BigEndian.Uint64(header)))
+	if !time.Now().Before(expiry) {
+		Zeroize(plaintext)
+		richErr := goerrors.New(ErrCodeExpired, fmt.Sprintf("ciphertext expired at %s", expiry.UTC().Format(time.RFC3339)))
+		return nil, fmt.Errorf("%w: %w", ErrExpired, richErr)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,72 @@
+// expiry_test.go: Test cases for expiring ciphertexts.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptWithExpiry_Valid(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	token, err := crypto.EncryptWithExpiry([]byte("user:42"), key, time.Minute)
+	if err != nil {
+		t.Fatalf("EncryptWithExpiry() error: %v", err)
+	}
+	plaintext, err := crypto.DecryptWithExpiry(token, key)
+	if err != nil || string(plaintext) != "user:42" {
+		t.Fatalf("DecryptWithExpiry() = %q, %v", plaintext, err)
+	}
+}
+
+func TestDecryptWithExpiry_Expired(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	token, err := crypto.EncryptWithExpiry([]byte("user:42"), key, time.Millisecond)
+	if err != nil {
+		t.Fatalf("EncryptWithExpiry() error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := crypto.DecryptWithExpiry(token, key); !errors.Is(err, crypto.ErrExpired) {
+		t.Errorf("expired token: got %v, want ErrExpired", err)
+	}
+
+	// Authenticity is checked before expiry.
+	other, _ := crypto.GenerateKey()
+	if _, err := crypto.DecryptWithExpiry(token, other); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("expired token, wrong key: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestDecryptWithExpiry_ExtendedExpiryRejected(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	token, _ := crypto.EncryptWithExpiry([]byte("user:42"), key, time.Millisecond)
+	raw, _ := base64.StdEncoding.DecodeString(token)
+	raw[0] = 0x7f // push the expiry far into the future
+	if _, err := crypto.DecryptWithExpiry(base64.StdEncoding.EncodeToString(raw), key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("tampered expiry: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestEncryptWithExpiry_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		if _, err := crypto.EncryptWithExpiry([]byte("x"), key, ttl); err == nil {
+			t.Errorf("EncryptWithExpiry(ttl=%v) succeeded", ttl)
+		}
+	}
+	if _, err := crypto.EncryptWithExpiry([]byte("x"), make([]byte, 16), time.Minute); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+	plain, _ := crypto.EncryptBytes([]byte("x"), key)
+	if _, err := crypto.DecryptWithExpiry(plain, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptWithExpiry() of plain envelope: got %v, want ErrDecrypt", err)
+	}
+}
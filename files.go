@@ -0,0 +1,186 @@
+// files.go: File helpers for integrity manifests and secure deletion.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// FileErrors collects per-file failures, keyed by path.
+//
+// It is returned by HashFiles when some files could not be processed; the
+// fingerprints of the remaining files are still returned.
+type FileErrors map[string]error
+
+// Error implements the error interface, listing the failing paths in sorted order.
+func (e FileErrors) Error() string {
+	paths := make([]string, 0, len(e))
+	for path := range e {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	msgs := make([]string, len(paths))
+	for i, path := range paths {
+		msgs[i] = fmt.Sprintf("%s: %v", path, e[path])
+	}
+	return fmt.Sprintf("crypto: failed to process %d file(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// HashFiles computes fingerprints for a list of files, suitable for an integrity manifest.
+//
+// Each file is streamed through SHA-256 (see FingerprintReader), so memory usage does
+// not depend on file sizes. Files are processed in parallel with at most GOMAXPROCS
+// files open at a time. A file that cannot be read does not abort the batch: its error
+// is collected and the other files are still hashed.
+//
+// Parameters:
+//   - paths: The files to fingerprint
+//
+// Returns:
+//   - A map from path to fingerprint for every file that was hashed successfully
+//   - nil if every file was hashed, or a FileErrors value describing each failure
+//
+// Example:
+//
+//	manifest, err := crypto.HashFiles([]string{"bin/app", "config.yaml"})
+//	var failures crypto.FileErrors
+//	if errors.As(err, &failures) {
+//		for path, ferr := range failures {
+//			log.Printf("skipped %s: %v", path, ferr)
+//		}
+//	}
+//	for path, fingerprint := range manifest {
+//		fmt.Println(fingerprint, path)
+//	}
+func HashFiles(paths []string) (map[string]string, error) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		results  = make(map[string]string, len(paths))
+		failures = FileErrors{}
+		jobs     = make(chan string)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				fingerprint, err := fingerprintFile(path)
+				mu.Lock()
+				if err != nil {
+					failures[path] = err
+				} else {
+					results[path] = fingerprint
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return results, failures
+	}
+	return results, nil
+}
+
+// fingerprintFile streams a single file through FingerprintReader.
+func fingerprintFile(path string) (string, error) {
+	f, err := os.Open(filepath.Clean(path)) // #nosec G304 -- paths are chosen by the caller
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	return FingerprintReader(f)
+}
+
+// SecureDeleteFile overwrites a file with random data and then removes it.
+//
+// The file's contents are overwritten passes times with bytes from crypto/rand, each
+// pass flushed to stable storage with fsync, before the file is unlinked. Symbolic
+// links and non-regular files are rejected.
+//
+// Limitations: overwriting in place only reaches the original blocks on filesystems
+// that update data in place. On SSDs and flash storage (wear leveling), copy-on-write
+// filesystems (Btrfs, ZFS, APFS), journaling modes that journal data, snapshots and
+// backups, old copies of the data may survive. For strong guarantees, keep secrets
+// encrypted at rest and destroy the key instead, or use full-disk encryption.
+//
+// Parameters:
+//   - path: The file to erase
+//   - passes: The number of overwrite passes (must be at least 1)
+//
+// Returns:
+//   - An error if the file cannot be overwritten or removed
+//
+// Example:
+//
+//	if err := crypto.SecureDeleteFile("export.csv", 1); err != nil {
+//		log.Fatal(err)
+//	}
+func SecureDeleteFile(path string, passes int) error {
+	if passes < 1 {
+		return goerrors.New("INVALID_PASSES", "number of overwrite passes must be at least 1")
+	}
+	path = filepath.Clean(path)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return goerrors.Wrap(err, "SECURE_DELETE_ERROR", "failed to stat file")
+	}
+	if !info.Mode().IsRegular() {
+		return goerrors.New("SECURE_DELETE_ERROR", fmt.Sprintf("%s is not a regular file", path))
+	}
+	if err := overwriteFile(path, info.Size(), passes); err != nil {
+		return goerrors.Wrap(err, "SECURE_DELETE_ERROR", "failed to overwrite file")
+	}
+	if err := os.Remove(path); err != nil {
+		return goerrors.Wrap(err, "SECURE_DELETE_ERROR", "failed to remove file")
+	}
+	return nil
+}
+
+// overwriteFile writes size random bytes over the start of path, passes times.
+func overwriteFile(path string, size int64, passes int) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0) // #nosec G304 -- paths are chosen by the caller
+	if err != nil {
+		return err
+	}
+	for i := 0; i < passes; i++ {
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			break
+		}
+		if _, err = io.CopyN(f, rand.Reader, size); err != nil {
+			break
+		}
+		if err = f.Sync(); err != nil {
+			break
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
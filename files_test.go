@@ -0,0 +1,140 @@
+// files_test.go: Test cases for file helpers.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestFingerprintReader_MatchesKeyFingerprint(t *testing.T) {
+	data := []byte("some data to fingerprint")
+	fingerprint, err := crypto.FingerprintReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("FingerprintReader() error: %v", err)
+	}
+	if fingerprint != crypto.GetKeyFingerprint(data) {
+		t.Errorf("Expected %s, got %s", crypto.GetKeyFingerprint(data), fingerprint)
+	}
+}
+
+func TestHashFiles(t *testing.T) {
+	dir := t.TempDir()
+	contents := map[string][]byte{
+		"a.txt": []byte("alpha"),
+		"b.txt": []byte("bravo"),
+		"c.bin": bytes.Repeat([]byte{0xAB}, 1<<20),
+	}
+	var paths []string
+	for name, data := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	manifest, err := crypto.HashFiles(paths)
+	if err != nil {
+		t.Fatalf("HashFiles() error: %v", err)
+	}
+	for name, data := range contents {
+		path := filepath.Join(dir, name)
+		if manifest[path] != crypto.GetKeyFingerprint(data) {
+			t.Errorf("Unexpected fingerprint for %s: %s", name, manifest[path])
+		}
+	}
+}
+
+func TestHashFiles_CollectsErrors(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.txt")
+	if err := os.WriteFile(good, []byte("ok"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.txt")
+
+	manifest, err := crypto.HashFiles([]string{good, missing, dir})
+	var failures crypto.FileErrors
+	if !errors.As(err, &failures) {
+		t.Fatalf("Expected FileErrors, got %v", err)
+	}
+	if len(failures) != 2 || failures[missing] == nil || failures[dir] == nil {
+		t.Errorf("Expected failures for missing file and directory, got %v", failures)
+	}
+	if manifest[good] != crypto.GetKeyFingerprint([]byte("ok")) {
+		t.Error("Expected readable file to be hashed despite other failures")
+	}
+	if err.Error() == "" {
+		t.Error("Expected non-empty error message")
+	}
+}
+
+func TestHashFiles_Empty(t *testing.T) {
+	manifest, err := crypto.HashFiles(nil)
+	if err != nil || len(manifest) != 0 {
+		t.Errorf("Expected empty manifest without error, got %v, %v", manifest, err)
+	}
+}
+
+func TestSecureDeleteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret.txt")
+	secret := bytes.Repeat([]byte("hunter2 "), 10000)
+	if err := os.WriteFile(path, secret, 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	// A hard link keeps the inode reachable so the overwrite can be observed.
+	witness := filepath.Join(dir, "witness")
+	if err := os.Link(path, witness); err != nil {
+		t.Skipf("hard links not supported: %v", err)
+	}
+
+	if err := crypto.SecureDeleteFile(path, 2); err != nil {
+		t.Fatalf("SecureDeleteFile() error: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected file to be removed, got %v", err)
+	}
+	remaining, err := os.ReadFile(witness)
+	if err != nil {
+		t.Fatalf("ReadFile() error: %v", err)
+	}
+	if len(remaining) != len(secret) || bytes.Contains(remaining, []byte("hunter2")) {
+		t.Error("Expected file contents to be overwritten in place")
+	}
+}
+
+func TestSecureDeleteFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	_ = os.WriteFile(path, []byte("x"), 0o600)
+
+	if err := crypto.SecureDeleteFile(path, 0); err == nil {
+		t.Error("Expected error for zero passes")
+	}
+	if err := crypto.SecureDeleteFile(filepath.Join(dir, "missing"), 1); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if err := crypto.SecureDeleteFile(dir, 1); err == nil {
+		t.Error("Expected error for directory")
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(path, link); err == nil {
+		if err := crypto.SecureDeleteFile(link, 1); err == nil {
+			t.Error("Expected error for symbolic link")
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected target file to be untouched, got %v", err)
+	}
+}
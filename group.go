@@ -0,0 +1,145 @@
+// group.go: Authenticated bundles of named fields sealed as one ciphertext.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// groupLabel domain-separates group envelopes from other authenticated headers.
+const groupLabel = "go-crypto/v1/group"
+
+// EncryptGroup encrypts several named fields into a single authenticated ciphertext.
+//
+// The fields are serialized in sorted name order as count || (name length || name ||
+// value length || value)..., with big-endian 32-bit lengths, and the whole encoding is
+// sealed once with AES-256-GCM. The group is therefore authenticated as a unit: a field
+// cannot be removed, added, renamed or swapped with a field from another group without
+// DecryptGroup failing. Encrypting the fields separately would allow exactly that
+// cut-and-paste. The encoding is canonical, so map iteration order does not matter.
+//
+// Parameters:
+//   - plaintexts: The fields to encrypt, keyed by name (may be empty; values can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if the key is invalid, a field is too large, or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptGroup(map[string][]byte{
+//		"iban":  []byte("DE89 3704 0044 0532 0130 00"),
+//		"owner": []byte("Alice"),
+//	}, key)
+func EncryptGroup(plaintexts map[string][]byte, key []byte) (string, error) {
+	names := make([]string, 0, len(plaintexts))
+	size := 4
+	for name, value := range plaintexts {
+		if uint64(len(name)) > math.MaxUint32 || uint64(len(value)) > math.MaxUint32 {
+			return "", goerrors.New("FIELD_TOO_LARGE", fmt.Sprintf("field %q exceeds the 4 GiB group field limit", name))
+		}
+		names = append(names, name)
+		size += 8 + len(name) + len(value)
+	}
+	sort.Strings(names)
+
+	encoded := make([]byte, 4, size)
+	binary.BigEndian.PutUint32(encoded, uint32(len(names)))
+	for _, name := range names {
+		encoded = appendLengthPrefixed(encoded, name)
+		encoded = appendLengthPrefixed(encoded, plaintexts[name])
+	}
+	defer Zeroize(encoded)
+
+	out, err := sealWithHeader(key, groupLabel, nil, encoded)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// DecryptGroup authenticates and decrypts a ciphertext produced by EncryptGroup.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded group ciphertext
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The fields, keyed by name
+//   - An error wrapping ErrDecrypt if authentication fails or the group is malformed
+//
+// Example:
+//
+//	fields, err := crypto.DecryptGroup(ciphertext, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	owner := fields["owner"]
+func DecryptGroup(ciphertext string, key []byte) (map[string][]byte, error) {
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	_, encoded, err := openWithHeader(key, groupLabel, data, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(encoded)
+
+	if len(encoded) < 4 {
+		return nil, groupError("group encoding truncated")
+	}
+	count := binary.BigEndian.Uint32(encoded)
+	rest := encoded[4:]
+	// Every field takes at least 8 bytes, which bounds the map size by the input.
+	if uint64(count) > uint64(len(rest)/8) {
+		return nil, groupError(fmt.Sprintf("group declares %d fields in %d bytes", count, len(rest)))
+	}
+	fields := make(map[string][]byte, count)
+	for i := uint32(0); i < count; i++ {
+		var name, value []byte
+		if name, rest, err = readGroupField(rest); err == nil {
+			value, rest, err = readGroupField(rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := fields[string(name)]; dup {
+			return nil, groupError(fmt.Sprintf("duplicate field %q", name))
+		}
+		fields[string(name)] = append([]byte{}, value...)
+	}
+	if len(rest) != 0 {
+		return nil, groupError(fmt.Sprintf("%d trailing bytes after group fields", len(rest)))
+	}
+	return fields, nil
+}
+
+// readGroupField splits a length-prefixed field off the front of data.
+func readGroupField(data []byte) (field, rest []byte, err error) {
+	if len(data) < 4 {
+		return nil, nil, groupError("group encoding truncated")
+	}
+	n := binary.BigEndian.Uint32(data)
+	if uint64(n) > uint64(len(data)-4) {
+		return nil, nil, groupError("group field length exceeds encoding")
+	}
+	return data[4 : 4+n], data[4+n:], nil
+}
+
+// groupError builds an error wrapping ErrDecrypt for a malformed group encoding.
+func groupError(msg string) error {
+	richErr := goerrors.New(ErrCodeDecrypt, msg)
+	return inputError(ErrDecrypt, richErr)
+}
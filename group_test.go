@@ -0,0 +1,94 @@
+// group_test.go: Test cases for authenticated field groups.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptGroup_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	fields := map[string][]byte{
+		"iban":  []byte("DE89 3704 0044 0532 0130 00"),
+		"owner": []byte("Alice"),
+		"note":  {},
+	}
+	ciphertext, err := crypto.EncryptGroup(fields, key)
+	if err != nil {
+		t.Fatalf("EncryptGroup() error: %v", err)
+	}
+	got, err := crypto.DecryptGroup(ciphertext, key)
+	if err != nil {
+		t.Fatalf("DecryptGroup() error: %v", err)
+	}
+	if len(got) != len(fields) {
+		t.Fatalf("DecryptGroup() returned %d fields, want %d", len(got), len(fields))
+	}
+	for name, want := range fields {
+		value, ok := got[name]
+		if !ok || string(value) != string(want) {
+			t.Errorf("field %q = %q (present %v), want %q", name, value, ok, want)
+		}
+	}
+}
+
+func TestEncryptGroup_Empty(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, err := crypto.EncryptGroup(nil, key)
+	if err != nil {
+		t.Fatalf("EncryptGroup(nil) error: %v", err)
+	}
+	got, err := crypto.DecryptGroup(ciphertext, key)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("DecryptGroup() = %v, %v; want empty map", got, err)
+	}
+}
+
+func TestDecryptGroup_Tampering(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptGroup(map[string][]byte{"a": []byte("1"), "b": []byte("2")}, key)
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+
+	for i := range raw {
+		tampered := append([]byte(nil), raw...)
+		tampered[i] ^= 0x01
+		if _, err := crypto.DecryptGroup(base64.StdEncoding.EncodeToString(tampered), key); !errors.Is(err, crypto.ErrDecrypt) {
+			t.Fatalf("flipping byte %d: got %v, want ErrDecrypt", i, err)
+		}
+	}
+
+	other, _ := crypto.GenerateKey()
+	if _, err := crypto.DecryptGroup(ciphertext, other); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong key: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestDecryptGroup_RejectsOtherEnvelopes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	// A plain envelope holding a valid group encoding must not be accepted as a group.
+	plain, _ := crypto.EncryptBytes([]byte{0, 0, 0, 0}, key)
+	if _, err := crypto.DecryptGroup(plain, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptGroup(EncryptBytes output): got %v, want ErrDecrypt", err)
+	}
+	group, _ := crypto.EncryptGroup(map[string][]byte{"a": []byte("1")}, key)
+	if _, err := crypto.DecryptBytes(group, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptBytes(EncryptGroup output): got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestEncryptGroup_InvalidKey(t *testing.T) {
+	if _, err := crypto.EncryptGroup(map[string][]byte{"a": nil}, make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("EncryptGroup(16-byte key): got %v, want ErrInvalidKeySize", err)
+	}
+	if _, err := crypto.DecryptGroup("", make([]byte, 32)); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("DecryptGroup(\"\"): got %v, want ErrEmptyPlaintext", err)
+	}
+}
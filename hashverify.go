@@ -0,0 +1,64 @@
+// hashverify.go: Decryption checked against an out-of-band published hash.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// ErrHashMismatch is returned when decrypted data does not match the expected hash.
+var ErrHashMismatch = errors.New("crypto: plaintext hash mismatch")
+
+// ErrCodeHashMismatch is the error code for plaintext hash mismatches.
+const ErrCodeHashMismatch = "CRYPTO_HASH_MISMATCH"
+
+// DecryptAndVerifyHash decrypts a ciphertext produced by EncryptBytes and checks that
+// the SHA-256 of the plaintext equals expectedSHA256.
+//
+// The GCM tag proves the ciphertext was produced by a key holder; the hash check adds
+// end-to-end integrity against a digest published separately, e.g. the release hash
+// of an encrypted artifact, so a key holder cannot substitute different content. The
+// comparison runs in constant time. On mismatch the plaintext is zeroized and not returned.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//   - expectedSHA256: The expected SHA-256 digest of the plaintext (32 bytes)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error wrapping ErrHashMismatch if the digest differs or has the wrong length,
+//     or another error if decryption fails
+//
+// Example:
+//
+//	artifact, err := crypto.DecryptAndVerifyHash(ciphertext, key, publishedDigest)
+//	if errors.Is(err, crypto.ErrHashMismatch) {
+//		log.Fatal("artifact does not match the published hash")
+//	}
+func DecryptAndVerifyHash(encryptedText string, key []byte, expectedSHA256 []byte) ([]byte, error) {
+	if len(expectedSHA256) != sha256.Size {
+		richErr := goerrors.New(ErrCodeHashMismatch, fmt.Sprintf("expected hash must be %d bytes (got %d)", sha256.Size, len(expectedSHA256)))
+		return nil, fmt.Errorf("%w: %w", ErrHashMismatch, richErr)
+	}
+	plaintext, err := DecryptBytes(encryptedText, key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(plaintext)
+	if subtle.ConstantTimeCompare(sum[:], expectedSHA256) != 1 {
+		Zeroize(plaintext)
+		richErr := goerrors.New(ErrCodeHashMismatch, "SHA-256 of the decrypted data does not match the expected hash")
+		return nil, fmt.Errorf("%w: %w", ErrHashMismatch, richErr)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,44 @@
+// hashverify_test.go: Test cases for hash-verified decryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestDecryptAndVerifyHash(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	artifact := []byte("release-1.2.3.tar.gz contents")
+	ciphertext, err := crypto.EncryptBytes(artifact, key)
+	if err != nil {
+		t.Fatalf("EncryptBytes() error: %v", err)
+	}
+	digest := sha256.Sum256(artifact)
+
+	plaintext, err := crypto.DecryptAndVerifyHash(ciphertext, key, digest[:])
+	if err != nil || string(plaintext) != string(artifact) {
+		t.Fatalf("DecryptAndVerifyHash() = %q, %v", plaintext, err)
+	}
+
+	wrong := sha256.Sum256([]byte("something else"))
+	plaintext, err = crypto.DecryptAndVerifyHash(ciphertext, key, wrong[:])
+	if !errors.Is(err, crypto.ErrHashMismatch) || plaintext != nil {
+		t.Errorf("wrong hash: got %q, %v, want ErrHashMismatch", plaintext, err)
+	}
+	if _, err := crypto.DecryptAndVerifyHash(ciphertext, key, digest[:16]); !errors.Is(err, crypto.ErrHashMismatch) {
+		t.Errorf("short hash: got %v, want ErrHashMismatch", err)
+	}
+
+	other, _ := crypto.GenerateKey()
+	if _, err := crypto.DecryptAndVerifyHash(ciphertext, other, digest[:]); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong key: got %v, want ErrDecrypt", err)
+	}
+}
@@ -8,6 +8,9 @@ package crypto
 
 import (
 	"crypto/sha256"
+	"fmt"
+	"math"
+	"time"
 
 	goerrors "github.com/agilira/go-errors"
 	"golang.org/x/crypto/argon2"
@@ -64,6 +67,167 @@ type KDFParams struct {
 	Threads uint8 `json:"threads,omitempty"`
 }
 
+// resolve returns the effective Argon2id parameters, with memory in KiB.
+// Zero fields (or a nil receiver) resolve to the package defaults.
+func (p *KDFParams) resolve() (time, memoryKiB uint32, threads uint8) {
+	time = uint32(DefaultTime)
+	memoryKiB = uint32(DefaultMemory * 1024)
+	threads = uint8(DefaultThreads)
+	if p != nil {
+		if p.Time > 0 {
+			time = p.Time
+		}
+		if p.Memory > 0 {
+			memoryKiB = p.Memory * 1024
+		}
+		if p.Threads > 0 {
+			threads = p.Threads
+		}
+	}
+	return time, memoryKiB, threads
+}
+
+// Equal reports whether p and other describe the same effective Argon2id parameters.
+//
+// Fields are compared after default substitution, so a zero field equals the default it
+// resolves to and a nil receiver or argument equals DefaultKDFParams(). Use it to detect
+// drift between parameters loaded from configuration and the expected policy.
+//
+// Parameters:
+//   - other: The parameters to compare with (may be nil)
+//
+// Returns:
+//   - true if both resolve to the same time, memory and thread values
+//
+// Example:
+//
+//	if !loaded.Equal(crypto.HardenedKDFParams()) {
+//		log.Printf("KDF parameters differ from the hardened policy: %+v", loaded)
+//	}
+func (p *KDFParams) Equal(other *KDFParams) bool {
+	t1, m1, th1 := p.resolve()
+	t2, m2, th2 := other.resolve()
+	return t1 == t2 && m1 == m2 && th1 == th2
+}
+
+// DefaultKDFParams returns the parameters used when nil is passed to DeriveKey:
+// Time: 3, Memory: 64 MB, Threads: 4.
+//
+// Each call returns a new value that the caller may modify.
+func DefaultKDFParams() *KDFParams {
+	return &KDFParams{Time: DefaultTime, Memory: DefaultMemory, Threads: DefaultThreads}
+}
+
+// HardenedKDFParams returns stronger parameters for high-value secrets:
+// Time: 4, Memory: 256 MB, Threads: 4.
+//
+// Derivation takes roughly four to five times as long as with the defaults and needs
+// 256 MB of memory per concurrent call, so size worker pools accordingly. Each call
+// returns a new value that the caller may modify.
+func HardenedKDFParams() *KDFParams {
+	return &KDFParams{Time: 4, Memory: 256, Threads: DefaultThreads}
+}
+
+// owaspArgon2idMinimums lists the OWASP Password Storage Cheat Sheet (2023) minimum
+// Argon2id configurations as memory in MiB indexed by iterations - 1. They trade
+// memory for iterations at roughly equal cost; beyond five iterations the last
+// entry applies.
+var owaspArgon2idMinimums = [...]uint32{46, 19, 12, 9, 7}
+
+// SecurityLevel returns a human-readable assessment of Argon2id parameters against
+// current published guidance.
+//
+// Parameters are assessed after default substitution, as DeriveKey would use them.
+// The result starts with one of three classifications, followed by the effective
+// values and, when the parameters fall short, the memory needed to comply:
+//   - "RFC 9106 recommended": at least 64 MiB and 3 iterations, the second recommended
+//     option of RFC 9106, which also exceeds the OWASP minimums
+//   - "OWASP-2023 compliant": meets one of the OWASP minimum configurations
+//     (46 MiB/t=1, 19 MiB/t=2, 12 MiB/t=3, 9 MiB/t=4, 7 MiB/t=5)
+//   - "below recommended memory": too little memory for the iteration count
+//
+// The assessment only covers the parameters. It says nothing about password
+// strength, salt handling, or whether the derivation time is acceptable on the
+// target hardware; benchmark that separately.
+//
+// Parameters:
+//   - params: The parameters to assess (nil for the defaults)
+//
+// Returns:
+//   - The assessment string
+//
+// Example:
+//
+//	fmt.Println(crypto.SecurityLevel(nil))
+//	// Output: RFC 9106 recommended (t=3, 64 MiB, p=4)
+//	fmt.Println(crypto.SecurityLevel(&crypto.KDFParams{Time: 1, Memory: 16}))
+//	// Output: below recommended memory (t=1, 16 MiB, p=4): OWASP-2023 requires at least 46 MiB at t=1
+func SecurityLevel(params *KDFParams) string {
+	time, memoryKiB, threads := params.resolve()
+	memoryMiB := memoryKiB / 1024
+	values := fmt.Sprintf("t=%d, %d MiB, p=%d", time, memoryMiB, threads)
+
+	if time >= 3 && memoryMiB >= 64 {
+		return "RFC 9106 recommended (" + values + ")"
+	}
+	i := min(time, uint32(len(owaspArgon2idMinimums))) - 1
+	required := owaspArgon2idMinimums[i]
+	if memoryMiB >= required {
+		return "OWASP-2023 compliant (" + values + ")"
+	}
+	return fmt.Sprintf("below recommended memory (%s): OWASP-2023 requires at least %d MiB at t=%d", values, required, i+1)
+}
+
+// Reference attacker model of EstimateCrackTime.
+const (
+	// crackReferenceBandwidth is the memory bandwidth assumed for one attacking GPU, in
+	// bytes per second (about 1 TB/s, a current high-end card).
+	crackReferenceBandwidth = 1e12
+
+	// argon2BytesPerBlockPass is the memory traffic per block and pass, in multiples of
+	// the block size: each block is computed from two blocks read and is written once.
+	argon2BytesPerBlockPass = 3
+)
+
+// EstimateCrackTime returns a rough estimate of the time one GPU needs to brute-force a
+// password protected with the given Argon2id parameters.
+//
+// Argon2id is memory-hard, so an attacker's guess rate is bound by memory bandwidth
+// rather than compute. Each guess is costed as the memory traffic of the derivation
+// (3 * memory * iterations bytes) at a reference bandwidth of 1 TB/s, and the expected
+// number of guesses is 2^(entropy-1), half the search space. The thread count does not
+// change the cost: an attacker parallelizes across guesses instead. With the defaults
+// (64 MiB, t=3) one guess costs about 0.6 ms, so a 40-bit password lasts about ten
+// GPU-years.
+//
+// This is an order-of-magnitude figure for communicating a security margin, not a
+// guarantee. It ignores specialized hardware, time-memory trade-offs, and the
+// attacker's number of GPUs (divide the result by it), and the entropy of real
+// passwords is usually far lower than their length suggests. Results beyond the range
+// of time.Duration (about 292 years) saturate at its maximum value.
+//
+// Parameters:
+//   - params: The Argon2id parameters (nil for the defaults)
+//   - passwordEntropyBits: The assumed password entropy in bits (negative values count as 0)
+//
+// Returns:
+//   - The estimated single-GPU time to find the password
+//
+// Example:
+//
+//	d := crypto.EstimateCrackTime(nil, 40)
+//	fmt.Printf("%.0f GPU-hours\n", d.Hours())
+func EstimateCrackTime(params *KDFParams, passwordEntropyBits float64) time.Duration {
+	passes, memoryKiB, _ := params.resolve()
+	perGuess := argon2BytesPerBlockPass * float64(memoryKiB) * 1024 * float64(passes) / crackReferenceBandwidth
+	guesses := math.Exp2(max(passwordEntropyBits, 0) - 1)
+	nanos := perGuess * guesses * float64(time.Second)
+	if !(nanos < math.MaxInt64) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(nanos)
+}
+
 // DeriveKey derives a key from a password and salt using Argon2id (the recommended variant).
 //
 // Argon2id is the recommended variant of Argon2, providing resistance against both
@@ -100,6 +264,8 @@ type KDFParams struct {
 //	key, err := crypto.DeriveKey(password, salt, 32, params)
 //
 // If params is nil, secure defaults are used (Time: 3, Memory: 64MB, Threads: 4).
+// No copy of the password or of intermediate values is kept; the password and the
+// returned key belong to the caller, who should Zeroize them when done.
 func DeriveKey(password, salt []byte, keyLen int, params *KDFParams) ([]byte, error) {
 	if len(password) == 0 {
 		return nil, goerrors.New("EMPTY_PASSWORD", "password cannot be empty")
@@ -111,23 +277,8 @@ func DeriveKey(password, salt []byte, keyLen int, params *KDFParams) ([]byte, er
 		return nil, goerrors.New("INVALID_KEYLEN", "key length must be positive")
 	}
 
-	// Set parameters with defaults
-	time := uint32(DefaultTime)
-	memory := uint32(DefaultMemory * 1024)
-	threads := uint8(DefaultThreads)
-
-	// Override with custom parameters if provided
-	if params != nil {
-		if params.Time > 0 {
-			time = params.Time
-		}
-		if params.Memory > 0 {
-			memory = params.Memory * 1024
-		}
-		if params.Threads > 0 {
-			threads = params.Threads
-		}
-	}
+	// Resolve parameters, substituting defaults for zero fields
+	time, memory, threads := params.resolve()
 
 	// Use Argon2id with determined parameters
 	// Note: Type conversions are safe due to parameter validation above
@@ -162,6 +313,275 @@ func DeriveKeyDefault(password, salt []byte, keyLen int) ([]byte, error) {
 	return DeriveKey(password, salt, keyLen, nil)
 }
 
+// maxDeriveKeysLen is the largest single key DeriveKeys can produce, the HKDF-SHA256
+// output limit of 255 hash blocks.
+const maxDeriveKeysLen = 255 * sha256.Size
+
+// DeriveKeys derives several independent keys from a password with a single Argon2id pass.
+//
+// The memory-hard step runs once to produce a 32-byte master secret, which is then
+// expanded with HKDF-SHA256 into one key per entry of keyLens. Each key uses its index
+// as HKDF info, so the keys are independent of each other: knowing one reveals nothing
+// about the rest. This gives separate encryption and MAC keys for the cost of one
+// DeriveKey call. The output is not compatible with DeriveKey for the same inputs.
+//
+// Parameters:
+//   - password: The password to derive the keys from (cannot be empty)
+//   - salt: The salt to use for key derivation (cannot be empty, should be random)
+//   - keyLens: The length of each key in bytes (at least one; each between 1 and 8160)
+//   - params: Custom Argon2id parameters (nil to use secure defaults)
+//
+// Returns:
+//   - The derived keys, in the order of keyLens
+//   - An error if any parameter is invalid
+//
+// Example:
+//
+//	keys, err := crypto.DeriveKeys(password, salt, []int{32, 32}, nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	encKey, macKey := keys[0], keys[1]
+func DeriveKeys(password, salt []byte, keyLens []int, params *KDFParams) ([][]byte, error) {
+	if len(keyLens) == 0 {
+		return nil, goerrors.New("INVALID_KEYLEN", "at least one key length is required")
+	}
+	for i, n := range keyLens {
+		if n <= 0 || n > maxDeriveKeysLen {
+			return nil, goerrors.New("INVALID_KEYLEN", fmt.Sprintf("key length %d at index %d must be between 1 and %d", n, i, maxDeriveKeysLen))
+		}
+	}
+	master, err := DeriveKey(password, salt, KeySize, params)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(master)
+
+	keys := make([][]byte, len(keyLens))
+	done := false
+	defer func() {
+		// Runs on errors and on panics unwinding through the loop alike.
+		if !done {
+			for _, key := range keys {
+				Zeroize(key)
+			}
+		}
+	}()
+	for i, n := range keyLens {
+		key, err := deriveSubkey(master, salt, fmt.Sprintf("go-crypto/v1/derive-keys/%d", i), n)
+		if err != nil {
+			return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to expand derived key")
+		}
+		keys[i] = key
+	}
+	done = true
+	return keys, nil
+}
+
+// deriveKeyStreamLabel is the HKDF info prefix of DeriveKeyStream segments.
+const deriveKeyStreamLabel = "go-crypto/v1/derive-key-stream"
+
+// DeriveKeyStream derives totalLen bytes of key material from a password with a single
+// Argon2id pass.
+//
+// Argon2id runs once, with the cost set by params, to produce a 32-byte base key, which
+// is then expanded with HKDF-SHA256 to totalLen bytes. The memory-hard cost is paid only
+// once, whatever the output length, so callers can cheaply carve many per-object keys
+// (e.g. 128 keys of 32 bytes from 4 KiB) out of one passphrase; expanding the base key
+// is negligible next to Argon2id. Outputs longer than 8160 bytes, the HKDF-SHA256 limit,
+// are made of independent 8160-byte segments expanded with their index in the HKDF info.
+// A prefix of the output does not depend on totalLen, so a longer stream extends a
+// shorter one. The output is not compatible with DeriveKey or DeriveKeys.
+//
+// Parameters:
+//   - password: The password to derive the material from (cannot be empty)
+//   - salt: The salt to use for key derivation (cannot be empty, should be random)
+//   - totalLen: The number of bytes to derive (must be positive)
+//   - params: Custom Argon2id parameters (nil to use secure defaults)
+//
+// Returns:
+//   - The derived key material; the caller should Zeroize it when done
+//   - An error if any parameter is invalid
+//
+// Example:
+//
+//	material, err := crypto.DeriveKeyStream(passphrase, salt, 100*crypto.KeySize, nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer crypto.Zeroize(material)
+//	objectKey := material[i*crypto.KeySize : (i+1)*crypto.KeySize]
+func DeriveKeyStream(password, salt []byte, totalLen int, params *KDFParams) ([]byte, error) {
+	if totalLen <= 0 {
+		return nil, goerrors.New("INVALID_KEYLEN", "key stream length must be positive")
+	}
+	base, err := DeriveKey(password, salt, KeySize, params)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(base)
+
+	out := make([]byte, totalLen)
+	done := false
+	defer func() {
+		if !done {
+			Zeroize(out)
+		}
+	}()
+	for segment, off := 0, 0; off < totalLen; segment++ {
+		n := min(maxDeriveKeysLen, totalLen-off)
+		block, err := deriveSubkey(base, salt, fmt.Sprintf("%s/%d", deriveKeyStreamLabel, segment), n)
+		if err != nil {
+			return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to expand key stream")
+		}
+		off += copy(out[off:], block)
+		Zeroize(block)
+	}
+	done = true
+	return out, nil
+}
+
+// pepperLabel domain-separates the pepper HMAC from other uses of the pepper.
+const pepperLabel = "go-crypto/v1/pepper"
+
+// DeriveKeyPeppered derives a key from a password, a salt and a pepper using Argon2id.
+//
+// The pepper is a server-side secret kept out of the database, e.g. in a secrets
+// manager or an HSM, whereas salts are usually stored next to the data they protect.
+// The password is first combined with the pepper as HMAC-SHA256(pepper, label ||
+// password), and the result is fed to Argon2id with the salt. An attacker who steals
+// the salts (and the data) but not the pepper cannot run an offline dictionary attack,
+// since every guess requires the pepper; with the pepper, the attack still costs a full
+// Argon2id evaluation per guess. Losing the pepper makes every derived key
+// unrecoverable, and rotating it means re-deriving from the passwords.
+//
+// Unlike HashPassword, which returns a self-describing PHC string meant for password
+// verification, DeriveKeyPeppered returns raw key material for encryption or MAC keys,
+// and does not record the parameters: store them (e.g. with a SaltRecord) to derive
+// the same key later. Its output differs from DeriveKey for the same password and salt.
+//
+// Parameters:
+//   - password: The password to derive the key from (cannot be empty)
+//   - salt: The salt to use for key derivation (cannot be empty, should be random)
+//   - pepper: The server secret (cannot be empty; should be at least 32 random bytes)
+//   - keyLen: The desired length of the derived key in bytes (must be positive)
+//   - params: Custom Argon2id parameters (nil to use secure defaults)
+//
+// Returns:
+//   - The derived key; the caller should Zeroize it when done
+//   - An error if any parameter is invalid
+//
+// Example:
+//
+//	key, err := crypto.DeriveKeyPeppered(password, user.Salt, pepperFromVault, crypto.KeySize, nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer crypto.Zeroize(key)
+func DeriveKeyPeppered(password, salt, pepper []byte, keyLen int, params *KDFParams) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, goerrors.New("EMPTY_PASSWORD", "password cannot be empty")
+	}
+	if len(pepper) == 0 {
+		return nil, goerrors.New("EMPTY_PEPPER", "pepper cannot be empty")
+	}
+	mac := newMAC(pepper)
+	mac.Write([]byte(pepperLabel))
+	mac.Write(password)
+	peppered := mac.Sum(nil)
+	defer Zeroize(peppered)
+	return DeriveKey(peppered, salt, keyLen, params)
+}
+
+// SaltRecord is a salt stored together with the version of the salt scheme that produced it.
+//
+// Keeping the version next to the salt (e.g. in a separate salts table) pins every
+// derivation to a scheme, so a migration to a new scheme can be tracked record by
+// record and old records remain derivable.
+type SaltRecord struct {
+	// Version identifies the salt scheme (must not be negative).
+	Version int `json:"version"`
+
+	// Salt is the salt value (cannot be empty).
+	Salt []byte `json:"salt"`
+}
+
+// DeriveKeyWithSaltRecord derives a key from a password and a versioned salt.
+//
+// Argon2id runs on the password and sr.Salt to produce a 32-byte master secret, which
+// HKDF-SHA256 expands with the salt version in its info string. The same password and
+// salt therefore yield unrelated keys under different versions, and a record whose
+// version was changed derives a key that fails to decrypt. The output is not
+// compatible with DeriveKey for the same inputs.
+//
+// Parameters:
+//   - password: The password to derive the key from (cannot be empty)
+//   - sr: The versioned salt
+//   - keyLen: The desired length of the derived key in bytes (between 1 and 8160)
+//   - params: Custom Argon2id parameters (nil to use secure defaults)
+//
+// Returns:
+//   - The derived key
+//   - An error if any parameter is invalid
+//
+// Example:
+//
+//	sr := crypto.SaltRecord{Version: 2, Salt: row.Salt}
+//	key, err := crypto.DeriveKeyWithSaltRecord(password, sr, 32, nil)
+func DeriveKeyWithSaltRecord(password []byte, sr SaltRecord, keyLen int, params *KDFParams) ([]byte, error) {
+	if sr.Version < 0 {
+		return nil, goerrors.New("INVALID_SALT_VERSION", fmt.Sprintf("salt version must not be negative (got %d)", sr.Version))
+	}
+	if keyLen <= 0 || keyLen > maxDeriveKeysLen {
+		return nil, goerrors.New("INVALID_KEYLEN", fmt.Sprintf("key length must be between 1 and %d", maxDeriveKeysLen))
+	}
+	master, err := DeriveKey(password, sr.Salt, KeySize, params)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(master)
+
+	key, err := deriveSubkey(master, sr.Salt, fmt.Sprintf("go-crypto/v1/salt-record/%d", sr.Version), keyLen)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to expand derived key")
+	}
+	return key, nil
+}
+
+// ExpandKey derives a subkey from a high-entropy master key with HKDF-SHA256.
+//
+// Unlike DeriveKey, ExpandKey is not meant for passwords: it runs no memory-hard step
+// and relies on masterKey already being uniformly random, such as a key from
+// GenerateKey. Distinct info strings yield independent subkeys, so one master key can
+// serve several purposes ("encryption", "mac", ...) without any two uses sharing a key.
+//
+// Parameters:
+//   - masterKey: The high-entropy input key (cannot be empty)
+//   - info: The context string identifying the subkey's purpose
+//   - keyLen: The desired length of the subkey in bytes (between 1 and 8160)
+//
+// Returns:
+//   - The subkey
+//   - An error if any parameter is invalid
+//
+// Example:
+//
+//	encKey, err := crypto.ExpandKey(masterKey, "encryption", 32)
+//	macKey, err := crypto.ExpandKey(masterKey, "mac", 32)
+func ExpandKey(masterKey []byte, info string, keyLen int) ([]byte, error) {
+	if len(masterKey) == 0 {
+		return nil, goerrors.New("EMPTY_KEY", "master key cannot be empty")
+	}
+	if keyLen <= 0 || keyLen > maxDeriveKeysLen {
+		return nil, goerrors.New("INVALID_KEYLEN", fmt.Sprintf("key length must be between 1 and %d", maxDeriveKeysLen))
+	}
+	key, err := deriveSubkey(masterKey, nil, info, keyLen)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to expand key")
+	}
+	return key, nil
+}
+
 // DeriveKeyWithParams derives a key from a password and salt using Argon2id with custom parameters.
 //
 // This is a legacy function that provides direct parameter control. For new code,
@@ -9,7 +9,9 @@ package crypto_test
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/agilira/go-crypto"
 )
@@ -354,3 +356,310 @@ func TestDeriveKeyWithCustomParams(t *testing.T) {
 		t.Error("Expected different keys for different parameters")
 	}
 }
+
+// TestDeriveKeys tests deriving several keys from one Argon2id pass
+func TestDeriveKeys(t *testing.T) {
+	password := []byte("test-password")
+	salt := []byte("test-salt-123456")
+	params := &crypto.KDFParams{Time: 1, Memory: 1, Threads: 1}
+
+	keys, err := crypto.DeriveKeys(password, salt, []int{32, 32, 16}, params)
+	if err != nil {
+		t.Fatalf("DeriveKeys() error: %v", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("Expected 3 keys, got %d", len(keys))
+	}
+	for i, want := range []int{32, 32, 16} {
+		if len(keys[i]) != want {
+			t.Errorf("Key %d: expected length %d, got %d", i, want, len(keys[i]))
+		}
+	}
+	if bytes.Equal(keys[0], keys[1]) {
+		t.Error("Expected independent keys, got identical encryption and MAC keys")
+	}
+
+	again, err := crypto.DeriveKeys(password, salt, []int{32, 32, 16}, params)
+	if err != nil {
+		t.Fatalf("DeriveKeys() second call error: %v", err)
+	}
+	for i := range keys {
+		if !bytes.Equal(keys[i], again[i]) {
+			t.Errorf("Key %d is not deterministic", i)
+		}
+	}
+
+	// A key depends only on its position, not on the lengths of the others
+	single, err := crypto.DeriveKeys(password, salt, []int{32}, params)
+	if err != nil {
+		t.Fatalf("DeriveKeys() single key error: %v", err)
+	}
+	if !bytes.Equal(single[0], keys[0]) {
+		t.Error("Expected the first key to be unaffected by additional key lengths")
+	}
+
+	direct, _ := crypto.DeriveKey(password, salt, 32, params)
+	if bytes.Equal(direct, keys[0]) {
+		t.Error("Expected DeriveKeys output to differ from the raw Argon2id output")
+	}
+}
+
+// TestDeriveKeys_InvalidParams tests DeriveKeys input validation
+func TestDeriveKeys_InvalidParams(t *testing.T) {
+	password := []byte("test-password")
+	salt := []byte("test-salt")
+	params := &crypto.KDFParams{Time: 1, Memory: 1, Threads: 1}
+
+	tests := []struct {
+		name     string
+		password []byte
+		salt     []byte
+		keyLens  []int
+	}{
+		{"no key lengths", password, salt, nil},
+		{"zero length", password, salt, []int{32, 0}},
+		{"negative length", password, salt, []int{-1}},
+		{"too long", password, salt, []int{255*32 + 1}},
+		{"empty password", nil, salt, []int{32}},
+		{"empty salt", password, nil, []int{32}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := crypto.DeriveKeys(tt.password, tt.salt, tt.keyLens, params); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+// TestKDFParams_Equal tests policy comparison after default substitution
+func TestKDFParams_Equal(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *crypto.KDFParams
+		want bool
+	}{
+		{"zero time equals default", &crypto.KDFParams{Time: 0}, &crypto.KDFParams{Time: crypto.DefaultTime}, true},
+		{"nil equals defaults", nil, crypto.DefaultKDFParams(), true},
+		{"nil equals zero value", &crypto.KDFParams{}, nil, true},
+		{"both nil", nil, nil, true},
+		{"different time", &crypto.KDFParams{Time: 2}, &crypto.KDFParams{Time: 3}, false},
+		{"different memory", &crypto.KDFParams{Memory: 32}, nil, false},
+		{"different threads", &crypto.KDFParams{Threads: 1}, nil, false},
+		{"hardened is not default", crypto.HardenedKDFParams(), crypto.DefaultKDFParams(), false},
+		{"hardened equals itself", crypto.HardenedKDFParams(), &crypto.KDFParams{Time: 4, Memory: 256, Threads: 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Equal(tt.a); got != tt.want {
+				t.Errorf("Equal() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestKDFParamsPresets tests that presets are independent copies
+func TestKDFParamsPresets(t *testing.T) {
+	p := crypto.DefaultKDFParams()
+	p.Time = 10
+	if crypto.DefaultKDFParams().Time != crypto.DefaultTime {
+		t.Error("Expected DefaultKDFParams to return a fresh value on each call")
+	}
+	h := crypto.HardenedKDFParams()
+	if h.Time < crypto.DefaultTime || h.Memory <= crypto.DefaultMemory {
+		t.Errorf("Expected hardened preset to exceed the defaults, got %+v", h)
+	}
+}
+
+func TestSecurityLevel(t *testing.T) {
+	tests := []struct {
+		params *crypto.KDFParams
+		want   string
+	}{
+		{nil, "RFC 9106 recommended (t=3, 64 MiB, p=4)"},
+		{crypto.HardenedKDFParams(), "RFC 9106 recommended (t=4, 256 MiB, p=4)"},
+		{&crypto.KDFParams{Time: 1, Memory: 46, Threads: 1}, "OWASP-2023 compliant (t=1, 46 MiB, p=1)"},
+		{&crypto.KDFParams{Time: 2, Memory: 19}, "OWASP-2023 compliant (t=2, 19 MiB, p=4)"},
+		{&crypto.KDFParams{Time: 10, Memory: 7}, "OWASP-2023 compliant (t=10, 7 MiB, p=4)"},
+		{&crypto.KDFParams{Time: 1, Memory: 16}, "below recommended memory (t=1, 16 MiB, p=4): OWASP-2023 requires at least 46 MiB at t=1"},
+		{&crypto.KDFParams{Time: 8, Memory: 1}, "below recommended memory (t=8, 1 MiB, p=4): OWASP-2023 requires at least 7 MiB at t=5"},
+	}
+	for _, tt := range tests {
+		if got := crypto.SecurityLevel(tt.params); got != tt.want {
+			t.Errorf("SecurityLevel(%+v) = %q, want %q", tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestDeriveKeyWithSaltRecord(t *testing.T) {
+	params := &crypto.KDFParams{Time: 1, Memory: 1, Threads: 1}
+	password := []byte("correct horse")
+	salt := []byte("0123456789abcdef")
+
+	v1, err := crypto.DeriveKeyWithSaltRecord(password, crypto.SaltRecord{Version: 1, Salt: salt}, 32, params)
+	if err != nil {
+		t.Fatalf("DeriveKeyWithSaltRecord() error: %v", err)
+	}
+	again, _ := crypto.DeriveKeyWithSaltRecord(password, crypto.SaltRecord{Version: 1, Salt: salt}, 32, params)
+	if !bytes.Equal(v1, again) {
+		t.Error("DeriveKeyWithSaltRecord() is not deterministic")
+	}
+	v2, _ := crypto.DeriveKeyWithSaltRecord(password, crypto.SaltRecord{Version: 2, Salt: salt}, 32, params)
+	if bytes.Equal(v1, v2) {
+		t.Error("different versions produced the same key")
+	}
+	plain, _ := crypto.DeriveKey(password, salt, 32, params)
+	if bytes.Equal(v1, plain) {
+		t.Error("salt record key equals DeriveKey output")
+	}
+	if long, err := crypto.DeriveKeyWithSaltRecord(password, crypto.SaltRecord{Salt: salt}, 64, params); err != nil || len(long) != 64 {
+		t.Errorf("64-byte key: got %d bytes, %v", len(long), err)
+	}
+
+	invalid := []struct {
+		name   string
+		sr     crypto.SaltRecord
+		keyLen int
+	}{
+		{"negative version", crypto.SaltRecord{Version: -1, Salt: salt}, 32},
+		{"empty salt", crypto.SaltRecord{Version: 1}, 32},
+		{"zero key length", crypto.SaltRecord{Version: 1, Salt: salt}, 0},
+		{"oversized key", crypto.SaltRecord{Version: 1, Salt: salt}, 8161},
+	}
+	for _, tt := range invalid {
+		if _, err := crypto.DeriveKeyWithSaltRecord(password, tt.sr, tt.keyLen, params); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestExpandKey(t *testing.T) {
+	master, _ := crypto.GenerateKey()
+	enc, err := crypto.ExpandKey(master, "encryption", 32)
+	if err != nil {
+		t.Fatalf("ExpandKey() error: %v", err)
+	}
+	again, _ := crypto.ExpandKey(master, "encryption", 32)
+	mac, _ := crypto.ExpandKey(master, "mac", 32)
+	if !bytes.Equal(enc, again) {
+		t.Error("ExpandKey() is not deterministic")
+	}
+	if bytes.Equal(enc, mac) || bytes.Equal(enc, master) {
+		t.Error("ExpandKey() subkeys are not separated")
+	}
+	if long, err := crypto.ExpandKey(master, "long", 8160); err != nil || len(long) != 8160 {
+		t.Errorf("ExpandKey(8160) = %d bytes, %v", len(long), err)
+	}
+	for _, n := range []int{0, -1, 8161} {
+		if _, err := crypto.ExpandKey(master, "x", n); err == nil {
+			t.Errorf("ExpandKey(keyLen=%d): expected error", n)
+		}
+	}
+	if _, err := crypto.ExpandKey(nil, "x", 32); err == nil {
+		t.Error("ExpandKey(nil master): expected error")
+	}
+}
+
+func TestEstimateCrackTime(t *testing.T) {
+	defaults := crypto.EstimateCrackTime(nil, 40)
+	years := defaults.Hours() / (24 * 365)
+	if years < 5 || years > 20 {
+		t.Errorf("Expected about ten GPU-years for 40 bits at the defaults, got %.1f", years)
+	}
+	if crypto.EstimateCrackTime(nil, 41) != 2*defaults {
+		t.Error("Expected one more bit of entropy to double the estimate")
+	}
+	hardened := crypto.EstimateCrackTime(&crypto.KDFParams{Time: 3, Memory: 128, Threads: 4}, 40)
+	if hardened != 2*defaults {
+		t.Errorf("Expected doubling memory to double the estimate, got %v vs %v", hardened, defaults)
+	}
+	if crypto.EstimateCrackTime(&crypto.KDFParams{Threads: 1}, 40) != defaults {
+		t.Error("Expected the thread count not to affect the estimate")
+	}
+	if got := crypto.EstimateCrackTime(nil, 128); got != time.Duration(math.MaxInt64) {
+		t.Errorf("Expected saturation for 128 bits, got %v", got)
+	}
+	if crypto.EstimateCrackTime(nil, -5) != crypto.EstimateCrackTime(nil, 0) {
+		t.Error("Expected negative entropy to count as zero")
+	}
+}
+
+func TestDeriveKeyStream(t *testing.T) {
+	password := []byte("test-password")
+	salt := []byte("test-salt-123456")
+	params := &crypto.KDFParams{Time: 1, Memory: 1, Threads: 1}
+
+	long, err := crypto.DeriveKeyStream(password, salt, 20000, params)
+	if err != nil {
+		t.Fatalf("DeriveKeyStream() error: %v", err)
+	}
+	if len(long) != 20000 {
+		t.Fatalf("Expected 20000 bytes, got %d", len(long))
+	}
+	short, err := crypto.DeriveKeyStream(password, salt, 4096, params)
+	if err != nil {
+		t.Fatalf("DeriveKeyStream() error: %v", err)
+	}
+	if !bytes.Equal(short, long[:4096]) {
+		t.Error("Expected a shorter stream to be a prefix of a longer one")
+	}
+	// Segments beyond the HKDF limit must not repeat the first one.
+	if bytes.Equal(long[:32], long[8160:8192]) {
+		t.Error("Expected independent segments")
+	}
+	base, _ := crypto.DeriveKey(password, salt, 32, params)
+	if bytes.Equal(short[:32], base) {
+		t.Error("Expected the stream to differ from DeriveKey output")
+	}
+	other, _ := crypto.DeriveKeyStream(password, []byte("other-salt-12345"), 64, params)
+	if bytes.Equal(other, short[:64]) {
+		t.Error("Expected a different salt to give a different stream")
+	}
+
+	if _, err := crypto.DeriveKeyStream(password, salt, 0, params); err == nil {
+		t.Error("Expected error for zero length")
+	}
+	if _, err := crypto.DeriveKeyStream(nil, salt, 32, params); err == nil {
+		t.Error("Expected error for empty password")
+	}
+}
+
+func TestDeriveKeyPeppered(t *testing.T) {
+	password := []byte("test-password")
+	salt := []byte("test-salt-123456")
+	pepper := []byte("server-side pepper, 32 bytes....")
+	params := &crypto.KDFParams{Time: 1, Memory: 1, Threads: 1}
+
+	key, err := crypto.DeriveKeyPeppered(password, salt, pepper, 32, params)
+	if err != nil {
+		t.Fatalf("DeriveKeyPeppered() error: %v", err)
+	}
+	if len(key) != 32 {
+		t.Fatalf("Expected a 32-byte key, got %d", len(key))
+	}
+	again, _ := crypto.DeriveKeyPeppered(password, salt, pepper, 32, params)
+	if !bytes.Equal(key, again) {
+		t.Error("Expected DeriveKeyPeppered to be deterministic")
+	}
+	plain, _ := crypto.DeriveKey(password, salt, 32, params)
+	if bytes.Equal(key, plain) {
+		t.Error("Expected the pepper to change the derived key")
+	}
+	otherPepper, _ := crypto.DeriveKeyPeppered(password, salt, []byte("another pepper"), 32, params)
+	if bytes.Equal(key, otherPepper) {
+		t.Error("Expected different peppers to derive different keys")
+	}
+
+	if _, err := crypto.DeriveKeyPeppered(password, salt, nil, 32, params); err == nil {
+		t.Error("Expected error for an empty pepper")
+	}
+	if _, err := crypto.DeriveKeyPeppered(nil, salt, pepper, 32, params); err == nil {
+		t.Error("Expected error for an empty password")
+	}
+	if _, err := crypto.DeriveKeyPeppered(password, nil, pepper, 32, params); err == nil {
+		t.Error("Expected error for an empty salt")
+	}
+}
@@ -0,0 +1,211 @@
+// keycache.go: Bounded, TTL-based in-memory cache for derived keys.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// DefaultKeyCacheSize is the number of entries a KeyCache holds when created with a non-positive size.
+const DefaultKeyCacheSize = 1024
+
+// KeyCache caches derived keys per identifier for a bounded time.
+//
+// It is meant for services deriving per-tenant keys with Argon2id, where deriving on
+// every request is too slow but keeping every key in memory forever is undesirable.
+// Entries expire after their TTL and the least recently used entry is evicted when the
+// cache is full; in both cases the cached key is zeroized. Concurrent misses for the
+// same identifier share a single derivation. A KeyCache is safe for concurrent use.
+type KeyCache struct {
+	mu       sync.Mutex
+	max      int
+	entries  map[string]*list.Element
+	lru      *list.List
+	inflight map[string]*keyCacheCall
+}
+
+// keyCacheEntry is a cached key, stored in the LRU list.
+type keyCacheEntry struct {
+	id      string
+	key     []byte
+	expires time.Time
+}
+
+// keyCacheCall tracks a derivation in progress so concurrent callers can wait for it.
+type keyCacheCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewKeyCache creates a KeyCache holding at most maxEntries keys.
+//
+// Parameters:
+//   - maxEntries: The maximum number of cached keys (DefaultKeyCacheSize if not positive)
+//
+// Returns:
+//   - An empty KeyCache
+//
+// Example:
+//
+//	cache := crypto.NewKeyCache(10000)
+//	key, err := cache.GetOrDerive(tenantID, func() ([]byte, error) {
+//		return crypto.DeriveKeyDefault(masterSecret, []byte(tenantID), crypto.KeySize)
+//	}, 10*time.Minute)
+func NewKeyCache(maxEntries int) *KeyCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultKeyCacheSize
+	}
+	return &KeyCache{
+		max:      maxEntries,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		inflight: make(map[string]*keyCacheCall),
+	}
+}
+
+// GetOrDerive returns the cached key for id, calling derive to produce it on a miss.
+//
+// The slice returned by derive is owned by the cache and will be zeroized on expiry or
+// eviction; callers always receive their own copy, which they may zeroize when done.
+// Errors from derive are returned as-is and are not cached. A non-positive ttl derives
+// the key without caching it.
+//
+// Parameters:
+//   - id: The cache key, e.g. a tenant identifier
+//   - derive: Produces the key on a cache miss
+//   - ttl: How long the derived key stays cached
+//
+// Returns:
+//   - A copy of the cached or newly derived key
+//   - The error returned by derive, if any
+func (c *KeyCache) GetOrDerive(id string, derive func() ([]byte, error), ttl time.Duration) ([]byte, error) {
+	for {
+		c.mu.Lock()
+		if key, ok := c.lookup(id); ok {
+			c.mu.Unlock()
+			return key, nil
+		}
+		if call, ok := c.inflight[id]; ok {
+			c.mu.Unlock()
+			<-call.done
+			if call.err != nil {
+				return nil, call.err
+			}
+			// Look again: the derived key is now cached (or was not cacheable).
+			continue
+		}
+		call := &keyCacheCall{done: make(chan struct{})}
+		c.inflight[id] = call
+		c.mu.Unlock()
+		return c.derive(id, call, derive, ttl)
+	}
+}
+
+// Invalidate removes and zeroizes the cached key for id, if any.
+func (c *KeyCache) Invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[id]; ok {
+		c.remove(elem)
+	}
+}
+
+// Purge removes and zeroizes all expired entries.
+//
+// Expired entries are also dropped lazily on access; Purge lets a background
+// goroutine bound how long an unused key lingers in memory.
+func (c *KeyCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if !now.Before(elem.Value.(*keyCacheEntry).expires) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// Clear removes and zeroizes every cached key.
+func (c *KeyCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.lru.Front(); elem != nil; elem = c.lru.Front() {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of cached keys, including expired ones not yet purged.
+func (c *KeyCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// lookup returns a copy of a live entry for id, dropping it if it has expired.
+// The caller must hold c.mu.
+func (c *KeyCache) lookup(id string) ([]byte, bool) {
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*keyCacheEntry)
+	if !time.Now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return append([]byte(nil), entry.key...), true
+}
+
+// derive runs derive for id and publishes the result, waking any waiters.
+// If derive panics, waiters receive an error and the panic propagates to the caller.
+func (c *KeyCache) derive(id string, call *keyCacheCall, derive func() ([]byte, error), ttl time.Duration) (key []byte, err error) {
+	returned := false
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, id)
+		if returned && err == nil && ttl > 0 {
+			c.store(id, key, ttl)
+			key = append([]byte(nil), key...)
+		}
+		c.mu.Unlock()
+		call.err = err
+		if !returned {
+			call.err = goerrors.New("KEY_DERIVE_ERROR", "key derivation panicked")
+		}
+		close(call.done)
+	}()
+	key, err = derive()
+	returned = true
+	return key, err
+}
+
+// store inserts key for id, evicting the least recently used entries as needed.
+// The caller must hold c.mu.
+func (c *KeyCache) store(id string, key []byte, ttl time.Duration) {
+	if elem, ok := c.entries[id]; ok {
+		c.remove(elem)
+	}
+	for c.lru.Len() >= c.max {
+		c.remove(c.lru.Back())
+	}
+	entry := &keyCacheEntry{id: id, key: key, expires: time.Now().Add(ttl)}
+	c.entries[id] = c.lru.PushFront(entry)
+}
+
+// remove drops elem from the cache and zeroizes its key. The caller must hold c.mu.
+func (c *KeyCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*keyCacheEntry)
+	delete(c.entries, entry.id)
+	Zeroize(entry.key)
+}
@@ -0,0 +1,124 @@
+// keycache_test.go: Test cases for the derived key cache.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestKeyCache_GetOrDerive(t *testing.T) {
+	cache := crypto.NewKeyCache(10)
+	var calls int
+	derive := func() ([]byte, error) {
+		calls++
+		return bytes.Repeat([]byte{byte(calls)}, crypto.KeySize), nil
+	}
+
+	first, err := cache.GetOrDerive("tenant-a", derive, time.Minute)
+	if err != nil {
+		t.Fatalf("GetOrDerive() error: %v", err)
+	}
+	second, _ := cache.GetOrDerive("tenant-a", derive, time.Minute)
+	if calls != 1 || !bytes.Equal(first, second) {
+		t.Errorf("Expected cached key on second call, derive called %d times", calls)
+	}
+
+	// Callers get independent copies.
+	crypto.Zeroize(first)
+	third, _ := cache.GetOrDerive("tenant-a", derive, time.Minute)
+	if !bytes.Equal(third, second) {
+		t.Error("Expected zeroizing a returned key not to affect the cache")
+	}
+
+	cache.Invalidate("tenant-a")
+	fourth, _ := cache.GetOrDerive("tenant-a", derive, time.Minute)
+	if calls != 2 || bytes.Equal(fourth, second) {
+		t.Errorf("Expected re-derivation after Invalidate, derive called %d times", calls)
+	}
+
+	wantErr := errors.New("boom")
+	if _, err := cache.GetOrDerive("tenant-b", func() ([]byte, error) { return nil, wantErr }, time.Minute); !errors.Is(err, wantErr) {
+		t.Errorf("Expected derive error, got %v", err)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Expected errors not to be cached, got %d entries", cache.Len())
+	}
+}
+
+func TestKeyCache_ExpiryAndEviction(t *testing.T) {
+	cache := crypto.NewKeyCache(2)
+	var owned [][]byte
+	derive := func() ([]byte, error) {
+		key := bytes.Repeat([]byte{0xff}, crypto.KeySize)
+		owned = append(owned, key)
+		return key, nil
+	}
+
+	_, _ = cache.GetOrDerive("short", derive, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("Expected expired entry to be purged, got %d entries", cache.Len())
+	}
+	if !bytes.Equal(owned[0], make([]byte, crypto.KeySize)) {
+		t.Error("Expected expired key to be zeroized")
+	}
+
+	_, _ = cache.GetOrDerive("a", derive, time.Minute)
+	_, _ = cache.GetOrDerive("b", derive, time.Minute)
+	_, _ = cache.GetOrDerive("a", derive, time.Minute) // a is now most recently used
+	_, _ = cache.GetOrDerive("c", derive, time.Minute)
+	if cache.Len() != 2 {
+		t.Errorf("Expected cache bounded to 2 entries, got %d", cache.Len())
+	}
+	if !bytes.Equal(owned[2], make([]byte, crypto.KeySize)) {
+		t.Error("Expected least recently used key to be zeroized on eviction")
+	}
+	if bytes.Equal(owned[1], make([]byte, crypto.KeySize)) {
+		t.Error("Expected recently used key to stay cached")
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 || !bytes.Equal(owned[1], make([]byte, crypto.KeySize)) {
+		t.Error("Expected Clear to drop and zeroize all keys")
+	}
+}
+
+func TestKeyCache_ConcurrentMissDerivesOnce(t *testing.T) {
+	cache := crypto.NewKeyCache(0)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	derive := func() ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return make([]byte, crypto.KeySize), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.GetOrDerive("tenant", derive, time.Minute); err != nil {
+				t.Errorf("GetOrDerive() error: %v", err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single derivation, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,105 @@
+// keyfile_test.go: Test cases for keyfile support.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func writeKeyfile(t *testing.T, contents []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keyfile")
+	if err := os.WriteFile(path, contents, 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	return path
+}
+
+func TestKeyFromFile_Formats(t *testing.T) {
+	raw, _ := crypto.GenerateKey()
+	other := bytes.Repeat([]byte("entropy"), 10000)
+	notHex := bytes.Repeat([]byte("z"), 64)
+
+	tests := []struct {
+		name     string
+		contents []byte
+		want     []byte
+	}{
+		{"raw 32 bytes", raw, raw},
+		{"64 hex characters", []byte(crypto.KeyToHex(raw)), raw},
+		{"64 non-hex characters", notHex, sha256Sum(notHex)},
+		{"arbitrary file", other, sha256Sum(other)},
+		{"short file", []byte("abc"), sha256Sum([]byte("abc"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := crypto.KeyFromFile(writeKeyfile(t, tt.contents))
+			if err != nil {
+				t.Fatalf("KeyFromFile() error: %v", err)
+			}
+			if !bytes.Equal(key, tt.want) {
+				t.Errorf("KeyFromFile() = %x, want %x", key, tt.want)
+			}
+		})
+	}
+}
+
+func sha256Sum(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+func TestKeyFromFile_Errors(t *testing.T) {
+	if _, err := crypto.KeyFromFile(writeKeyfile(t, nil)); err == nil {
+		t.Error("KeyFromFile() of empty file succeeded")
+	}
+	if _, err := crypto.KeyFromFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("KeyFromFile() of missing file succeeded")
+	}
+}
+
+func TestDeriveKeyWithKeyfile(t *testing.T) {
+	params := &crypto.KDFParams{Time: 1, Memory: 1, Threads: 1}
+	salt := []byte("0123456789abcdef")
+	keyfileA := writeKeyfile(t, []byte("keyfile A contents"))
+	keyfileB := writeKeyfile(t, []byte("keyfile B contents"))
+
+	k1, err := crypto.DeriveKeyWithKeyfile([]byte("pw"), salt, keyfileA, 32, params)
+	if err != nil {
+		t.Fatalf("DeriveKeyWithKeyfile() error: %v", err)
+	}
+	k2, _ := crypto.DeriveKeyWithKeyfile([]byte("pw"), salt, keyfileA, 32, params)
+	if !bytes.Equal(k1, k2) {
+		t.Error("DeriveKeyWithKeyfile() is not deterministic")
+	}
+	if k3, _ := crypto.DeriveKeyWithKeyfile([]byte("pw"), salt, keyfileB, 32, params); bytes.Equal(k1, k3) {
+		t.Error("different keyfiles produced the same key")
+	}
+	if k4, _ := crypto.DeriveKeyWithKeyfile([]byte("other"), salt, keyfileA, 32, params); bytes.Equal(k1, k4) {
+		t.Error("different passwords produced the same key")
+	}
+	if k5, _ := crypto.DeriveKey([]byte("pw"), salt, 32, params); bytes.Equal(k1, k5) {
+		t.Error("keyfile did not contribute to the key")
+	}
+
+	// The keyfile alone is allowed.
+	if _, err := crypto.DeriveKeyWithKeyfile(nil, salt, keyfileA, 32, params); err != nil {
+		t.Errorf("DeriveKeyWithKeyfile() without password error: %v", err)
+	}
+	if _, err := crypto.DeriveKeyWithKeyfile([]byte("pw"), salt, filepath.Join(t.TempDir(), "missing"), 32, params); err == nil {
+		t.Error("DeriveKeyWithKeyfile() with missing keyfile succeeded")
+	}
+	if _, err := crypto.DeriveKeyWithKeyfile([]byte("pw"), nil, keyfileA, 32, params); err == nil {
+		t.Error("DeriveKeyWithKeyfile() with empty salt succeeded")
+	}
+}
@@ -0,0 +1,117 @@
+// keyhint.go: Ciphertexts stamped with the fingerprint of their key.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// keyHintSize is the size of the raw key fingerprint prefix (the 8 bytes behind GetKeyFingerprint).
+const keyHintSize = 8
+
+// keyHintLabel domain-separates key-hinted envelopes from other authenticated headers.
+const keyHintLabel = "go-crypto/v1/key-hint"
+
+// EncryptWithKeyHint encrypts plaintext and stamps the result with the key's fingerprint.
+//
+// The fingerprint, as returned by GetKeyFingerprint, is stored in clear in front of the
+// envelope and authenticated as additional data. Decryptors holding many keys can read
+// it with CiphertextKeyHint to pick the right key without trial decryption. The
+// fingerprint identifies the key but reveals nothing usable about it; it does let an
+// observer tell which ciphertexts share a key.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A base64-encoded string containing the fingerprint, nonce, ciphertext and tag
+//   - An error if encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptWithKeyHint([]byte("payload"), key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptWithKeyHint(plaintext, key []byte) (string, error) {
+	hint := sha256.Sum256(key)
+	out, err := sealWithHeader(key, keyHintLabel, hint[:keyHintSize], plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// CiphertextKeyHint returns the key fingerprint stored in a ciphertext produced by
+// EncryptWithKeyHint, without decrypting it.
+//
+// The hint is not authenticated until the ciphertext is decrypted; use it only to
+// select a key, never as proof of origin.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext
+//
+// Returns:
+//   - The fingerprint in the format of GetKeyFingerprint
+//   - An error if the ciphertext is empty, malformed or too short
+//
+// Example:
+//
+//	hint, err := crypto.CiphertextKeyHint(ciphertext)
+//	key := keysByFingerprint[hint]
+func CiphertextKeyHint(ciphertext string) (string, error) {
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < keyHintSize {
+		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short for key hint")
+		return "", fmt.Errorf("%w: %w", ErrCiphertextShort, richErr)
+	}
+	return hex.EncodeToString(data[:keyHintSize]), nil
+}
+
+// DecryptWithKeyHint decrypts a ciphertext produced by EncryptWithKeyHint.
+//
+// A key whose fingerprint differs from the stored hint is rejected before any
+// decryption is attempted.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error wrapping ErrDecrypt if the key does not match the hint or authentication fails
+//
+// Example:
+//
+//	hint, _ := crypto.CiphertextKeyHint(ciphertext)
+//	plaintext, err := crypto.DecryptWithKeyHint(ciphertext, keysByFingerprint[hint])
+func DecryptWithKeyHint(ciphertext string, key []byte) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	expected := sha256.Sum256(key)
+	if len(data) >= keyHintSize && subtle.ConstantTimeCompare(data[:keyHintSize], expected[:keyHintSize]) != 1 {
+		richErr := goerrors.New(ErrCodeDecrypt, "key fingerprint does not match the ciphertext key hint")
+		return nil, inputError(ErrDecrypt, richErr)
+	}
+	_, plaintext, err := openWithHeader(key, keyHintLabel, data, keyHintSize)
+	return plaintext, err
+}
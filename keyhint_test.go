@@ -0,0 +1,69 @@
+// keyhint_test.go: Test cases for key-hinted ciphertexts.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptWithKeyHint(t *testing.T) {
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	keys := map[string][]byte{
+		crypto.GetKeyFingerprint(keyA): keyA,
+		crypto.GetKeyFingerprint(keyB): keyB,
+	}
+
+	ciphertext, err := crypto.EncryptWithKeyHint([]byte("routed"), keyB)
+	if err != nil {
+		t.Fatalf("EncryptWithKeyHint() error: %v", err)
+	}
+	hint, err := crypto.CiphertextKeyHint(ciphertext)
+	if err != nil {
+		t.Fatalf("CiphertextKeyHint() error: %v", err)
+	}
+	if hint != crypto.GetKeyFingerprint(keyB) {
+		t.Errorf("Expected hint %s, got %s", crypto.GetKeyFingerprint(keyB), hint)
+	}
+	plaintext, err := crypto.DecryptWithKeyHint(ciphertext, keys[hint])
+	if err != nil || string(plaintext) != "routed" {
+		t.Errorf("Expected round trip, got %q err=%v", plaintext, err)
+	}
+	if _, err := crypto.DecryptWithKeyHint(ciphertext, keyA); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for mismatched key, got %v", err)
+	}
+}
+
+func TestEncryptWithKeyHint_Tampering(t *testing.T) {
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptWithKeyHint([]byte("x"), keyA)
+
+	// Replacing the hint with another key's fingerprint is detected.
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+	stampedB, _ := crypto.EncryptWithKeyHint(nil, keyB)
+	rawB, _ := base64.StdEncoding.DecodeString(stampedB)
+	copy(raw[:8], rawB[:8])
+	forged := base64.StdEncoding.EncodeToString(raw)
+	if _, err := crypto.DecryptWithKeyHint(forged, keyB); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for forged hint, got %v", err)
+	}
+
+	if _, err := crypto.CiphertextKeyHint("AAAA"); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort, got %v", err)
+	}
+	if _, err := crypto.CiphertextKeyHint(""); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("Expected ErrEmptyPlaintext, got %v", err)
+	}
+	if _, err := crypto.DecryptWithKeyHint("AAAA", keyA); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort, got %v", err)
+	}
+}
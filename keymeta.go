@@ -0,0 +1,84 @@
+// keymeta.go: Key usage tracking for rotation policies.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// KeyMetadata tracks the creation time of a key and the number of encryptions performed
+// with it, so rotation policies can be enforced in code rather than by external
+// bookkeeping.
+//
+// Counting encryptions matters for AES-GCM with random nonces: the chance of a nonce
+// collision, which breaks confidentiality and authenticity for the colliding messages,
+// grows with the number of messages, and NIST SP 800-38D limits a key to 2^32 of them.
+// A Cipher created with NewCipherWithMetadata counts its encryptions here; other
+// encryption paths can call RecordOperation. The counter lives in memory: persist
+// Created and Operations alongside the key and restore them with NewKeyMetadata to keep
+// the count across restarts. KeyMetadata is safe for concurrent use.
+type KeyMetadata struct {
+	created time.Time
+	ops     atomic.Uint64
+}
+
+// NewKeyMetadata returns metadata for a key created at created that has already been
+// used for ops encryptions.
+//
+// Parameters:
+//   - created: The key's creation time (time.Now() for a new key)
+//   - ops: The number of encryptions already performed (0 for a new key)
+//
+// Returns:
+//   - The key metadata
+//
+// Example:
+//
+//	meta := crypto.NewKeyMetadata(record.CreatedAt, record.Operations)
+func NewKeyMetadata(created time.Time, ops uint64) *KeyMetadata {
+	m := &KeyMetadata{created: created}
+	m.ops.Store(ops)
+	return m
+}
+
+// Created returns the key's creation time.
+func (m *KeyMetadata) Created() time.Time {
+	return m.created
+}
+
+// Operations returns the number of encryptions recorded so far.
+func (m *KeyMetadata) Operations() uint64 {
+	return m.ops.Load()
+}
+
+// RecordOperation records one encryption and returns the new count.
+func (m *KeyMetadata) RecordOperation() uint64 {
+	return m.ops.Add(1)
+}
+
+// NeedsRotation reports whether the key is older than maxAge or has been used for at
+// least maxOps encryptions.
+//
+// Parameters:
+//   - maxAge: The maximum key age (0 to ignore age)
+//   - maxOps: The maximum number of encryptions (0 to ignore usage)
+//
+// Returns:
+//   - true if either limit has been reached
+//
+// Example:
+//
+//	if meta.NeedsRotation(90*24*time.Hour, 1<<32) {
+//		rotateKey()
+//	}
+func (m *KeyMetadata) NeedsRotation(maxAge time.Duration, maxOps uint64) bool {
+	if maxAge > 0 && time.Since(m.created) >= maxAge {
+		return true
+	}
+	return maxOps > 0 && m.ops.Load() >= maxOps
+}
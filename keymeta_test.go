@@ -0,0 +1,72 @@
+// keymeta_test.go: Test cases for key usage tracking.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestKeyMetadata_NeedsRotation(t *testing.T) {
+	fresh := crypto.NewKeyMetadata(time.Now(), 0)
+	if fresh.NeedsRotation(time.Hour, 10) {
+		t.Error("A fresh key should not need rotation")
+	}
+	if fresh.NeedsRotation(0, 0) {
+		t.Error("Disabled limits should never require rotation")
+	}
+
+	old := crypto.NewKeyMetadata(time.Now().Add(-2*time.Hour), 0)
+	if !old.NeedsRotation(time.Hour, 0) {
+		t.Error("A key older than maxAge should need rotation")
+	}
+	if old.NeedsRotation(0, 10) {
+		t.Error("Age should be ignored when maxAge is 0")
+	}
+
+	used := crypto.NewKeyMetadata(time.Now(), 9)
+	if used.NeedsRotation(time.Hour, 10) {
+		t.Error("A key below maxOps should not need rotation")
+	}
+	if got := used.RecordOperation(); got != 10 {
+		t.Errorf("RecordOperation() = %d, want 10", got)
+	}
+	if !used.NeedsRotation(time.Hour, 10) {
+		t.Error("A key that reached maxOps should need rotation")
+	}
+}
+
+func TestCipher_Metadata(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plain, _ := crypto.NewCipher(key)
+	if plain.Metadata() != nil {
+		t.Error("NewCipher should not track metadata")
+	}
+
+	meta := crypto.NewKeyMetadata(time.Now(), 5)
+	c, err := crypto.NewCipherWithMetadata(key, nil, meta)
+	if err != nil {
+		t.Fatalf("NewCipherWithMetadata() error: %v", err)
+	}
+	if c.Metadata() != meta {
+		t.Error("Metadata() should return the configured metadata")
+	}
+	ciphertext, _ := c.Encrypt([]byte("counted"))
+	_, _ = c.EncryptFixed(nil, []byte("counted"))
+	_, _ = c.Decrypt(ciphertext)
+	if got := meta.Operations(); got != 7 {
+		t.Errorf("Operations() = %d, want 7", got)
+	}
+
+	counter, _ := crypto.NewCipherWithMetadata(key, crypto.NewCounterNonces(nil), nil)
+	_, _ = counter.EncryptFixed(nil, []byte("counted"))
+	if got := counter.Metadata().Operations(); got != 1 {
+		t.Errorf("Operations() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,137 @@
+// keyring.go: Keys generated into and used from the Linux kernel keyring.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+var (
+	// ErrKeyring is returned when a kernel keyring operation fails.
+	ErrKeyring = errors.New("crypto: keyring error")
+
+	// ErrKeyringUnsupported is returned by the keyring functions on platforms without a kernel keyring.
+	ErrKeyringUnsupported = errors.New("crypto: kernel keyring not supported on this platform")
+)
+
+// ErrCodeKeyring is the error code for kernel keyring failures.
+const ErrCodeKeyring = "CRYPTO_KEYRING"
+
+// GenerateKeyInKeyring generates a new AES-256 key and stores it in the Linux session keyring.
+//
+// The key is added as a "user" key with the given description, and only the kernel's
+// serial number is returned. The long-term copy of the key lives in kernel memory,
+// outside the Go heap, so it is not exposed by heap dumps or core files of the process
+// and is released by RevokeKeyringKey or when the session ends. EncryptWithKeyringKey and
+// DecryptWithKeyringKey read the key back transiently for each operation and zeroize it
+// afterwards. The key is not sealed to a TPM; any process in the same session with the
+// right permissions can read it.
+//
+// On platforms other than Linux it returns an error wrapping ErrKeyringUnsupported.
+//
+// Parameters:
+//   - desc: The key description, used to find the key with keyctl(1) (cannot be empty)
+//
+// Returns:
+//   - The kernel key serial number
+//   - An error if key generation or the keyring operation fails
+//
+// Example:
+//
+//	keyID, err := crypto.GenerateKeyInKeyring("myapp:data-key")
+//	if errors.Is(err, crypto.ErrKeyringUnsupported) {
+//		// fall back to an in-memory key
+//	}
+//	ciphertext, err := crypto.EncryptWithKeyringKey(keyID, []byte("secret"))
+func GenerateKeyInKeyring(desc string) (keyID int, err error) {
+	if desc == "" {
+		return 0, keyringError(nil, "key description cannot be empty")
+	}
+	key := make([]byte, KeySize)
+	defer Zeroize(key)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return 0, goerrors.Wrap(err, "KEY_GEN_ERROR", "failed to generate random key")
+	}
+	return keyringAdd(desc, key)
+}
+
+// EncryptWithKeyringKey encrypts plaintext with a key stored by GenerateKeyInKeyring.
+//
+// The key is read from the keyring into a temporary buffer that is zeroized before
+// returning. The output has the same format as EncryptBytes.
+//
+// Parameters:
+//   - keyID: The key serial number returned by GenerateKeyInKeyring
+//   - plaintext: The byte slice to encrypt (can be empty)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if the key cannot be read or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptWithKeyringKey(keyID, []byte("secret"))
+func EncryptWithKeyringKey(keyID int, plaintext []byte) (string, error) {
+	key, err := keyringRead(keyID)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(key)
+	return EncryptBytes(plaintext, key)
+}
+
+// DecryptWithKeyringKey decrypts a ciphertext with a key stored by GenerateKeyInKeyring.
+//
+// Parameters:
+//   - keyID: The key serial number returned by GenerateKeyInKeyring
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if the key cannot be read or decryption fails
+//
+// Example:
+//
+//	plaintext, err := crypto.DecryptWithKeyringKey(keyID, ciphertext)
+func DecryptWithKeyringKey(keyID int, encryptedText string) ([]byte, error) {
+	key, err := keyringRead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(key)
+	return DecryptBytes(encryptedText, key)
+}
+
+// RevokeKeyringKey revokes a key stored by GenerateKeyInKeyring.
+//
+// Once revoked, the key can no longer be read and the kernel discards it, so
+// ciphertexts encrypted under it become permanently undecryptable.
+//
+// Parameters:
+//   - keyID: The key serial number returned by GenerateKeyInKeyring
+//
+// Returns:
+//   - An error if the key does not exist or cannot be revoked
+func RevokeKeyringKey(keyID int) error {
+	return keyringRevoke(keyID)
+}
+
+// keyringError builds an error wrapping ErrKeyring.
+func keyringError(err error, msg string) error {
+	var richErr error
+	if err != nil {
+		richErr = goerrors.Wrap(err, ErrCodeKeyring, msg)
+	} else {
+		richErr = goerrors.New(ErrCodeKeyring, msg)
+	}
+	return fmt.Errorf("%w: %w", ErrKeyring, richErr)
+}
@@ -0,0 +1,76 @@
+// keyring_linux.go: Linux kernel keyring access through the add_key and keyctl syscalls.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build linux
+
+package crypto
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// Constants from <linux/keyctl.h>.
+const (
+	keySpecSessionKeyring = -3
+	keyctlRevoke          = 3
+	keyctlRead            = 11
+)
+
+// keyringAdd stores key as a "user" key in the session keyring and returns its serial.
+func keyringAdd(desc string, key []byte) (int, error) {
+	keyType, err := syscall.BytePtrFromString("user")
+	if err != nil {
+		return 0, keyringError(err, "invalid key type")
+	}
+	description, err := syscall.BytePtrFromString(desc)
+	if err != nil {
+		return 0, keyringError(err, "invalid key description")
+	}
+	ring := keySpecSessionKeyring
+	// #nosec G103 -- pointers to live Go memory passed to add_key(2) for the call's duration
+	id, _, errno := syscall.Syscall6(syscall.SYS_ADD_KEY,
+		uintptr(unsafe.Pointer(keyType)),
+		uintptr(unsafe.Pointer(description)),
+		uintptr(unsafe.Pointer(&key[0])),
+		uintptr(len(key)),
+		uintptr(ring),
+		0)
+	if errno != 0 {
+		return 0, keyringError(errno, "add_key failed")
+	}
+	return int(id), nil
+}
+
+// keyringRead reads the payload of key keyID, which must be KeySize bytes.
+func keyringRead(keyID int) ([]byte, error) {
+	buf := make([]byte, KeySize+1)
+	// #nosec G103 -- pointer to live Go memory passed to keyctl(2) for the call's duration
+	n, _, errno := syscall.Syscall6(syscall.SYS_KEYCTL,
+		keyctlRead,
+		uintptr(keyID),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+		0, 0)
+	if errno != 0 {
+		Zeroize(buf)
+		return nil, keyringError(errno, fmt.Sprintf("keyctl read of key %d failed", keyID))
+	}
+	if int(n) != KeySize {
+		Zeroize(buf)
+		return nil, keyringError(nil, fmt.Sprintf("key %d holds %d bytes, want %d", keyID, n, KeySize))
+	}
+	return buf[:KeySize], nil
+}
+
+// keyringRevoke revokes key keyID.
+func keyringRevoke(keyID int) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_KEYCTL, keyctlRevoke, uintptr(keyID), 0); errno != 0 {
+		return keyringError(errno, fmt.Sprintf("keyctl revoke of key %d failed", keyID))
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// keyring_other.go: Kernel keyring stubs for platforms other than Linux.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !linux
+
+package crypto
+
+import (
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// keyringAdd reports that the kernel keyring is unavailable.
+func keyringAdd(string, []byte) (int, error) {
+	return 0, keyringUnsupported()
+}
+
+// keyringRead reports that the kernel keyring is unavailable.
+func keyringRead(int) ([]byte, error) {
+	return nil, keyringUnsupported()
+}
+
+// keyringRevoke reports that the kernel keyring is unavailable.
+func keyringRevoke(int) error {
+	return keyringUnsupported()
+}
+
+// keyringUnsupported builds an error wrapping ErrKeyringUnsupported.
+func keyringUnsupported() error {
+	return fmt.Errorf("%w: %w", ErrKeyringUnsupported, goerrors.New(ErrCodeKeyring, "kernel keyring requires Linux"))
+}
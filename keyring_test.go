@@ -0,0 +1,77 @@
+// keyring_test.go: Test cases for kernel keyring keys.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+// keyringKey generates a keyring key or skips the test where the keyring is unavailable.
+func keyringKey(t *testing.T) int {
+	t.Helper()
+	keyID, err := crypto.GenerateKeyInKeyring("go-crypto-test:" + t.Name())
+	if runtime.GOOS != "linux" {
+		if !errors.Is(err, crypto.ErrKeyringUnsupported) {
+			t.Fatalf("GenerateKeyInKeyring() on %s: got %v, want ErrKeyringUnsupported", runtime.GOOS, err)
+		}
+		t.Skip("kernel keyring requires Linux")
+	}
+	if err != nil {
+		// Containers commonly block the keyring syscalls with seccomp.
+		t.Skipf("kernel keyring unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = crypto.RevokeKeyringKey(keyID) })
+	return keyID
+}
+
+func TestKeyringKey_RoundTrip(t *testing.T) {
+	keyID := keyringKey(t)
+	ciphertext, err := crypto.EncryptWithKeyringKey(keyID, []byte("kept out of the heap"))
+	if err != nil {
+		t.Fatalf("EncryptWithKeyringKey() error: %v", err)
+	}
+	plaintext, err := crypto.DecryptWithKeyringKey(keyID, ciphertext)
+	if err != nil || string(plaintext) != "kept out of the heap" {
+		t.Fatalf("DecryptWithKeyringKey() = %q, %v", plaintext, err)
+	}
+
+	otherID := keyringKey(t)
+	if _, err := crypto.DecryptWithKeyringKey(otherID, ciphertext); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("decrypt with another keyring key: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestKeyringKey_Errors(t *testing.T) {
+	if _, err := crypto.GenerateKeyInKeyring(""); err == nil {
+		t.Error("GenerateKeyInKeyring(\"\") succeeded")
+	}
+	if _, err := crypto.EncryptWithKeyringKey(-12345, []byte("x")); err == nil {
+		t.Error("EncryptWithKeyringKey() with unknown key succeeded")
+	}
+	if err := crypto.RevokeKeyringKey(-12345); err == nil {
+		t.Error("RevokeKeyringKey() with unknown key succeeded")
+	}
+	if runtime.GOOS != "linux" {
+		if _, err := crypto.DecryptWithKeyringKey(1, "AAAA"); !errors.Is(err, crypto.ErrKeyringUnsupported) {
+			t.Errorf("DecryptWithKeyringKey() on %s: got %v, want ErrKeyringUnsupported", runtime.GOOS, err)
+		}
+	}
+}
+
+func TestRevokeKeyringKey(t *testing.T) {
+	keyID := keyringKey(t)
+	if err := crypto.RevokeKeyringKey(keyID); err != nil {
+		t.Fatalf("RevokeKeyringKey() error: %v", err)
+	}
+	if _, err := crypto.EncryptWithKeyringKey(keyID, []byte("x")); !errors.Is(err, crypto.ErrKeyring) {
+		t.Errorf("EncryptWithKeyringKey() after revoke: got %v, want ErrKeyring", err)
+	}
+}
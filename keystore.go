@@ -0,0 +1,163 @@
+// keystore.go: In-memory key store holding keys encrypted under a master key.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"fmt"
+	"sync"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// keyStoreLabel domain-separates key store entries from other authenticated headers.
+const keyStoreLabel = "go-crypto/v1/keystore"
+
+// EncryptedKeyStore holds keys in memory encrypted under a master key and decrypts each
+// one only for the duration of a WithKey callback.
+//
+// A memory disclosure (a core dump, a heap dump in a crash report, an out-of-bounds
+// read) then exposes the master key and at most the keys in use at that moment, rather
+// than every key the service holds in plaintext. It is a hardening measure, not an
+// isolation boundary: anyone who can read the whole process memory also gets the
+// master key. Each entry is encrypted with AES-256-GCM bound to its identifier, so
+// entries cannot be swapped in memory between identifiers. An EncryptedKeyStore is safe
+// for concurrent use; call Destroy when it is no longer needed.
+type EncryptedKeyStore struct {
+	mu        sync.RWMutex
+	masterKey []byte
+	entries   map[string][]byte
+}
+
+// NewEncryptedKeyStore creates an empty store protected by masterKey.
+//
+// Parameters:
+//   - masterKey: The 32-byte master key (must be exactly KeySize bytes); it is copied,
+//     so the caller may zeroize its own copy afterwards
+//
+// Returns:
+//   - An empty EncryptedKeyStore
+//   - An error if the master key size is invalid
+//
+// Example:
+//
+//	masterKey, _ := crypto.GenerateKey()
+//	store, err := crypto.NewEncryptedKeyStore(masterKey)
+//	crypto.Zeroize(masterKey)
+//	defer store.Destroy()
+func NewEncryptedKeyStore(masterKey []byte) (*EncryptedKeyStore, error) {
+	if err := checkKey(masterKey); err != nil {
+		return nil, err
+	}
+	return &EncryptedKeyStore{
+		masterKey: append([]byte(nil), masterKey...),
+		entries:   make(map[string][]byte),
+	}, nil
+}
+
+// Put encrypts key and stores it under id, replacing any existing entry.
+//
+// The store keeps only the encrypted form; the caller should Zeroize key afterwards.
+//
+// Parameters:
+//   - id: The key identifier
+//   - key: The key to store (cannot be empty)
+//
+// Returns:
+//   - An error if key is empty, the store was destroyed or encryption fails
+//
+// Example:
+//
+//	if err := store.Put("tenant-42", tenantKey); err != nil {
+//		log.Fatal(err)
+//	}
+//	crypto.Zeroize(tenantKey)
+func (s *EncryptedKeyStore) Put(id string, key []byte) error {
+	if len(key) == 0 {
+		return goerrors.New("EMPTY_KEY", "key cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.masterKey == nil {
+		return goerrors.New("KEYSTORE_DESTROYED", "key store has been destroyed")
+	}
+	sealed, err := sealWithHeader(s.masterKey, keyStoreEntryLabel(id), nil, key)
+	if err != nil {
+		return err
+	}
+	s.entries[id] = sealed
+	return nil
+}
+
+// WithKey decrypts the key stored under id, passes it to fn and zeroizes it when fn
+// returns.
+//
+// The key slice is valid only during fn and must not be retained or shared beyond it;
+// copy it if necessary. Calls for different identifiers, and for the same one, may run
+// concurrently.
+//
+// Parameters:
+//   - id: The key identifier
+//   - fn: The function using the key
+//
+// Returns:
+//   - The error returned by fn
+//   - An error if id is unknown, the store was destroyed or the entry fails to decrypt
+//
+// Example:
+//
+//	err := store.WithKey("tenant-42", func(key []byte) error {
+//		ciphertext, err = crypto.EncryptBytes(record, key)
+//		return err
+//	})
+func (s *EncryptedKeyStore) WithKey(id string, fn func(key []byte) error) error {
+	s.mu.RLock()
+	if s.masterKey == nil {
+		s.mu.RUnlock()
+		return goerrors.New("KEYSTORE_DESTROYED", "key store has been destroyed")
+	}
+	sealed, ok := s.entries[id]
+	if !ok {
+		s.mu.RUnlock()
+		return goerrors.New("KEY_NOT_FOUND", fmt.Sprintf("no key stored under %q", id))
+	}
+	_, key, err := openWithHeader(s.masterKey, keyStoreEntryLabel(id), sealed, 0)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	defer Zeroize(key)
+	return fn(key)
+}
+
+// Delete removes the key stored under id, if any.
+func (s *EncryptedKeyStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+}
+
+// Len returns the number of stored keys.
+func (s *EncryptedKeyStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+// Destroy zeroizes the master key and drops every entry. Later calls to Put and WithKey
+// fail. Destroy is idempotent.
+func (s *EncryptedKeyStore) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	Zeroize(s.masterKey)
+	s.masterKey = nil
+	clear(s.entries)
+}
+
+// keyStoreEntryLabel returns the authenticated label binding an entry to id.
+func keyStoreEntryLabel(id string) string {
+	return string(appendLengthPrefixed([]byte(keyStoreLabel), []byte(id)))
+}
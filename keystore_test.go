@@ -0,0 +1,140 @@
+// keystore_test.go: Test cases for the in-memory encrypted key store.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptedKeyStore(t *testing.T) {
+	masterKey, _ := crypto.GenerateKey()
+	store, err := crypto.NewEncryptedKeyStore(masterKey)
+	if err != nil {
+		t.Fatalf("NewEncryptedKeyStore() error: %v", err)
+	}
+	defer store.Destroy()
+
+	key, _ := crypto.GenerateKey()
+	original := append([]byte(nil), key...)
+	if err := store.Put("tenant-1", key); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	crypto.Zeroize(key)
+
+	var seen []byte
+	err = store.WithKey("tenant-1", func(k []byte) error {
+		if !bytes.Equal(k, original) {
+			t.Error("WithKey passed the wrong key")
+		}
+		seen = k
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithKey() error: %v", err)
+	}
+	if !bytes.Equal(seen, make([]byte, len(seen))) {
+		t.Error("The key should be zeroized after the callback")
+	}
+
+	sentinel := errors.New("callback failed")
+	if err := store.WithKey("tenant-1", func([]byte) error { return sentinel }); !errors.Is(err, sentinel) {
+		t.Errorf("Expected the callback error, got %v", err)
+	}
+	if err := store.WithKey("missing", func([]byte) error { return nil }); err == nil {
+		t.Error("Expected an error for an unknown id")
+	}
+
+	store.Delete("tenant-1")
+	if store.Len() != 0 {
+		t.Errorf("Len() = %d after Delete, want 0", store.Len())
+	}
+	if err := store.Put("empty", nil); err == nil {
+		t.Error("Expected an error for an empty key")
+	}
+}
+
+func TestEncryptedKeyStore_Concurrent(t *testing.T) {
+	masterKey, _ := crypto.GenerateKey()
+	store, _ := crypto.NewEncryptedKeyStore(masterKey)
+	defer store.Destroy()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			id := fmt.Sprintf("key-%d", g)
+			want := bytes.Repeat([]byte{byte(g)}, crypto.KeySize)
+			for i := 0; i < 50; i++ {
+				if err := store.Put(id, append([]byte(nil), want...)); err != nil {
+					t.Errorf("Put() error: %v", err)
+					return
+				}
+				err := store.WithKey(id, func(k []byte) error {
+					if !bytes.Equal(k, want) {
+						return fmt.Errorf("wrong key for %s", id)
+					}
+					return nil
+				})
+				if err != nil {
+					t.Errorf("WithKey() error: %v", err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if store.Len() != 8 {
+		t.Errorf("Len() = %d, want 8", store.Len())
+	}
+}
+
+func TestEncryptedKeyStore_Destroy(t *testing.T) {
+	if _, err := crypto.NewEncryptedKeyStore(make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	masterKey, _ := crypto.GenerateKey()
+	store, _ := crypto.NewEncryptedKeyStore(masterKey)
+	_ = store.Put("a", []byte("secret key material"))
+	store.Destroy()
+	store.Destroy()
+	if err := store.WithKey("a", func([]byte) error { return nil }); err == nil {
+		t.Error("Expected an error after Destroy")
+	}
+	if err := store.Put("b", []byte("x")); err == nil {
+		t.Error("Expected Put to fail after Destroy")
+	}
+}
+
+func TestEncryptedKeyStore_WithKeyPanic(t *testing.T) {
+	masterKey, _ := crypto.GenerateKey()
+	store, _ := crypto.NewEncryptedKeyStore(masterKey)
+	defer store.Destroy()
+	_ = store.Put("k", bytes.Repeat([]byte{0xaa}, crypto.KeySize))
+
+	var seen []byte
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the callback panic to propagate, got %v", r)
+			}
+		}()
+		_ = store.WithKey("k", func(key []byte) error {
+			seen = key
+			panic("boom")
+		})
+	}()
+	if !bytes.Equal(seen, make([]byte, crypto.KeySize)) {
+		t.Error("The key should be zeroized when the callback panics")
+	}
+}
@@ -9,12 +9,18 @@ package crypto
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
+	"hash"
 	"io"
+	"strings"
 
 	goerrors "github.com/agilira/go-errors"
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
 // KeyToBase64 encodes a key as a base64 string.
@@ -115,6 +121,116 @@ func KeyFromHex(s string) ([]byte, error) {
 	return key, nil
 }
 
+// KeyFromHexCT decodes a hexadecimal string to a key in constant time.
+//
+// It accepts the same input as KeyFromHex, but the decoding of each character uses
+// only arithmetic and masking, without branches or table lookups that depend on the
+// secret value. Only the input length, and whether the input as a whole is valid,
+// can be observed through timing. Use it when key material is decoded where a
+// co-located process could measure timing or cache behavior. Unlike KeyFromHex, the
+// returned error does not reveal the position of an invalid character.
+//
+// Parameters:
+//   - s: The hexadecimal string to decode
+//
+// Returns:
+//   - The decoded key as a byte slice
+//   - An error if the input has odd length or contains non-hexadecimal characters
+//
+// Example:
+//
+//	key, err := crypto.KeyFromHexCT(os.Getenv("SMARTCARD_KEY_HEX"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer crypto.Zeroize(key)
+func KeyFromHexCT(s string) ([]byte, error) {
+	if len(s)%2 != 0 {
+		return nil, goerrors.New("HEX_DECODE_ERROR", "failed to decode hex key: odd length")
+	}
+	key := make([]byte, len(s)/2)
+	var invalid uint32
+	for i := range key {
+		hi, hiOK := hexNibbleCT(s[2*i])
+		lo, loOK := hexNibbleCT(s[2*i+1])
+		key[i] = hi<<4 | lo
+		invalid |= (hiOK & loOK) ^ 1
+	}
+	if invalid != 0 {
+		Zeroize(key)
+		return nil, goerrors.New("HEX_DECODE_ERROR", "failed to decode hex key: invalid character")
+	}
+	return key, nil
+}
+
+// hexNibbleCT decodes one hexadecimal character without secret-dependent branches.
+// It returns the nibble value and 1 if c is a valid hex digit, 0 otherwise.
+func hexNibbleCT(c byte) (byte, uint32) {
+	num := uint32(c) ^ '0'
+	numMask := ((num - 10) >> 8) & 0xff // 0xff if c is '0'..'9'
+	alpha := (uint32(c) &^ 0x20) - 'A' + 10
+	alphaMask := (((alpha - 10) ^ (alpha - 16)) >> 8) & 0xff // 0xff if c is 'A'..'F' or 'a'..'f'
+	value := (num & numMask) | (alpha & alphaMask)
+	return byte(value), ((numMask | alphaMask) >> 7) & 1
+}
+
+// PEMKeyType is the PEM block type used by KeyToPEM and accepted by KeyFromPEM.
+const PEMKeyType = "AES-256-GCM KEY"
+
+// KeyToPEM encodes a key as a PEM block of type PEMKeyType.
+//
+// PEM files fit tooling that already manages certificates and private keys, such as
+// secret mounts and configuration management. The output contains the raw key, so the
+// file must be protected like any other key material.
+//
+// Parameters:
+//   - key: The key to encode
+//
+// Returns:
+//   - The PEM-encoded key, terminated by a newline
+//
+// Example:
+//
+//	key, _ := crypto.GenerateKey()
+//	err := os.WriteFile("data.key", crypto.KeyToPEM(key), 0o600)
+func KeyToPEM(key []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: PEMKeyType, Bytes: key})
+}
+
+// KeyFromPEM decodes a key from the first PEM block in pemData.
+//
+// The block must be of type PEMKeyType and hold exactly KeySize bytes. Data after the
+// first block is ignored.
+//
+// Parameters:
+//   - pemData: The PEM-encoded data, e.g. the contents of a key file
+//
+// Returns:
+//   - The decoded key as a byte slice
+//   - An error if no PEM block is found, the block type is wrong, or the key size is invalid
+//
+// Example:
+//
+//	data, err := os.ReadFile("/run/secrets/data.key")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	key, err := crypto.KeyFromPEM(data)
+func KeyFromPEM(pemData []byte) ([]byte, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, goerrors.New("PEM_DECODE_ERROR", "no PEM block found")
+	}
+	if block.Type != PEMKeyType {
+		return nil, goerrors.New("PEM_DECODE_ERROR", fmt.Sprintf("unexpected PEM block type %q, want %q", block.Type, PEMKeyType))
+	}
+	if err := ValidateKey(block.Bytes); err != nil {
+		Zeroize(block.Bytes)
+		return nil, err
+	}
+	return block.Bytes, nil
+}
+
 // Zeroize securely wipes a byte slice from memory.
 //
 // This function overwrites all bytes in the slice with zeros to prevent
@@ -123,6 +239,10 @@ func KeyFromHex(s string) ([]byte, error) {
 //
 // Note: This function modifies the original slice in place.
 //
+// The package wipes the secret intermediates it allocates with deferred calls, or with
+// deferred checks where a buffer is returned on success, so they are also wiped when a
+// panic unwinds through the package, e.g. from a panicking callback or io.Writer.
+//
 // Parameters:
 //   - b: The byte slice to zeroize
 //
@@ -139,6 +259,93 @@ func Zeroize(b []byte) {
 	}
 }
 
+// wipingBuffer collects secret data of unknown size. Unlike bytes.Buffer, it zeroizes
+// the old backing array each time it grows, so no stale copy of the data is left on the
+// heap; the caller wipes Bytes when done.
+type wipingBuffer struct {
+	b []byte
+}
+
+// grow ensures room for n more bytes, wiping the backing array it replaces.
+func (w *wipingBuffer) grow(n int) {
+	if len(w.b)+n <= cap(w.b) {
+		return
+	}
+	grown := make([]byte, len(w.b), max(2*cap(w.b), len(w.b)+n, 512))
+	copy(grown, w.b)
+	Zeroize(w.b)
+	w.b = grown
+}
+
+// Write appends p to the buffer.
+func (w *wipingBuffer) Write(p []byte) (int, error) {
+	w.grow(len(p))
+	w.b = append(w.b, p...)
+	return len(p), nil
+}
+
+// ReadFrom reads r until EOF straight into the buffer, so that io.Copy does not stage
+// the data in an intermediate buffer of its own.
+func (w *wipingBuffer) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	for {
+		w.grow(1)
+		n, err := r.Read(w.b[len(w.b):cap(w.b)])
+		w.b = w.b[:len(w.b)+n]
+		total += int64(n)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// Len returns the number of bytes written.
+func (w *wipingBuffer) Len() int {
+	return len(w.b)
+}
+
+// Bytes returns the buffered data.
+func (w *wipingBuffer) Bytes() []byte {
+	return w.b
+}
+
+// ConstantTimeSelect returns a copy of a if condition is non-zero and a copy of b
+// otherwise, without branching on condition.
+//
+// An if statement on a secret condition, such as whether a real or a decoy key is
+// used, can leak the choice through timing and branch prediction. ConstantTimeSelect
+// always copies b and then conditionally overwrites it with a using
+// subtle.ConstantTimeCopy, so its running time depends only on the slice lengths. It
+// is the selection counterpart of subtle.ConstantTimeCompare. The lengths are treated
+// as public: a and b must have the same length.
+//
+// Parameters:
+//   - condition: Selects a when non-zero, b when zero
+//   - a: The value returned when condition is non-zero
+//   - b: The value returned when condition is zero
+//
+// Returns:
+//   - A new slice holding the selected value, or nil if a and b differ in length
+//
+// Example:
+//
+//	key := crypto.ConstantTimeSelect(isReal, realKey, decoyKey)
+//	defer crypto.Zeroize(key)
+func ConstantTimeSelect(condition int, a, b []byte) []byte {
+	if len(a) != len(b) {
+		return nil
+	}
+	// 1 if condition is non-zero, 0 otherwise, computed without a branch.
+	c := int((uint64(condition) | -uint64(condition)) >> 63)
+	out := make([]byte, len(b))
+	copy(out, b)
+	subtle.ConstantTimeCopy(c, out, a)
+	return out
+}
+
 // GetKeyFingerprint generates a fingerprint for a key (non-cryptographic).
 //
 // This function creates a short, human-readable identifier for a key by computing
@@ -170,6 +377,265 @@ func GetKeyFingerprint(key []byte) string {
 	return fmt.Sprintf("%016x", hash[:8])
 }
 
+// GetKeyFingerprints returns the fingerprint of each key, in order.
+//
+// Each entry is formatted as by GetKeyFingerprint (an empty string for an empty key),
+// so results can be stored and compared across runs, e.g. in a key inventory.
+//
+// Parameters:
+//   - keys: The keys to fingerprint
+//
+// Returns:
+//   - One fingerprint per key, at the same index
+//
+// Example:
+//
+//	for i, fp := range crypto.GetKeyFingerprints(keys) {
+//		fmt.Printf("key %d: %s\n", i, fp)
+//	}
+func GetKeyFingerprints(keys [][]byte) []string {
+	fingerprints := make([]string, len(keys))
+	for i, key := range keys {
+		fingerprints[i] = GetKeyFingerprint(key)
+	}
+	return fingerprints
+}
+
+// FindKeyByFingerprint returns the index of the first key whose fingerprint matches fingerprint.
+//
+// Every candidate is compared in constant time and the scan does not stop at the first
+// match, so the timing reveals neither how much of a fingerprint matched nor which
+// key did. The comparison ignores the case of the hexadecimal digits.
+//
+// Parameters:
+//   - keys: The candidate keys
+//   - fingerprint: A fingerprint as returned by GetKeyFingerprint
+//
+// Returns:
+//   - The index of the matching key
+//   - true if a key matched, false otherwise (the index is then -1)
+//
+// Example:
+//
+//	hint, _ := crypto.CiphertextKeyHint(ciphertext)
+//	if i, ok := crypto.FindKeyByFingerprint(keys, hint); ok {
+//		plaintext, err := crypto.DecryptWithKeyHint(ciphertext, keys[i])
+//	}
+func FindKeyByFingerprint(keys [][]byte, fingerprint string) (int, bool) {
+	want := []byte(strings.ToLower(fingerprint))
+	found := -1
+	for i, key := range keys {
+		match := subtle.ConstantTimeCompare([]byte(GetKeyFingerprint(key)), want) == 1
+		if match && found < 0 && len(want) > 0 {
+			found = i
+		}
+	}
+	return found, found >= 0
+}
+
+// FingerprintReader computes a fingerprint of everything read from r.
+//
+// The data is streamed through SHA-256, so arbitrarily large inputs (files, network
+// streams) are fingerprinted in constant memory. The result uses the same format as
+// GetKeyFingerprint: the first 8 bytes of the SHA-256 digest as 16 hexadecimal characters.
+//
+// Parameters:
+//   - r: The reader providing the data to fingerprint
+//
+// Returns:
+//   - A 16-character hexadecimal fingerprint
+//   - An error if reading fails
+//
+// Example:
+//
+//	f, _ := os.Open("release.tar.gz")
+//	defer f.Close()
+//	fingerprint, err := crypto.FingerprintReader(f)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func FingerprintReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", goerrors.Wrap(err, "FINGERPRINT_READ_ERROR", "failed to read data for fingerprint")
+	}
+	return fmt.Sprintf("%016x", h.Sum(nil)[:8]), nil
+}
+
+// FingerprintHasher computes a fingerprint of data written to it in pieces.
+//
+// It is the incremental counterpart of FingerprintReader for data that arrives in
+// several writes, e.g. chunks received over the network. Sum returns the same
+// format as GetKeyFingerprint, and for non-empty data the same value as
+// GetKeyFingerprint of the concatenated writes. If nothing was written, Sum returns
+// the fingerprint of empty input, as FingerprintReader does. A FingerprintHasher is
+// not safe for concurrent use.
+type FingerprintHasher struct {
+	h hash.Hash
+}
+
+// NewFingerprintHasher returns an empty FingerprintHasher.
+//
+// Example:
+//
+//	fh := crypto.NewFingerprintHasher()
+//	for chunk := range chunks {
+//		fh.Write(chunk)
+//	}
+//	fmt.Println("content fingerprint:", fh.Sum())
+func NewFingerprintHasher() *FingerprintHasher {
+	return &FingerprintHasher{h: sha256.New()}
+}
+
+// Write adds p to the fingerprinted data. It never returns an error.
+func (f *FingerprintHasher) Write(p []byte) (int, error) {
+	return f.h.Write(p)
+}
+
+// Sum returns the 16-character hexadecimal fingerprint of the data written so far.
+// It does not change the state, so more data can be written afterwards.
+func (f *FingerprintHasher) Sum() string {
+	return fmt.Sprintf("%016x", f.h.Sum(nil)[:8])
+}
+
+// Reset discards the data written so far.
+func (f *FingerprintHasher) Reset() {
+	f.h.Reset()
+}
+
+// KeyCommitmentNonceSize is the size in bytes of the random nonce used by CommitKey.
+const KeyCommitmentNonceSize = 32
+
+// keyCommitmentLabel domain-separates key commitments from other SHA-256 uses.
+const keyCommitmentLabel = "go-crypto/v1/key-commitment"
+
+// CommitKey computes a hiding commitment to a key for commit-reveal protocols.
+//
+// The commitment is SHA-256(label || nonce || key) with a fresh random nonce, so it
+// reveals nothing about the key and cannot be linked to other commitments to the same
+// key. Publish the commitment now; later reveal the key and nonce so that others can
+// check them with VerifyKeyCommitment.
+//
+// Parameters:
+//   - key: The key to commit to (cannot be empty)
+//
+// Returns:
+//   - The commitment as a 64-character hexadecimal string
+//   - The KeyCommitmentNonceSize-byte nonce to reveal together with the key
+//   - An error if the key is empty or nonce generation fails
+//
+// Example:
+//
+//	commitment, nonce, err := crypto.CommitKey(key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	publish(commitment)
+//	// ... later
+//	ok := crypto.VerifyKeyCommitment(key, nonce, commitment)
+func CommitKey(key []byte) (commitment string, nonce []byte, err error) {
+	if len(key) == 0 {
+		return "", nil, goerrors.New("EMPTY_KEY", "key cannot be empty")
+	}
+	nonce = make([]byte, KeyCommitmentNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, goerrors.Wrap(err, "NONCE_GEN_ERROR", "failed to generate commitment nonce")
+	}
+	digest := keyCommitment(key, nonce)
+	return hex.EncodeToString(digest[:]), nonce, nil
+}
+
+// VerifyKeyCommitment reports whether key and nonce open the given commitment.
+//
+// The comparison is constant-time. Malformed commitments and nonces of the wrong
+// size are rejected.
+//
+// Parameters:
+//   - key: The revealed key
+//   - nonce: The revealed nonce returned by CommitKey
+//   - commitment: The previously published commitment
+//
+// Returns:
+//   - true if the commitment matches, false otherwise
+func VerifyKeyCommitment(key, nonce []byte, commitment string) bool {
+	if len(key) == 0 || len(nonce) != KeyCommitmentNonceSize {
+		return false
+	}
+	expected, err := hex.DecodeString(commitment)
+	if err != nil {
+		return false
+	}
+	digest := keyCommitment(key, nonce)
+	return subtle.ConstantTimeCompare(digest[:], expected) == 1
+}
+
+// keyCommitment computes SHA-256(label || nonce || key). The fixed nonce size keeps
+// the encoding unambiguous.
+func keyCommitment(key, nonce []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(keyCommitmentLabel))
+	h.Write(nonce)
+	h.Write(key)
+	var digest [sha256.Size]byte
+	h.Sum(digest[:0])
+	return digest
+}
+
+// keyAgreementLabel domain-separates key agreement proofs from other HMACs under the same key.
+const keyAgreementLabel = "go-crypto/v1/key-agreement"
+
+// KeyAgreementProof proves possession of a key by answering a challenge, without revealing the key.
+//
+// The proof is HMAC-SHA256(key, label || challenge). Two nodes that derived a key
+// independently, e.g. from a shared password, can confirm they hold the same key
+// over an untrusted channel: one sends a fresh random challenge, the other answers
+// with its proof, and the first checks it with VerifyKeyAgreement. A mismatch reveals
+// a wrong password, salt or KDF parameters before any real data is encrypted. The
+// proof reveals nothing about the key beyond this check.
+//
+// For mutual confirmation, each side must issue its own challenge, and a node must
+// never answer a challenge it issued itself; otherwise an attacker can reflect a
+// node's challenge back to it and relay the node's own proof. Binding the sender's
+// identity into the challenge (e.g. hostname || random bytes) prevents this.
+//
+// Parameters:
+//   - key: The key to prove possession of (cannot be empty)
+//   - challenge: The verifier's challenge, at least 16 fresh random bytes
+//
+// Returns:
+//   - The MACSize-byte proof, or nil if the key or challenge is empty
+//
+// Example:
+//
+//	challenge, _ := crypto.GenerateNonce(32) // sent to the peer
+//	proof := crypto.KeyAgreementProof(peerKey, challenge) // computed by the peer
+//	if !crypto.VerifyKeyAgreement(key, challenge, proof) {
+//		log.Fatal("peer derived a different key")
+//	}
+func KeyAgreementProof(key, challenge []byte) []byte {
+	mac := newMAC(key)
+	if mac == nil || len(challenge) == 0 {
+		return nil
+	}
+	mac.Write([]byte(keyAgreementLabel))
+	mac.Write(challenge)
+	return mac.Sum(nil)
+}
+
+// VerifyKeyAgreement reports, in constant time, whether proof answers challenge under key.
+//
+// Parameters:
+//   - key: The verifier's own key
+//   - challenge: The challenge sent to the peer
+//   - proof: The peer's KeyAgreementProof
+//
+// Returns:
+//   - true if the peer holds the same key; false otherwise, including for an empty key or challenge
+func VerifyKeyAgreement(key, challenge, proof []byte) bool {
+	expected := KeyAgreementProof(key, challenge)
+	return expected != nil && subtle.ConstantTimeCompare(expected, proof) == 1
+}
+
 // GenerateKey generates a cryptographically secure random key of KeySize bytes.
 //
 // This function creates a new 32-byte (256-bit) key suitable for AES-256 encryption.
@@ -197,6 +663,61 @@ func GenerateKey() ([]byte, error) {
 	return key, nil
 }
 
+// mixedKeyInfo is the HKDF info string used by GenerateKeyMixed.
+const mixedKeyInfo = "go-crypto/v1/mixed-key"
+
+// GenerateKeyMixed generates a KeySize key from the OS random source combined with extra entropy.
+//
+// KeySize bytes are read from crypto/rand and combined with every extraEntropy input
+// using HKDF-SHA256. Each input is length-prefixed before mixing, so inputs cannot be
+// shifted between arguments. As long as either the OS random source or the extra
+// entropy is unpredictable to an attacker, the resulting key is unpredictable: a
+// compromised OS RNG alone cannot determine the key.
+//
+// Extra entropy only adds security, it never reduces it: even constant, empty or
+// attacker-known extra inputs leave the key as strong as one from GenerateKey.
+// Typical sources are hardware tokens, user input timings or a second RNG.
+//
+// Parameters:
+//   - extraEntropy: Additional entropy sources to mix in (optional)
+//
+// Returns:
+//   - A 32-byte key as a byte slice
+//   - An error if reading from the OS random source fails
+//
+// Example:
+//
+//	tokenBytes := readHardwareToken()
+//	key, err := crypto.GenerateKeyMixed(tokenBytes, mouseSamples)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func GenerateKeyMixed(extraEntropy ...[]byte) ([]byte, error) {
+	osKey, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(osKey)
+
+	size := len(osKey)
+	for _, e := range extraEntropy {
+		size += 8 + len(e)
+	}
+	ikm := make([]byte, 0, size)
+	ikm = append(ikm, osKey...)
+	for _, e := range extraEntropy {
+		ikm = binary.BigEndian.AppendUint64(ikm, uint64(len(e)))
+		ikm = append(ikm, e...)
+	}
+	defer Zeroize(ikm)
+
+	key, err := deriveSubkey(ikm, nil, mixedKeyInfo, KeySize)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_GEN_ERROR", "failed to mix key entropy")
+	}
+	return key, nil
+}
+
 // GenerateNonce generates a cryptographically secure random nonce of the given size.
 //
 // A nonce (number used once) is a random value that should be used only once
@@ -224,12 +745,54 @@ func GenerateNonce(size int) ([]byte, error) {
 		return nil, goerrors.New("INVALID_NONCE_SIZE", "nonce size must be positive")
 	}
 	nonce := make([]byte, size)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+	if _, err := io.ReadFull(nonceReader(), nonce); err != nil {
 		return nil, goerrors.Wrap(err, "NONCE_GEN_ERROR", "failed to generate nonce")
 	}
 	return nonce, nil
 }
 
+// NonceSizeFor returns the nonce size in bytes required by alg, or 0 if alg is unknown.
+//
+// Example:
+//
+//	fmt.Println(crypto.NonceSizeFor(crypto.AlgorithmAES256GCM))         // Output: 12
+//	fmt.Println(crypto.NonceSizeFor(crypto.AlgorithmXChaCha20Poly1305)) // Output: 24
+func NonceSizeFor(alg Algorithm) int {
+	switch alg {
+	case AlgorithmAES256GCM:
+		return gcmNonceSize
+	case AlgorithmXChaCha20Poly1305:
+		return chacha20poly1305.NonceSizeX
+	default:
+		return 0
+	}
+}
+
+// GenerateNonceFor generates a random nonce of the size required by alg.
+//
+// Prefer it to GenerateNonce when more than one algorithm is in use, so a nonce can
+// never be generated with the size of a different algorithm.
+//
+// Parameters:
+//   - alg: The algorithm the nonce is for
+//
+// Returns:
+//   - A byte slice containing NonceSizeFor(alg) random bytes
+//   - An error wrapping ErrUnsupportedAlgorithm if alg is unknown, or an error if
+//     nonce generation fails
+//
+// Example:
+//
+//	nonce, err := crypto.GenerateNonceFor(crypto.AlgorithmXChaCha20Poly1305)
+func GenerateNonceFor(alg Algorithm) ([]byte, error) {
+	size := NonceSizeFor(alg)
+	if size == 0 {
+		richErr := goerrors.New(ErrCodeUnsupportedAlgorithm, fmt.Sprintf("no nonce size known for %s", alg))
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedAlgorithm, richErr)
+	}
+	return GenerateNonce(size)
+}
+
 // ValidateKey checks that a key has the correct size for AES-256.
 //
 // This function verifies that the provided key is exactly 32 bytes (256 bits),
@@ -251,10 +814,107 @@ func GenerateNonce(size int) ([]byte, error) {
 //	}
 //	fmt.Println("Key is valid for AES-256")
 //
-// The function will return an error if the key is not exactly 32 bytes.
+// The function will return an error if the key is not exactly 32 bytes. A nil key
+// yields an error wrapping ErrNilKey, so a missing key can be told apart from one of the
+// wrong length.
 func ValidateKey(key []byte) error {
+	if key == nil {
+		return nilKeyError()
+	}
 	if len(key) != KeySize {
 		return goerrors.New("INVALID_KEY_SIZE", fmt.Sprintf("key size must be %d bytes for AES-256, got %d", KeySize, len(key)))
 	}
 	return nil
 }
+
+// minDistinctKeyBytes is the minimum number of distinct byte values ValidateKeyStrength
+// accepts. A uniformly random 32-byte key has about 30 distinct values on average and
+// fewer than 16 with probability below 1e-16.
+const minDistinctKeyBytes = 16
+
+// ValidateKeyStrength checks that a key has the right size and does not look like a
+// placeholder or human-chosen value.
+//
+// In addition to the size check performed by ValidateKey, it rejects keys that are
+// made of a single repeated byte, a short repeating pattern, an arithmetic sequence
+// (e.g. 0x00 0x01 0x02 ...), too few distinct byte values, or printable ASCII only
+// (a passphrase used as a raw key). Each of these occurs for a random key with
+// negligible probability, so keys from GenerateKey never trigger a false positive.
+//
+// The check cannot prove that a key is random; it only catches obviously weak keys,
+// such as test fixtures accidentally shipped to production.
+//
+// Parameters:
+//   - key: The key to validate
+//
+// Returns:
+//   - An error describing the weakness, nil if no weakness was detected
+//
+// Example:
+//
+//	key, err := crypto.KeyFromBase64(os.Getenv("APP_KEY"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := crypto.ValidateKeyStrength(key); err != nil {
+//		log.Fatal("Refusing weak key:", err)
+//	}
+func ValidateKeyStrength(key []byte) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	if period := keyRepeatPeriod(key); period > 0 {
+		return goerrors.New("WEAK_KEY", fmt.Sprintf("key repeats a %d-byte pattern", period))
+	}
+	if isArithmeticSequence(key) {
+		return goerrors.New("WEAK_KEY", "key is a sequential byte pattern")
+	}
+
+	var seen [256]bool
+	distinct, printable := 0, true
+	for _, b := range key {
+		if !seen[b] {
+			seen[b] = true
+			distinct++
+		}
+		if b < 0x20 || b > 0x7e {
+			printable = false
+		}
+	}
+	if distinct < minDistinctKeyBytes {
+		return goerrors.New("WEAK_KEY", fmt.Sprintf("key has only %d distinct byte values", distinct))
+	}
+	if printable {
+		return goerrors.New("WEAK_KEY", "key consists only of printable ASCII characters")
+	}
+	return nil
+}
+
+// keyRepeatPeriod returns the smallest period (up to half the key length) with which
+// key repeats itself, or 0 if there is none.
+func keyRepeatPeriod(key []byte) int {
+	for period := 1; period <= len(key)/2; period++ {
+		repeats := true
+		for i := period; i < len(key); i++ {
+			if key[i] != key[i-period] {
+				repeats = false
+				break
+			}
+		}
+		if repeats {
+			return period
+		}
+	}
+	return 0
+}
+
+// isArithmeticSequence reports whether consecutive bytes of key differ by a constant step.
+func isArithmeticSequence(key []byte) bool {
+	step := key[1] - key[0]
+	for i := 2; i < len(key); i++ {
+		if key[i]-key[i-1] != step {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+// keyutils_internal_test.go: Test cases for the unexported memory hygiene helpers.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWipingBuffer_WipesOnGrow(t *testing.T) {
+	var w wipingBuffer
+	secret := bytes.Repeat([]byte{0xa5}, 300)
+	w.Write(secret)
+	old := w.b[:cap(w.b)]
+	w.Write(bytes.Repeat([]byte{0x5a}, 1000))
+	if &old[0] == &w.b[0] {
+		t.Fatal("expected the buffer to reallocate")
+	}
+	if !bytes.Equal(old, make([]byte, len(old))) {
+		t.Error("old backing array was not wiped")
+	}
+	want := append(append([]byte(nil), secret...), bytes.Repeat([]byte{0x5a}, 1000)...)
+	if !bytes.Equal(w.Bytes(), want) || w.Len() != len(want) {
+		t.Errorf("buffer holds %d bytes, want %d", w.Len(), len(want))
+	}
+}
+
+func TestWipingBuffer_ReadFrom(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	var w wipingBuffer
+	// io.LimitReader hides bytes.Reader's WriteTo, so io.Copy goes through ReadFrom.
+	n, err := io.Copy(&w, io.LimitReader(bytes.NewReader(data), int64(len(data))))
+	if err != nil || n != int64(len(data)) || !bytes.Equal(w.Bytes(), data) {
+		t.Fatalf("io.Copy() = %d, %v", n, err)
+	}
+
+	w = wipingBuffer{}
+	if _, err := w.ReadFrom(io.MultiReader(bytes.NewReader(data[:10]), errReader{})); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadFrom() error = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if !bytes.Equal(w.Bytes(), data[:10]) {
+		t.Errorf("ReadFrom() kept %q", w.Bytes())
+	}
+}
+
+// errReader fails every read.
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
@@ -7,7 +7,13 @@
 package crypto_test
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 
 	"crypto/rand"
@@ -43,6 +49,31 @@ func TestGenerateNonce_ValidAndInvalid(t *testing.T) {
 	}
 }
 
+func TestGenerateNonceFor(t *testing.T) {
+	tests := []struct {
+		alg  crypto.Algorithm
+		want int
+	}{
+		{crypto.AlgorithmAES256GCM, 12},
+		{crypto.AlgorithmXChaCha20Poly1305, 24},
+	}
+	for _, tt := range tests {
+		if got := crypto.NonceSizeFor(tt.alg); got != tt.want {
+			t.Errorf("NonceSizeFor(%s) = %d, want %d", tt.alg, got, tt.want)
+		}
+		nonce, err := crypto.GenerateNonceFor(tt.alg)
+		if err != nil || len(nonce) != tt.want {
+			t.Errorf("GenerateNonceFor(%s) = %d bytes, %v", tt.alg, len(nonce), err)
+		}
+	}
+	if got := crypto.NonceSizeFor(crypto.Algorithm(999)); got != 0 {
+		t.Errorf("NonceSizeFor(unknown) = %d, want 0", got)
+	}
+	if _, err := crypto.GenerateNonceFor(crypto.Algorithm(999)); !errors.Is(err, crypto.ErrUnsupportedAlgorithm) {
+		t.Errorf("GenerateNonceFor(unknown): got %v, want ErrUnsupportedAlgorithm", err)
+	}
+}
+
 func TestValidateKey(t *testing.T) {
 	validKey := make([]byte, crypto.KeySize)
 	if err := crypto.ValidateKey(validKey); err != nil {
@@ -54,6 +85,39 @@ func TestValidateKey(t *testing.T) {
 	}
 }
 
+func TestValidateKeyStrength(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		key, _ := crypto.GenerateKey()
+		if err := crypto.ValidateKeyStrength(key); err != nil {
+			t.Fatalf("Expected random key to pass, got error: %v", err)
+		}
+	}
+
+	sequential := make([]byte, crypto.KeySize)
+	descending := make([]byte, crypto.KeySize)
+	lowDistinct := make([]byte, crypto.KeySize)
+	for i := range sequential {
+		sequential[i] = byte(i)
+		descending[i] = byte(0xff - 3*i)
+		lowDistinct[i] = byte(0x80 + (i*7/3)%10)
+	}
+	weak := map[string][]byte{
+		"wrong size":   make([]byte, 16),
+		"all zeros":    make([]byte, crypto.KeySize),
+		"all 0xAA":     bytes.Repeat([]byte{0xaa}, crypto.KeySize),
+		"repeated":     bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, crypto.KeySize/4),
+		"sequential":   sequential,
+		"descending":   descending,
+		"low distinct": lowDistinct,
+		"ascii":        []byte("my-super-secret-key-0123456789ab"),
+	}
+	for name, key := range weak {
+		if err := crypto.ValidateKeyStrength(key); err == nil {
+			t.Errorf("%s: expected weak key to be rejected", name)
+		}
+	}
+}
+
 func TestKeyBase64RoundTrip(t *testing.T) {
 	key, _ := crypto.GenerateKey()
 	b64 := crypto.KeyToBase64(key)
@@ -260,3 +324,261 @@ func TestGenerateNonceWithMockedRandomFailure(t *testing.T) {
 		t.Error("Expected error when random generation fails")
 	}
 }
+
+func TestGenerateKeyMixed(t *testing.T) {
+	key, err := crypto.GenerateKeyMixed([]byte("hardware-token"), []byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("GenerateKeyMixed() error: %v", err)
+	}
+	if len(key) != crypto.KeySize {
+		t.Errorf("Expected key length %d, got %d", crypto.KeySize, len(key))
+	}
+	again, _ := crypto.GenerateKeyMixed([]byte("hardware-token"), []byte{1, 2, 3})
+	if string(key) == string(again) {
+		t.Error("Expected different keys for identical extra entropy")
+	}
+	noExtra, err := crypto.GenerateKeyMixed()
+	if err != nil {
+		t.Fatalf("GenerateKeyMixed() without extra entropy error: %v", err)
+	}
+	if len(noExtra) != crypto.KeySize {
+		t.Errorf("Expected key length %d, got %d", crypto.KeySize, len(noExtra))
+	}
+}
+
+func TestGenerateKeyMixed_RandomFailure(t *testing.T) {
+	originalReader := rand.Reader
+	defer func() { rand.Reader = originalReader }()
+	rand.Reader = &failingReader{}
+
+	if _, err := crypto.GenerateKeyMixed([]byte("extra")); err == nil {
+		t.Error("Expected error when the OS random source fails")
+	}
+}
+
+func TestCommitKey(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	commitment, nonce, err := crypto.CommitKey(key)
+	if err != nil {
+		t.Fatalf("CommitKey() error: %v", err)
+	}
+	if len(commitment) != 64 || len(nonce) != crypto.KeyCommitmentNonceSize {
+		t.Errorf("Unexpected commitment %q or nonce length %d", commitment, len(nonce))
+	}
+	if !crypto.VerifyKeyCommitment(key, nonce, commitment) {
+		t.Error("Expected commitment to verify")
+	}
+
+	other, _ := crypto.GenerateKey()
+	again, _, _ := crypto.CommitKey(key)
+	if again == commitment {
+		t.Error("Expected commitments to the same key to differ")
+	}
+	if crypto.VerifyKeyCommitment(other, nonce, commitment) {
+		t.Error("Expected commitment to reject a different key")
+	}
+	badNonce := append([]byte{}, nonce...)
+	badNonce[0] ^= 1
+	if crypto.VerifyKeyCommitment(key, badNonce, commitment) || crypto.VerifyKeyCommitment(key, nonce[:16], commitment) {
+		t.Error("Expected commitment to reject a wrong nonce")
+	}
+	if crypto.VerifyKeyCommitment(key, nonce, "zz") || crypto.VerifyKeyCommitment(key, nonce, commitment[:62]) {
+		t.Error("Expected malformed commitments to be rejected")
+	}
+	if _, _, err := crypto.CommitKey(nil); err == nil {
+		t.Error("Expected error for empty key")
+	}
+}
+
+func TestKeyAgreementProof(t *testing.T) {
+	params := &crypto.KDFParams{Time: 1, Memory: 8, Threads: 1}
+	salt := []byte("shared-salt-0001")
+	nodeA, _ := crypto.DeriveKey([]byte("shared password"), salt, crypto.KeySize, params)
+	nodeB, _ := crypto.DeriveKey([]byte("shared password"), salt, crypto.KeySize, params)
+	mistyped, _ := crypto.DeriveKey([]byte("shared passwrod"), salt, crypto.KeySize, params)
+
+	challenge, _ := crypto.GenerateNonce(32)
+	proof := crypto.KeyAgreementProof(nodeB, challenge)
+	if len(proof) != crypto.MACSize {
+		t.Fatalf("Expected %d-byte proof, got %d", crypto.MACSize, len(proof))
+	}
+	if bytes.Contains(proof, nodeB[:8]) {
+		t.Error("Proof must not contain key material")
+	}
+	if !crypto.VerifyKeyAgreement(nodeA, challenge, proof) {
+		t.Error("Expected nodes with the same derived key to agree")
+	}
+	if crypto.VerifyKeyAgreement(nodeA, challenge, crypto.KeyAgreementProof(mistyped, challenge)) {
+		t.Error("Expected a derivation mismatch to be detected")
+	}
+	other, _ := crypto.GenerateNonce(32)
+	if crypto.VerifyKeyAgreement(nodeA, other, proof) {
+		t.Error("Expected a proof not to answer a different challenge")
+	}
+	if bytes.Equal(proof, crypto.MACFields(nodeB, challenge)) {
+		t.Error("Expected proofs to be domain-separated from field MACs")
+	}
+	if crypto.KeyAgreementProof(nil, challenge) != nil || crypto.KeyAgreementProof(nodeA, nil) != nil {
+		t.Error("Expected no proof for an empty key or challenge")
+	}
+	if crypto.VerifyKeyAgreement(nil, challenge, nil) || crypto.VerifyKeyAgreement(nodeA, nil, nil) {
+		t.Error("Expected empty inputs never to verify")
+	}
+}
+
+func TestKeyFromHexCT(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, encoded := range []string{crypto.KeyToHex(key), strings.ToUpper(crypto.KeyToHex(key))} {
+		decoded, err := crypto.KeyFromHexCT(encoded)
+		if err != nil {
+			t.Fatalf("KeyFromHexCT() error: %v", err)
+		}
+		if !bytes.Equal(decoded, key) {
+			t.Error("Expected KeyFromHexCT to match the original key")
+		}
+	}
+
+	// Every byte value must be classified exactly like encoding/hex.
+	for c := 0; c < 256; c++ {
+		input := string([]byte{'a', byte(c)})
+		want, wantErr := hex.DecodeString(input)
+		got, gotErr := crypto.KeyFromHexCT(input)
+		if (wantErr == nil) != (gotErr == nil) || !bytes.Equal(want, got) {
+			t.Errorf("Mismatch for byte 0x%02x: want %x/%v, got %x/%v", c, want, wantErr, got, gotErr)
+		}
+	}
+
+	if _, err := crypto.KeyFromHexCT("abc"); err == nil {
+		t.Error("Expected error for odd-length input")
+	}
+	if got, err := crypto.KeyFromHexCT(""); err != nil || len(got) != 0 {
+		t.Errorf("Expected empty key for empty input, got %x err=%v", got, err)
+	}
+}
+
+func TestKeyPEMRoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	encoded := crypto.KeyToPEM(key)
+	if !strings.HasPrefix(string(encoded), "-----BEGIN "+crypto.PEMKeyType+"-----\n") {
+		t.Fatalf("KeyToPEM() has unexpected header: %q", encoded)
+	}
+	decoded, err := crypto.KeyFromPEM(encoded)
+	if err != nil {
+		t.Fatalf("KeyFromPEM() error: %v", err)
+	}
+	if !bytes.Equal(decoded, key) {
+		t.Error("KeyFromPEM() did not return the original key")
+	}
+
+	// Surrounding text, as in a bundle with comments, is ignored.
+	withText := append([]byte("# data key\n"), encoded...)
+	if decoded, err := crypto.KeyFromPEM(withText); err != nil || !bytes.Equal(decoded, key) {
+		t.Errorf("KeyFromPEM() with leading text: %v", err)
+	}
+}
+
+func TestKeyFromPEM_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not PEM", []byte("not a pem file")},
+		{"wrong type", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: make([]byte, 32)})},
+		{"short key", crypto.KeyToPEM(make([]byte, 16))},
+		{"long key", crypto.KeyToPEM(make([]byte, 64))},
+	}
+	for _, tt := range tests {
+		if _, err := crypto.KeyFromPEM(tt.data); err == nil {
+			t.Errorf("%s: KeyFromPEM() succeeded, want error", tt.name)
+		}
+	}
+}
+
+func TestGetKeyFingerprints(t *testing.T) {
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	got := crypto.GetKeyFingerprints([][]byte{keyA, nil, keyB})
+	want := []string{crypto.GetKeyFingerprint(keyA), "", crypto.GetKeyFingerprint(keyB)}
+	if len(got) != len(want) {
+		t.Fatalf("GetKeyFingerprints() returned %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if len(crypto.GetKeyFingerprints(nil)) != 0 {
+		t.Error("GetKeyFingerprints(nil) should be empty")
+	}
+}
+
+func TestFindKeyByFingerprint(t *testing.T) {
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	keys := [][]byte{keyA, keyB, keyB}
+
+	if i, ok := crypto.FindKeyByFingerprint(keys, crypto.GetKeyFingerprint(keyB)); !ok || i != 1 {
+		t.Errorf("FindKeyByFingerprint(keyB) = %d, %v; want 1, true", i, ok)
+	}
+	if i, ok := crypto.FindKeyByFingerprint(keys, strings.ToUpper(crypto.GetKeyFingerprint(keyA))); !ok || i != 0 {
+		t.Errorf("FindKeyByFingerprint(uppercase keyA) = %d, %v; want 0, true", i, ok)
+	}
+
+	other, _ := crypto.GenerateKey()
+	for _, fp := range []string{crypto.GetKeyFingerprint(other), "", crypto.GetKeyFingerprint(keyA)[:8]} {
+		if i, ok := crypto.FindKeyByFingerprint(keys, fp); ok || i != -1 {
+			t.Errorf("FindKeyByFingerprint(%q) = %d, %v; want -1, false", fp, i, ok)
+		}
+	}
+	if _, ok := crypto.FindKeyByFingerprint([][]byte{nil}, ""); ok {
+		t.Error("empty fingerprint should not match an empty key")
+	}
+}
+
+func TestFingerprintHasher(t *testing.T) {
+	data := []byte("assembled from several network chunks")
+	fh := crypto.NewFingerprintHasher()
+	for i := 0; i < len(data); i += 7 {
+		if n, err := fh.Write(data[i:min(i+7, len(data))]); err != nil || n == 0 {
+			t.Fatalf("Write() = %d, %v", n, err)
+		}
+	}
+	if got, want := fh.Sum(), crypto.GetKeyFingerprint(data); got != want {
+		t.Errorf("Sum() = %s, want %s", got, want)
+	}
+	if fh.Sum() != fh.Sum() {
+		t.Error("Sum() changed the state")
+	}
+
+	fh.Reset()
+	empty, _ := crypto.FingerprintReader(bytes.NewReader(nil))
+	if got := fh.Sum(); got != empty {
+		t.Errorf("Sum() after Reset = %s, want %s", got, empty)
+	}
+}
+
+func TestConstantTimeSelect(t *testing.T) {
+	a := []byte("real key material")
+	b := []byte("decoy key materia")
+	for _, tc := range []struct {
+		condition int
+		want      []byte
+	}{{1, a}, {0, b}, {-1, a}, {2, a}, {math.MaxInt32, a}, {math.MinInt32, a}} {
+		got := crypto.ConstantTimeSelect(tc.condition, a, b)
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("ConstantTimeSelect(%d) = %q, want %q", tc.condition, got, tc.want)
+		}
+	}
+	got := crypto.ConstantTimeSelect(1, a, b)
+	got[0] ^= 0xff
+	if a[0] != 'r' {
+		t.Error("Expected ConstantTimeSelect to return a copy")
+	}
+	if crypto.ConstantTimeSelect(1, a, b[:3]) != nil {
+		t.Error("Expected nil for slices of different lengths")
+	}
+	if got := crypto.ConstantTimeSelect(0, nil, nil); len(got) != 0 {
+		t.Errorf("Expected an empty result for empty inputs, got %q", got)
+	}
+}
@@ -0,0 +1,309 @@
+// mac.go: Streaming HMAC-SHA256 authentication for append-only files such as audit logs.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// MACSize is the size of the HMAC-SHA256 trailer written by NewMACWriter.
+const MACSize = sha256.Size
+
+// macReadChunk is the read size used by the VerifyMACReader reader.
+const macReadChunk = 32 << 10
+
+// ErrInvalidMAC is returned when a MAC trailer does not match the data.
+var ErrInvalidMAC = errors.New("crypto: MAC verification failed")
+
+// ErrCodeInvalidMAC is the error code for MAC verification failures.
+const ErrCodeInvalidMAC = "CRYPTO_INVALID_MAC"
+
+// macWriter passes writes through while accumulating an HMAC over them.
+type macWriter struct {
+	w      io.Writer
+	mac    hash.Hash
+	closed bool
+}
+
+// NewMACWriter returns a writer that copies everything to w and, on Close, appends an
+// HMAC-SHA256 of all written bytes as a MACSize-byte trailer.
+//
+// The output is data || HMAC-SHA256(key, data), so a whole file is authenticated in a
+// single streaming pass without buffering. Close does not close w. Writes after Close
+// fail. An empty key is rejected on the first Write or Close.
+//
+// Parameters:
+//   - w: The destination writer
+//   - key: The MAC key (should be at least 32 random bytes, separate from encryption keys)
+//
+// Returns:
+//   - A writer that must be closed to emit the MAC
+//
+// Example:
+//
+//	f, _ := os.Create("audit.log")
+//	mw := crypto.NewMACWriter(f, macKey)
+//	fmt.Fprintln(mw, "user alice logged in")
+//	if err := mw.Close(); err != nil {
+//		log.Fatal(err)
+//	}
+//	f.Close()
+func NewMACWriter(w io.Writer, key []byte) io.WriteCloser {
+	return &macWriter{w: w, mac: newMAC(key)}
+}
+
+// Write implements io.Writer.
+func (m *macWriter) Write(p []byte) (int, error) {
+	if err := m.check(); err != nil {
+		return 0, err
+	}
+	n, err := m.w.Write(p)
+	m.mac.Write(p[:n])
+	return n, err
+}
+
+// Close writes the MAC trailer.
+func (m *macWriter) Close() error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	m.closed = true
+	_, err := m.w.Write(m.mac.Sum(nil))
+	return err
+}
+
+// check reports whether the writer can still accept data.
+func (m *macWriter) check() error {
+	if m.mac == nil {
+		return goerrors.New("EMPTY_KEY", "MAC key cannot be empty")
+	}
+	if m.closed {
+		return goerrors.New("WRITER_CLOSED", "MAC writer already closed")
+	}
+	return nil
+}
+
+// macReader releases data while holding back the trailing MAC until it can be verified.
+type macReader struct {
+	r       io.Reader
+	mac     hash.Hash
+	pending []byte
+	eof     bool
+	err     error
+}
+
+// VerifyMACReader returns a reader over the data of a NewMACWriter stream that verifies
+// the MAC trailer as it reaches the end.
+//
+// The returned reader yields the data without the trailer. Verification happens in the
+// same pass: the final Read returns io.EOF only if the MAC matches, and an error
+// wrapping ErrInvalidMAC otherwise (including when the stream is shorter than a MAC).
+// Data read before that point is unauthenticated and must not be trusted or acted upon
+// until io.EOF has been observed.
+//
+// Parameters:
+//   - r: The stream produced by NewMACWriter
+//   - key: The MAC key used for writing (cannot be empty)
+//
+// Returns:
+//   - A reader over the authenticated data
+//   - An error if the key is empty
+//
+// Example:
+//
+//	f, _ := os.Open("audit.log")
+//	vr, err := crypto.VerifyMACReader(f, macKey)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	data, err := io.ReadAll(vr) // err wraps ErrInvalidMAC if the log was altered
+func VerifyMACReader(r io.Reader, key []byte) (io.Reader, error) {
+	mac := newMAC(key)
+	if mac == nil {
+		return nil, goerrors.New("EMPTY_KEY", "MAC key cannot be empty")
+	}
+	return &macReader{r: r, mac: mac}, nil
+}
+
+// Read implements io.Reader.
+func (m *macReader) Read(p []byte) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(m.pending) <= MACSize && !m.eof {
+		if err := m.fill(); err != nil {
+			m.err = err
+			return 0, err
+		}
+	}
+
+	n := min(len(p), len(m.pending)-MACSize)
+	if n > 0 {
+		copy(p, m.pending[:n])
+		m.mac.Write(p[:n])
+		m.pending = m.pending[n:]
+		return n, nil
+	}
+	m.err = m.verify()
+	return 0, m.err
+}
+
+// fill appends the next chunk of the underlying stream to pending.
+func (m *macReader) fill() error {
+	if cap(m.pending)-len(m.pending) < macReadChunk {
+		grown := make([]byte, len(m.pending), len(m.pending)+macReadChunk)
+		copy(grown, m.pending)
+		m.pending = grown
+	}
+	n, err := m.r.Read(m.pending[len(m.pending):cap(m.pending)])
+	m.pending = m.pending[:len(m.pending)+n]
+	if err == io.EOF {
+		m.eof = true
+		return nil
+	}
+	return err
+}
+
+// verify checks the held-back trailer once all data has been released.
+func (m *macReader) verify() error {
+	if len(m.pending) < MACSize {
+		return macError(fmt.Sprintf("stream too short for a %d-byte MAC", MACSize))
+	}
+	if !hmac.Equal(m.mac.Sum(nil), m.pending) {
+		return macError("MAC does not match data")
+	}
+	return io.EOF
+}
+
+// macFieldsLabel domain-separates field MACs from HMACs computed over raw data with the same key.
+const macFieldsLabel = "go-crypto/v1/mac-fields"
+
+// MACFields computes an HMAC-SHA256 over a sequence of fields without concatenation ambiguity.
+//
+// The MAC covers a domain label, the number of fields, and each field preceded by its
+// big-endian 32-bit length, so ("ab", "c") and ("a", "bc") produce different MACs, as
+// do field lists differing only by a trailing empty field. Use it to sign request
+// components such as method, path and body, or webhook payloads, without inventing a
+// canonicalization scheme. Field order matters.
+//
+// Parameters:
+//   - key: The MAC key (should be at least 32 random bytes, separate from encryption keys)
+//   - fields: The fields to authenticate, in order (individual fields may be empty)
+//
+// Returns:
+//   - The MACSize-byte MAC, or nil if the key is empty
+//
+// Example:
+//
+//	sig := crypto.MACFields(webhookKey, []byte(r.Method), []byte(r.URL.Path), body)
+//	r.Header.Set("X-Signature", hex.EncodeToString(sig))
+func MACFields(key []byte, fields ...[]byte) []byte {
+	mac := newMAC(key)
+	if mac == nil {
+		return nil
+	}
+	mac.Write([]byte(macFieldsLabel))
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(fields)))
+	mac.Write(length[:])
+	for _, field := range fields {
+		binary.BigEndian.PutUint32(length[:], uint32(len(field)))
+		mac.Write(length[:])
+		mac.Write(field)
+	}
+	return mac.Sum(nil)
+}
+
+// VerifyMACFields reports, in constant time, whether mac is the MACFields MAC of fields under key.
+//
+// Parameters:
+//   - key: The MAC key used to compute mac
+//   - mac: The MAC to check
+//   - fields: The fields, in the order they were authenticated
+//
+// Returns:
+//   - true if the MAC matches; false otherwise, including for an empty key
+//
+// Example:
+//
+//	sig, _ := hex.DecodeString(r.Header.Get("X-Signature"))
+//	if !crypto.VerifyMACFields(webhookKey, sig, []byte(r.Method), []byte(r.URL.Path), body) {
+//		http.Error(w, "bad signature", http.StatusUnauthorized)
+//		return
+//	}
+func VerifyMACFields(key, mac []byte, fields ...[]byte) bool {
+	expected := MACFields(key, fields...)
+	return expected != nil && hmac.Equal(expected, mac)
+}
+
+// pseudonymLabel domain-separates pseudonyms from other HMACs computed with the same secret.
+const pseudonymLabel = "go-crypto/v1/pseudonym"
+
+// PseudonymSize is the number of HMAC bytes kept in a PseudonymizeID identifier (128 bits).
+const PseudonymSize = 16
+
+// pseudonymEncoding is unpadded base32; PseudonymizeID lowercases it for use in URLs and file names.
+var pseudonymEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// PseudonymizeID derives a stable pseudonymous identifier from a value such as an email address.
+//
+// The identifier is HMAC-SHA256(secret, label || value) truncated to PseudonymSize bytes
+// and encoded as 26 characters of lowercase, unpadded base32. It is deterministic under
+// the same secret, so datasets pseudonymized with one secret can still be joined, but it
+// cannot be reversed or linked to the value without the secret, unlike a plain hash,
+// which is trivially reversed by hashing candidate emails. Use a different secret per
+// dataset or recipient to keep their identifiers unlinkable. Normalize values (e.g.
+// lowercase and trim emails) before calling if equivalent spellings should match.
+//
+// Parameters:
+//   - value: The identifying value to pseudonymize
+//   - secret: The pseudonymization secret (should be at least 32 random bytes, kept
+//     separate from the pseudonymized data)
+//
+// Returns:
+//   - The identifier, or an empty string if the secret is empty
+//
+// Example:
+//
+//	email := strings.ToLower(strings.TrimSpace(user.Email))
+//	analytics.Track(crypto.PseudonymizeID([]byte(email), analyticsSecret), "signup")
+func PseudonymizeID(value, secret []byte) string {
+	mac := newMAC(secret)
+	if mac == nil {
+		return ""
+	}
+	mac.Write([]byte(pseudonymLabel))
+	mac.Write(value)
+	return strings.ToLower(pseudonymEncoding.EncodeToString(mac.Sum(nil)[:PseudonymSize]))
+}
+
+// newMAC returns an HMAC-SHA256 instance for key, or nil if the key is empty.
+func newMAC(key []byte) hash.Hash {
+	if len(key) == 0 {
+		return nil
+	}
+	return hmac.New(sha256.New, key)
+}
+
+// macError builds an error wrapping ErrInvalidMAC.
+func macError(msg string) error {
+	richErr := goerrors.New(ErrCodeInvalidMAC, msg)
+	return fmt.Errorf("%w: %w", ErrInvalidMAC, richErr)
+}
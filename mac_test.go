@@ -0,0 +1,198 @@
+// mac_test.go: Test cases for streaming HMAC writers and readers.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/agilira/go-crypto"
+)
+
+func writeMACStream(t *testing.T, key []byte, lines int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := crypto.NewMACWriter(&buf, key)
+	for i := 0; i < lines; i++ {
+		if _, err := fmt.Fprintf(mw, "event %d\n", i); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestMACWriter_Format(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	stream := writeMACStream(t, key, 3)
+	data, trailer := stream[:len(stream)-crypto.MACSize], stream[len(stream)-crypto.MACSize:]
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	if !bytes.Equal(trailer, mac.Sum(nil)) {
+		t.Error("trailer is not HMAC-SHA256 of the data")
+	}
+	if string(data) != "event 0\nevent 1\nevent 2\n" {
+		t.Errorf("data = %q", data)
+	}
+}
+
+func TestMACWriter_Misuse(t *testing.T) {
+	mw := crypto.NewMACWriter(io.Discard, []byte("k"))
+	if err := mw.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if _, err := mw.Write([]byte("late")); err == nil {
+		t.Error("Write() after Close succeeded")
+	}
+	if err := mw.Close(); err == nil {
+		t.Error("second Close() succeeded")
+	}
+	if _, err := crypto.NewMACWriter(io.Discard, nil).Write([]byte("x")); err == nil {
+		t.Error("Write() with empty key succeeded")
+	}
+}
+
+func TestVerifyMACReader_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, lines := range []int{0, 1, 10000} {
+		stream := writeMACStream(t, key, lines)
+		vr, err := crypto.VerifyMACReader(bytes.NewReader(stream), key)
+		if err != nil {
+			t.Fatalf("VerifyMACReader() error: %v", err)
+		}
+		data, err := io.ReadAll(vr)
+		if err != nil {
+			t.Fatalf("%d lines: ReadAll() error: %v", lines, err)
+		}
+		if !bytes.Equal(data, stream[:len(stream)-crypto.MACSize]) {
+			t.Errorf("%d lines: data mismatch", lines)
+		}
+	}
+}
+
+func TestVerifyMACReader_SmallReads(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	stream := writeMACStream(t, key, 50)
+	vr, _ := crypto.VerifyMACReader(iotest.OneByteReader(bytes.NewReader(stream)), key)
+	if err := iotest.TestReader(vr, stream[:len(stream)-crypto.MACSize]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVerifyMACReader_Tampering(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	stream := writeMACStream(t, key, 5)
+
+	cases := map[string][]byte{
+		"flipped data":     append([]byte{stream[0] ^ 1}, stream[1:]...),
+		"flipped trailer":  append(append([]byte(nil), stream[:len(stream)-1]...), stream[len(stream)-1]^1),
+		"truncated":        stream[:len(stream)-1],
+		"appended":         append(append([]byte(nil), stream...), '\n'),
+		"shorter than MAC": stream[:10],
+		"empty":            nil,
+	}
+	for name, data := range cases {
+		vr, _ := crypto.VerifyMACReader(bytes.NewReader(data), key)
+		if _, err := io.ReadAll(vr); !errors.Is(err, crypto.ErrInvalidMAC) {
+			t.Errorf("%s: got %v, want ErrInvalidMAC", name, err)
+		}
+	}
+
+	other, _ := crypto.GenerateKey()
+	vr, _ := crypto.VerifyMACReader(bytes.NewReader(stream), other)
+	if _, err := io.ReadAll(vr); !errors.Is(err, crypto.ErrInvalidMAC) {
+		t.Errorf("wrong key: got %v, want ErrInvalidMAC", err)
+	}
+	if _, err := crypto.VerifyMACReader(bytes.NewReader(stream), nil); err == nil {
+		t.Error("VerifyMACReader() with empty key succeeded")
+	}
+}
+
+func TestMACFields_Unambiguous(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	base := crypto.MACFields(key, []byte("ab"), []byte("c"))
+	if len(base) != crypto.MACSize {
+		t.Fatalf("Expected %d-byte MAC, got %d", crypto.MACSize, len(base))
+	}
+	variants := map[string][][]byte{
+		"shifted boundary": {[]byte("a"), []byte("bc")},
+		"concatenated":     {[]byte("abc")},
+		"trailing empty":   {[]byte("ab"), []byte("c"), {}},
+		"reordered":        {[]byte("c"), []byte("ab")},
+	}
+	for name, fields := range variants {
+		if bytes.Equal(crypto.MACFields(key, fields...), base) {
+			t.Errorf("%s: expected a different MAC", name)
+		}
+	}
+	plain := hmac.New(sha256.New, key)
+	plain.Write([]byte("abc"))
+	if bytes.Equal(plain.Sum(nil), base) {
+		t.Error("Expected field MACs to differ from a plain HMAC")
+	}
+}
+
+func TestVerifyMACFields(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	fields := [][]byte{[]byte("POST"), []byte("/hooks/order"), []byte(`{"id":42}`)}
+	mac := crypto.MACFields(key, fields...)
+
+	if !crypto.VerifyMACFields(key, mac, fields...) {
+		t.Error("Expected MAC to verify")
+	}
+	tampered := append([]byte(nil), mac...)
+	tampered[0] ^= 0x01
+	if crypto.VerifyMACFields(key, tampered, fields...) {
+		t.Error("Expected tampered MAC to be rejected")
+	}
+	if crypto.VerifyMACFields(key, mac, fields[0], fields[1], []byte(`{"id":43}`)) {
+		t.Error("Expected modified field to be rejected")
+	}
+	if crypto.VerifyMACFields(bytes.Repeat([]byte{0x43}, 32), mac, fields...) {
+		t.Error("Expected wrong key to be rejected")
+	}
+	if crypto.MACFields(nil, fields...) != nil || crypto.VerifyMACFields(nil, nil, fields...) {
+		t.Error("Expected an empty key to produce no MAC and never verify")
+	}
+}
+
+func TestPseudonymizeID(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	id := crypto.PseudonymizeID([]byte("alice@example.com"), secret)
+	if len(id) != 26 || strings.ToLower(id) != id || strings.ContainsRune(id, '=') {
+		t.Errorf("Unexpected identifier format %q", id)
+	}
+	if crypto.PseudonymizeID([]byte("alice@example.com"), secret) != id {
+		t.Error("Expected the identifier to be deterministic under the same secret")
+	}
+	if crypto.PseudonymizeID([]byte("bob@example.com"), secret) == id {
+		t.Error("Expected different values to have different identifiers")
+	}
+	if crypto.PseudonymizeID([]byte("alice@example.com"), []byte("another secret")) == id {
+		t.Error("Expected different secrets to produce unlinkable identifiers")
+	}
+	plain := hmac.New(sha256.New, secret)
+	plain.Write([]byte("alice@example.com"))
+	undomained := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(plain.Sum(nil)[:crypto.PseudonymSize])
+	if strings.ToLower(undomained) == id {
+		t.Error("Expected the identifier to be domain-separated from a plain HMAC")
+	}
+	if crypto.PseudonymizeID([]byte("alice@example.com"), nil) != "" {
+		t.Error("Expected an empty identifier for an empty secret")
+	}
+}
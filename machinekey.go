@@ -0,0 +1,157 @@
+// machinekey.go: Keys bound to the identity of the host machine.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"slices"
+	"sync/atomic"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// machineKeyLabel domain-separates machine identifiers from other Argon2id inputs.
+const machineKeyLabel = "go-crypto/v1/machine-key"
+
+// machineIDFiles are the locations of the system machine identifier, in order of preference.
+var machineIDFiles = []string{"/etc/machine-id", "/var/lib/dbus/machine-id", "/etc/hostid"}
+
+// MachineIDSource returns stable identifiers of the current machine, used by
+// DeriveMachineKey. It must return the same bytes on every call on the same machine.
+type MachineIDSource func() ([]byte, error)
+
+// machineIDHolder wraps a MachineIDSource so it can be stored atomically.
+type machineIDHolder struct {
+	source MachineIDSource
+}
+
+// currentMachineIDSource holds the installed source; nil means SystemMachineID (the default).
+var currentMachineIDSource atomic.Pointer[machineIDHolder]
+
+// SetMachineIDSource replaces the identifier source used by DeriveMachineKey, e.g. with
+// HardwareAddrs, a platform-specific source (the IOPlatformUUID on macOS, the
+// MachineGuid registry value on Windows) or a fixed value in tests.
+//
+// Passing nil restores SystemMachineID. SetMachineIDSource is safe to call
+// concurrently with DeriveMachineKey.
+//
+// Example:
+//
+//	crypto.SetMachineIDSource(func() ([]byte, error) { return []byte("test-host"), nil })
+//	defer crypto.SetMachineIDSource(nil)
+func SetMachineIDSource(src MachineIDSource) {
+	if src == nil {
+		currentMachineIDSource.Store(nil)
+		return
+	}
+	currentMachineIDSource.Store(&machineIDHolder{source: src})
+}
+
+// SystemMachineID returns the operating system's machine identifier, read from
+// /etc/machine-id or /var/lib/dbus/machine-id (systemd and D-Bus, on Linux) or
+// /etc/hostid (FreeBSD). It is the default MachineIDSource.
+//
+// Returns:
+//   - The identifier, with surrounding whitespace removed
+//   - An error if none of the files exists or holds an identifier, e.g. on macOS and
+//     Windows; install a suitable source with SetMachineIDSource there
+func SystemMachineID() ([]byte, error) {
+	for _, name := range machineIDFiles {
+		id, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		if id = bytes.TrimSpace(id); len(id) > 0 {
+			return id, nil
+		}
+	}
+	return nil, goerrors.New("MACHINE_ID_ERROR", "no machine identifier found; set one with SetMachineIDSource")
+}
+
+// HardwareAddrs is a MachineIDSource built from the hardware (MAC) addresses of the
+// machine's non-loopback network interfaces, sorted so that the result does not depend
+// on interface order.
+//
+// Hardware addresses are less stable than a machine identifier: adding or removing an
+// adapter (including USB, VPN and container interfaces) or address randomization
+// changes the result, and with it the derived key.
+//
+// Returns:
+//   - The concatenated sorted addresses
+//   - An error if the interfaces cannot be listed or none has a hardware address
+func HardwareAddrs() ([]byte, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, goerrors.Wrap(err, "MACHINE_ID_ERROR", "failed to list network interfaces")
+	}
+	var addrs [][]byte
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 && len(iface.HardwareAddr) > 0 {
+			addrs = append(addrs, iface.HardwareAddr)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, goerrors.New("MACHINE_ID_ERROR", "no network interface with a hardware address")
+	}
+	slices.SortFunc(addrs, bytes.Compare)
+	var id []byte
+	for _, addr := range addrs {
+		id = appendLengthPrefixed(id, addr)
+	}
+	return id, nil
+}
+
+// DeriveMachineKey derives a 32-byte key from the identifiers of the current machine,
+// so that data encrypted with it only decrypts on the same machine.
+//
+// The identifiers come from the installed MachineIDSource (SystemMachineID unless
+// replaced with SetMachineIDSource) and are stretched with Argon2id under salt.
+//
+// This binds data to a machine; it does not make it secret from that machine. Machine
+// identifiers are not secrets: /etc/machine-id is world-readable, and hardware
+// addresses are visible on the local network. Anyone who can run code on the machine,
+// or who learns its identifiers, can derive the key, so a machine key only stops data
+// copied to another machine from being decrypted there. Combine it with a user secret
+// (e.g. by deriving a key from both) where local users must be kept out, and do not
+// rely on it as tamper-proof license enforcement. Identifiers can also change: a
+// reinstall, a restored backup or a cloned virtual machine image yields a new or a
+// shared identifier, and the key changes with it, so keep a recovery path for data
+// that must survive such events.
+//
+// Parameters:
+//   - salt: The application salt (cannot be empty; may be a fixed per-application value)
+//   - params: Custom Argon2id parameters (nil to use secure defaults)
+//
+// Returns:
+//   - The 32-byte machine key
+//   - An error if the identifiers cannot be read or key derivation fails
+//
+// Example:
+//
+//	key, err := crypto.DeriveMachineKey([]byte("myapp/license-cache"), nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer crypto.Zeroize(key)
+func DeriveMachineKey(salt []byte, params *KDFParams) ([]byte, error) {
+	source := MachineIDSource(SystemMachineID)
+	if h := currentMachineIDSource.Load(); h != nil {
+		source = h.source
+	}
+	id, err := source()
+	if err != nil {
+		return nil, goerrors.Wrap(err, "MACHINE_ID_ERROR", "failed to read machine identifiers")
+	}
+	if len(id) == 0 {
+		return nil, goerrors.New("MACHINE_ID_ERROR", "machine identifier source returned no data")
+	}
+	input := append([]byte(machineKeyLabel), id...)
+	defer Zeroize(input)
+	return DeriveKey(input, salt, KeySize, params)
+}
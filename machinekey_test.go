@@ -0,0 +1,72 @@
+// machinekey_test.go: Test cases for machine-bound key derivation.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestDeriveMachineKey(t *testing.T) {
+	params := &crypto.KDFParams{Time: 1, Memory: 8, Threads: 1}
+	salt := []byte("test-app")
+	crypto.SetMachineIDSource(func() ([]byte, error) { return []byte("machine-a"), nil })
+	defer crypto.SetMachineIDSource(nil)
+
+	a1, err := crypto.DeriveMachineKey(salt, params)
+	if err != nil {
+		t.Fatalf("DeriveMachineKey() error: %v", err)
+	}
+	a2, _ := crypto.DeriveMachineKey(salt, params)
+	if len(a1) != crypto.KeySize || !bytes.Equal(a1, a2) {
+		t.Fatal("DeriveMachineKey should be deterministic and return KeySize bytes")
+	}
+	otherSalt, _ := crypto.DeriveMachineKey([]byte("other-app"), params)
+	if bytes.Equal(a1, otherSalt) {
+		t.Error("Different salts should derive different keys")
+	}
+
+	crypto.SetMachineIDSource(func() ([]byte, error) { return []byte("machine-b"), nil })
+	b, _ := crypto.DeriveMachineKey(salt, params)
+	if bytes.Equal(a1, b) {
+		t.Error("Different machines should derive different keys")
+	}
+	if plain, _ := crypto.DeriveKey([]byte("machine-b"), salt, crypto.KeySize, params); bytes.Equal(plain, b) {
+		t.Error("Machine keys should be domain-separated from DeriveKey")
+	}
+}
+
+func TestDeriveMachineKey_Errors(t *testing.T) {
+	defer crypto.SetMachineIDSource(nil)
+	boom := errors.New("boom")
+	crypto.SetMachineIDSource(func() ([]byte, error) { return nil, boom })
+	if _, err := crypto.DeriveMachineKey([]byte("salt"), nil); !errors.Is(err, boom) {
+		t.Errorf("Expected the source error, got %v", err)
+	}
+	crypto.SetMachineIDSource(func() ([]byte, error) { return nil, nil })
+	if _, err := crypto.DeriveMachineKey([]byte("salt"), nil); err == nil {
+		t.Error("Expected an error for an empty identifier")
+	}
+	crypto.SetMachineIDSource(func() ([]byte, error) { return []byte("id"), nil })
+	if _, err := crypto.DeriveMachineKey(nil, nil); err == nil {
+		t.Error("Expected an error for an empty salt")
+	}
+}
+
+func TestSystemMachineID(t *testing.T) {
+	id, err := crypto.SystemMachineID()
+	if err != nil {
+		t.Skipf("No machine identifier on this system: %v", err)
+	}
+	again, _ := crypto.SystemMachineID()
+	if len(id) == 0 || !bytes.Equal(id, again) {
+		t.Error("SystemMachineID should return a stable, non-empty identifier")
+	}
+}
@@ -0,0 +1,250 @@
+// noncegen.go: Nonce generation combining a random prefix with a counter.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// NonceGenerator produces nonces of the form random prefix || big-endian counter.
+//
+// The prefix is drawn once per generator from the nonce source (crypto/rand unless
+// UseBufferedRandom is enabled); the counter starts at zero and is incremented for
+// every nonce. Nonces from one generator therefore never repeat
+// until the counter overflows, which is detected and reported as an error. Distinct
+// generators (e.g. one per process) under the same key only collide if they draw the
+// same prefix.
+//
+// Collision probability: with a prefix of r bytes and n generators sharing a key, the
+// chance that any two draw the same prefix is about n²/2^(8r+1). For a 12-byte GCM nonce
+// with a 6-byte prefix this is about 1.8e-9 for 1000 generators, and each generator can
+// produce 2^48 nonces. By comparison, purely random 96-bit nonces reach a collision
+// probability of 2^-32 after about 2^32 messages, the limit recommended by NIST SP 800-38D.
+//
+// NonceGenerator implements NonceStrategy and is safe for concurrent use.
+type NonceGenerator struct {
+	mu        sync.Mutex
+	prefixLen int
+	prefix    []byte
+	counter   uint64
+	spent     bool
+}
+
+// NewNonceGenerator creates a NonceGenerator whose nonces start with randomPrefixLen random bytes.
+//
+// The prefix is generated on the first call to Next, so errors from crypto/rand or an
+// invalid prefix length are reported there.
+//
+// Parameters:
+//   - randomPrefixLen: The number of random bytes at the start of each nonce
+//
+// Returns:
+//   - A new NonceGenerator
+//
+// Example:
+//
+//	gen := crypto.NewNonceGenerator(6)
+//	c, err := crypto.NewCipherWithNonceStrategy(key, gen)
+func NewNonceGenerator(randomPrefixLen int) *NonceGenerator {
+	return &NonceGenerator{prefixLen: randomPrefixLen}
+}
+
+// Next returns a fresh nonce of total bytes: the random prefix followed by the counter
+// encoded big-endian in the remaining total-randomPrefixLen bytes.
+//
+// Parameters:
+//   - total: The nonce size (e.g. 12 for AES-GCM); must exceed the prefix length
+//
+// Returns:
+//   - The nonce
+//   - An error wrapping ErrNonceGen if the sizes are invalid, the prefix cannot be
+//     generated, or the counter space is exhausted
+//
+// Example:
+//
+//	nonce, err := gen.Next(12)
+func (g *NonceGenerator) Next(total int) ([]byte, error) {
+	width := total - g.prefixLen
+	if g.prefixLen < 0 || width <= 0 {
+		return nil, nonceGenError(fmt.Sprintf("invalid nonce layout: %d-byte prefix in a %d-byte nonce", g.prefixLen, total))
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.prefix == nil {
+		prefix := make([]byte, g.prefixLen)
+		if _, err := io.ReadFull(nonceReader(), prefix); err != nil {
+			richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate nonce prefix")
+			return nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+		}
+		g.prefix = prefix
+	}
+
+	limit := uint64(math.MaxUint64)
+	if width < 8 {
+		limit = 1<<(8*uint(width)) - 1
+	}
+	if g.spent || g.counter > limit {
+		return nil, nonceGenError(fmt.Sprintf("nonce counter exhausted for a %d-byte counter", width))
+	}
+
+	nonce := make([]byte, total)
+	copy(nonce, g.prefix)
+	for i, c := total-1, g.counter; i >= g.prefixLen && c > 0; i, c = i-1, c>>8 {
+		nonce[i] = byte(c)
+	}
+	g.counter++
+	g.spent = g.counter == 0
+	return nonce, nil
+}
+
+// nonceGenError builds an error wrapping ErrNonceGen.
+func nonceGenError(msg string) error {
+	richErr := goerrors.New(ErrCodeNonceGen, msg)
+	return fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+}
+
+// maxNoncePoolCapacity bounds the number of nonces a NoncePool keeps ready.
+const maxNoncePoolCapacity = 1 << 16
+
+// NoncePool dispenses random nonces pre-generated by a background goroutine.
+//
+// Under bursty load, drawing every nonce from crypto/rand adds a system call, and its
+// latency, to each encryption. A NoncePool keeps up to capacity nonces ready, generated
+// in batches from the nonce source (crypto/rand unless UseBufferedRandom is enabled),
+// and refills as they are consumed, so Get usually returns without touching the random
+// source. Every nonce is handed out exactly once and then forgotten by the pool, so
+// nonces are never reused; they are as random as those of RandomNonces, with the same
+// limit of about 2^32 messages per key.
+//
+// NoncePool implements NonceStrategy, so a Cipher can draw from it with
+// NewCipherWithNonceStrategy, and is safe for concurrent use. Close stops the
+// background goroutine.
+type NoncePool struct {
+	size    int
+	nonces  chan []byte
+	done    chan struct{}
+	closing sync.Once
+	err     error // set by the filler before it closes nonces
+}
+
+// NewNoncePool starts a pool of random nonces of nonceSize bytes, keeping up to
+// capacity of them ready.
+//
+// Parameters:
+//   - nonceSize: The size of each nonce (e.g. 12 for AES-GCM; between 1 and 64)
+//   - capacity: The number of nonces kept ready (between 1 and 65536)
+//
+// Returns:
+//   - A running NoncePool; call Close when it is no longer needed
+//   - An error wrapping ErrNonceGen if a size is out of range
+//
+// Example:
+//
+//	pool, err := crypto.NewNoncePool(12, 4096)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer pool.Close()
+//	c, err := crypto.NewCipherWithNonceStrategy(key, pool)
+func NewNoncePool(nonceSize, capacity int) (*NoncePool, error) {
+	if nonceSize < 1 || nonceSize > 64 {
+		return nil, nonceGenError(fmt.Sprintf("nonce size must be between 1 and 64 (got %d)", nonceSize))
+	}
+	if capacity < 1 || capacity > maxNoncePoolCapacity {
+		return nil, nonceGenError(fmt.Sprintf("nonce pool capacity must be between 1 and %d (got %d)", maxNoncePoolCapacity, capacity))
+	}
+	p := &NoncePool{
+		size:   nonceSize,
+		nonces: make(chan []byte, capacity),
+		done:   make(chan struct{}),
+	}
+	go p.fill(capacity)
+	return p, nil
+}
+
+// fill generates nonces in batches of up to batch until the pool is closed or the
+// random source fails.
+func (p *NoncePool) fill(batch int) {
+	for {
+		buf := make([]byte, p.size*batch)
+		if _, err := io.ReadFull(nonceReader(), buf); err != nil {
+			p.err = err
+			close(p.nonces)
+			return
+		}
+		for len(buf) > 0 {
+			select {
+			case p.nonces <- buf[:p.size:p.size]:
+				buf = buf[p.size:]
+			case <-p.done:
+				return
+			}
+		}
+	}
+}
+
+// Get returns the next nonce, waiting for the background goroutine if the pool is
+// momentarily empty.
+//
+// Returns:
+//   - A fresh nonce of the pool's size, owned by the caller
+//   - nil if the pool is closed or the random source failed (Next reports the error)
+//
+// Example:
+//
+//	nonce := pool.Get()
+func (p *NoncePool) Get() []byte {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+	select {
+	case nonce := <-p.nonces:
+		return nonce
+	case <-p.done:
+		return nil
+	}
+}
+
+// Next implements NonceStrategy, returning a nonce from the pool.
+//
+// Parameters:
+//   - size: The nonce size; must equal the pool's nonce size
+//
+// Returns:
+//   - The nonce
+//   - An error wrapping ErrNonceGen if size does not match, the pool is closed, or the
+//     random source failed
+func (p *NoncePool) Next(size int) ([]byte, error) {
+	if size != p.size {
+		return nil, nonceGenError(fmt.Sprintf("nonce pool produces %d-byte nonces, not %d", p.size, size))
+	}
+	if nonce := p.Get(); nonce != nil {
+		return nonce, nil
+	}
+	select {
+	case <-p.done:
+		return nil, nonceGenError("nonce pool is closed")
+	default:
+	}
+	// The filler stored err before closing nonces, which Get observed.
+	richErr := goerrors.Wrap(p.err, ErrCodeNonceGen, "nonce pool random source failed")
+	return nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+}
+
+// Close stops the background goroutine. Nonces still in the pool are discarded, and
+// later calls to Get return nil. Close is idempotent.
+func (p *NoncePool) Close() {
+	p.closing.Do(func() { close(p.done) })
+}
@@ -0,0 +1,158 @@
+// noncegen_test.go: Test cases for the random-prefix counter nonce generator.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestNonceGenerator_Next(t *testing.T) {
+	gen := crypto.NewNonceGenerator(6)
+	first, err := gen.Next(12)
+	if err != nil {
+		t.Fatalf("Next() error: %v", err)
+	}
+	if !bytes.Equal(first[6:], make([]byte, 6)) {
+		t.Errorf("Expected counter to start at zero, got %x", first)
+	}
+	second, _ := gen.Next(12)
+	if !bytes.Equal(first[:6], second[:6]) {
+		t.Error("Expected the prefix to stay constant")
+	}
+	if !bytes.Equal(second[6:], []byte{0, 0, 0, 0, 0, 1}) {
+		t.Errorf("Expected counter 1, got %x", second[6:])
+	}
+
+	other, _ := crypto.NewNonceGenerator(6).Next(12)
+	if bytes.Equal(first[:6], other[:6]) {
+		t.Error("Expected different generators to draw different prefixes")
+	}
+
+	key, _ := crypto.GenerateKey()
+	c, _ := crypto.NewCipherWithNonceStrategy(key, gen)
+	ciphertext, err := c.Encrypt([]byte("x"))
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if plaintext, err := crypto.DecryptBytes(ciphertext, key); err != nil || string(plaintext) != "x" {
+		t.Errorf("Expected round trip with NonceGenerator, got %q err=%v", plaintext, err)
+	}
+}
+
+func TestNonceGenerator_Overflow(t *testing.T) {
+	gen := crypto.NewNonceGenerator(11)
+	seen := make(map[string]bool)
+	for i := 0; i < 256; i++ {
+		nonce, err := gen.Next(12)
+		if err != nil {
+			t.Fatalf("Next() error at %d: %v", i, err)
+		}
+		seen[string(nonce)] = true
+	}
+	if len(seen) != 256 {
+		t.Errorf("Expected 256 unique nonces, got %d", len(seen))
+	}
+	if _, err := gen.Next(12); !errors.Is(err, crypto.ErrNonceGen) {
+		t.Errorf("Expected ErrNonceGen on counter overflow, got %v", err)
+	}
+}
+
+func TestNonceGenerator_Errors(t *testing.T) {
+	if _, err := crypto.NewNonceGenerator(12).Next(12); !errors.Is(err, crypto.ErrNonceGen) {
+		t.Errorf("Expected ErrNonceGen when the prefix fills the nonce, got %v", err)
+	}
+	if _, err := crypto.NewNonceGenerator(-1).Next(12); !errors.Is(err, crypto.ErrNonceGen) {
+		t.Errorf("Expected ErrNonceGen for negative prefix, got %v", err)
+	}
+
+	originalReader := rand.Reader
+	defer func() { rand.Reader = originalReader }()
+	rand.Reader = &failingReader{}
+	if _, err := crypto.NewNonceGenerator(4).Next(12); !errors.Is(err, crypto.ErrNonceGen) {
+		t.Errorf("Expected ErrNonceGen when the prefix cannot be generated, got %v", err)
+	}
+}
+
+func TestNoncePool_Unique(t *testing.T) {
+	pool, err := crypto.NewNoncePool(12, 64)
+	if err != nil {
+		t.Fatalf("NewNoncePool() error: %v", err)
+	}
+	defer pool.Close()
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				nonce := pool.Get()
+				if len(nonce) != 12 {
+					t.Errorf("Get() returned %d bytes, want 12", len(nonce))
+					return
+				}
+				mu.Lock()
+				if seen[string(nonce)] {
+					t.Errorf("Nonce %x dispensed twice", nonce)
+				}
+				seen[string(nonce)] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNoncePool_Cipher(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pool, err := crypto.NewNoncePool(12, 16)
+	if err != nil {
+		t.Fatalf("NewNoncePool() error: %v", err)
+	}
+	defer pool.Close()
+	c, err := crypto.NewCipherWithNonceStrategy(key, pool)
+	if err != nil {
+		t.Fatalf("NewCipherWithNonceStrategy() error: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		ciphertext, err := c.Encrypt([]byte("pooled"))
+		if err != nil {
+			t.Fatalf("Encrypt() error: %v", err)
+		}
+		if plaintext, err := crypto.DecryptBytes(ciphertext, key); err != nil || string(plaintext) != "pooled" {
+			t.Fatalf("DecryptBytes() = %q, %v", plaintext, err)
+		}
+	}
+}
+
+func TestNoncePool_Errors(t *testing.T) {
+	for _, sizes := range [][2]int{{0, 1}, {65, 1}, {12, 0}, {12, 1<<16 + 1}} {
+		if _, err := crypto.NewNoncePool(sizes[0], sizes[1]); !errors.Is(err, crypto.ErrNonceGen) {
+			t.Errorf("NewNoncePool(%d, %d): expected ErrNonceGen, got %v", sizes[0], sizes[1], err)
+		}
+	}
+	pool, _ := crypto.NewNoncePool(12, 4)
+	if _, err := pool.Next(24); !errors.Is(err, crypto.ErrNonceGen) {
+		t.Errorf("Expected ErrNonceGen for a size mismatch, got %v", err)
+	}
+	pool.Close()
+	pool.Close()
+	if nonce := pool.Get(); nonce != nil {
+		t.Errorf("Get() after Close returned %x", nonce)
+	}
+	if _, err := pool.Next(12); !errors.Is(err, crypto.ErrNonceGen) {
+		t.Errorf("Expected ErrNonceGen after Close, got %v", err)
+	}
+}
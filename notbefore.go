@@ -0,0 +1,141 @@
+// notbefore.go: Ciphertexts that a trusted decryptor opens only after a given time.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// notBeforeHeaderSize is the size of the not-before header: Unix milliseconds as a big-endian int64.
+const notBeforeHeaderSize = 8
+
+// notBeforeLabel domain-separates sealed-until envelopes from other authenticated headers.
+const notBeforeLabel = "go-crypto/v1/not-before"
+
+// notBeforeWindow is the width of the time windows that SealUntil derives subkeys for.
+const notBeforeWindow = time.Hour
+
+// ErrNotYetValid is returned when an authentic ciphertext is opened before its not-before time.
+var ErrNotYetValid = errors.New("crypto: ciphertext not yet valid")
+
+// ErrCodeNotYetValid is the error code for ciphertexts opened too early.
+const ErrCodeNotYetValid = "CRYPTO_NOT_YET_VALID"
+
+// SealUntil encrypts plaintext so that OpenSealed refuses it before notBefore.
+//
+// The plaintext is encrypted under a subkey derived from masterKey with HKDF-SHA256 for
+// the hour-long time window containing notBefore, and notBefore itself is stored in
+// clear, with millisecond precision, as authenticated metadata that cannot be moved
+// earlier without the key. This gives "decryptable only after T" semantics for
+// scheduled reveals and staged rollouts within a single trusted system.
+//
+// This is enforcement by a trusted decryptor, not a cryptographic time-lock: anyone
+// holding masterKey can derive the window subkey and decrypt at any time, and the
+// check relies on the decrypting machine's clock. Keep masterKey on the systems that
+// are trusted to honour the schedule.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - masterKey: The 32-byte master key (must be exactly KeySize bytes)
+//   - notBefore: The earliest time at which OpenSealed returns the plaintext
+//
+// Returns:
+//   - A base64-encoded string containing the not-before time, nonce, ciphertext and tag
+//   - An error if encryption fails
+//
+// Example:
+//
+//	sealed, err := crypto.SealUntil(announcement, masterKey, launch)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func SealUntil(plaintext, masterKey []byte, notBefore time.Time) (string, error) {
+	if err := checkKey(masterKey); err != nil {
+		return "", err
+	}
+	var header [notBeforeHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:], uint64(notBefore.UnixMilli()))
+	subkey, err := notBeforeKey(masterKey, notBefore)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(subkey)
+
+	out, err := sealWithHeader(subkey, notBeforeLabel, header[:], plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// OpenSealed decrypts a ciphertext produced by SealUntil once its not-before time has passed.
+//
+// Authenticity is verified first: a tampered or foreign ciphertext fails with ErrDecrypt
+// whatever its not-before time, so ErrNotYetValid always refers to a genuine ciphertext.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - masterKey: The 32-byte master key used for sealing
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error wrapping ErrNotYetValid if the not-before time has not been reached, or
+//     another error if decryption fails
+//
+// Example:
+//
+//	announcement, err := crypto.OpenSealed(sealed, masterKey)
+//	if errors.Is(err, crypto.ErrNotYetValid) {
+//		return // not launched yet
+//	}
+func OpenSealed(encryptedText string, masterKey []byte) ([]byte, error) {
+	if err := checkKey(masterKey); err != nil {
+		return nil, err
+	}
+	data, err := decodeCiphertext(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < notBeforeHeaderSize {
+		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
+		return nil, inputError(ErrCiphertextShort, richErr)
+	}
+	notBefore := time.UnixMilli(int64(binary.BigEndian.Uint64(data[:notBeforeHeaderSize])))
+	subkey, err := notBeforeKey(masterKey, notBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(subkey)
+
+	_, plaintext, err := openWithHeader(subkey, notBeforeLabel, data, notBeforeHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().Before(notBefore) {
+		Zeroize(plaintext)
+		richErr := goerrors.New(ErrCodeNotYetValid, fmt.Sprintf("ciphertext not valid before %s", notBefore.UTC().Format(time.RFC3339)))
+		return nil, fmt.Errorf("%w: %w", ErrNotYetValid, richErr)
+	}
+	return plaintext, nil
+}
+
+// notBeforeKey derives the subkey of the time window containing notBefore.
+func notBeforeKey(masterKey []byte, notBefore time.Time) ([]byte, error) {
+	var window [8]byte
+	binary.BigEndian.PutUint64(window[:], uint64(notBefore.UnixMilli()/notBeforeWindow.Milliseconds()))
+	subkey, err := deriveSubkey(masterKey, window[:], notBeforeLabel, KeySize)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to derive time-window key")
+	}
+	return subkey, nil
+}
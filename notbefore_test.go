@@ -0,0 +1,69 @@
+// notbefore_test.go: Test cases for ciphertexts sealed until a given time.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestSealUntil_Past(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sealed, err := crypto.SealUntil([]byte("release notes"), key, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("SealUntil() error: %v", err)
+	}
+	plaintext, err := crypto.OpenSealed(sealed, key)
+	if err != nil || string(plaintext) != "release notes" {
+		t.Fatalf("OpenSealed() = %q, %v", plaintext, err)
+	}
+}
+
+func TestOpenSealed_NotYetValid(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sealed, _ := crypto.SealUntil([]byte("release notes"), key, time.Now().Add(time.Hour))
+	if _, err := crypto.OpenSealed(sealed, key); !errors.Is(err, crypto.ErrNotYetValid) {
+		t.Errorf("future seal: got %v, want ErrNotYetValid", err)
+	}
+	// Authenticity is checked before the time.
+	other, _ := crypto.GenerateKey()
+	if _, err := crypto.OpenSealed(sealed, other); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("future seal, wrong key: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestOpenSealed_EarlierTimeRejected(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sealed, _ := crypto.SealUntil([]byte("release notes"), key, time.Now().Add(time.Hour))
+	raw, _ := base64.StdEncoding.DecodeString(sealed)
+	raw[7] ^= 0x01 // move the not-before time by a millisecond
+	if _, err := crypto.OpenSealed(base64.StdEncoding.EncodeToString(raw), key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("tampered time: got %v, want ErrDecrypt", err)
+	}
+	raw[0] = 0 // move it to 1970
+	if _, err := crypto.OpenSealed(base64.StdEncoding.EncodeToString(raw), key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("tampered time: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestSealUntil_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	if _, err := crypto.SealUntil([]byte("x"), make([]byte, 16), time.Now()); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+	if _, err := crypto.OpenSealed("AAAA", key); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("short ciphertext: got %v, want ErrCiphertextShort", err)
+	}
+	expiring, _ := crypto.EncryptWithExpiry([]byte("x"), key, time.Minute)
+	if _, err := crypto.OpenSealed(expiring, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("OpenSealed() of expiring envelope: got %v, want ErrDecrypt", err)
+	}
+}
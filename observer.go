@@ -0,0 +1,89 @@
+// observer.go: Optional instrumentation hooks for cryptographic operations.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Observer receives notifications about completed encryption and decryption operations.
+//
+// Implementations can be used to export metrics (call counters, failure counters,
+// latency histograms) or to log anomalies such as a sudden spike in decryption
+// failures, which may indicate tampering. Observers are invoked synchronously on
+// the calling goroutine and must be safe for concurrent use; slow observers slow
+// down every cryptographic call.
+//
+// Example:
+//
+//	type metrics struct{ encrypts, decryptFailures atomic.Int64 }
+//
+//	func (m *metrics) ObserveEncrypt(d time.Duration, err error) { m.encrypts.Add(1) }
+//	func (m *metrics) ObserveDecrypt(d time.Duration, err error) {
+//		if err != nil {
+//			m.decryptFailures.Add(1)
+//		}
+//	}
+//
+//	crypto.SetObserver(&metrics{})
+type Observer interface {
+	// ObserveEncrypt is called after each encryption with its duration and result.
+	ObserveEncrypt(dur time.Duration, err error)
+
+	// ObserveDecrypt is called after each decryption with its duration and result.
+	ObserveDecrypt(dur time.Duration, err error)
+}
+
+// observerHolder wraps the Observer interface so it can be stored atomically.
+type observerHolder struct {
+	observer Observer
+}
+
+// currentObserver holds the installed observer; nil means no observer (the default).
+var currentObserver atomic.Pointer[observerHolder]
+
+// SetObserver installs an Observer that is notified by EncryptBytes and DecryptBytes
+// (and therefore by Encrypt and Decrypt).
+//
+// Passing nil removes the current observer, restoring the default no-op behavior.
+// When no observer is installed the instrumentation adds no measurable overhead:
+// timestamps are not even taken. SetObserver is safe to call concurrently with
+// cryptographic operations.
+//
+// Example:
+//
+//	crypto.SetObserver(myPrometheusObserver)
+//	defer crypto.SetObserver(nil)
+func SetObserver(o Observer) {
+	if o == nil {
+		currentObserver.Store(nil)
+		return
+	}
+	currentObserver.Store(&observerHolder{observer: o})
+}
+
+// loadObserver returns the installed observer or nil.
+func loadObserver() Observer {
+	if h := currentObserver.Load(); h != nil {
+		return h.observer
+	}
+	return nil
+}
+
+// observeDecrypt reports a decryption started at start to obs, wiping plaintext if the
+// observer panics, since the plaintext then never reaches the caller.
+func observeDecrypt(obs Observer, start time.Time, plaintext []byte, err error) {
+	observed := false
+	defer func() {
+		if !observed {
+			Zeroize(plaintext)
+		}
+	}()
+	obs.ObserveDecrypt(time.Since(start), err)
+	observed = true
+}
@@ -0,0 +1,112 @@
+// observer_test.go: Test cases for operation observers.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+type recordingObserver struct {
+	mu              sync.Mutex
+	encrypts        int
+	encryptFailures int
+	decrypts        int
+	decryptFailures int
+}
+
+func (r *recordingObserver) ObserveEncrypt(dur time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.encrypts++
+	if err != nil {
+		r.encryptFailures++
+	}
+}
+
+func (r *recordingObserver) ObserveDecrypt(dur time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decrypts++
+	if err != nil {
+		r.decryptFailures++
+	}
+}
+
+func TestSetObserver_RecordsOperations(t *testing.T) {
+	obs := &recordingObserver{}
+	crypto.SetObserver(obs)
+	defer crypto.SetObserver(nil)
+
+	key, _ := crypto.GenerateKey()
+	ciphertext, err := crypto.Encrypt("observed", key)
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if _, err := crypto.Decrypt(ciphertext, key); err != nil {
+		t.Fatalf("Decrypt() error: %v", err)
+	}
+	_, _ = crypto.EncryptBytes([]byte("data"), make([]byte, 8))
+	wrongKey, _ := crypto.GenerateKey()
+	_, _ = crypto.DecryptBytes(ciphertext, wrongKey)
+
+	if obs.encrypts != 2 || obs.encryptFailures != 1 {
+		t.Errorf("Expected 2 encrypts with 1 failure, got %d with %d failures", obs.encrypts, obs.encryptFailures)
+	}
+	if obs.decrypts != 2 || obs.decryptFailures != 1 {
+		t.Errorf("Expected 2 decrypts with 1 failure, got %d with %d failures", obs.decrypts, obs.decryptFailures)
+	}
+}
+
+func TestSetObserver_RecordsFixedOperations(t *testing.T) {
+	obs := &recordingObserver{}
+	crypto.SetObserver(obs)
+	defer crypto.SetObserver(nil)
+
+	key, _ := crypto.GenerateKey()
+	c, err := crypto.NewCipher(key)
+	if err != nil {
+		t.Fatalf("NewCipher() error: %v", err)
+	}
+	sealed, err := c.EncryptFixed(nil, []byte("observed"))
+	if err != nil {
+		t.Fatalf("EncryptFixed() error: %v", err)
+	}
+	prefix := []byte("prefix:")
+	out, err := c.DecryptFixed(prefix, sealed)
+	if err != nil || !bytes.Equal(out, []byte("prefix:observed")) {
+		t.Fatalf("DecryptFixed() = %q, %v", out, err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	_, _ = c.DecryptFixed(nil, sealed)
+	_, _ = c.DecryptFixed(nil, sealed[:4])
+
+	if obs.encrypts != 1 || obs.encryptFailures != 0 {
+		t.Errorf("Expected 1 encrypt with no failures, got %d with %d failures", obs.encrypts, obs.encryptFailures)
+	}
+	if obs.decrypts != 3 || obs.decryptFailures != 2 {
+		t.Errorf("Expected 3 decrypts with 2 failures, got %d with %d failures", obs.decrypts, obs.decryptFailures)
+	}
+}
+
+func TestSetObserver_NilRestoresNoop(t *testing.T) {
+	obs := &recordingObserver{}
+	crypto.SetObserver(obs)
+	crypto.SetObserver(nil)
+
+	key, _ := crypto.GenerateKey()
+	if _, err := crypto.Encrypt("not observed", key); err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if obs.encrypts != 0 {
+		t.Errorf("Expected no observations after SetObserver(nil), got %d", obs.encrypts)
+	}
+}
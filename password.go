@@ -0,0 +1,510 @@
+// password.go: Password hashing in the PHC string format using Argon2id.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	goerrors "github.com/agilira/go-errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// Password hashing parameters.
+const (
+	// PasswordSaltSize is the size in bytes of the random salt generated by HashPassword.
+	PasswordSaltSize = 16
+
+	// PasswordHashSize is the size in bytes of the Argon2id output stored by HashPassword.
+	PasswordHashSize = 32
+
+	// maxPHCMemoryKiB bounds the memory parameter accepted when parsing a hash (4 GiB),
+	// so a crafted hash string cannot make verification allocate unbounded memory.
+	maxPHCMemoryKiB = 4 * 1024 * 1024
+
+	// maxPHCTime bounds the iteration count accepted when parsing a hash.
+	maxPHCTime = 1024
+)
+
+// phcHash is the parsed form of an Argon2id PHC string.
+type phcHash struct {
+	time      uint32
+	memoryKiB uint32
+	threads   uint8
+	version   int // parameter policy version; 0 when absent
+	salt      []byte
+	hash      []byte
+}
+
+// HashPassword hashes a password with Argon2id and returns it in the PHC string format.
+//
+// A random PasswordSaltSize-byte salt is generated for every call, and the parameters are
+// embedded in the output so that VerifyPassword does not need them:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+//
+// The salt and hash are encoded with unpadded standard base64, as produced by the
+// reference implementation, so hashes are interchangeable with other Argon2 libraries.
+//
+// Parameters:
+//   - password: The password to hash (cannot be empty)
+//   - params: Custom Argon2id parameters (nil to use secure defaults)
+//
+// Returns:
+//   - The encoded hash string, suitable for storage
+//   - An error if hashing fails
+//
+// Example:
+//
+//	encoded, err := crypto.HashPassword([]byte("correct horse battery staple"), nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	// store encoded in the user record
+func HashPassword(password []byte, params *KDFParams) (string, error) {
+	time, memoryKiB, threads := params.resolve()
+	return hashPasswordPHC(password, time, memoryKiB, threads, 0)
+}
+
+// VerifyPassword checks a password against a hash produced by HashPassword.
+//
+// The parameters and salt are read from the encoded hash and the comparison is
+// performed in constant time. The hash is parsed leniently (see PHCLenient), so hashes
+// produced by other Argon2 tools verify even if their encoding is not canonical.
+//
+// Parameters:
+//   - password: The password to check
+//   - encoded: The PHC string returned by HashPassword
+//
+// Returns:
+//   - true if the password matches, false otherwise
+//   - An error if the encoded hash is malformed
+//
+// Example:
+//
+//	ok, err := crypto.VerifyPassword([]byte(input), user.PasswordHash)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if !ok {
+//		// reject login
+//	}
+func VerifyPassword(password []byte, encoded string) (bool, error) {
+	return VerifyPasswordWithMode(password, encoded, PHCLenient)
+}
+
+// PHCMode selects how strictly VerifyPasswordWithMode parses a PHC string.
+type PHCMode int
+
+const (
+	// PHCLenient accepts the encoding variations produced by other Argon2 tools:
+	// surrounding whitespace, spaces around the parameters, and padded as well as
+	// unpadded standard base64 in the salt and hash fields. It is the mode used by
+	// VerifyPassword and the other functions that read hashes.
+	PHCLenient PHCMode = iota
+
+	// PHCStrict accepts only the canonical encoding produced by HashPassword, i.e. by
+	// the reference implementation: no whitespace and unpadded base64.
+	PHCStrict
+)
+
+// VerifyPasswordWithMode checks a password against an Argon2id PHC string, parsing it
+// according to mode.
+//
+// Parameters may appear in any order in both modes. Use PHCStrict where hashes are
+// expected to be canonical and a deviation should be reported, e.g. when validating
+// hashes before import.
+//
+// Parameters:
+//   - password: The password to check
+//   - encoded: The PHC string
+//   - mode: PHCLenient or PHCStrict
+//
+// Returns:
+//   - true if the password matches, false otherwise
+//   - An error if the encoded hash is malformed under mode
+//
+// Example:
+//
+//	ok, err := crypto.VerifyPasswordWithMode([]byte(input), imported, crypto.PHCStrict)
+func VerifyPasswordWithMode(password []byte, encoded string, mode PHCMode) (bool, error) {
+	h, err := parsePHCMode(encoded, mode == PHCStrict)
+	if err != nil {
+		return false, err
+	}
+	defer h.zeroize()
+	return h.verify(password), nil
+}
+
+// NeedsRehash reports whether an encoded hash was produced with parameters other
+// than params (nil meaning the secure defaults).
+//
+// Call it after a successful VerifyPassword, while the plaintext password is still
+// available, and store a fresh hash when it returns true. Malformed hashes always
+// need rehashing.
+//
+// Example:
+//
+//	if ok && crypto.NeedsRehash(user.PasswordHash, currentParams) {
+//		user.PasswordHash, _ = crypto.HashPassword([]byte(input), currentParams)
+//	}
+func NeedsRehash(encoded string, params *KDFParams) bool {
+	h, err := parsePHC(encoded)
+	if err != nil {
+		return true
+	}
+	time, memoryKiB, threads := params.resolve()
+	return h.time != time || h.memoryKiB != memoryKiB || h.threads != threads || len(h.hash) != PasswordHashSize
+}
+
+// PasswordHasher hashes and verifies passwords with a fixed set of Argon2id parameters.
+//
+// It centralizes the password policy of an application: configure one hasher at
+// startup and use it on every login and registration instead of passing parameters to
+// each call. Hashes use the same PHC format as HashPassword. The parameters are copied
+// at construction and never change, so a PasswordHasher is safe for concurrent use.
+//
+// Example:
+//
+//	hasher := crypto.NewPasswordHasher(&crypto.KDFParams{Time: 3, Memory: 64, Threads: 4})
+//	encoded, err := hasher.Hash([]byte(password))
+type PasswordHasher struct {
+	time      uint32
+	memoryKiB uint32
+	threads   uint8
+}
+
+// NewPasswordHasher returns a hasher using params, with zero fields (or a nil params)
+// resolving to the package defaults.
+func NewPasswordHasher(params *KDFParams) *PasswordHasher {
+	time, memoryKiB, threads := params.resolve()
+	return &PasswordHasher{time: time, memoryKiB: memoryKiB, threads: threads}
+}
+
+// Hash hashes a password with a fresh random salt and returns it in the PHC string format.
+//
+// Example:
+//
+//	user.PasswordHash, err = hasher.Hash([]byte(password))
+func (ph *PasswordHasher) Hash(password []byte) (string, error) {
+	return hashPasswordPHC(password, ph.time, ph.memoryKiB, ph.threads, 0)
+}
+
+// Verify checks a password against a PHC hash in constant time.
+//
+// Verification uses the parameters stored in the hash, so hashes created with other
+// parameters still verify; use NeedsRehash to detect and upgrade them.
+//
+// Example:
+//
+//	ok, err := hasher.Verify([]byte(input), user.PasswordHash)
+func (ph *PasswordHasher) Verify(password []byte, encoded string) (bool, error) {
+	return VerifyPassword(password, encoded)
+}
+
+// NeedsRehash reports whether an encoded hash was produced with parameters other than
+// the hasher's. Malformed hashes always need rehashing.
+//
+// Example:
+//
+//	if ok && hasher.NeedsRehash(user.PasswordHash) {
+//		user.PasswordHash, _ = hasher.Hash([]byte(input))
+//	}
+func (ph *PasswordHasher) NeedsRehash(encoded string) bool {
+	h, err := parsePHC(encoded)
+	if err != nil {
+		return true
+	}
+	return h.time != ph.time || h.memoryKiB != ph.memoryKiB || h.threads != ph.threads || len(h.hash) != PasswordHashSize
+}
+
+// ParamPolicy maps parameter versions to Argon2id parameter sets.
+//
+// Versioned password hashes record the policy version they were produced with, so
+// strengthening parameters organization-wide is a matter of registering a new version
+// and making it current: NeedsRehashVersioned then flags every hash created under an
+// older version. A ParamPolicy is safe for concurrent use.
+//
+// Example:
+//
+//	policy := crypto.NewParamPolicy()
+//	_ = policy.Register(2, crypto.KDFParams{Time: 4, Memory: 128, Threads: 4})
+//	_ = policy.SetCurrent(2)
+//	crypto.SetParamPolicy(policy)
+type ParamPolicy struct {
+	mu       sync.RWMutex
+	versions map[int]KDFParams
+	current  int
+}
+
+// NewParamPolicy returns a policy whose version 1 (the current version) holds the
+// package defaults.
+func NewParamPolicy() *ParamPolicy {
+	return &ParamPolicy{
+		versions: map[int]KDFParams{1: {Time: DefaultTime, Memory: DefaultMemory, Threads: DefaultThreads}},
+		current:  1,
+	}
+}
+
+// Register associates a parameter set with a version. Versions must be positive;
+// registering an existing version replaces its parameters.
+func (p *ParamPolicy) Register(version int, params KDFParams) error {
+	if version <= 0 {
+		return goerrors.New("INVALID_POLICY_VERSION", "policy version must be positive")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.versions[version] = params
+	return nil
+}
+
+// SetCurrent selects the version used for new hashes. The version must be registered.
+func (p *ParamPolicy) SetCurrent(version int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.versions[version]; !ok {
+		return goerrors.New("UNKNOWN_POLICY_VERSION", fmt.Sprintf("policy version %d is not registered", version))
+	}
+	p.current = version
+	return nil
+}
+
+// Current returns the version used for new hashes.
+func (p *ParamPolicy) Current() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current
+}
+
+// Params returns the parameters registered for version.
+func (p *ParamPolicy) Params(version int) (KDFParams, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	params, ok := p.versions[version]
+	return params, ok
+}
+
+// currentPolicy holds the policy used by the package-level versioned functions.
+var currentPolicy atomic.Pointer[ParamPolicy]
+
+func init() {
+	currentPolicy.Store(NewParamPolicy())
+}
+
+// SetParamPolicy installs the policy used by HashPasswordVersioned,
+// VerifyPasswordVersioned and NeedsRehashVersioned. Passing nil restores the
+// default policy (version 1 with the package defaults).
+func SetParamPolicy(p *ParamPolicy) {
+	if p == nil {
+		p = NewParamPolicy()
+	}
+	currentPolicy.Store(p)
+}
+
+// HashPasswordVersioned hashes a password with the parameters registered for version
+// in the installed ParamPolicy, recording the version in the hash.
+//
+// The output is a PHC string with an additional pv (policy version) parameter:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4,pv=1$<salt>$<hash>
+//
+// Other Argon2 implementations do not understand the pv parameter; use HashPassword
+// when hashes must be portable.
+//
+// Example:
+//
+//	encoded, err := crypto.HashPasswordVersioned(password, policy.Current())
+func HashPasswordVersioned(password []byte, version int) (string, error) {
+	params, ok := currentPolicy.Load().Params(version)
+	if !ok {
+		return "", goerrors.New("UNKNOWN_POLICY_VERSION", fmt.Sprintf("policy version %d is not registered", version))
+	}
+	time, memoryKiB, threads := params.resolve()
+	return hashPasswordPHC(password, time, memoryKiB, threads, version)
+}
+
+// VerifyPasswordVersioned checks a password against a hash produced by
+// HashPasswordVersioned and returns the policy version embedded in the hash.
+//
+// Verification uses the parameters stored in the hash itself, so hashes created
+// under versions that are no longer registered still verify.
+//
+// Example:
+//
+//	ok, version, err := crypto.VerifyPasswordVersioned(password, user.PasswordHash)
+func VerifyPasswordVersioned(password []byte, encoded string) (bool, int, error) {
+	h, err := parsePHC(encoded)
+	if err != nil {
+		return false, 0, err
+	}
+	defer h.zeroize()
+	if h.version == 0 {
+		return false, 0, goerrors.New("INVALID_HASH", "hash does not carry a policy version")
+	}
+	return h.verify(password), h.version, nil
+}
+
+// NeedsRehashVersioned reports whether an encoded hash was produced under a policy
+// version other than the current version of the installed ParamPolicy. Hashes without
+// a version and malformed hashes always need rehashing.
+//
+// Example:
+//
+//	if ok && crypto.NeedsRehashVersioned(user.PasswordHash) {
+//		user.PasswordHash, _ = crypto.HashPasswordVersioned(password, policy.Current())
+//	}
+func NeedsRehashVersioned(encoded string) bool {
+	h, err := parsePHC(encoded)
+	if err != nil {
+		return true
+	}
+	return h.version != currentPolicy.Load().Current()
+}
+
+// hashPasswordPHC hashes password with a fresh salt and encodes the result.
+func hashPasswordPHC(password []byte, time, memoryKiB uint32, threads uint8, version int) (string, error) {
+	if len(password) == 0 {
+		return "", goerrors.New("EMPTY_PASSWORD", "password cannot be empty")
+	}
+	salt := make([]byte, PasswordSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", goerrors.Wrap(err, "SALT_GEN_ERROR", "failed to generate salt")
+	}
+	h := &phcHash{
+		time:      time,
+		memoryKiB: memoryKiB,
+		threads:   threads,
+		version:   version,
+		salt:      salt,
+	}
+	defer h.zeroize()
+	h.hash = argon2.IDKey(password, salt, time, memoryKiB, threads, PasswordHashSize)
+	return h.encode(), nil
+}
+
+// verify recomputes the hash of password and compares it in constant time.
+func (h *phcHash) verify(password []byte) bool {
+	computed := argon2.IDKey(password, h.salt, h.time, h.memoryKiB, h.threads, uint32(len(h.hash)))
+	defer Zeroize(computed)
+	return subtle.ConstantTimeCompare(computed, h.hash) == 1
+}
+
+// zeroize wipes the raw hash. The encoded PHC string is immutable and cannot be wiped;
+// it is the value meant to be stored, so only the binary intermediates are cleared.
+func (h *phcHash) zeroize() {
+	Zeroize(h.hash)
+}
+
+// encode renders the hash as a PHC string.
+func (h *phcHash) encode() string {
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", h.memoryKiB, h.time, h.threads)
+	if h.version > 0 {
+		params += fmt.Sprintf(",pv=%d", h.version)
+	}
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s", argon2.Version, params,
+		base64.RawStdEncoding.EncodeToString(h.salt), base64.RawStdEncoding.EncodeToString(h.hash))
+}
+
+// parsePHC parses an Argon2id PHC string leniently.
+func parsePHC(encoded string) (*phcHash, error) {
+	return parsePHCMode(encoded, false)
+}
+
+// parsePHCMode parses an Argon2id PHC string, accepting only the canonical encoding if
+// strict is set.
+func parsePHCMode(encoded string, strict bool) (*phcHash, error) {
+	if !strict {
+		encoded = strings.TrimSpace(encoded)
+	}
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return nil, goerrors.New("INVALID_HASH", "hash is not in PHC format")
+	}
+	if parts[1] != "argon2id" {
+		return nil, goerrors.New("UNSUPPORTED_HASH", fmt.Sprintf("unsupported hash algorithm %q", parts[1]))
+	}
+	if parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, goerrors.New("UNSUPPORTED_HASH", fmt.Sprintf("unsupported Argon2 version %q", parts[2]))
+	}
+
+	h := &phcHash{}
+	var seen [4]bool
+	for _, kv := range strings.Split(parts[3], ",") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, goerrors.New("INVALID_HASH", fmt.Sprintf("malformed hash parameter %q", kv))
+		}
+		if !strict {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		}
+		var n uint64
+		var err error
+		switch key {
+		case "m":
+			n, err = parsePHCUint(value, 8, maxPHCMemoryKiB, &seen[0])
+			h.memoryKiB = uint32(n)
+		case "t":
+			n, err = parsePHCUint(value, 1, maxPHCTime, &seen[1])
+			h.time = uint32(n)
+		case "p":
+			n, err = parsePHCUint(value, 1, 255, &seen[2])
+			h.threads = uint8(n)
+		case "pv":
+			n, err = parsePHCUint(value, 1, 1<<31-1, &seen[3])
+			h.version = int(n)
+		default:
+			err = fmt.Errorf("unknown parameter %q", key)
+		}
+		if err != nil {
+			return nil, goerrors.Wrap(err, "INVALID_HASH", "invalid hash parameters")
+		}
+	}
+	if !seen[0] || !seen[1] || !seen[2] {
+		return nil, goerrors.New("INVALID_HASH", "hash parameters m, t and p are required")
+	}
+
+	var err error
+	if h.salt, err = decodePHCBase64(parts[4], strict); err != nil || len(h.salt) < 8 {
+		return nil, goerrors.New("INVALID_HASH", "invalid hash salt")
+	}
+	if h.hash, err = decodePHCBase64(parts[5], strict); err != nil || len(h.hash) < 16 || len(h.hash) > 64 {
+		return nil, goerrors.New("INVALID_HASH", "invalid hash value")
+	}
+	return h, nil
+}
+
+// decodePHCBase64 decodes a PHC salt or hash field: unpadded standard base64, or in
+// lenient mode also padded standard base64.
+func decodePHCBase64(field string, strict bool) ([]byte, error) {
+	if !strict && strings.HasSuffix(field, "=") {
+		return base64.StdEncoding.DecodeString(field)
+	}
+	return base64.RawStdEncoding.DecodeString(field)
+}
+
+// parsePHCUint parses a decimal PHC parameter within [minValue, maxValue], rejecting duplicates.
+func parsePHCUint(value string, minValue, maxValue uint64, seen *bool) (uint64, error) {
+	if *seen {
+		return 0, fmt.Errorf("duplicate parameter")
+	}
+	*seen = true
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if n < minValue || n > maxValue {
+		return 0, fmt.Errorf("parameter %d out of range [%d, %d]", n, minValue, maxValue)
+	}
+	return n, nil
+}
@@ -0,0 +1,280 @@
+// password_test.go: Test cases for PHC password hashing and parameter policies.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+// fastParams keeps password hashing tests quick.
+var fastParams = &crypto.KDFParams{Time: 1, Memory: 1, Threads: 1}
+
+func TestHashPassword_RoundTrip(t *testing.T) {
+	encoded, err := crypto.HashPassword([]byte("s3cret"), fastParams)
+	if err != nil {
+		t.Fatalf("HashPassword() error: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Unexpected PHC prefix: %s", encoded)
+	}
+
+	ok, err := crypto.VerifyPassword([]byte("s3cret"), encoded)
+	if err != nil || !ok {
+		t.Errorf("Expected password to verify, got ok=%v err=%v", ok, err)
+	}
+	ok, err = crypto.VerifyPassword([]byte("wrong"), encoded)
+	if err != nil || ok {
+		t.Errorf("Expected wrong password to be rejected, got ok=%v err=%v", ok, err)
+	}
+
+	again, _ := crypto.HashPassword([]byte("s3cret"), fastParams)
+	if again == encoded {
+		t.Error("Expected different hashes thanks to random salts")
+	}
+}
+
+// Intermediate buffers are internal and cannot be inspected from outside the package;
+// this checks that wiping them neither corrupts the output nor touches caller memory.
+func TestHashPassword_ZeroizationSafety(t *testing.T) {
+	password := []byte("s3cret")
+	encoded, err := crypto.HashPassword(password, fastParams)
+	if err != nil {
+		t.Fatalf("HashPassword() error: %v", err)
+	}
+	if string(password) != "s3cret" {
+		t.Errorf("HashPassword() modified the caller's password: %q", password)
+	}
+	hash := encoded[strings.LastIndex(encoded, "$")+1:]
+	if hash == strings.Repeat("A", len(hash)) {
+		t.Errorf("encoded hash was wiped before encoding: %s", encoded)
+	}
+	for i := 0; i < 2; i++ {
+		if ok, err := crypto.VerifyPassword(password, encoded); err != nil || !ok {
+			t.Fatalf("VerifyPassword() call %d = %v, %v", i, ok, err)
+		}
+	}
+	if string(password) != "s3cret" {
+		t.Errorf("VerifyPassword() modified the caller's password: %q", password)
+	}
+}
+
+func TestHashPassword_InvalidInputs(t *testing.T) {
+	if _, err := crypto.HashPassword(nil, fastParams); err == nil {
+		t.Error("Expected error for empty password")
+	}
+
+	malformed := []string{
+		"",
+		"not-a-hash",
+		"$argon2i$v=19$m=1024,t=1,p=1$c29tZXNhbHQ$aGFzaGhhc2hoYXNoaGFzaA",
+		"$argon2id$v=16$m=1024,t=1,p=1$c29tZXNhbHQ$aGFzaGhhc2hoYXNoaGFzaA",
+		"$argon2id$v=19$m=1024,t=1$c29tZXNhbHQ$aGFzaGhhc2hoYXNoaGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1,x=2$c29tZXNhbHQ$aGFzaGhhc2hoYXNoaGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1,p=2$c29tZXNhbHQ$aGFzaGhhc2hoYXNoaGFzaA",
+		"$argon2id$v=19$m=99999999999,t=1,p=1$c29tZXNhbHQ$aGFzaGhhc2hoYXNoaGFzaA",
+		"$argon2id$v=19$m=1024,t=0,p=1$c29tZXNhbHQ$aGFzaGhhc2hoYXNoaGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$aGFzaGhhc2hoYXNoaGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$c29tZXNhbHQ$c2hvcnQ",
+	}
+	for _, encoded := range malformed {
+		if _, err := crypto.VerifyPassword([]byte("pw"), encoded); err == nil {
+			t.Errorf("Expected error for malformed hash %q", encoded)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	encoded, _ := crypto.HashPassword([]byte("s3cret"), fastParams)
+	if crypto.NeedsRehash(encoded, fastParams) {
+		t.Error("Expected no rehash for identical parameters")
+	}
+	if !crypto.NeedsRehash(encoded, &crypto.KDFParams{Time: 2, Memory: 1, Threads: 1}) {
+		t.Error("Expected rehash for different parameters")
+	}
+	if !crypto.NeedsRehash(encoded, nil) {
+		t.Error("Expected rehash against the defaults")
+	}
+	if !crypto.NeedsRehash("garbage", nil) {
+		t.Error("Expected rehash for malformed hash")
+	}
+}
+
+func TestParamPolicy_Versioned(t *testing.T) {
+	policy := crypto.NewParamPolicy()
+	if err := policy.Register(1, *fastParams); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	if err := policy.Register(2, crypto.KDFParams{Time: 2, Memory: 1, Threads: 1}); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	crypto.SetParamPolicy(policy)
+	defer crypto.SetParamPolicy(nil)
+
+	v1, err := crypto.HashPasswordVersioned([]byte("s3cret"), 1)
+	if err != nil {
+		t.Fatalf("HashPasswordVersioned() error: %v", err)
+	}
+	if !strings.Contains(v1, ",pv=1$") {
+		t.Errorf("Expected policy version in hash, got %s", v1)
+	}
+	ok, version, err := crypto.VerifyPasswordVersioned([]byte("s3cret"), v1)
+	if err != nil || !ok || version != 1 {
+		t.Errorf("Expected verification with version 1, got ok=%v version=%d err=%v", ok, version, err)
+	}
+	if crypto.NeedsRehashVersioned(v1) {
+		t.Error("Expected no rehash while version 1 is current")
+	}
+
+	if err := policy.SetCurrent(2); err != nil {
+		t.Fatalf("SetCurrent() error: %v", err)
+	}
+	if !crypto.NeedsRehashVersioned(v1) {
+		t.Error("Expected rehash after bumping the current version")
+	}
+	v2, _ := crypto.HashPasswordVersioned([]byte("s3cret"), policy.Current())
+	if crypto.NeedsRehashVersioned(v2) {
+		t.Error("Expected no rehash for a hash at the current version")
+	}
+	// Versioned hashes also verify with the plain API.
+	if ok, err := crypto.VerifyPassword([]byte("s3cret"), v2); err != nil || !ok {
+		t.Errorf("Expected VerifyPassword to accept versioned hash, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestParamPolicy_Errors(t *testing.T) {
+	policy := crypto.NewParamPolicy()
+	if err := policy.Register(0, *fastParams); err == nil {
+		t.Error("Expected error for non-positive version")
+	}
+	if err := policy.SetCurrent(5); err == nil {
+		t.Error("Expected error for unregistered version")
+	}
+	if _, err := crypto.HashPasswordVersioned([]byte("pw"), 42); err == nil {
+		t.Error("Expected error for unknown version")
+	}
+	plain, _ := crypto.HashPassword([]byte("pw"), fastParams)
+	if _, _, err := crypto.VerifyPasswordVersioned([]byte("pw"), plain); err == nil {
+		t.Error("Expected error for hash without policy version")
+	}
+	if !crypto.NeedsRehashVersioned(plain) {
+		t.Error("Expected unversioned hash to need rehashing")
+	}
+}
+
+func TestPasswordHasher(t *testing.T) {
+	params := &crypto.KDFParams{Time: 1, Memory: 1, Threads: 1}
+	hasher := crypto.NewPasswordHasher(params)
+	params.Time = 2 // the hasher keeps its own copy
+
+	encoded, err := hasher.Hash([]byte("s3cret"))
+	if err != nil {
+		t.Fatalf("Hash() error: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Unexpected PHC prefix: %s", encoded)
+	}
+	if ok, err := hasher.Verify([]byte("s3cret"), encoded); err != nil || !ok {
+		t.Errorf("Expected password to verify, got ok=%v err=%v", ok, err)
+	}
+	if ok, _ := hasher.Verify([]byte("wrong"), encoded); ok {
+		t.Error("Expected wrong password to be rejected")
+	}
+	if hasher.NeedsRehash(encoded) {
+		t.Error("Expected no rehash for the hasher's own parameters")
+	}
+	if !crypto.NewPasswordHasher(params).NeedsRehash(encoded) {
+		t.Error("Expected rehash under different parameters")
+	}
+	if !hasher.NeedsRehash("not a hash") {
+		t.Error("Expected malformed hash to need rehashing")
+	}
+	if _, err := hasher.Hash(nil); err == nil {
+		t.Error("Expected error for empty password")
+	}
+}
+
+func TestPasswordHasher_Concurrent(t *testing.T) {
+	hasher := crypto.NewPasswordHasher(fastParams)
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			password := []byte(fmt.Sprintf("password-%d", i))
+			encoded, err := hasher.Hash(password)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if ok, err := hasher.Verify(password, encoded); err != nil || !ok {
+				errs <- fmt.Errorf("password %d did not verify: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// referencePHCHashes are Argon2id test vectors from the reference implementation's
+// test suite (phc-winner-argon2 src/test.c), all for the password "password". They
+// also verify with libsodium's crypto_pwhash_str_verify.
+var referencePHCHashes = []string{
+	"$argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+	"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+}
+
+func TestVerifyPassword_ReferenceHashes(t *testing.T) {
+	for i, encoded := range referencePHCHashes {
+		if i > 0 && testing.Short() {
+			t.Skip("skipping 64 MiB reference hash in short mode")
+		}
+		for _, mode := range []crypto.PHCMode{crypto.PHCLenient, crypto.PHCStrict} {
+			ok, err := crypto.VerifyPasswordWithMode([]byte("password"), encoded, mode)
+			if err != nil || !ok {
+				t.Errorf("VerifyPasswordWithMode(%q, %d) = %v, %v; want true", encoded, mode, ok, err)
+			}
+			if ok, _ := crypto.VerifyPasswordWithMode([]byte("passwore"), encoded, mode); ok {
+				t.Errorf("Wrong password verified against %q", encoded)
+			}
+		}
+	}
+}
+
+func TestVerifyPassword_Lenient(t *testing.T) {
+	variants := []string{
+		// Padded base64, as emitted by some libraries.
+		"$argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHQ=$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc=",
+		// Surrounding whitespace, e.g. a trailing newline from a file or CLI.
+		"  $argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc\n",
+		// Different parameter order and spacing.
+		"$argon2id$v=19$p=2, t=2, m=256$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc",
+	}
+	for _, encoded := range variants {
+		if ok, err := crypto.VerifyPassword([]byte("password"), encoded); err != nil || !ok {
+			t.Errorf("VerifyPassword(%q) = %v, %v; want true", encoded, ok, err)
+		}
+		if ok, err := crypto.VerifyPasswordWithMode([]byte("password"), encoded, crypto.PHCStrict); err == nil || ok {
+			t.Errorf("PHCStrict accepted non-canonical %q", encoded)
+		}
+	}
+	reordered := "$argon2id$v=19$t=2,p=2,m=256$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc"
+	if ok, err := crypto.VerifyPasswordWithMode([]byte("password"), reordered, crypto.PHCStrict); err != nil || !ok {
+		t.Errorf("PHCStrict should accept any parameter order, got %v, %v", ok, err)
+	}
+	if _, err := crypto.VerifyPassword([]byte("password"), "$argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHQ=$!!!"); err == nil {
+		t.Error("Expected an error for an invalid hash field")
+	}
+}
@@ -0,0 +1,78 @@
+// purpose.go: Encryption under purpose-specific subkeys of a master key.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	goerrors "github.com/agilira/go-errors"
+)
+
+// EncryptForPurpose derives the subkey for purpose from masterKey and encrypts with it.
+//
+// The subkey is ExpandKey(masterKey, purpose, KeySize), so data encrypted for one
+// purpose cannot be decrypted under another and the master key itself is never used
+// to encrypt. The output has the EncryptBytes format and decrypts with DecryptBytes
+// under the same subkey.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - masterKey: The 32-byte master key (must be exactly KeySize bytes)
+//   - purpose: The purpose label, used as HKDF info (cannot be empty)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if the key or purpose is invalid or encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptForPurpose(sessionData, masterKey, "session-cookie")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptForPurpose(plaintext, masterKey []byte, purpose string) (string, error) {
+	subkey, err := purposeKey(masterKey, purpose)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(subkey)
+	return EncryptBytes(plaintext, subkey)
+}
+
+// DecryptForPurpose re-derives the subkey for purpose and decrypts a ciphertext
+// produced by EncryptForPurpose.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - masterKey: The 32-byte master key (must be exactly KeySize bytes)
+//   - purpose: The purpose label used at encryption time
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if the key or purpose is invalid, or decryption fails (ErrDecrypt when
+//     the purpose differs)
+//
+// Example:
+//
+//	sessionData, err := crypto.DecryptForPurpose(cookie, masterKey, "session-cookie")
+func DecryptForPurpose(encryptedText string, masterKey []byte, purpose string) ([]byte, error) {
+	subkey, err := purposeKey(masterKey, purpose)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(subkey)
+	return DecryptBytes(encryptedText, subkey)
+}
+
+// purposeKey validates the inputs and derives the subkey for purpose.
+func purposeKey(masterKey []byte, purpose string) ([]byte, error) {
+	if err := checkKey(masterKey); err != nil {
+		return nil, err
+	}
+	if purpose == "" {
+		return nil, goerrors.New("EMPTY_PURPOSE", "purpose cannot be empty")
+	}
+	return ExpandKey(masterKey, purpose, KeySize)
+}
@@ -0,0 +1,56 @@
+// purpose_test.go: Test cases for purpose-specific subkey encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptForPurpose_RoundTrip(t *testing.T) {
+	master, _ := crypto.GenerateKey()
+	ciphertext, err := crypto.EncryptForPurpose([]byte("cookie"), master, "session")
+	if err != nil {
+		t.Fatalf("EncryptForPurpose() error: %v", err)
+	}
+	plaintext, err := crypto.DecryptForPurpose(ciphertext, master, "session")
+	if err != nil || string(plaintext) != "cookie" {
+		t.Fatalf("DecryptForPurpose() = %q, %v", plaintext, err)
+	}
+
+	// The subkey is ExpandKey(master, purpose, KeySize).
+	subkey, _ := crypto.ExpandKey(master, "session", crypto.KeySize)
+	if plaintext, err := crypto.DecryptBytes(ciphertext, subkey); err != nil || string(plaintext) != "cookie" {
+		t.Errorf("DecryptBytes() with ExpandKey subkey = %q, %v", plaintext, err)
+	}
+}
+
+func TestDecryptForPurpose_DomainSeparation(t *testing.T) {
+	master, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptForPurpose([]byte("cookie"), master, "session")
+	if _, err := crypto.DecryptForPurpose(ciphertext, master, "csrf"); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("other purpose: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.DecryptBytes(ciphertext, master); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("master key: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestEncryptForPurpose_InvalidInputs(t *testing.T) {
+	master, _ := crypto.GenerateKey()
+	if _, err := crypto.EncryptForPurpose([]byte("x"), master, ""); err == nil {
+		t.Error("empty purpose: expected error")
+	}
+	if _, err := crypto.EncryptForPurpose([]byte("x"), make([]byte, 16), "session"); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+	if _, err := crypto.DecryptForPurpose("AAAA", nil, "session"); !errors.Is(err, crypto.ErrNilKey) {
+		t.Errorf("nil key: got %v, want ErrNilKey", err)
+	}
+}
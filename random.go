@@ -0,0 +1,142 @@
+// random.go: Selection of the random source used for nonce generation.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	goerrors "github.com/agilira/go-errors"
+	"golang.org/x/crypto/chacha20"
+)
+
+// BufferedRandomReseedInterval is the number of bytes the buffered random source
+// produces before it draws a fresh key from the operating system (64 MiB).
+const BufferedRandomReseedInterval = 64 << 20
+
+// maxBufferedRandomSize bounds the buffer size accepted by UseBufferedRandom (1 MiB).
+const maxBufferedRandomSize = 1 << 20
+
+// bufferedRand holds the source installed by UseBufferedRandom; nil selects crypto/rand.
+var bufferedRand atomic.Pointer[bufferedRandom]
+
+// UseBufferedRandom selects the random source used for nonce generation.
+//
+// By default every nonce is read from crypto/rand, which costs a system call on some
+// platforms. With a positive bufSize, nonces are instead drawn from a userspace
+// ChaCha20 generator keyed from crypto/rand: keystream is produced bufSize bytes at a
+// time and handed out from the buffer, so most nonces cost no system call. The
+// generator uses fast key erasure: every refill replaces the key with the first 32
+// bytes of its own output and wipes served bytes, so a later memory compromise does
+// not reveal earlier nonces. After BufferedRandomReseedInterval bytes the key is
+// replaced with fresh operating system entropy. The output is cryptographically
+// secure; the option only trades system calls for a little memory and a mutex.
+//
+// Only nonces, nonce prefixes and stream nonce prefixes use the selected source. Keys
+// and salts are always read from crypto/rand. The setting is process-wide and safe to
+// change concurrently with encryption; a bufSize of zero or less restores crypto/rand.
+//
+// Parameters:
+//   - bufSize: The keystream buffer size in bytes (at most 1 MiB), or 0 to disable
+//
+// Returns:
+//   - An error if bufSize is too large or the generator cannot be seeded
+//
+// Example:
+//
+//	if err := crypto.UseBufferedRandom(4096); err != nil {
+//		log.Fatal(err)
+//	}
+func UseBufferedRandom(bufSize int) error {
+	if bufSize <= 0 {
+		bufferedRand.Store(nil)
+		return nil
+	}
+	if bufSize > maxBufferedRandomSize {
+		return goerrors.New("INVALID_BUFFER_SIZE", "buffered random size must be at most 1 MiB")
+	}
+	b := &bufferedRandom{buf: make([]byte, KeySize+bufSize)}
+	b.pos = len(b.buf)
+	if err := b.reseed(); err != nil {
+		return err
+	}
+	bufferedRand.Store(b)
+	return nil
+}
+
+// nonceReader returns the random source used for nonce generation.
+func nonceReader() io.Reader {
+	if b := bufferedRand.Load(); b != nil {
+		return b
+	}
+	return rand.Reader
+}
+
+// bufferedRandom is a ChaCha20 keystream generator with fast key erasure.
+type bufferedRandom struct {
+	mu        sync.Mutex
+	key       [KeySize]byte
+	buf       []byte // key || output; buf[pos:] has not been served yet
+	pos       int
+	generated int
+}
+
+// Read fills p with random bytes. It never fails once the generator is seeded, except
+// when a scheduled reseed cannot read from crypto/rand.
+func (b *bufferedRandom) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for n < len(p) {
+		if b.pos == len(b.buf) {
+			if err := b.refill(); err != nil {
+				return n, err
+			}
+		}
+		c := copy(p[n:], b.buf[b.pos:])
+		clear(b.buf[b.pos : b.pos+c])
+		b.pos += c
+		n += c
+	}
+	return n, nil
+}
+
+// refill generates a new buffer of keystream and rotates the key. The caller must hold b.mu.
+func (b *bufferedRandom) refill() error {
+	if b.generated >= BufferedRandomReseedInterval {
+		if err := b.reseed(); err != nil {
+			return err
+		}
+	}
+	// The key is used once, so a fixed all-zero nonce is safe.
+	var nonce [chacha20.NonceSize]byte
+	c, err := chacha20.NewUnauthenticatedCipher(b.key[:], nonce[:])
+	if err != nil {
+		return err
+	}
+	clear(b.buf)
+	c.XORKeyStream(b.buf, b.buf)
+	copy(b.key[:], b.buf[:KeySize])
+	clear(b.buf[:KeySize])
+	b.pos = KeySize
+	b.generated += len(b.buf) - KeySize
+	return nil
+}
+
+// reseed replaces the key with fresh operating system entropy and discards buffered
+// output. The caller must hold b.mu, or have exclusive access to b.
+func (b *bufferedRandom) reseed() error {
+	if _, err := io.ReadFull(rand.Reader, b.key[:]); err != nil {
+		return goerrors.Wrap(err, "RANDOM_SEED_ERROR", "failed to seed buffered random source")
+	}
+	clear(b.buf)
+	b.pos = len(b.buf)
+	b.generated = 0
+	return nil
+}
@@ -0,0 +1,103 @@
+// ratchet.go: Symmetric key ratchet for forward-secret message streams.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"sync"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// HKDF info strings of the ratchet's two outputs. Distinct labels keep message keys
+// independent of the chain key that replaces the current one.
+const (
+	ratchetChainInfo   = "go-crypto/v1/ratchet/chain"
+	ratchetMessageInfo = "go-crypto/v1/ratchet/message"
+)
+
+// Ratchet is a symmetric key ratchet (a KDF chain) that yields one key per message.
+//
+// Each call to Next derives a message key and a new chain key from the current chain
+// key with HKDF-SHA256, then zeroizes and discards the old chain key. Because HKDF is
+// one-way, an attacker who learns the current state cannot recover earlier message
+// keys: this gives forward secrecy for message streams. It does not give
+// post-compromise security: a leaked state exposes all future keys. That property
+// needs fresh Diffie-Hellman input, as in the full Double Ratchet, which this type
+// does not implement.
+//
+// Sender and receiver start from the same root key and call Next once per message
+// in the same order. A receiver handling out-of-order delivery must derive and
+// store the skipped keys itself. A Ratchet is safe for concurrent use.
+type Ratchet struct {
+	mu    sync.Mutex
+	chain []byte
+}
+
+// NewRatchet creates a Ratchet whose chain starts at rootKey.
+//
+// The root key is copied; the caller should zeroize its own copy once both parties
+// have created their ratchets, since it allows deriving every message key.
+//
+// Parameters:
+//   - rootKey: The 32-byte shared root key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A new Ratchet
+//   - An error if the key size is invalid
+//
+// Example:
+//
+//	r, err := crypto.NewRatchet(sharedKey)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	crypto.Zeroize(sharedKey)
+//	msgKey, err := r.Next()
+//	ciphertext, err := crypto.EncryptBytes(message, msgKey)
+//	crypto.Zeroize(msgKey)
+func NewRatchet(rootKey []byte) (*Ratchet, error) {
+	if err := checkKey(rootKey); err != nil {
+		return nil, err
+	}
+	return &Ratchet{chain: append([]byte(nil), rootKey...)}, nil
+}
+
+// Next derives the key for the next message and advances the chain.
+//
+// The returned key is owned by the caller, who should zeroize it after use so the
+// message cannot be decrypted from memory later.
+//
+// Returns:
+//   - A 32-byte message key
+//   - An error if the Ratchet has been destroyed or key derivation fails
+func (r *Ratchet) Next() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.chain == nil {
+		return nil, goerrors.New("RATCHET_DESTROYED", "ratchet has been destroyed")
+	}
+	messageKey, err := deriveSubkey(r.chain, nil, ratchetMessageInfo, KeySize)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to derive message key")
+	}
+	chain, err := deriveSubkey(r.chain, nil, ratchetChainInfo, KeySize)
+	if err != nil {
+		Zeroize(messageKey)
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to derive chain key")
+	}
+	Zeroize(r.chain)
+	r.chain = chain
+	return messageKey, nil
+}
+
+// Destroy zeroizes the chain key. Subsequent calls to Next fail.
+func (r *Ratchet) Destroy() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	Zeroize(r.chain)
+	r.chain = nil
+}
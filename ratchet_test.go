@@ -0,0 +1,76 @@
+// ratchet_test.go: Test cases for the symmetric key ratchet.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestRatchet_SendReceive(t *testing.T) {
+	root, _ := crypto.GenerateKey()
+	sender, err := crypto.NewRatchet(root)
+	if err != nil {
+		t.Fatalf("NewRatchet() error: %v", err)
+	}
+	receiver, _ := crypto.NewRatchet(root)
+
+	seen := make(map[string]bool)
+	for i, msg := range []string{"one", "two", "three"} {
+		sendKey, err := sender.Next()
+		if err != nil {
+			t.Fatalf("sender.Next() error: %v", err)
+		}
+		ciphertext, _ := crypto.EncryptBytes([]byte(msg), sendKey)
+
+		recvKey, _ := receiver.Next()
+		plaintext, err := crypto.DecryptBytes(ciphertext, recvKey)
+		if err != nil || string(plaintext) != msg {
+			t.Fatalf("message %d: DecryptBytes() = %q, %v", i, plaintext, err)
+		}
+		if bytes.Equal(sendKey, root) || seen[string(sendKey)] {
+			t.Errorf("message %d: key repeated", i)
+		}
+		seen[string(sendKey)] = true
+	}
+}
+
+func TestRatchet_Deterministic(t *testing.T) {
+	root := bytes.Repeat([]byte{7}, crypto.KeySize)
+	a, _ := crypto.NewRatchet(root)
+	b, _ := crypto.NewRatchet(root)
+	crypto.Zeroize(root) // the ratchets keep their own copy
+	for i := 0; i < 4; i++ {
+		ka, _ := a.Next()
+		kb, _ := b.Next()
+		if !bytes.Equal(ka, kb) {
+			t.Fatalf("step %d: ratchets diverged", i)
+		}
+	}
+}
+
+func TestRatchet_Destroy(t *testing.T) {
+	root, _ := crypto.GenerateKey()
+	r, _ := crypto.NewRatchet(root)
+	r.Destroy()
+	if _, err := r.Next(); err == nil {
+		t.Error("Next() after Destroy succeeded")
+	}
+	r.Destroy() // idempotent
+}
+
+func TestNewRatchet_InvalidKey(t *testing.T) {
+	if _, err := crypto.NewRatchet(make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+	if _, err := crypto.NewRatchet(nil); !errors.Is(err, crypto.ErrNilKey) {
+		t.Errorf("nil key: got %v, want ErrNilKey", err)
+	}
+}
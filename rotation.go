@@ -0,0 +1,181 @@
+// rotation.go: Helpers for key rotation and mixed-key datasets.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// DecryptTryKeys decrypts a ciphertext produced by EncryptBytes by trying each candidate key in order.
+//
+// Because AES-GCM authenticates the ciphertext, a wrong key is detected reliably and
+// never yields garbage plaintext, so trying several keys is safe. This is useful during
+// key rotation, or when migrating datasets encrypted under a mix of keys.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext
+//   - keys: The candidate 32-byte keys, tried in order
+//
+// Returns:
+//   - The decrypted plaintext
+//   - The index in keys of the key that decrypted it (-1 on failure)
+//   - An error wrapping ErrDecrypt, joining the per-key failures, if no key works
+//
+// Example:
+//
+//	plaintext, idx, err := crypto.DecryptTryKeys(ciphertext, [][]byte{currentKey, previousKey})
+//	if err == nil && idx != 0 {
+//		// re-encrypt under the current key
+//	}
+func DecryptTryKeys(ciphertext string, keys [][]byte) ([]byte, int, error) {
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, -1, err
+	}
+	errs := make([]error, 0, len(keys)+1)
+	errs = append(errs, goerrors.New(ErrCodeDecrypt, fmt.Sprintf("none of the %d candidate keys could decrypt the ciphertext", len(keys))))
+	for i, key := range keys {
+		gcm, err := newGCM(key)
+		if err == nil {
+			var plaintext []byte
+			if plaintext, err = openAEAD(gcm, data, nil); err == nil {
+				return plaintext, i, nil
+			}
+		}
+		errs = append(errs, fmt.Errorf("key %d: %w", i, err))
+	}
+	return nil, -1, fmt.Errorf("%w: %w", ErrDecrypt, errors.Join(errs...))
+}
+
+// rekeyMaxLine is the longest line RekeyStream accepts, enough for a 96 MiB plaintext.
+const rekeyMaxLine = 128 << 20
+
+// Rekey re-encrypts a ciphertext produced by EncryptBytes from oldKey to newKey.
+//
+// The ciphertext is authenticated and decrypted with oldKey, then encrypted with newKey
+// under a fresh nonce. The intermediate plaintext is zeroized before returning.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext encrypted under oldKey
+//   - oldKey: The current 32-byte key
+//   - newKey: The 32-byte key to re-encrypt under
+//
+// Returns:
+//   - The ciphertext encrypted under newKey
+//   - An error if decryption with oldKey or encryption with newKey fails
+//
+// Example:
+//
+//	rotated, err := crypto.Rekey(ciphertext, previousKey, currentKey)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func Rekey(ciphertext string, oldKey, newKey []byte) (string, error) {
+	oldCipher, err := NewCipher(oldKey)
+	if err != nil {
+		return "", err
+	}
+	newCipher, err := NewCipher(newKey)
+	if err != nil {
+		return "", err
+	}
+	return rekey(ciphertext, oldCipher, newCipher)
+}
+
+// RekeyStream re-encrypts newline-delimited ciphertexts from src to dst, from oldKey to newKey.
+//
+// It is RekeyStreamProgress without a progress callback.
+func RekeyStream(dst io.Writer, src io.Reader, oldKey, newKey []byte) error {
+	return RekeyStreamProgress(dst, src, oldKey, newKey, nil)
+}
+
+// RekeyStreamProgress re-encrypts newline-delimited ciphertexts from src to dst, from
+// oldKey to newKey, reporting progress after each line.
+//
+// Each line of src must hold one EncryptBytes ciphertext; a trailing "\r" is ignored
+// and empty lines are copied through unchanged. Every ciphertext is rekeyed as with
+// Rekey and written to dst in the same order, one per line, so a bulk job can stream a
+// whole dataset from one store to another in constant memory. Processing stops at the
+// first line that fails; its 1-based line number is included in the error, and some of
+// the lines before it may already have been written to dst.
+//
+// Parameters:
+//   - dst: The destination for the rekeyed ciphertexts
+//   - src: The source of ciphertexts, one per line
+//   - oldKey: The current 32-byte key
+//   - newKey: The 32-byte key to re-encrypt under
+//   - progress: Called with the number of lines processed so far (nil to disable)
+//
+// Returns:
+//   - nil if every line was rekeyed
+//   - An error naming the failing line, wrapping the underlying error (e.g. ErrDecrypt
+//     or ErrBase64Decode), or a read or write error
+//
+// Example:
+//
+//	err := crypto.RekeyStreamProgress(out, in, previousKey, currentKey, func(n int) {
+//		if n%100000 == 0 {
+//			log.Printf("rekeyed %d records", n)
+//		}
+//	})
+func RekeyStreamProgress(dst io.Writer, src io.Reader, oldKey, newKey []byte, progress func(lines int)) error {
+	oldCipher, err := NewCipher(oldKey)
+	if err != nil {
+		return err
+	}
+	newCipher, err := NewCipher(newKey)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64<<10), rekeyMaxLine)
+	w := bufio.NewWriter(dst)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if text != "" {
+			rotated, err := rekey(text, oldCipher, newCipher)
+			if err != nil {
+				return goerrors.Wrap(err, "REKEY_ERROR", fmt.Sprintf("failed to rekey line %d", line))
+			}
+			if _, err := w.WriteString(rotated); err != nil {
+				return goerrors.Wrap(err, "REKEY_ERROR", fmt.Sprintf("failed to write line %d", line))
+			}
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			return goerrors.Wrap(err, "REKEY_ERROR", fmt.Sprintf("failed to write line %d", line))
+		}
+		if progress != nil {
+			progress(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return goerrors.Wrap(err, "REKEY_ERROR", fmt.Sprintf("failed to read line %d", line+1))
+	}
+	if err := w.Flush(); err != nil {
+		return goerrors.Wrap(err, "REKEY_ERROR", "failed to flush output")
+	}
+	return nil
+}
+
+// rekey decrypts ciphertext with from and encrypts the plaintext with to, zeroizing it.
+func rekey(ciphertext string, from, to *Cipher) (string, error) {
+	plaintext, err := from.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(plaintext)
+	return to.Encrypt(plaintext)
+}
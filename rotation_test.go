@@ -0,0 +1,129 @@
+// rotation_test.go: Test cases for key rotation helpers.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestDecryptTryKeys(t *testing.T) {
+	oldKey, _ := crypto.GenerateKey()
+	newKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytes([]byte("migrate me"), oldKey)
+
+	plaintext, idx, err := crypto.DecryptTryKeys(ciphertext, [][]byte{newKey, make([]byte, 3), oldKey})
+	if err != nil {
+		t.Fatalf("DecryptTryKeys() error: %v", err)
+	}
+	if idx != 2 || string(plaintext) != "migrate me" {
+		t.Errorf("Expected key index 2 and original plaintext, got %d %q", idx, plaintext)
+	}
+
+	_, idx, err = crypto.DecryptTryKeys(ciphertext, [][]byte{newKey, otherKey})
+	if !errors.Is(err, crypto.ErrDecrypt) || idx != -1 {
+		t.Errorf("Expected ErrDecrypt and index -1, got %d %v", idx, err)
+	}
+	if !strings.Contains(err.Error(), "key 1:") {
+		t.Errorf("Expected per-key details in error, got %v", err)
+	}
+	if _, _, err := crypto.DecryptTryKeys(ciphertext, nil); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for no keys, got %v", err)
+	}
+	if _, _, err := crypto.DecryptTryKeys("!!!", [][]byte{oldKey}); !errors.Is(err, crypto.ErrBase64Decode) {
+		t.Errorf("Expected ErrBase64Decode, got %v", err)
+	}
+}
+
+func TestRekey(t *testing.T) {
+	oldKey, _ := crypto.GenerateKey()
+	newKey, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytes([]byte("rotate me"), oldKey)
+
+	rotated, err := crypto.Rekey(ciphertext, oldKey, newKey)
+	if err != nil {
+		t.Fatalf("Rekey() error: %v", err)
+	}
+	plaintext, err := crypto.DecryptBytes(rotated, newKey)
+	if err != nil || string(plaintext) != "rotate me" {
+		t.Fatalf("DecryptBytes(rotated) = %q, %v", plaintext, err)
+	}
+	if _, err := crypto.Rekey(ciphertext, newKey, oldKey); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt with wrong old key, got %v", err)
+	}
+	if _, err := crypto.Rekey(ciphertext, oldKey, make([]byte, 3)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize for bad new key, got %v", err)
+	}
+}
+
+func TestRekeyStream(t *testing.T) {
+	oldKey, _ := crypto.GenerateKey()
+	newKey, _ := crypto.GenerateKey()
+	records := []string{"alpha", "", "gamma"}
+	var src strings.Builder
+	for _, r := range records {
+		ciphertext, _ := crypto.EncryptBytes([]byte(r), oldKey)
+		src.WriteString(ciphertext + "\r\n")
+	}
+	src.WriteString("\n")
+
+	var dst bytes.Buffer
+	var reported []int
+	err := crypto.RekeyStreamProgress(&dst, strings.NewReader(src.String()), oldKey, newKey, func(n int) {
+		reported = append(reported, n)
+	})
+	if err != nil {
+		t.Fatalf("RekeyStreamProgress() error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(dst.String(), "\n"), "\n")
+	if len(lines) != 4 || lines[3] != "" {
+		t.Fatalf("Expected 3 records and a blank line, got %q", lines)
+	}
+	for i, r := range records {
+		plaintext, err := crypto.DecryptBytes(lines[i], newKey)
+		if err != nil || string(plaintext) != r {
+			t.Errorf("line %d: got %q, %v; want %q", i+1, plaintext, err, r)
+		}
+	}
+	if len(reported) != 4 || reported[3] != 4 {
+		t.Errorf("Expected progress for 4 lines, got %v", reported)
+	}
+}
+
+func TestRekeyStream_Malformed(t *testing.T) {
+	oldKey, _ := crypto.GenerateKey()
+	newKey, _ := crypto.GenerateKey()
+	good, _ := crypto.EncryptBytes([]byte("ok"), oldKey)
+	foreign, _ := crypto.EncryptBytes([]byte("ok"), newKey)
+
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"bad base64", good + "\n!!!\n", crypto.ErrBase64Decode},
+		{"wrong key", good + "\n" + foreign + "\n", crypto.ErrDecrypt},
+	}
+	for _, tt := range tests {
+		var dst bytes.Buffer
+		err := crypto.RekeyStream(&dst, strings.NewReader(tt.input), oldKey, newKey)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+		if err != nil && !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("%s: expected line number in error, got %v", tt.name, err)
+		}
+		if strings.Count(dst.String(), "\n") > 1 {
+			t.Errorf("%s: expected at most the first line in output, got %q", tt.name, dst.String())
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// safe_test.go: Test cases for nonce-misuse-resistant encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestSafeEncrypt_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, plaintext := range [][]byte{nil, []byte("x"), bytes.Repeat([]byte("block"), 100)} {
+		ciphertext, err := crypto.SafeEncrypt(plaintext, key)
+		if err != nil {
+			t.Fatalf("SafeEncrypt() error: %v", err)
+		}
+		got, err := crypto.SafeDecrypt(ciphertext, key)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("SafeDecrypt() = %q, %v, want %q", got, err, plaintext)
+		}
+	}
+
+	a, _ := crypto.SafeEncrypt([]byte("same"), key)
+	b, _ := crypto.SafeEncrypt([]byte("same"), key)
+	if a == b {
+		t.Error("SafeEncrypt() is deterministic; expected random nonces")
+	}
+}
+
+func TestSafeDecrypt_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.SafeEncrypt([]byte("record"), key)
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+
+	other, _ := crypto.GenerateKey()
+	if _, err := crypto.SafeDecrypt(ciphertext, other); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong key: got %v, want ErrDecrypt", err)
+	}
+	for _, i := range []int{1, 17, len(raw) - 1} { // nonce, IV, ciphertext
+		tampered := append([]byte(nil), raw...)
+		tampered[i] ^= 1
+		if _, err := crypto.SafeDecrypt(base64.StdEncoding.EncodeToString(tampered), key); !errors.Is(err, crypto.ErrDecrypt) {
+			t.Errorf("tampered byte %d: got %v, want ErrDecrypt", i, err)
+		}
+	}
+
+	unknown := append([]byte{0x7f}, raw[1:]...)
+	if _, err := crypto.SafeDecrypt(base64.StdEncoding.EncodeToString(unknown), key); !errors.Is(err, crypto.ErrUnsupportedAlgorithm) {
+		t.Errorf("unknown version: got %v, want ErrUnsupportedAlgorithm", err)
+	}
+	if _, err := crypto.SafeDecrypt(base64.StdEncoding.EncodeToString(raw[:20]), key); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("short input: got %v, want ErrCiphertextShort", err)
+	}
+	gcm, _ := crypto.EncryptBytes([]byte("record"), key)
+	if _, err := crypto.SafeDecrypt(gcm, key); err == nil {
+		t.Error("SafeDecrypt() accepted an EncryptBytes ciphertext")
+	}
+	if _, err := crypto.SafeEncrypt([]byte("x"), make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+}
@@ -0,0 +1,157 @@
+// sealer.go: Authenticated encryption with incrementally supplied additional data.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+)
+
+// streamedAADLabel domain-separates digests of streamed additional data.
+const streamedAADLabel = "go-crypto/v1/streamed-aad"
+
+// Sealer encrypts a message bound to additional data supplied in pieces.
+//
+// AES-GCM takes its additional data in one buffer, so large or streamed AAD, such as a
+// big protocol header, would otherwise have to be collected in memory first. A Sealer
+// hashes the AAD with SHA-256 as it is written and binds the digest to the ciphertext
+// instead, so memory use does not depend on the AAD size. The AAD must be written in
+// the same bytes to an Opener, in any split, to decrypt; only the concatenation
+// matters, not how it was divided into writes. Because the digest rather than the AAD
+// itself is authenticated, the output is not interchangeable with EncryptWithAAD.
+//
+// After Seal, the Sealer is reset and can be reused for the next message. A Sealer is
+// not safe for concurrent use.
+type Sealer struct {
+	aead cipher.AEAD
+	aad  hash.Hash
+}
+
+// Opener decrypts a message sealed by a Sealer, with the additional data supplied in
+// pieces. It mirrors Sealer and is likewise reset by Open and not safe for concurrent
+// use.
+type Opener struct {
+	aead cipher.AEAD
+	aad  hash.Hash
+}
+
+// NewSealer creates a Sealer for key.
+//
+// Parameters:
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A Sealer with empty additional data
+//   - An error if the key size is invalid or cipher initialization fails
+//
+// Example:
+//
+//	s, err := crypto.NewSealer(key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, field := range headerFields {
+//		s.WriteAAD(field)
+//	}
+//	ciphertext, err := s.Seal(body)
+func NewSealer(key []byte) (*Sealer, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead, aad: sha256.New()}, nil
+}
+
+// WriteAAD appends p to the additional data of the next message.
+func (s *Sealer) WriteAAD(p []byte) {
+	s.aad.Write(p)
+}
+
+// Write appends p to the additional data, so that AAD can be copied from a reader
+// with io.Copy. It never fails.
+func (s *Sealer) Write(p []byte) (int, error) {
+	return s.aad.Write(p)
+}
+
+// Seal encrypts plaintext bound to the additional data written so far and resets the
+// additional data.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error if nonce generation fails
+func (s *Sealer) Seal(plaintext []byte) (string, error) {
+	aad := s.aad.Sum([]byte(streamedAADLabel))
+	s.aad.Reset()
+	out, err := sealAEAD(s.aead, nil, plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// NewOpener creates an Opener for key.
+//
+// Parameters:
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - An Opener with empty additional data
+//   - An error if the key size is invalid or cipher initialization fails
+//
+// Example:
+//
+//	o, err := crypto.NewOpener(key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if _, err := io.Copy(o, headerReader); err != nil {
+//		log.Fatal(err)
+//	}
+//	body, err := o.Open(ciphertext)
+func NewOpener(key []byte) (*Opener, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Opener{aead: aead, aad: sha256.New()}, nil
+}
+
+// WriteAAD appends p to the additional data of the next message.
+func (o *Opener) WriteAAD(p []byte) {
+	o.aad.Write(p)
+}
+
+// Write appends p to the additional data, so that AAD can be copied from a reader
+// with io.Copy. It never fails.
+func (o *Opener) Write(p []byte) (int, error) {
+	return o.aad.Write(p)
+}
+
+// Open authenticates and decrypts a ciphertext sealed by a Sealer against the
+// additional data written so far, and resets the additional data.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error wrapping ErrDecrypt if the ciphertext was tampered with, the key is
+//     wrong, or the additional data does not match
+func (o *Opener) Open(ciphertext string) ([]byte, error) {
+	aad := o.aad.Sum([]byte(streamedAADLabel))
+	o.aad.Reset()
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return openAEAD(o.aead, data, aad)
+}
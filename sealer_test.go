@@ -0,0 +1,73 @@
+// sealer_test.go: Test cases for encryption with streamed additional data.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestSealer_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	header := bytes.Repeat([]byte("large protocol header "), 10000)
+	s, err := crypto.NewSealer(key)
+	if err != nil {
+		t.Fatalf("NewSealer() error: %v", err)
+	}
+	for i := 0; i < len(header); i += 1000 {
+		s.WriteAAD(header[i:min(i+1000, len(header))])
+	}
+	ciphertext, err := s.Seal([]byte("body"))
+	if err != nil {
+		t.Fatalf("Seal() error: %v", err)
+	}
+
+	o, err := crypto.NewOpener(key)
+	if err != nil {
+		t.Fatalf("NewOpener() error: %v", err)
+	}
+	// The split of the AAD into writes does not matter.
+	if _, err := io.Copy(o, bytes.NewReader(header)); err != nil {
+		t.Fatalf("io.Copy() error: %v", err)
+	}
+	body, err := o.Open(ciphertext)
+	if err != nil || string(body) != "body" {
+		t.Fatalf("Open() = %q, %v", body, err)
+	}
+
+	// Both sides reset after each message.
+	second, _ := s.Seal([]byte("no aad"))
+	if body, err := o.Open(second); err != nil || string(body) != "no aad" {
+		t.Errorf("Open() after reset = %q, %v", body, err)
+	}
+}
+
+func TestOpener_Rejects(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	s, _ := crypto.NewSealer(key)
+	s.WriteAAD([]byte("header"))
+	ciphertext, _ := s.Seal([]byte("body"))
+
+	o, _ := crypto.NewOpener(key)
+	o.WriteAAD([]byte("headeR"))
+	if _, err := o.Open(ciphertext); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for different AAD, got %v", err)
+	}
+	if _, err := o.Open(ciphertext); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for missing AAD, got %v", err)
+	}
+	if _, err := crypto.DecryptWithAAD(ciphertext, key, []byte("header")); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected Sealer output to be distinct from EncryptWithAAD, got %v", err)
+	}
+	if _, err := crypto.NewSealer(key[:16]); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
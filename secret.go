@@ -0,0 +1,153 @@
+// secret.go: JSON-serializable values that are encrypted on the wire.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// ErrNoSecretKey is returned when a Secret is marshaled or unmarshaled without a key.
+var ErrNoSecretKey = errors.New("crypto: no key available for Secret")
+
+// ErrCodeNoSecretKey is the error code for Secret values used without a key.
+const ErrCodeNoSecretKey = "CRYPTO_NO_SECRET_KEY"
+
+// secretKey holds the package-level key registered with SetSecretKey.
+var secretKey atomic.Pointer[[]byte]
+
+// SetSecretKey registers the package-level key used by Secret values that are not
+// bound to a key with WithKey.
+//
+// The key is copied. Passing nil removes the registered key. SetSecretKey is safe to
+// call concurrently with JSON encoding and decoding.
+//
+// Parameters:
+//   - key: The 32-byte key (must be exactly KeySize bytes), or nil
+//
+// Returns:
+//   - An error if the key size is invalid
+//
+// Example:
+//
+//	if err := crypto.SetSecretKey(configKey); err != nil {
+//		log.Fatal(err)
+//	}
+//	err := json.Unmarshal(data, &cfg) // cfg.DBPassword is a crypto.Secret
+func SetSecretKey(key []byte) error {
+	if key == nil {
+		secretKey.Store(nil)
+		return nil
+	}
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	stored := append([]byte(nil), key...)
+	secretKey.Store(&stored)
+	return nil
+}
+
+// Secret is a value that is held in plaintext in memory and encrypted when encoded as JSON.
+//
+// Its JSON form is a string holding the EncryptBytes ciphertext of the value, so
+// configuration files and API payloads never contain the plaintext. Because the
+// json.Marshaler interfaces take no arguments, the key comes either from WithKey
+// (bound to the value) or from the package-level key set with SetSecretKey. To decode
+// with a bound key, populate the field with a keyed zero value before unmarshaling:
+//
+//	cfg := Config{Password: crypto.Secret{}.WithKey(key)}
+//	err := json.Unmarshal(data, &cfg)
+//
+// String and fmt formatting print a redaction marker instead of the value, so a Secret
+// does not leak through logs. The zero value is an empty secret.
+type Secret struct {
+	value []byte
+	key   []byte
+}
+
+// NewSecret returns a Secret holding a copy of value.
+//
+// Example:
+//
+//	cfg.APIToken = crypto.NewSecret([]byte(token))
+func NewSecret(value []byte) Secret {
+	return Secret{value: append([]byte(nil), value...)}
+}
+
+// WithKey returns a copy of s that uses key instead of the package-level key.
+func (s Secret) WithKey(key []byte) Secret {
+	s.key = append([]byte(nil), key...)
+	return s
+}
+
+// Bytes returns the plaintext value. The returned slice aliases the Secret's storage.
+func (s Secret) Bytes() []byte {
+	return s.value
+}
+
+// String returns a redaction marker, never the plaintext.
+func (s Secret) String() string {
+	return "[REDACTED]"
+}
+
+// GoString returns a redaction marker, so %#v does not reveal the plaintext either.
+func (s Secret) GoString() string {
+	return "crypto.Secret{[REDACTED]}"
+}
+
+// MarshalJSON encrypts the value and encodes the ciphertext as a JSON string.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	key, err := s.resolveKey()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := EncryptBytes(s.value, key)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ciphertext)
+}
+
+// UnmarshalJSON decodes a JSON string holding a ciphertext and decrypts it.
+// A JSON null leaves the Secret unchanged.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var ciphertext string
+	if err := json.Unmarshal(data, &ciphertext); err != nil {
+		return err
+	}
+	key, err := s.resolveKey()
+	if err != nil {
+		return err
+	}
+	value, err := DecryptBytes(ciphertext, key)
+	if err != nil {
+		return err
+	}
+	Zeroize(s.value)
+	s.value = value
+	return nil
+}
+
+// resolveKey returns the bound key, falling back to the package-level key.
+func (s Secret) resolveKey() ([]byte, error) {
+	if s.key != nil {
+		return s.key, nil
+	}
+	if key := secretKey.Load(); key != nil {
+		return *key, nil
+	}
+	richErr := goerrors.New(ErrCodeNoSecretKey, "no key bound with WithKey and none registered with SetSecretKey")
+	return nil, fmt.Errorf("%w: %w", ErrNoSecretKey, richErr)
+}
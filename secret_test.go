@@ -0,0 +1,123 @@
+// secret_test.go: Test cases for JSON-encrypted Secret values.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+type secretConfig struct {
+	User     string        `json:"user"`
+	Password crypto.Secret `json:"password"`
+}
+
+func TestSecret_JSONRoundTripWithPackageKey(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	if err := crypto.SetSecretKey(key); err != nil {
+		t.Fatalf("SetSecretKey() error: %v", err)
+	}
+	defer func() { _ = crypto.SetSecretKey(nil) }()
+
+	cfg := secretConfig{User: "app", Password: crypto.NewSecret([]byte("hunter2"))}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Fatalf("marshaled JSON contains the plaintext: %s", data)
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("wire form is not a JSON string: %v", err)
+	}
+	if plaintext, err := crypto.DecryptBytes(raw["password"], key); err != nil || string(plaintext) != "hunter2" {
+		t.Fatalf("wire form is not an EncryptBytes ciphertext: %q, %v", plaintext, err)
+	}
+
+	var decoded secretConfig
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if string(decoded.Password.Bytes()) != "hunter2" {
+		t.Errorf("Password = %q, want %q", decoded.Password.Bytes(), "hunter2")
+	}
+}
+
+func TestSecret_BoundKey(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	if err := crypto.SetSecretKey(other); err != nil {
+		t.Fatalf("SetSecretKey() error: %v", err)
+	}
+	defer func() { _ = crypto.SetSecretKey(nil) }()
+
+	data, err := json.Marshal(crypto.NewSecret([]byte("token")).WithKey(key))
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+
+	decoded := crypto.Secret{}.WithKey(key)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() with bound key error: %v", err)
+	}
+	if string(decoded.Bytes()) != "token" {
+		t.Errorf("Bytes() = %q, want %q", decoded.Bytes(), "token")
+	}
+
+	var wrong crypto.Secret
+	if err := json.Unmarshal(data, &wrong); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("json.Unmarshal() with package key: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestSecret_NoKey(t *testing.T) {
+	_ = crypto.SetSecretKey(nil)
+
+	if _, err := json.Marshal(crypto.NewSecret([]byte("x"))); !errors.Is(err, crypto.ErrNoSecretKey) {
+		t.Errorf("json.Marshal() without key: got %v, want ErrNoSecretKey", err)
+	}
+	var s crypto.Secret
+	if err := json.Unmarshal([]byte(`"AAAA"`), &s); !errors.Is(err, crypto.ErrNoSecretKey) {
+		t.Errorf("json.Unmarshal() without key: got %v, want ErrNoSecretKey", err)
+	}
+}
+
+func TestSecret_NullAndInvalid(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	s := crypto.Secret{}.WithKey(key)
+	if err := json.Unmarshal([]byte("null"), &s); err != nil {
+		t.Errorf("json.Unmarshal(null) error: %v", err)
+	}
+	if len(s.Bytes()) != 0 {
+		t.Errorf("null produced %q, want empty", s.Bytes())
+	}
+	if err := json.Unmarshal([]byte("42"), &s); err == nil {
+		t.Error("json.Unmarshal(42) succeeded, want error")
+	}
+}
+
+func TestSetSecretKey_InvalidSize(t *testing.T) {
+	if err := crypto.SetSecretKey(make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("SetSecretKey(16 bytes): got %v, want ErrInvalidKeySize", err)
+	}
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	s := crypto.NewSecret([]byte("hunter2"))
+	for _, format := range []string{"%v", "%s", "%+v", "%#v"} {
+		if out := fmt.Sprintf(format, s); strings.Contains(out, "hunter2") {
+			t.Errorf("fmt %s leaked the plaintext: %q", format, out)
+		}
+	}
+}
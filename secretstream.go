@@ -0,0 +1,401 @@
+// secretstream.go: libsodium crypto_secretstream_xchacha20poly1305 compatible streams.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	goerrors "github.com/agilira/go-errors"
+	"golang.org/x/crypto/chacha20"
+	//lint:ignore SA1019 secretstream is built from raw ChaCha20 and Poly1305, as in libsodium.
+	"golang.org/x/crypto/poly1305"
+)
+
+// Secretstream format parameters, matching libsodium's crypto_secretstream_xchacha20poly1305.
+//
+// A stream is a 24-byte header followed by messages of 17 bytes of overhead each:
+//
+//	header  = random (24 bytes); the key schedule is HChaCha20(key, header[:16]) and header[16:]
+//	message = encrypted tag (1 byte) || ChaCha20 ciphertext || Poly1305 MAC (16 bytes)
+//
+// libsodium leaves message boundaries to the application. The functions here use the
+// framing of libsodium's file encryption example: every message except the last
+// carries exactly chunkSize bytes of plaintext, and only the last carries the final tag.
+const (
+	// SecretStreamChunkSize is the default plaintext size of each secretstream message (4 KiB),
+	// the chunk size of libsodium's documented file encryption example.
+	SecretStreamChunkSize = 4096
+
+	// SecretStreamHeaderSize is the size of the secretstream header.
+	SecretStreamHeaderSize = 24
+
+	// SecretStreamOverhead is the number of bytes each secretstream message adds to its plaintext.
+	SecretStreamOverhead = 1 + poly1305.TagSize
+
+	secretStreamCounterSize = 4
+	secretStreamINonceSize  = 8
+)
+
+// Secretstream message tags.
+const (
+	secretStreamTagMessage byte = 0
+	secretStreamTagPush    byte = 1
+	secretStreamTagRekey   byte = 2
+	secretStreamTagFinal        = secretStreamTagPush | secretStreamTagRekey
+)
+
+// EncryptSecretStream encrypts everything read from r into a libsodium secretstream written to dst.
+//
+// The output can be decrypted with libsodium's crypto_secretstream_xchacha20poly1305_pull
+// (and its bindings for Node.js, Python, Rust and others) reading messages of
+// SecretStreamChunkSize + SecretStreamOverhead bytes. The plaintext is split into
+// SecretStreamChunkSize-byte messages and the last one, possibly empty, carries the
+// final tag, so truncation is detected on decryption.
+//
+// Parameters:
+//   - r: The reader providing the plaintext
+//   - key: The 32-byte key (must be exactly KeySize bytes)
+//   - dst: The writer receiving the secretstream
+//
+// Returns:
+//   - An error if encryption, reading or writing fails
+//
+// Example:
+//
+//	in, _ := os.Open("export.csv")
+//	out, _ := os.Create("export.csv.enc")
+//	if err := crypto.EncryptSecretStream(in, key, out); err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptSecretStream(r io.Reader, key []byte, dst io.Writer) error {
+	return EncryptSecretStreamWithChunkSize(r, key, dst, SecretStreamChunkSize)
+}
+
+// EncryptSecretStreamWithChunkSize is like EncryptSecretStream with a custom plaintext
+// chunk size, between 1 and MaxChunkSize, for peers that read other message sizes.
+//
+// Example:
+//
+//	err := crypto.EncryptSecretStreamWithChunkSize(in, key, out, 64*1024)
+func EncryptSecretStreamWithChunkSize(r io.Reader, key []byte, dst io.Writer, chunkSize int) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if err := checkSecretStreamChunkSize(chunkSize); err != nil {
+		return err
+	}
+	var header [SecretStreamHeaderSize]byte
+	if _, err := io.ReadFull(nonceReader(), header[:]); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate secretstream header")
+		return fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	s, err := newSecretStreamState(key, header[:])
+	if err != nil {
+		return err
+	}
+	defer s.zeroize()
+	if _, err := dst.Write(header[:]); err != nil {
+		return err
+	}
+
+	// Keep one chunk of lookahead: a chunk is final only if the reader is exhausted after it.
+	current := make([]byte, chunkSize)
+	next := make([]byte, chunkSize)
+	out := make([]byte, 0, chunkSize+SecretStreamOverhead)
+	defer Zeroize(current)
+	defer Zeroize(next)
+
+	n, err := readSecretStreamChunk(r, current)
+	if err != nil {
+		return err
+	}
+	for {
+		var m int
+		if n == chunkSize {
+			if m, err = readSecretStreamChunk(r, next); err != nil {
+				return err
+			}
+		}
+		final := n < chunkSize || m == 0
+		tag := secretStreamTagMessage
+		if final {
+			tag = secretStreamTagFinal
+		}
+		out, err = s.push(out[:0], current[:n], tag)
+		if err != nil {
+			return err
+		}
+		if _, err := dst.Write(out); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+		current, next, n = next, current, m
+	}
+}
+
+// DecryptSecretStream decrypts a libsodium secretstream read from r and writes the plaintext to dst.
+//
+// It reads streams produced by crypto_secretstream_xchacha20poly1305_push with messages
+// of SecretStreamChunkSize bytes of plaintext (SecretStreamChunkSize + SecretStreamOverhead
+// on the wire), the framing of libsodium's file encryption example. Rekey tags sent by
+// the peer are honoured. The stream must end with the final tag, and nothing may follow
+// it. As with DecryptStream, each message is written to dst as soon as it authenticates,
+// so consumers may observe a prefix of a stream that later turns out to be corrupt.
+//
+// Parameters:
+//   - r: The reader providing the secretstream
+//   - key: The 32-byte key (must be exactly KeySize bytes)
+//   - dst: The writer receiving the plaintext
+//
+// Returns:
+//   - An error if the stream is malformed or truncated (ErrInvalidStream), a message fails
+//     authentication (ErrDecrypt), or reading or writing fails
+//
+// Example:
+//
+//	in, _ := os.Open("export.csv.enc") // written by libsodium
+//	if err := crypto.DecryptSecretStream(in, key, os.Stdout); err != nil {
+//		log.Fatal(err)
+//	}
+func DecryptSecretStream(r io.Reader, key []byte, dst io.Writer) error {
+	return DecryptSecretStreamWithChunkSize(r, key, dst, SecretStreamChunkSize)
+}
+
+// DecryptSecretStreamWithChunkSize is like DecryptSecretStream for streams whose
+// messages carry chunkSize bytes of plaintext, between 1 and MaxChunkSize.
+//
+// Example:
+//
+//	err := crypto.DecryptSecretStreamWithChunkSize(in, key, out, 64*1024)
+func DecryptSecretStreamWithChunkSize(r io.Reader, key []byte, dst io.Writer, chunkSize int) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if err := checkSecretStreamChunkSize(chunkSize); err != nil {
+		return err
+	}
+	var header [SecretStreamHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return secretStreamError("stream too short for header")
+		}
+		return err
+	}
+	s, err := newSecretStreamState(key, header[:])
+	if err != nil {
+		return err
+	}
+	defer s.zeroize()
+
+	in := make([]byte, chunkSize+SecretStreamOverhead)
+	plaintext := make([]byte, 0, chunkSize)
+	defer Zeroize(plaintext[:cap(plaintext)])
+
+	for index := 0; ; index++ {
+		n, err := io.ReadFull(r, in)
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			if n < SecretStreamOverhead {
+				return secretStreamError(fmt.Sprintf("stream truncated at message %d", index))
+			}
+		default:
+			return err
+		}
+		var tag byte
+		plaintext, tag, err = s.pull(plaintext[:0], in[:n])
+		if err != nil {
+			richErr := goerrors.Wrap(err, ErrCodeDecrypt, fmt.Sprintf("failed to decrypt secretstream message %d", index))
+			return fmt.Errorf("%w: %w", ErrDecrypt, richErr)
+		}
+		if tag == secretStreamTagFinal {
+			var extra [1]byte
+			if m, _ := io.ReadFull(r, extra[:]); m > 0 {
+				return secretStreamError(fmt.Sprintf("data after final message %d", index))
+			}
+		} else if n < len(in) {
+			return secretStreamError(fmt.Sprintf("stream truncated: message %d is not final", index))
+		}
+		if _, err := dst.Write(plaintext); err != nil {
+			return err
+		}
+		if tag == secretStreamTagFinal {
+			return nil
+		}
+	}
+}
+
+// secretStreamState is the key schedule of a secretstream: the subkey and the
+// 12-byte ChaCha20 nonce, counter (little-endian) || inonce.
+type secretStreamState struct {
+	key   [KeySize]byte
+	nonce [secretStreamCounterSize + secretStreamINonceSize]byte
+}
+
+// newSecretStreamState derives the initial state from a key and a stream header.
+func newSecretStreamState(key, header []byte) (*secretStreamState, error) {
+	subkey, err := chacha20.HChaCha20(key, header[:16])
+	if err != nil {
+		return nil, goerrors.Wrap(err, ErrCodeCipherInit, "failed to derive secretstream key")
+	}
+	s := &secretStreamState{}
+	copy(s.key[:], subkey)
+	Zeroize(subkey)
+	s.resetCounter()
+	copy(s.nonce[secretStreamCounterSize:], header[16:])
+	return s, nil
+}
+
+// push encrypts one message with tag and appends it to dst.
+func (s *secretStreamState) push(dst, plaintext []byte, tag byte) ([]byte, error) {
+	mac, stream, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	var block [64]byte
+	block[0] = tag
+	stream.XORKeyStream(block[:], block[:])
+	mac.Write(block[:])
+
+	n := len(dst)
+	dst = append(dst, block[0])
+	dst = append(dst, plaintext...)
+	ciphertext := dst[n+1:]
+	stream.XORKeyStream(ciphertext, ciphertext)
+	tagMAC := s.finish(mac, ciphertext)
+	dst = append(dst, tagMAC[:]...)
+	s.advance(tagMAC[:], tag)
+	return dst, nil
+}
+
+// pull authenticates and decrypts one message, appending the plaintext to dst.
+func (s *secretStreamState) pull(dst, message []byte) ([]byte, byte, error) {
+	mac, stream, err := s.begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	var block [64]byte
+	block[0] = message[0]
+	stream.XORKeyStream(block[:], block[:])
+	tag := block[0]
+	block[0] = message[0]
+	mac.Write(block[:])
+
+	ciphertext := message[1 : len(message)-poly1305.TagSize]
+	expected := s.finish(mac, ciphertext)
+	if subtle.ConstantTimeCompare(expected[:], message[len(message)-poly1305.TagSize:]) != 1 {
+		return nil, 0, errors.New("message authentication failed")
+	}
+	if tag > secretStreamTagFinal {
+		return nil, 0, fmt.Errorf("unknown tag %d", tag)
+	}
+	n := len(dst)
+	dst = append(dst, ciphertext...)
+	stream.XORKeyStream(dst[n:], dst[n:])
+	s.advance(expected[:], tag)
+	return dst, tag, nil
+}
+
+// begin creates the message's Poly1305 instance from the first keystream block and
+// returns the cipher positioned at block 1.
+func (s *secretStreamState) begin() (*poly1305.MAC, *chacha20.Cipher, error) {
+	stream, err := chacha20.NewUnauthenticatedCipher(s.key[:], s.nonce[:])
+	if err != nil {
+		return nil, nil, goerrors.Wrap(err, ErrCodeCipherInit, "failed to create cipher")
+	}
+	var block [64]byte
+	stream.XORKeyStream(block[:], block[:])
+	var macKey [32]byte
+	copy(macKey[:], block[:32])
+	Zeroize(block[:])
+	mac := poly1305.New(&macKey)
+	Zeroize(macKey[:])
+	return mac, stream, nil
+}
+
+// finish authenticates the ciphertext and the lengths, as libsodium does, and returns the MAC.
+func (s *secretStreamState) finish(mac *poly1305.MAC, ciphertext []byte) [poly1305.TagSize]byte {
+	var pad [16]byte
+	mac.Write(ciphertext)
+	// libsodium pads with (0x10 - 64 + mlen) & 0xf bytes, which is reproduced exactly.
+	mac.Write(pad[:(0x10-64+len(ciphertext))&0xf])
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], 0) // no additional data
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(64+len(ciphertext)))
+	mac.Write(lengths[:])
+	var out [poly1305.TagSize]byte
+	mac.Sum(out[:0])
+	return out
+}
+
+// advance updates the state after a message: the MAC is folded into the inonce, the
+// counter is incremented, and the key is ratcheted on rekey tags or counter wraparound.
+func (s *secretStreamState) advance(mac []byte, tag byte) {
+	inonce := s.nonce[secretStreamCounterSize:]
+	subtle.XORBytes(inonce, inonce, mac[:secretStreamINonceSize])
+	counter := binary.LittleEndian.Uint32(s.nonce[:secretStreamCounterSize]) + 1
+	binary.LittleEndian.PutUint32(s.nonce[:secretStreamCounterSize], counter)
+	if tag&secretStreamTagRekey != 0 || counter == 0 {
+		s.rekey()
+	}
+}
+
+// rekey replaces the key and inonce with ChaCha20 output under the current state.
+func (s *secretStreamState) rekey() {
+	var next [KeySize + secretStreamINonceSize]byte
+	copy(next[:], s.key[:])
+	copy(next[KeySize:], s.nonce[secretStreamCounterSize:])
+	stream, err := chacha20.NewUnauthenticatedCipher(s.key[:], s.nonce[:])
+	if err != nil {
+		panic(err) // the key and nonce sizes are fixed
+	}
+	stream.XORKeyStream(next[:], next[:])
+	copy(s.key[:], next[:KeySize])
+	copy(s.nonce[secretStreamCounterSize:], next[KeySize:])
+	Zeroize(next[:])
+	s.resetCounter()
+}
+
+// resetCounter sets the message counter back to 1.
+func (s *secretStreamState) resetCounter() {
+	binary.LittleEndian.PutUint32(s.nonce[:secretStreamCounterSize], 1)
+}
+
+// zeroize wipes the key schedule.
+func (s *secretStreamState) zeroize() {
+	Zeroize(s.key[:])
+	Zeroize(s.nonce[:])
+}
+
+// readSecretStreamChunk reads up to len(buf) bytes, returning fewer only at the end of r.
+func readSecretStreamChunk(r io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, nil
+	}
+	return n, err
+}
+
+// checkSecretStreamChunkSize validates a secretstream chunk size.
+func checkSecretStreamChunkSize(chunkSize int) error {
+	if chunkSize <= 0 || chunkSize > MaxChunkSize {
+		return secretStreamError(fmt.Sprintf("chunk size must be between 1 and %d (got %d)", MaxChunkSize, chunkSize))
+	}
+	return nil
+}
+
+// secretStreamError builds an error wrapping ErrInvalidStream.
+func secretStreamError(msg string) error {
+	richErr := goerrors.New(ErrCodeInvalidStream, msg)
+	return fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+}
@@ -0,0 +1,115 @@
+// secretstream_test.go: Test cases for libsodium compatible secretstream encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+// libsodiumSecretStream was produced by libsodium 1.0.18 with key 00..1f, 16-byte
+// messages, a rekey tag on the second message and the final tag on the third.
+const libsodiumSecretStream = "fbf4882311146ad2cf52b4fde51df11419388e987cbcd8781338e25ff8ebdebcddac673ce318ab47a709e4cdcf7e4bc01cbbc2c4c6e52359f7d92f320980f683adc42f5509c5d92aca0567892e5f018e0a0c6539dfa7db40a0825d50dc9fcd93cc09ce04401da05f25a47a93b1f5737a3c7233f0d413"
+
+func secretStreamTestKey() []byte {
+	key := make([]byte, crypto.KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	return key
+}
+
+func TestDecryptSecretStream_Libsodium(t *testing.T) {
+	stream, _ := hex.DecodeString(libsodiumSecretStream)
+	var out bytes.Buffer
+	if err := crypto.DecryptSecretStreamWithChunkSize(bytes.NewReader(stream), secretStreamTestKey(), &out, 16); err != nil {
+		t.Fatalf("DecryptSecretStreamWithChunkSize() error: %v", err)
+	}
+	if out.String() != "The quick brown fox jumps over the lazy dog" {
+		t.Errorf("Unexpected plaintext %q", out.String())
+	}
+}
+
+func TestSecretStream_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, size := range []int{0, 1, 100, crypto.SecretStreamChunkSize, crypto.SecretStreamChunkSize + 1, 3*crypto.SecretStreamChunkSize + 7} {
+		plaintext := bytes.Repeat([]byte{0xa5}, size)
+		var enc bytes.Buffer
+		if err := crypto.EncryptSecretStream(bytes.NewReader(plaintext), key, &enc); err != nil {
+			t.Fatalf("EncryptSecretStream(%d) error: %v", size, err)
+		}
+		messages := size/crypto.SecretStreamChunkSize + 1
+		if size > 0 && size%crypto.SecretStreamChunkSize == 0 {
+			messages--
+		}
+		if want := crypto.SecretStreamHeaderSize + size + messages*crypto.SecretStreamOverhead; enc.Len() != want {
+			t.Errorf("size %d: stream length %d, want %d", size, enc.Len(), want)
+		}
+		var dec bytes.Buffer
+		if err := crypto.DecryptSecretStream(&enc, key, &dec); err != nil {
+			t.Fatalf("DecryptSecretStream(%d) error: %v", size, err)
+		}
+		if !bytes.Equal(dec.Bytes(), plaintext) {
+			t.Errorf("size %d: round-trip mismatch", size)
+		}
+	}
+}
+
+func TestDecryptSecretStream_Errors(t *testing.T) {
+	key := secretStreamTestKey()
+	stream, _ := hex.DecodeString(libsodiumSecretStream)
+	decrypt := func(data []byte) error {
+		return crypto.DecryptSecretStreamWithChunkSize(bytes.NewReader(data), key, &bytes.Buffer{}, 16)
+	}
+
+	if err := decrypt(stream[:10]); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for short header, got %v", err)
+	}
+	if err := decrypt(stream[:crypto.SecretStreamHeaderSize+16+crypto.SecretStreamOverhead]); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for a stream without the final message, got %v", err)
+	}
+	// A full-size final message ends the stream, so anything after it is trailing data.
+	var full bytes.Buffer
+	_ = crypto.EncryptSecretStreamWithChunkSize(bytes.NewReader(make([]byte, 16)), key, &full, 16)
+	if err := decrypt(append(full.Bytes(), 0)); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for trailing data, got %v", err)
+	}
+	if err := decrypt(append(append([]byte(nil), stream...), 0)); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for a byte appended to a short final message, got %v", err)
+	}
+	tampered := append([]byte(nil), stream...)
+	tampered[len(tampered)-20] ^= 1
+	if err := decrypt(tampered); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for a tampered message, got %v", err)
+	}
+	wrongKey, _ := crypto.GenerateKey()
+	if err := crypto.DecryptSecretStreamWithChunkSize(bytes.NewReader(stream), wrongKey, &bytes.Buffer{}, 16); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for the wrong key, got %v", err)
+	}
+	if err := crypto.DecryptSecretStream(bytes.NewReader(stream), key, &bytes.Buffer{}); !errors.Is(err, crypto.ErrInvalidStream) && !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected an error for the wrong chunk size, got %v", err)
+	}
+}
+
+func TestSecretStream_InvalidParameters(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	if err := crypto.EncryptSecretStream(bytes.NewReader(nil), key[:16], &bytes.Buffer{}); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	for _, size := range []int{0, -1, crypto.MaxChunkSize + 1} {
+		if err := crypto.EncryptSecretStreamWithChunkSize(bytes.NewReader(nil), key, &bytes.Buffer{}, size); !errors.Is(err, crypto.ErrInvalidStream) {
+			t.Errorf("Expected ErrInvalidStream for chunk size %d, got %v", size, err)
+		}
+		if err := crypto.DecryptSecretStreamWithChunkSize(bytes.NewReader(nil), key, &bytes.Buffer{}, size); !errors.Is(err, crypto.ErrInvalidStream) {
+			t.Errorf("Expected ErrInvalidStream for chunk size %d, got %v", size, err)
+		}
+	}
+}
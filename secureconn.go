@@ -0,0 +1,401 @@
+// secureconn.go: Encrypted framing over a net.Conn or other byte stream for peers sharing a key.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// Secure connection format.
+//
+// Both peers first send a hello = magic "AGCN" || version (1 byte) || random salt (32
+// bytes). Each direction then uses its own AES-256-GCM key, derived with HKDF-SHA256
+// from the shared key, both salts and the sending peer's salt, so the two directions and
+// every connection use independent keys. Data follows as frames:
+//
+//	frame = final flag (top bit) | ciphertext length (uint32, big-endian) || ciphertext || tag
+//
+// The nonce of each frame is an implicit 64-bit counter, and the frame header is
+// authenticated as additional data, so reordered, replayed, dropped or modified frames
+// are detected. Close sends an authenticated final frame, so truncation is detected too.
+const (
+	// SecureConnMaxFrame is the largest plaintext carried by a single SecureConn frame (64 KiB).
+	SecureConnMaxFrame = 64 * 1024
+
+	secureConnSaltSize   = 32
+	secureConnHelloSize  = 4 + 1 + secureConnSaltSize
+	secureConnVersion    = 1
+	secureConnFinalFlag  = 1 << 31
+	secureConnHeaderSize = 4
+
+	secureConnCloseTimeout = 5 * time.Second
+)
+
+// secureConnMagic identifies the secure connection hello.
+var secureConnMagic = [4]byte{'A', 'G', 'C', 'N'}
+
+// secureConnLabel is the HKDF info prefix for the per-direction keys.
+const secureConnLabel = "go-crypto/v1/secure-conn"
+
+// SecureConn is a net.Conn that encrypts and authenticates everything written to it.
+//
+// It provides a simple encrypted channel between two peers that already share a key,
+// without certificates or a TLS stack. It offers confidentiality, integrity, replay
+// and truncation protection within a connection, but no forward secrecy: anyone who
+// later obtains the shared key can decrypt recorded traffic. Prefer TLS when it is
+// available.
+//
+// Read and Write may be called concurrently with each other; concurrent Reads (or
+// Writes) are serialized. Deadlines apply to the underlying connection.
+type SecureConn struct {
+	conn net.Conn
+	s    *secureStream
+}
+
+// secureStream implements the secure connection protocol over any bidirectional byte
+// stream. It backs both SecureConn and NewSecureStream.
+type secureStream struct {
+	rwc io.ReadWriteCloser
+
+	rmu     sync.Mutex
+	recv    cipher.AEAD
+	rseq    uint64
+	rbuf    []byte
+	pending []byte
+	rerr    error
+
+	wmu    sync.Mutex
+	send   cipher.AEAD
+	wseq   uint64
+	wbuf   []byte
+	werr   error
+	closed bool
+}
+
+// NewSecureConn performs the handshake on conn and returns an encrypted connection.
+//
+// Both peers must call NewSecureConn with the same key; there are no client or server
+// roles. The handshake exchanges random salts, so each connection gets fresh keys even
+// though the shared key stays the same. It blocks until the peer's hello arrives; set a
+// deadline on conn to bound it. Authentication of the peer is implicit: with the wrong
+// key, the first Read on either side fails with ErrDecrypt.
+//
+// Parameters:
+//   - conn: The established connection, e.g. from net.Dial or Listener.Accept
+//   - key: The shared 32-byte key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The encrypted connection, which takes ownership of conn
+//   - An error if the key is invalid or the handshake fails
+//
+// Example:
+//
+//	raw, err := net.Dial("tcp", "peer:7000")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	conn, err := crypto.NewSecureConn(raw, sharedKey)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer conn.Close()
+//	fmt.Fprintln(conn, "hello over an encrypted channel")
+func NewSecureConn(conn net.Conn, key []byte) (*SecureConn, error) {
+	s, err := newSecureStream(conn, key)
+	if err != nil {
+		return nil, err
+	}
+	return &SecureConn{conn: conn, s: s}, nil
+}
+
+// NewSecureStream performs the secure connection handshake on rwc and returns a stream
+// that transparently encrypts writes and decrypts reads.
+//
+// It is NewSecureConn for bidirectional byte streams that are not a net.Conn, such as
+// a subprocess's stdin and stdout, a pair of os.Pipe ends, or a multiplexed stream. It
+// uses the same handshake and framing, so a NewSecureStream peer can talk to a
+// NewSecureConn peer; both ends must share the key. rwc must deliver data written by
+// the peer: the handshake blocks until the peer's hello arrives, so a plain file, which
+// only reads back its own contents, cannot be wrapped. Each Write is framed and sent
+// immediately. Close sends the authenticated final frame, so the peer can detect
+// truncation, and closes rwc; if rwc has a SetWriteDeadline method, sending the final
+// frame times out as with SecureConn.
+//
+// Parameters:
+//   - rwc: The bidirectional stream, connected to a peer
+//   - key: The shared 32-byte key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The encrypted stream, which takes ownership of rwc; its Read, Write and Close
+//     behave as SecureConn's
+//   - An error if the key is invalid or the handshake fails
+//
+// Example:
+//
+//	// rw joins a subprocess's stdout (for reading) and stdin (for writing).
+//	stream, err := crypto.NewSecureStream(rw, sharedKey)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer stream.Close()
+//	fmt.Fprintln(stream, "hello over an encrypted pipe")
+func NewSecureStream(rwc io.ReadWriteCloser, key []byte) (io.ReadWriteCloser, error) {
+	return newSecureStream(rwc, key)
+}
+
+// newSecureStream performs the handshake on rwc.
+func newSecureStream(rwc io.ReadWriteCloser, key []byte) (*secureStream, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	hello := make([]byte, secureConnHelloSize)
+	copy(hello, secureConnMagic[:])
+	hello[4] = secureConnVersion
+	if _, err := io.ReadFull(rand.Reader, hello[5:]); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate connection salt")
+		return nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+
+	// Write concurrently with reading, so synchronous transports such as net.Pipe
+	// do not deadlock with both peers writing first.
+	writeDone := make(chan error, 1)
+	go func() {
+		_, err := rwc.Write(hello)
+		writeDone <- err
+	}()
+	peer := make([]byte, secureConnHelloSize)
+	_, readErr := io.ReadFull(rwc, peer)
+	if writeErr := <-writeDone; writeErr != nil {
+		return nil, goerrors.Wrap(writeErr, "HANDSHAKE_ERROR", "failed to send secure connection hello")
+	}
+	if readErr != nil {
+		return nil, goerrors.Wrap(readErr, "HANDSHAKE_ERROR", "failed to read secure connection hello")
+	}
+	if !bytes.Equal(peer[:4], secureConnMagic[:]) || peer[4] != secureConnVersion {
+		return nil, secureConnError("peer did not send a valid secure connection hello")
+	}
+	mySalt, peerSalt := hello[5:], peer[5:]
+	if bytes.Equal(mySalt, peerSalt) {
+		// Only a peer reflecting our own hello produces equal salts.
+		return nil, secureConnError("peer echoed our hello")
+	}
+
+	salts := make([]byte, 0, 2*secureConnSaltSize)
+	if bytes.Compare(mySalt, peerSalt) < 0 {
+		salts = append(append(salts, mySalt...), peerSalt...)
+	} else {
+		salts = append(append(salts, peerSalt...), mySalt...)
+	}
+	send, err := secureConnAEAD(key, salts, mySalt)
+	if err != nil {
+		return nil, err
+	}
+	recv, err := secureConnAEAD(key, salts, peerSalt)
+	if err != nil {
+		return nil, err
+	}
+	return &secureStream{rwc: rwc, send: send, recv: recv}, nil
+}
+
+// secureConnAEAD derives the AEAD for the direction whose sender chose senderSalt.
+func secureConnAEAD(key, salts, senderSalt []byte) (cipher.AEAD, error) {
+	subkey, err := deriveSubkey(key, salts, secureConnLabel+string(senderSalt), KeySize)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to derive connection key")
+	}
+	defer Zeroize(subkey)
+	return newGCM(subkey)
+}
+
+// Write encrypts p and sends it in one or more frames.
+//
+// Returns:
+//   - The number of plaintext bytes sent
+//   - An error if the connection is closed or the underlying write fails; after a
+//     write error the connection cannot send any more data
+func (c *SecureConn) Write(p []byte) (int, error) { return c.s.Write(p) }
+
+// Read reads and decrypts data from the connection.
+//
+// Frames are reassembled across partial reads of the underlying connection, and data
+// from one frame may be returned over several calls.
+//
+// Returns:
+//   - The number of bytes read
+//   - io.EOF after the peer closed the connection with Close
+//   - An error wrapping ErrDecrypt if a frame fails authentication, or ErrInvalidStream
+//     if a frame is malformed or the connection ends without a final frame. Read errors,
+//     including deadline timeouts, leave the framing undefined and are permanent
+func (c *SecureConn) Read(p []byte) (int, error) { return c.s.Read(p) }
+
+// Close sends the final frame, so the peer can tell a clean close from truncation,
+// and closes the underlying connection. Sending the final frame times out after five
+// seconds if the peer does not read it.
+func (c *SecureConn) Close() error { return c.s.Close() }
+
+// Write encrypts p and sends it in frames of at most SecureConnMaxFrame bytes.
+func (c *secureStream) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.werr != nil {
+		return 0, c.werr
+	}
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	n := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), SecureConnMaxFrame)]
+		if err := c.writeFrame(chunk, false); err != nil {
+			c.werr = err
+			return n, err
+		}
+		n += len(chunk)
+		p = p[len(chunk):]
+	}
+	return n, nil
+}
+
+// writeFrame seals plaintext into a frame and writes it.
+func (c *secureStream) writeFrame(plaintext []byte, final bool) error {
+	if c.wseq == math.MaxUint64 {
+		return secureConnError("frame counter exhausted")
+	}
+	size := len(plaintext) + c.send.Overhead()
+	header := uint32(size)
+	if final {
+		header |= secureConnFinalFlag
+	}
+	c.wbuf = binary.BigEndian.AppendUint32(c.wbuf[:0], header)
+	c.wbuf = c.send.Seal(c.wbuf, secureConnNonce(c.wseq), plaintext, c.wbuf[:secureConnHeaderSize])
+	c.wseq++
+	if _, err := c.rwc.Write(c.wbuf); err != nil {
+		return goerrors.Wrap(err, "WRITE_ERROR", "failed to write frame")
+	}
+	return nil
+}
+
+// Read returns decrypted data, reading and authenticating frames as needed.
+func (c *secureStream) Read(p []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	for len(c.pending) == 0 {
+		if c.rerr != nil {
+			return 0, c.rerr
+		}
+		if len(p) == 0 {
+			return 0, nil
+		}
+		c.rerr = c.readFrame()
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// readFrame reads, authenticates and decrypts the next frame into pending.
+// It returns io.EOF after the final frame.
+func (c *secureStream) readFrame() error {
+	var hdr [secureConnHeaderSize]byte
+	if _, err := io.ReadFull(c.rwc, hdr[:]); err != nil {
+		return secureConnReadError(err)
+	}
+	header := binary.BigEndian.Uint32(hdr[:])
+	final := header&secureConnFinalFlag != 0
+	size := int(header &^ secureConnFinalFlag)
+	if size < c.recv.Overhead() || size > SecureConnMaxFrame+c.recv.Overhead() {
+		return secureConnError(fmt.Sprintf("invalid frame length %d", size))
+	}
+	if cap(c.rbuf) < size {
+		c.rbuf = make([]byte, size)
+	}
+	frame := c.rbuf[:size]
+	if _, err := io.ReadFull(c.rwc, frame); err != nil {
+		return secureConnReadError(err)
+	}
+	if c.rseq == math.MaxUint64 {
+		return secureConnError("frame counter exhausted")
+	}
+	plaintext, err := c.recv.Open(frame[:0], secureConnNonce(c.rseq), frame, hdr[:])
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeDecrypt, fmt.Sprintf("frame %d failed authentication", c.rseq))
+		return fmt.Errorf("%w: %w", ErrDecrypt, richErr)
+	}
+	c.rseq++
+	c.pending = plaintext
+	if final {
+		return io.EOF
+	}
+	return nil
+}
+
+// Close sends the final frame and closes the underlying stream.
+func (c *secureStream) Close() error {
+	c.wmu.Lock()
+	var finalErr error
+	if !c.closed && c.werr == nil {
+		// Like TLS close_notify, do not let an unresponsive peer block Close forever.
+		if d, ok := c.rwc.(interface{ SetWriteDeadline(time.Time) error }); ok {
+			_ = d.SetWriteDeadline(time.Now().Add(secureConnCloseTimeout))
+		}
+		finalErr = c.writeFrame(nil, true)
+	}
+	c.closed = true
+	c.wmu.Unlock()
+	if err := c.rwc.Close(); err != nil {
+		return err
+	}
+	return finalErr
+}
+
+// LocalAddr returns the local network address of the underlying connection.
+func (c *SecureConn) LocalAddr() net.Addr { return c.conn.LocalAddr() }
+
+// RemoteAddr returns the remote network address of the underlying connection.
+func (c *SecureConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// SetDeadline sets the read and write deadlines of the underlying connection.
+func (c *SecureConn) SetDeadline(t time.Time) error { return c.conn.SetDeadline(t) }
+
+// SetReadDeadline sets the read deadline of the underlying connection.
+func (c *SecureConn) SetReadDeadline(t time.Time) error { return c.conn.SetReadDeadline(t) }
+
+// SetWriteDeadline sets the write deadline of the underlying connection.
+func (c *SecureConn) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
+
+// secureConnNonce returns the GCM nonce for frame number seq: 4 zero bytes || seq.
+func secureConnNonce(seq uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], seq)
+	return nonce
+}
+
+// secureConnReadError maps a read failure of the underlying stream. A connection
+// that ends without a final frame is reported as truncated.
+func secureConnReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return secureConnError("connection closed without a final frame")
+	}
+	return goerrors.Wrap(err, "READ_ERROR", "failed to read frame")
+}
+
+// secureConnError builds an error wrapping ErrInvalidStream.
+func secureConnError(msg string) error {
+	richErr := goerrors.New(ErrCodeInvalidStream, msg)
+	return fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+}
@@ -0,0 +1,301 @@
+// secureconn_test.go: Test cases for encrypted connections.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+// securePair returns two connected SecureConns built from a net.Pipe.
+func securePair(t *testing.T, keyA, keyB []byte) (*crypto.SecureConn, *crypto.SecureConn) {
+	t.Helper()
+	rawA, rawB := net.Pipe()
+	type result struct {
+		conn *crypto.SecureConn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		c, err := crypto.NewSecureConn(rawB, keyB)
+		done <- result{c, err}
+	}()
+	a, err := crypto.NewSecureConn(rawA, keyA)
+	if err != nil {
+		t.Fatalf("NewSecureConn() error: %v", err)
+	}
+	r := <-done
+	if r.err != nil {
+		t.Fatalf("NewSecureConn() peer error: %v", r.err)
+	}
+	t.Cleanup(func() {
+		_ = rawA.Close()
+		_ = rawB.Close()
+	})
+	return a, r.conn
+}
+
+func TestSecureConn_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	a, b := securePair(t, key, key)
+
+	// A message larger than one frame, in both directions at once.
+	msgA := bytes.Repeat([]byte("a->b "), 3*crypto.SecureConnMaxFrame/5+7)
+	msgB := []byte("b->a")
+	errs := make(chan error, 2)
+	go func() {
+		_, err := a.Write(msgA)
+		buf := make([]byte, len(msgB))
+		if err == nil {
+			_, err = io.ReadFull(a, buf)
+		}
+		if err == nil && !bytes.Equal(buf, msgB) {
+			err = errors.New("reverse direction mismatch")
+		}
+		if err == nil {
+			err = a.Close()
+		}
+		errs <- err
+	}()
+	go func() {
+		_, err := b.Write(msgB)
+		errs <- err
+	}()
+
+	got, err := io.ReadAll(b)
+	if err != nil {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	if !bytes.Equal(got, msgA) {
+		t.Errorf("received %d bytes, want %d", len(got), len(msgA))
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("writer error: %v", err)
+		}
+	}
+}
+
+func TestSecureConn_SmallReads(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	a, b := securePair(t, key, key)
+	go func() {
+		_, _ = a.Write([]byte("hello, "))
+		_, _ = a.Write([]byte("world"))
+		_ = a.Close()
+	}()
+	var got []byte
+	buf := make([]byte, 3)
+	for {
+		n, err := b.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read() error: %v", err)
+		}
+	}
+	if string(got) != "hello, world" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSecureConn_WrongKey(t *testing.T) {
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	a, b := securePair(t, keyA, keyB)
+	go func() { _, _ = a.Write([]byte("secret")) }()
+	if _, err := b.Read(make([]byte, 16)); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Read() with wrong key: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestSecureConn_Truncation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	rawA, rawB := net.Pipe()
+	done := make(chan *crypto.SecureConn, 1)
+	go func() {
+		c, _ := crypto.NewSecureConn(rawB, key)
+		done <- c
+	}()
+	a, err := crypto.NewSecureConn(rawA, key)
+	if err != nil {
+		t.Fatalf("NewSecureConn() error: %v", err)
+	}
+	b := <-done
+	go func() {
+		_, _ = a.Write([]byte("partial"))
+		_ = rawA.Close() // close without the final frame
+	}()
+	got, err := io.ReadAll(b)
+	if !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("ReadAll() after truncation: got %v, want ErrInvalidStream", err)
+	}
+	if string(got) != "partial" {
+		t.Errorf("got %q before truncation", got)
+	}
+}
+
+func TestSecureConn_TamperedFrame(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	rawA, rawB := net.Pipe()
+	// Interpose a relay that flips a bit in the first data frame.
+	relayA, relayB := net.Pipe()
+	go func() {
+		buf := make([]byte, 4096)
+		first := true
+		for {
+			n, err := rawB.Read(buf)
+			if n > 0 {
+				// The first read carries the 37-byte hello; tamper with the next one.
+				if !first {
+					buf[n-1] ^= 1
+				}
+				first = false
+				if _, werr := relayA.Write(buf[:n]); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				_ = relayA.Close()
+				return
+			}
+		}
+	}()
+	go func() { _, _ = io.Copy(rawB, relayA) }()
+
+	done := make(chan *crypto.SecureConn, 1)
+	go func() {
+		c, _ := crypto.NewSecureConn(relayB, key)
+		done <- c
+	}()
+	a, err := crypto.NewSecureConn(rawA, key)
+	if err != nil {
+		t.Fatalf("NewSecureConn() error: %v", err)
+	}
+	b := <-done
+	go func() { _, _ = a.Write([]byte("tamper me")) }()
+	if _, err := b.Read(make([]byte, 16)); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Read() of tampered frame: got %v, want ErrDecrypt", err)
+	}
+	_ = a.Close()
+	_ = b.Close()
+}
+
+func TestNewSecureConn_Errors(t *testing.T) {
+	a, b := net.Pipe()
+	defer func() { _ = a.Close() }()
+	if _, err := crypto.NewSecureConn(a, make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+
+	// A peer that is not speaking the protocol.
+	go func() {
+		_, _ = b.Write(bytes.Repeat([]byte{'x'}, 37))
+		_, _ = io.Copy(io.Discard, b)
+	}()
+	key, _ := crypto.GenerateKey()
+	if _, err := crypto.NewSecureConn(a, key); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("bad hello: got %v, want ErrInvalidStream", err)
+	}
+}
+
+func TestSecureConn_WriteAfterClose(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	a, b := securePair(t, key, key)
+	go func() { _, _ = io.Copy(io.Discard, b) }()
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if _, err := a.Write([]byte("late")); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Write() after Close: got %v, want net.ErrClosed", err)
+	}
+}
+
+// pipeStream joins the read end of one io.Pipe and the write end of another.
+type pipeStream struct {
+	*io.PipeReader
+	*io.PipeWriter
+}
+
+func (p pipeStream) Close() error {
+	_ = p.PipeReader.Close()
+	return p.PipeWriter.Close()
+}
+
+func TestNewSecureStream_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	r1, w1 := io.Pipe()
+	r2, w2 := io.Pipe()
+	type result struct {
+		stream io.ReadWriteCloser
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		s, err := crypto.NewSecureStream(pipeStream{r2, w1}, key)
+		done <- result{s, err}
+	}()
+	a, err := crypto.NewSecureStream(pipeStream{r1, w2}, key)
+	if err != nil {
+		t.Fatalf("NewSecureStream() error: %v", err)
+	}
+	peer := <-done
+	if peer.err != nil {
+		t.Fatalf("NewSecureStream() peer error: %v", peer.err)
+	}
+	b := peer.stream
+
+	message := bytes.Repeat([]byte("stream data "), 10000) // several frames
+	go func() {
+		_, _ = a.Write(message)
+		_ = a.Close()
+	}()
+	got, err := io.ReadAll(b)
+	if err != nil {
+		t.Fatalf("ReadAll() error: %v", err)
+	}
+	if !bytes.Equal(got, message) {
+		t.Errorf("Received %d bytes, want %d", len(got), len(message))
+	}
+	_ = b.Close()
+}
+
+func TestNewSecureStream_InteropWithSecureConn(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	rawA, rawB := net.Pipe()
+	defer func() { _ = rawA.Close() }()
+	done := make(chan error, 1)
+	go func() {
+		c, err := crypto.NewSecureConn(rawB, key)
+		if err == nil {
+			_, err = c.Write([]byte("from conn"))
+			_ = c.Close()
+		}
+		done <- err
+	}()
+	s, err := crypto.NewSecureStream(rawA, key)
+	if err != nil {
+		t.Fatalf("NewSecureStream() error: %v", err)
+	}
+	got, err := io.ReadAll(s)
+	if err != nil || string(got) != "from conn" {
+		t.Errorf("ReadAll() = %q, %v", got, err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("SecureConn peer error: %v", err)
+	}
+	if _, err := crypto.NewSecureStream(rawA, make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+}
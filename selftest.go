@@ -0,0 +1,229 @@
+// selftest.go: Known-answer self-tests for startup validation of the cryptographic primitives.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+	"golang.org/x/crypto/argon2"
+	pbkdf2 "golang.org/x/crypto/pbkdf2"
+)
+
+// ErrSelfTest is returned when a known-answer self-test produces an unexpected result.
+var ErrSelfTest = errors.New("crypto: self-test failed")
+
+// ErrCodeSelfTest is the error code for self-test failures.
+const ErrCodeSelfTest = "CRYPTO_SELF_TEST"
+
+// SelfTest runs known-answer tests (KATs) against the primitives used by this package.
+//
+// Each primitive is exercised with a hardcoded input and its output compared with a
+// published reference value:
+//   - AES-256-GCM: NIST GCM specification, test case 14 (encryption and decryption)
+//   - Argon2id: reference implementation vector (password "password", salt "somesalt")
+//   - PBKDF2-SHA256: widely published vector (password "password", salt "salt", 4096 iterations)
+//   - HMAC-SHA256: RFC 4231, test case 2
+//
+// Call SelfTest once at startup, before processing real data, to confirm that the
+// library behaves correctly in the target environment (compiler, architecture,
+// assembly implementations). It is a standard requirement for security-certified
+// deployments.
+//
+// Returns:
+//   - nil if every known-answer test passes
+//   - An error wrapping ErrSelfTest that names the failing primitive otherwise
+//
+// Example:
+//
+//	if err := crypto.SelfTest(); err != nil {
+//		log.Fatal("crypto self-test failed: ", err)
+//	}
+func SelfTest() error {
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"AES-256-GCM", selfTestAESGCM},
+		{"Argon2id", selfTestArgon2id},
+		{"PBKDF2-SHA256", selfTestPBKDF2},
+		{"HMAC-SHA256", selfTestHMAC},
+	}
+	for _, tc := range tests {
+		if err := tc.run(); err != nil {
+			richErr := goerrors.Wrap(err, ErrCodeSelfTest, fmt.Sprintf("%s known-answer test failed", tc.name))
+			return fmt.Errorf("%w: %w", ErrSelfTest, richErr)
+		}
+	}
+	return nil
+}
+
+// errKATMismatch is reported when a primitive's output differs from the expected value.
+var errKATMismatch = errors.New("output does not match known answer")
+
+// mustHex decodes a hardcoded hexadecimal constant.
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("crypto: invalid hex constant: " + err.Error())
+	}
+	return b
+}
+
+// selfTestAESGCM checks AES-256-GCM against NIST GCM test case 14.
+func selfTestAESGCM() error {
+	key := make([]byte, 32)
+	nonce := make([]byte, 12)
+	plaintext := make([]byte, 16)
+	expected := mustHex("cea7403d4d606b6e074ec5d3baf39d18" + "d0d1c8a799996bf0265b98b5d48ab919")
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	sealed := gcm.Seal(nil, nonce, plaintext, nil)
+	if !bytes.Equal(sealed, expected) {
+		return errKATMismatch
+	}
+	opened, err := gcm.Open(nil, nonce, expected, nil)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(opened, plaintext) {
+		return errKATMismatch
+	}
+	return nil
+}
+
+// selfTestArgon2id checks Argon2id against the reference implementation vector
+// (t=2, m=64 KiB, p=2, 24-byte output).
+func selfTestArgon2id() error {
+	expected := mustHex("350ac37222f436ccb5c0972f1ebd3bf6b958bf2071841362")
+	out := argon2.IDKey([]byte("password"), []byte("somesalt"), 2, 64, 2, uint32(len(expected)))
+	if !bytes.Equal(out, expected) {
+		return errKATMismatch
+	}
+	return nil
+}
+
+// selfTestPBKDF2 checks PBKDF2-HMAC-SHA256 with 4096 iterations.
+func selfTestPBKDF2() error {
+	expected := mustHex("c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a")
+	out := pbkdf2.Key([]byte("password"), []byte("salt"), 4096, len(expected), sha256.New)
+	if !bytes.Equal(out, expected) {
+		return errKATMismatch
+	}
+	return nil
+}
+
+// selfTestHMAC checks HMAC-SHA256 against RFC 4231, test case 2.
+func selfTestHMAC() error {
+	expected := mustHex("5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843")
+	mac := hmac.New(sha256.New, []byte("Jefe"))
+	mac.Write([]byte("what do ya want for nothing?"))
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errKATMismatch
+	}
+	return nil
+}
+
+// argon2StabilityPassword is the password of the Argon2id stability vectors.
+const argon2StabilityPassword = "correct horse battery staple"
+
+// VerifyArgon2Stability checks that Argon2id, as used for stored password hashes and
+// derived keys, still produces the outputs it produced when the vectors were recorded.
+//
+// Stored hashes and password-derived keys only keep verifying if Argon2id output never
+// changes, so an upstream behavior change in golang.org/x/crypto/argon2 would silently
+// invalidate every stored hash. VerifyArgon2Stability catches that loudly. Beyond the
+// small reference vector run by SelfTest, it checks:
+//   - DeriveKey at the default cost (t=3, 64 MiB, 4 lanes, 32 bytes), recorded with
+//     golang.org/x/crypto v0.41.0
+//   - Argon2id at t=3, 64 MiB, 1 lane, 32 bytes, as computed by libsodium
+//   - VerifyPassword on a PHC string produced by libsodium's crypto_pwhash_str
+//     (m=19456, t=2, p=1), which also covers PHC parsing
+//
+// It is not run at init time because it costs about three password hashes (roughly
+// 150 MiB of memory passes). Call it at startup or in CI after upgrading dependencies.
+//
+// Returns:
+//   - nil if every vector matches
+//   - An error wrapping ErrSelfTest that names the failing vector otherwise
+//
+// Example:
+//
+//	if err := crypto.VerifyArgon2Stability(); err != nil {
+//		log.Fatal("argon2 output changed; stored password hashes would not verify: ", err)
+//	}
+func VerifyArgon2Stability() error {
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"Argon2id reference", selfTestArgon2id},
+		{"DeriveKey 4 lanes", selfTestArgon2Lanes},
+		{"Argon2id libsodium", selfTestArgon2Libsodium},
+		{"Argon2id PHC", selfTestArgon2PHC},
+	}
+	for _, tc := range tests {
+		if err := tc.run(); err != nil {
+			richErr := goerrors.Wrap(err, ErrCodeSelfTest, fmt.Sprintf("%s stability vector failed", tc.name))
+			return fmt.Errorf("%w: %w", ErrSelfTest, richErr)
+		}
+	}
+	return nil
+}
+
+// selfTestArgon2Lanes checks DeriveKey at the default cost, with four lanes.
+func selfTestArgon2Lanes() error {
+	expected := mustHex("48df864d1e9c24e08ef3fd63ebd89ad5cb346a0385cbc607a5a825b1454f0094")
+	out, err := DeriveKey([]byte(argon2StabilityPassword), []byte("go-crypto/argon2"), KeySize, &KDFParams{Time: 3, Memory: 64, Threads: 4})
+	if err != nil {
+		return err
+	}
+	defer Zeroize(out)
+	if !bytes.Equal(out, expected) {
+		return errKATMismatch
+	}
+	return nil
+}
+
+// selfTestArgon2Libsodium checks Argon2id against libsodium's crypto_pwhash
+// (opslimit 3, memlimit 64 MiB).
+func selfTestArgon2Libsodium() error {
+	expected := mustHex("f31c545870ea9b749ac5e14c6dde976aef5078423cdaa81c1e2979b5b656b89b")
+	out := argon2.IDKey([]byte(argon2StabilityPassword), []byte("go-crypto/argon2"), 3, 64*1024, 1, KeySize)
+	defer Zeroize(out)
+	if !bytes.Equal(out, expected) {
+		return errKATMismatch
+	}
+	return nil
+}
+
+// selfTestArgon2PHC verifies a PHC string produced by libsodium's crypto_pwhash_str.
+func selfTestArgon2PHC() error {
+	const encoded = "$argon2id$v=19$m=19456,t=2,p=1$A8CYD9qjOJygwqh7/4ThYQ$8jzTS3iFXJzcZ6kDhUVzpLhnXb84D/IK1ZAOAkgmYWE"
+	ok, err := VerifyPassword([]byte(argon2StabilityPassword), encoded)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errKATMismatch
+	}
+	return nil
+}
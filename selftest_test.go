@@ -0,0 +1,28 @@
+// selftest_test.go: Test cases for known-answer self-tests.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestSelfTest_Passes(t *testing.T) {
+	if err := crypto.SelfTest(); err != nil {
+		t.Fatalf("SelfTest() error: %v", err)
+	}
+}
+
+func TestVerifyArgon2Stability(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs several 64 MiB Argon2id derivations")
+	}
+	if err := crypto.VerifyArgon2Stability(); err != nil {
+		t.Fatalf("VerifyArgon2Stability() error: %v", err)
+	}
+}
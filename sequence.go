@@ -0,0 +1,106 @@
+// sequence.go: Encryption bound to a monotonically increasing sequence number for replay protection.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// sequenceHeaderSize is the size of the big-endian sequence number prefix.
+const sequenceHeaderSize = 8
+
+// sequenceLabel domain-separates sequence envelopes from other authenticated headers.
+const sequenceLabel = "go-crypto/v1/sequence"
+
+// ErrReplay is returned when a message's sequence number is not greater than the last one seen.
+var ErrReplay = errors.New("crypto: replayed or out-of-order sequence number")
+
+// ErrCodeReplay is the error code for replay detection failures.
+const ErrCodeReplay = "CRYPTO_REPLAY"
+
+// EncryptWithSequence encrypts plaintext and binds it to a sequence number.
+//
+// The sequence number is stored in clear in front of the ciphertext and authenticated
+// as additional data, so it cannot be altered without the decryption failing. Producers
+// should use a strictly increasing counter per key (e.g. a message queue offset),
+// starting at 1 so that a consumer with no history can pass lastSeen = 0.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - seq: The sequence number to bind to the message
+//
+// Returns:
+//   - A base64-encoded string containing the sequence number, nonce, ciphertext and tag
+//   - An error if encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptWithSequence([]byte("order #42"), key, 42)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptWithSequence(plaintext, key []byte, seq uint64) (string, error) {
+	var header [sequenceHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:], seq)
+	out, err := sealWithHeader(key, sequenceLabel, header[:], plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// DecryptWithSequence decrypts a ciphertext produced by EncryptWithSequence and
+// rejects replays.
+//
+// The message is authenticated first; only then is its sequence number compared
+// with lastSeen. If the sequence number is not strictly greater than lastSeen the
+// function returns an error wrapping ErrReplay. Consumers should persist the returned
+// sequence number and pass it as lastSeen for the next message.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext returned by EncryptWithSequence
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//   - lastSeen: The highest sequence number accepted so far
+//
+// Returns:
+//   - The decrypted plaintext (nil on error)
+//   - The authenticated sequence number of the message (0 if authentication failed)
+//   - An error if decryption fails or the message is a replay
+//
+// Example:
+//
+//	plaintext, seq, err := crypto.DecryptWithSequence(ciphertext, key, lastSeen)
+//	if errors.Is(err, crypto.ErrReplay) {
+//		// drop the duplicate
+//	}
+//	lastSeen = seq
+func DecryptWithSequence(ciphertext string, key []byte, lastSeen uint64) ([]byte, uint64, error) {
+	if err := checkKey(key); err != nil {
+		return nil, 0, err
+	}
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, 0, err
+	}
+	header, plaintext, err := openWithHeader(key, sequenceLabel, data, sequenceHeaderSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	seq := binary.BigEndian.Uint64(header)
+	if seq <= lastSeen {
+		Zeroize(plaintext)
+		richErr := goerrors.New(ErrCodeReplay, fmt.Sprintf("sequence number %d is not greater than last seen %d", seq, lastSeen))
+		return nil, seq, fmt.Errorf("%w: %w", ErrReplay, richErr)
+	}
+	return plaintext, seq, nil
+}
@@ -0,0 +1,88 @@
+// sequence_test.go: Test cases for sequence-bound encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptWithSequence_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, err := crypto.EncryptWithSequence([]byte("message"), key, 7)
+	if err != nil {
+		t.Fatalf("EncryptWithSequence() error: %v", err)
+	}
+	plaintext, seq, err := crypto.DecryptWithSequence(ciphertext, key, 6)
+	if err != nil {
+		t.Fatalf("DecryptWithSequence() error: %v", err)
+	}
+	if string(plaintext) != "message" {
+		t.Errorf("Expected plaintext %q, got %q", "message", plaintext)
+	}
+	if seq != 7 {
+		t.Errorf("Expected sequence 7, got %d", seq)
+	}
+}
+
+func TestDecryptWithSequence_Replay(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptWithSequence([]byte("message"), key, 7)
+
+	for _, lastSeen := range []uint64{7, 8, 1 << 63} {
+		plaintext, seq, err := crypto.DecryptWithSequence(ciphertext, key, lastSeen)
+		if !errors.Is(err, crypto.ErrReplay) {
+			t.Errorf("Expected ErrReplay for lastSeen %d, got %v", lastSeen, err)
+		}
+		if plaintext != nil {
+			t.Error("Expected nil plaintext on replay")
+		}
+		if seq != 7 {
+			t.Errorf("Expected reported sequence 7, got %d", seq)
+		}
+	}
+}
+
+func TestDecryptWithSequence_TamperedSequence(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptWithSequence([]byte("message"), key, 7)
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+	binary.BigEndian.PutUint64(raw[:8], 100)
+
+	_, _, err := crypto.DecryptWithSequence(base64.StdEncoding.EncodeToString(raw), key, 0)
+	if !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for modified sequence number, got %v", err)
+	}
+}
+
+func TestDecryptWithSequence_RejectsPlainCiphertext(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytes([]byte("message"), key)
+	if _, _, err := crypto.DecryptWithSequence(ciphertext, key, 0); err == nil {
+		t.Error("Expected error when decrypting a ciphertext without sequence binding")
+	}
+}
+
+func TestSequence_InvalidInputs(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	if _, err := crypto.EncryptWithSequence([]byte("m"), make([]byte, 16), 1); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	if _, _, err := crypto.DecryptWithSequence("", key, 0); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("Expected ErrEmptyPlaintext, got %v", err)
+	}
+	if _, _, err := crypto.DecryptWithSequence("%%%", key, 0); !errors.Is(err, crypto.ErrBase64Decode) {
+		t.Errorf("Expected ErrBase64Decode, got %v", err)
+	}
+	if _, _, err := crypto.DecryptWithSequence("AAAA", key, 0); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+// sign.go: Ed25519 signed encryption and self-signed key certificates.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// ErrInvalidSignature is returned when the signature of a signed ciphertext does not verify.
+var ErrInvalidSignature = errors.New("crypto: signature verification failed")
+
+// ErrCodeInvalidSignature is the error code for failed signature verification.
+const ErrCodeInvalidSignature = "CRYPTO_INVALID_SIGNATURE"
+
+// ErrInvalidCertificate is returned when a key certificate is malformed.
+var ErrInvalidCertificate = errors.New("crypto: invalid key certificate")
+
+// ErrCodeInvalidCertificate is the error code for malformed key certificates.
+const ErrCodeInvalidCertificate = "CRYPTO_INVALID_CERTIFICATE"
+
+// Signed envelope format:
+//
+//	signer public key (32 bytes) || nonce || ciphertext || tag || Ed25519 signature (64 bytes)
+//
+// The signer's public key is authenticated as additional data of the AES-256-GCM
+// envelope, and the signature covers signedLabel || everything before it.
+const signedLabel = "go-crypto/v1/signed"
+
+// EncryptAndSign encrypts plaintext with encKey and signs the ciphertext with an
+// Ed25519 private key, so that a recipient can verify who produced it.
+//
+// The symmetric key alone proves only that the sender holds encKey, which every
+// recipient of a shared key does. The signature adds sender authentication. Signing the
+// ciphertext (encrypt-then-sign) lets DecryptAndVerify reject forgeries without
+// decrypting them. On its own, encrypt-then-sign would let anyone strip the signature
+// and re-sign the ciphertext as their own; to prevent this, the signer's public key is
+// also bound into the encryption, so a re-signed ciphertext fails authentication under
+// encKey. Note that the signature is verifiable by anyone with the public key, so it
+// proves to third parties that the signer produced this ciphertext.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - encKey: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - signingPriv: The signer's Ed25519 private key (ed25519.PrivateKeySize bytes)
+//
+// Returns:
+//   - A base64-encoded string containing the signer key, the envelope and the signature
+//   - An error if a key is invalid or encryption fails
+//
+// Example:
+//
+//	pub, priv, _ := ed25519.GenerateKey(nil)
+//	blob, err := crypto.EncryptAndSign(report, key, priv)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptAndSign(plaintext, encKey, signingPriv []byte) (string, error) {
+	if err := checkKey(encKey); err != nil {
+		return "", err
+	}
+	if len(signingPriv) != ed25519.PrivateKeySize {
+		richErr := goerrors.New(ErrCodeInvalidKey, fmt.Sprintf("invalid Ed25519 private key size: must be %d bytes (got %d)", ed25519.PrivateKeySize, len(signingPriv)))
+		return "", fmt.Errorf("%w: %w", ErrInvalidKeySize, richErr)
+	}
+	priv := ed25519.PrivateKey(signingPriv)
+	pub := priv.Public().(ed25519.PublicKey)
+	out, err := sealWithHeader(encKey, signedLabel, pub, plaintext)
+	if err != nil {
+		return "", err
+	}
+	out = append(out, ed25519.Sign(priv, signedMessage(out))...)
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// DecryptAndVerify verifies the signature of a ciphertext produced by EncryptAndSign
+// against signingPub and, only if it verifies, decrypts it.
+//
+// Parameters:
+//   - blob: The base64-encoded signed ciphertext
+//   - encKey: The 32-byte decryption key (must be exactly KeySize bytes)
+//   - signingPub: The expected signer's Ed25519 public key (ed25519.PublicKeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error wrapping ErrInvalidSignature if the blob was not signed by signingPub or
+//     was modified, or ErrDecrypt if it fails to decrypt under encKey
+//
+// Example:
+//
+//	report, err := crypto.DecryptAndVerify(blob, key, senderPub)
+//	if errors.Is(err, crypto.ErrInvalidSignature) {
+//		// not produced by the expected sender
+//	}
+func DecryptAndVerify(blob string, encKey, signingPub []byte) ([]byte, error) {
+	if err := checkKey(encKey); err != nil {
+		return nil, err
+	}
+	if len(signingPub) != ed25519.PublicKeySize {
+		richErr := goerrors.New(ErrCodeInvalidKey, fmt.Sprintf("invalid Ed25519 public key size: must be %d bytes (got %d)", ed25519.PublicKeySize, len(signingPub)))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeySize, richErr)
+	}
+	data, err := decodeCiphertext(blob)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < ed25519.PublicKeySize+CiphertextOverhead+ed25519.SignatureSize {
+		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
+		return nil, inputError(ErrCiphertextShort, richErr)
+	}
+	envelope, sig := data[:len(data)-ed25519.SignatureSize], data[len(data)-ed25519.SignatureSize:]
+	if subtle.ConstantTimeCompare(envelope[:ed25519.PublicKeySize], signingPub) != 1 ||
+		!ed25519.Verify(signingPub, signedMessage(envelope), sig) {
+		richErr := goerrors.New(ErrCodeInvalidSignature, "signature does not verify under the signer's public key")
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, richErr)
+	}
+	_, plaintext, err := openWithHeader(encKey, signedLabel, envelope, ed25519.PublicKeySize)
+	return plaintext, err
+}
+
+// signedMessage returns the domain-separated message signed for envelope.
+func signedMessage(envelope []byte) []byte {
+	return append([]byte(signedLabel), envelope...)
+}
+
+// Key certificate format:
+//
+//	magic "AGKC" || version (1 byte) || expiry (int64 Unix seconds, big-endian) ||
+//	public key (32 bytes) || identity length (uint32, big-endian) || identity ||
+//	Ed25519 signature (64 bytes)
+//
+// The signature covers certLabel || everything before it.
+const (
+	certLabel   = "go-crypto/v1/key-certificate"
+	certVersion = 1
+	certMinSize = 4 + 1 + 8 + ed25519.PublicKeySize + 4 + ed25519.SignatureSize
+)
+
+// certMagic identifies a key certificate.
+var certMagic = []byte("AGKC")
+
+// GenerateSigningKeyPair generates an Ed25519 key pair for EncryptAndSign and
+// CreateKeyCertificate.
+//
+// Returns:
+//   - The 32-byte public key and the 64-byte private key
+//   - An error if the random source fails
+//
+// Example:
+//
+//	pub, priv, err := crypto.GenerateSigningKeyPair()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer crypto.Zeroize(priv)
+func GenerateSigningKeyPair() (pub, priv []byte, err error) {
+	pub, priv, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, goerrors.Wrap(err, "KEY_GEN_ERROR", "failed to generate signing key")
+	}
+	return pub, priv, nil
+}
+
+// CreateKeyCertificate binds the public key of priv to an identity string until an
+// expiry time, in a compact self-signed certificate.
+//
+// It is a lightweight alternative to X.509 for internal identity binding: the
+// certificate holds the identity, the public key and the expiry, signed with priv.
+// Being self-signed, it proves that the holder of the private key claims the identity,
+// not that the claim is true; anyone can create a certificate for any identity with
+// their own key. Trust comes from how the certificate or its public key reaches the
+// verifier, e.g. keys pinned in configuration or exchanged over an authenticated
+// channel. There is no revocation; keep validity short and rotate.
+//
+// Parameters:
+//   - priv: The Ed25519 private key (ed25519.PrivateKeySize bytes)
+//   - identity: The identity to bind, e.g. "billing-service" (cannot be empty)
+//   - validity: How long the certificate is valid, from now (must be positive)
+//
+// Returns:
+//   - The binary certificate
+//   - An error if the key, identity or validity is invalid
+//
+// Example:
+//
+//	cert, err := crypto.CreateKeyCertificate(priv, "billing-service", 30*24*time.Hour)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func CreateKeyCertificate(priv []byte, identity string, validity time.Duration) ([]byte, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		richErr := goerrors.New(ErrCodeInvalidKey, fmt.Sprintf("invalid Ed25519 private key size: must be %d bytes (got %d)", ed25519.PrivateKeySize, len(priv)))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeySize, richErr)
+	}
+	if identity == "" {
+		return nil, goerrors.New("EMPTY_IDENTITY", "identity cannot be empty")
+	}
+	if validity <= 0 {
+		return nil, goerrors.New("INVALID_VALIDITY", "validity must be positive")
+	}
+	key := ed25519.PrivateKey(priv)
+	cert := make([]byte, 0, certMinSize+len(identity))
+	cert = append(append(cert, certMagic...), certVersion)
+	cert = binary.BigEndian.AppendUint64(cert, uint64(time.Now().Add(validity).Unix()))
+	cert = append(cert, key.Public().(ed25519.PublicKey)...)
+	cert = appendLengthPrefixed(cert, identity)
+	return append(cert, ed25519.Sign(key, append([]byte(certLabel), cert...))...), nil
+}
+
+// VerifyKeyCertificate checks the signature and expiry of a certificate produced by
+// CreateKeyCertificate and returns the public key and identity it binds.
+//
+// The signature is checked against the public key inside the certificate, so a valid
+// result means only that the certificate is well-formed, unmodified and unexpired;
+// compare the returned public key or identity against what the verifier trusts.
+//
+// Parameters:
+//   - cert: The binary certificate
+//
+// Returns:
+//   - The certified public key and identity
+//   - An error wrapping ErrInvalidCertificate if the certificate is malformed,
+//     ErrInvalidSignature if its signature does not verify, or ErrExpired if it has
+//     expired
+//
+// Example:
+//
+//	pub, identity, err := crypto.VerifyKeyCertificate(cert)
+//	if err != nil || !bytes.Equal(pub, pinnedKeys[identity]) {
+//		return errors.New("untrusted peer")
+//	}
+func VerifyKeyCertificate(cert []byte) (pub []byte, identity string, err error) {
+	if len(cert) < certMinSize || !bytes.Equal(cert[:4], certMagic) || cert[4] != certVersion {
+		richErr := goerrors.New(ErrCodeInvalidCertificate, "not a key certificate")
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidCertificate, richErr)
+	}
+	body, sig := cert[:len(cert)-ed25519.SignatureSize], cert[len(cert)-ed25519.SignatureSize:]
+	expiry := int64(binary.BigEndian.Uint64(body[5:13]))
+	pub = body[13 : 13+ed25519.PublicKeySize]
+	rest := body[13+ed25519.PublicKeySize:]
+	if uint64(binary.BigEndian.Uint32(rest)) != uint64(len(rest)-4) {
+		richErr := goerrors.New(ErrCodeInvalidCertificate, "invalid identity length")
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidCertificate, richErr)
+	}
+	if !ed25519.Verify(pub, append([]byte(certLabel), body...), sig) {
+		richErr := goerrors.New(ErrCodeInvalidSignature, "key certificate signature does not verify")
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidSignature, richErr)
+	}
+	if !time.Now().Before(time.Unix(expiry, 0)) {
+		richErr := goerrors.New(ErrCodeExpired, fmt.Sprintf("key certificate expired at %s", time.Unix(expiry, 0).UTC().Format(time.RFC3339)))
+		return nil, "", fmt.Errorf("%w: %w", ErrExpired, richErr)
+	}
+	return append([]byte(nil), pub...), string(rest[4:]), nil
+}
@@ -0,0 +1,134 @@
+// sign_test.go: Test cases for encrypt-then-sign.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptAndSign_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	for _, plaintext := range [][]byte{nil, []byte("signed report")} {
+		blob, err := crypto.EncryptAndSign(plaintext, key, priv)
+		if err != nil {
+			t.Fatalf("EncryptAndSign() error: %v", err)
+		}
+		decrypted, err := crypto.DecryptAndVerify(blob, key, pub)
+		if err != nil {
+			t.Fatalf("DecryptAndVerify() error: %v", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Round-trip mismatch: got %q", decrypted)
+		}
+	}
+}
+
+func TestDecryptAndVerify_Rejects(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, otherPriv, _ := ed25519.GenerateKey(nil)
+	blob, _ := crypto.EncryptAndSign([]byte("signed report"), key, priv)
+
+	if _, err := crypto.DecryptAndVerify(blob, key, otherPub); !errors.Is(err, crypto.ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for the wrong signer, got %v", err)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(blob)
+	tampered := append([]byte(nil), data...)
+	tampered[ed25519.PublicKeySize+20] ^= 1
+	if _, err := crypto.DecryptAndVerify(base64.StdEncoding.EncodeToString(tampered), key, pub); !errors.Is(err, crypto.ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for a tampered ciphertext, got %v", err)
+	}
+
+	// Stripping the signature and re-signing with another key must not produce a
+	// ciphertext the other signer can claim.
+	envelope := append([]byte(nil), data[:len(data)-ed25519.SignatureSize]...)
+	copy(envelope, otherPub)
+	resigned := append(envelope, ed25519.Sign(otherPriv, append([]byte("go-crypto/v1/signed"), envelope...))...)
+	if _, err := crypto.DecryptAndVerify(base64.StdEncoding.EncodeToString(resigned), key, otherPub); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for a re-signed ciphertext, got %v", err)
+	}
+
+	wrongKey, _ := crypto.GenerateKey()
+	if _, err := crypto.DecryptAndVerify(blob, wrongKey, pub); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for the wrong encryption key, got %v", err)
+	}
+	if _, err := crypto.DecryptAndVerify(base64.StdEncoding.EncodeToString(data[:50]), key, pub); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort, got %v", err)
+	}
+	if _, err := crypto.EncryptAndSign([]byte("x"), key, priv[:32]); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize for a short private key, got %v", err)
+	}
+	if _, err := crypto.DecryptAndVerify(blob, key, pub[:16]); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize for a short public key, got %v", err)
+	}
+}
+
+func TestKeyCertificate(t *testing.T) {
+	pub, priv, err := crypto.GenerateSigningKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateSigningKeyPair() error: %v", err)
+	}
+	cert, err := crypto.CreateKeyCertificate(priv, "billing-service", time.Hour)
+	if err != nil {
+		t.Fatalf("CreateKeyCertificate() error: %v", err)
+	}
+	gotPub, identity, err := crypto.VerifyKeyCertificate(cert)
+	if err != nil {
+		t.Fatalf("VerifyKeyCertificate() error: %v", err)
+	}
+	if !bytes.Equal(gotPub, pub) || identity != "billing-service" {
+		t.Errorf("VerifyKeyCertificate() = %x, %q", gotPub, identity)
+	}
+
+	tampered := append([]byte(nil), cert...)
+	tampered[len(cert)-ed25519.SignatureSize-1] ^= 1 // last byte of the identity
+	if _, _, err := crypto.VerifyKeyCertificate(tampered); !errors.Is(err, crypto.ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for a modified identity, got %v", err)
+	}
+	otherPub, _, _ := crypto.GenerateSigningKeyPair()
+	swapped := append([]byte(nil), cert...)
+	copy(swapped[13:], otherPub)
+	if _, _, err := crypto.VerifyKeyCertificate(swapped); !errors.Is(err, crypto.ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for a substituted key, got %v", err)
+	}
+	if _, _, err := crypto.VerifyKeyCertificate(cert[:40]); !errors.Is(err, crypto.ErrInvalidCertificate) {
+		t.Errorf("Expected ErrInvalidCertificate for a truncated certificate, got %v", err)
+	}
+	if _, _, err := crypto.VerifyKeyCertificate(append(append([]byte(nil), cert...), 0)); !errors.Is(err, crypto.ErrInvalidCertificate) {
+		t.Errorf("Expected ErrInvalidCertificate for trailing data, got %v", err)
+	}
+}
+
+func TestKeyCertificate_Expiry(t *testing.T) {
+	_, priv, _ := crypto.GenerateSigningKeyPair()
+	// The expiry is stored in whole seconds, rounded down, so this is already expired.
+	cert, err := crypto.CreateKeyCertificate(priv, "short-lived", time.Nanosecond)
+	if err != nil {
+		t.Fatalf("CreateKeyCertificate() error: %v", err)
+	}
+	if _, _, err := crypto.VerifyKeyCertificate(cert); !errors.Is(err, crypto.ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+	for _, tc := range []struct {
+		priv     []byte
+		identity string
+		validity time.Duration
+	}{{priv[:32], "x", time.Hour}, {priv, "", time.Hour}, {priv, "x", 0}} {
+		if _, err := crypto.CreateKeyCertificate(tc.priv, tc.identity, tc.validity); err == nil {
+			t.Errorf("Expected an error for %d-byte key, identity %q, validity %v", len(tc.priv), tc.identity, tc.validity)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+// siv.go: Deterministic authenticated encryption using AES-SIV (RFC 5297).
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	goerrors "github.com/agilira/go-errors"
+	"golang.org/x/crypto/hkdf"
+)
+
+// sivTagSize is the size of the synthetic IV produced by S2V (one AES block).
+const sivTagSize = aes.BlockSize
+
+// searchableInfo is the HKDF info string used to derive the AES-SIV key for searchable encryption.
+const searchableInfo = "go-crypto/v1/searchable-aes-siv"
+
+// ErrCodeSIV is the error code for AES-SIV failures.
+const ErrCodeSIV = "CRYPTO_SIV"
+
+// EncryptSearchable encrypts a plaintext deterministically using AES-SIV (RFC 5297).
+//
+// Unlike EncryptBytes, equal plaintexts encrypted under the same key always produce
+// equal ciphertexts. This makes the output suitable for equality lookups on encrypted
+// database columns (e.g. finding a user by encrypted email) while remaining authenticated:
+// any modification of the ciphertext is detected on decryption.
+//
+// The 32-byte key is expanded with HKDF-SHA256 into the two 256-bit keys required by
+// AES-SIV, so the same key material used elsewhere in the package can be supplied.
+// Using a dedicated key for searchable columns is still recommended.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - A base64-encoded string containing the synthetic IV followed by the ciphertext
+//   - An error if encryption fails
+//
+// Example:
+//
+//	key, _ := crypto.GenerateKey()
+//	a, _ := crypto.EncryptSearchable([]byte("alice@example.com"), key)
+//	b, _ := crypto.EncryptSearchable([]byte("alice@example.com"), key)
+//	fmt.Println(a == b) // Output: true
+//
+// Privacy tradeoff: deterministic encryption reveals which records share the same
+// plaintext. An observer of the ciphertexts learns equality (and frequency) of values,
+// though not the values themselves. Do not use it for low-cardinality fields
+// (booleans, country codes, ...) where frequency analysis reveals the data; use
+// EncryptBytes whenever equality search is not required.
+func EncryptSearchable(plaintext, key []byte) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	sivKey, err := deriveSubkey(key, nil, searchableInfo, 2*KeySize)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeSIV, "failed to derive SIV key")
+		return "", fmt.Errorf("%w: %w", ErrCipherInit, richErr)
+	}
+	defer Zeroize(sivKey)
+
+	out, err := sivSeal(sivKey, plaintext)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeCipherInit, "failed to create cipher")
+		return "", fmt.Errorf("%w: %w", ErrCipherInit, richErr)
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// DecryptSearchable decrypts a ciphertext produced by EncryptSearchable.
+//
+// The synthetic IV doubles as the authentication tag: if the ciphertext has been
+// tampered with, or the wrong key is used, the function returns ErrDecrypt.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext returned by EncryptSearchable
+//   - key: The 32-byte key used for encryption (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if decryption fails (authentication failure, corruption, or invalid input)
+//
+// Example:
+//
+//	ciphertext, _ := crypto.EncryptSearchable([]byte("alice@example.com"), key)
+//	plaintext, err := crypto.DecryptSearchable(ciphertext, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func DecryptSearchable(ciphertext string, key []byte) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < sivTagSize {
+		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
+		return nil, inputError(ErrCiphertextShort, richErr)
+	}
+	sivKey, err := deriveSubkey(key, nil, searchableInfo, 2*KeySize)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeSIV, "failed to derive SIV key")
+		return nil, fmt.Errorf("%w: %w", ErrCipherInit, richErr)
+	}
+	defer Zeroize(sivKey)
+
+	plaintext, err := sivOpen(sivKey, data)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeDecrypt, "failed to decrypt")
+		return nil, inputError(ErrDecrypt, richErr)
+	}
+	return plaintext, nil
+}
+
+// deterministicPasswordSalt is the fixed Argon2id salt of EncryptWithPasswordDeterministic.
+// A per-message random salt would make the output non-deterministic.
+var deterministicPasswordSalt = []byte("go-crypto/v1/pwd")
+
+// deterministicPasswordInfo is the HKDF info string used to derive the AES-SIV key from
+// the password-derived master key.
+const deterministicPasswordInfo = "go-crypto/v1/password-aes-siv"
+
+// EncryptWithPasswordDeterministic encrypts a plaintext deterministically under a password.
+//
+// The key is derived from the password with Argon2id over a fixed, library-wide salt,
+// and the plaintext is encrypted with AES-SIV (RFC 5297), whose synthetic IV is computed
+// from the key and the plaintext. The same password, parameters and plaintext therefore
+// always produce the same ciphertext, which suits idempotent-write systems that compare
+// or deduplicate stored ciphertexts.
+//
+// Security implications, all consequences of determinism:
+//   - Equal plaintexts encrypted under the same password are detectable, and their
+//     frequency is visible to anyone who sees the ciphertexts.
+//   - The fixed salt means an attacker can run one password-guessing campaign against
+//     every ciphertext produced by this function, across all users and deployments,
+//     instead of one per salt. Only use it with high-entropy passwords.
+//
+// Prefer ArchiveEncryptWithPassword, which uses a random salt and nonce, whenever
+// determinism is not required.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - password: The password to derive the key from (cannot be empty)
+//   - params: Custom Argon2id parameters (nil to use secure defaults); the same
+//     parameters must be passed to DecryptWithPasswordDeterministic
+//
+// Returns:
+//   - A base64-encoded string containing the synthetic IV followed by the ciphertext
+//   - An error if key derivation or encryption fails
+//
+// Example:
+//
+//	a, _ := crypto.EncryptWithPasswordDeterministic(record, passphrase, nil)
+//	b, _ := crypto.EncryptWithPasswordDeterministic(record, passphrase, nil)
+//	fmt.Println(a == b) // Output: true
+func EncryptWithPasswordDeterministic(plaintext, password []byte, params *KDFParams) (string, error) {
+	sivKey, err := deterministicPasswordKey(password, params)
+	if err != nil {
+		return "", err
+	}
+	defer Zeroize(sivKey)
+
+	out, err := sivSeal(sivKey, plaintext)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeCipherInit, "failed to create cipher")
+		return "", fmt.Errorf("%w: %w", ErrCipherInit, richErr)
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// DecryptWithPasswordDeterministic decrypts a ciphertext produced by EncryptWithPasswordDeterministic.
+//
+// The Argon2id parameters are not stored in the ciphertext, so params must match the
+// ones used for encryption; otherwise, as with a wrong password, ErrDecrypt is returned.
+//
+// Parameters:
+//   - ciphertext: The base64-encoded ciphertext returned by EncryptWithPasswordDeterministic
+//   - password: The password used at encryption time (cannot be empty)
+//   - params: The Argon2id parameters used at encryption time (nil for the defaults)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if decryption fails (authentication failure, corruption, or invalid input)
+//
+// Example:
+//
+//	plaintext, err := crypto.DecryptWithPasswordDeterministic(ciphertext, passphrase, nil)
+func DecryptWithPasswordDeterministic(ciphertext string, password []byte, params *KDFParams) ([]byte, error) {
+	data, err := decodeCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < sivTagSize {
+		richErr := goerrors.New(ErrCodeCipherShort, "ciphertext too short")
+		return nil, inputError(ErrCiphertextShort, richErr)
+	}
+	sivKey, err := deterministicPasswordKey(password, params)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(sivKey)
+
+	plaintext, err := sivOpen(sivKey, data)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeDecrypt, "failed to decrypt")
+		return nil, inputError(ErrDecrypt, richErr)
+	}
+	return plaintext, nil
+}
+
+// deterministicPasswordKey derives the AES-SIV key of the deterministic password functions.
+func deterministicPasswordKey(password []byte, params *KDFParams) ([]byte, error) {
+	master, err := DeriveKey(password, deterministicPasswordSalt, KeySize, params)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(master)
+
+	sivKey, err := deriveSubkey(master, nil, deterministicPasswordInfo, 2*KeySize)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeSIV, "failed to derive SIV key")
+		return nil, fmt.Errorf("%w: %w", ErrCipherInit, richErr)
+	}
+	return sivKey, nil
+}
+
+// errSIVAuth is the internal authentication failure reported by sivOpen.
+var errSIVAuth = errors.New("message authentication failed")
+
+// deriveSubkey expands key material into a subkey of the requested length with HKDF-SHA256.
+func deriveSubkey(secret, salt []byte, info string, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// sivSeal encrypts plaintext with AES-SIV under a 32, 48 or 64 byte key, authenticating
+// the given associated data components. The output is V || C as defined by RFC 5297.
+func sivSeal(key, plaintext []byte, ad ...[]byte) ([]byte, error) {
+	macBlock, ctrBlock, err := sivCiphers(key)
+	if err != nil {
+		return nil, err
+	}
+	v := s2v(macBlock, plaintext, ad)
+	out := make([]byte, sivTagSize+len(plaintext))
+	copy(out, v[:])
+	sivCTR(ctrBlock, v, out[sivTagSize:], plaintext)
+	return out, nil
+}
+
+// sivOpen decrypts and authenticates V || C produced by sivSeal.
+func sivOpen(key, data []byte, ad ...[]byte) ([]byte, error) {
+	if len(data) < sivTagSize {
+		return nil, errSIVAuth
+	}
+	macBlock, ctrBlock, err := sivCiphers(key)
+	if err != nil {
+		return nil, err
+	}
+	var v [sivTagSize]byte
+	copy(v[:], data[:sivTagSize])
+	plaintext := make([]byte, len(data)-sivTagSize)
+	sivCTR(ctrBlock, v, plaintext, data[sivTagSize:])
+	expected := s2v(macBlock, plaintext, ad)
+	if subtle.ConstantTimeCompare(expected[:], v[:]) != 1 {
+		Zeroize(plaintext)
+		return nil, errSIVAuth
+	}
+	return plaintext, nil
+}
+
+// sivCiphers splits an AES-SIV key into its S2V (MAC) and CTR halves.
+func sivCiphers(key []byte) (cipher.Block, cipher.Block, error) {
+	switch len(key) {
+	case 32, 48, 64:
+	default:
+		return nil, nil, fmt.Errorf("invalid AES-SIV key size %d", len(key))
+	}
+	half := len(key) / 2
+	macBlock, err := aes.NewCipher(key[:half])
+	if err != nil {
+		return nil, nil, err
+	}
+	ctrBlock, err := aes.NewCipher(key[half:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return macBlock, ctrBlock, nil
+}
+
+// sivCTR applies AES-CTR keyed by block, using the synthetic IV with the two
+// reserved bits cleared as the initial counter (RFC 5297, section 2.6).
+func sivCTR(block cipher.Block, v [sivTagSize]byte, dst, src []byte) {
+	if len(src) == 0 {
+		return
+	}
+	q := v
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+	cipher.NewCTR(block, q[:]).XORKeyStream(dst, src)
+}
+
+// s2v implements the S2V pseudo-random function over the associated data
+// components followed by the plaintext (RFC 5297, section 2.4).
+func s2v(block cipher.Block, plaintext []byte, ad [][]byte) [sivTagSize]byte {
+	k1, k2 := cmacSubkeys(block)
+
+	var zero [sivTagSize]byte
+	d := cmac(block, k1, k2, zero[:])
+	for _, s := range ad {
+		d = sivDouble(d)
+		m := cmac(block, k1, k2, s)
+		subtle.XORBytes(d[:], d[:], m[:])
+	}
+
+	var t []byte
+	if len(plaintext) >= sivTagSize {
+		t = make([]byte, len(plaintext))
+		copy(t, plaintext)
+		tail := t[len(t)-sivTagSize:]
+		subtle.XORBytes(tail, tail, d[:])
+	} else {
+		d = sivDouble(d)
+		var padded [sivTagSize]byte
+		copy(padded[:], plaintext)
+		padded[len(plaintext)] = 0x80
+		subtle.XORBytes(d[:], d[:], padded[:])
+		t = d[:]
+	}
+	tag := cmac(block, k1, k2, t)
+	if len(plaintext) >= sivTagSize {
+		Zeroize(t)
+	}
+	return tag
+}
+
+// sivDouble multiplies a block by x in GF(2^128) (the "dbl" operation of RFC 5297).
+func sivDouble(in [sivTagSize]byte) [sivTagSize]byte {
+	var out [sivTagSize]byte
+	carry := in[0] >> 7
+	for i := 0; i < sivTagSize-1; i++ {
+		out[i] = in[i]<<1 | in[i+1]>>7
+	}
+	out[sivTagSize-1] = in[sivTagSize-1] << 1
+	out[sivTagSize-1] ^= 0x87 & -carry
+	return out
+}
+
+// cmacSubkeys derives the two AES-CMAC subkeys (RFC 4493, section 2.3).
+func cmacSubkeys(block cipher.Block) ([sivTagSize]byte, [sivTagSize]byte) {
+	var l [sivTagSize]byte
+	block.Encrypt(l[:], l[:])
+	k1 := sivDouble(l)
+	k2 := sivDouble(k1)
+	return k1, k2
+}
+
+// cmac computes AES-CMAC of msg using the precomputed subkeys (RFC 4493).
+func cmac(block cipher.Block, k1, k2 [sivTagSize]byte, msg []byte) [sivTagSize]byte {
+	var x [sivTagSize]byte
+	n := (len(msg) + sivTagSize - 1) / sivTagSize
+	complete := n > 0 && len(msg)%sivTagSize == 0
+	if n == 0 {
+		n = 1
+	}
+	for i := 0; i < n-1; i++ {
+		subtle.XORBytes(x[:], x[:], msg[i*sivTagSize:(i+1)*sivTagSize])
+		block.Encrypt(x[:], x[:])
+	}
+	var last [sivTagSize]byte
+	rest := msg[(n-1)*sivTagSize:]
+	if complete {
+		subtle.XORBytes(last[:], rest, k1[:])
+	} else {
+		copy(last[:], rest)
+		last[len(rest)] = 0x80
+		subtle.XORBytes(last[:], last[:], k2[:])
+	}
+	subtle.XORBytes(x[:], x[:], last[:])
+	block.Encrypt(x[:], x[:])
+	return x
+}
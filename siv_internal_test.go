@@ -0,0 +1,113 @@
+// siv_internal_test.go: Known-answer tests for the AES-SIV and AES-CMAC primitives.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// unhex decodes a hex string, ignoring spaces as used in the RFC listings.
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return b
+}
+
+// TestCMAC_RFC4493 checks cmac against the AES-128 examples of RFC 4493, section 4.
+func TestCMAC_RFC4493(t *testing.T) {
+	block, _ := aes.NewCipher(unhex(t, "2b7e1516 28aed2a6 abf71588 09cf4f3c"))
+	k1, k2 := cmacSubkeys(block)
+	if want := unhex(t, "fbeed618 35713366 7c85e08f 7236a8de"); !bytes.Equal(k1[:], want) {
+		t.Errorf("K1 = %x, want %x", k1, want)
+	}
+	if want := unhex(t, "f7ddac30 6ae266cc f90bc11e e46d513b"); !bytes.Equal(k2[:], want) {
+		t.Errorf("K2 = %x, want %x", k2, want)
+	}
+	msg := unhex(t, "6bc1bee2 2e409f96 e93d7e11 7393172a ae2d8a57 1e03ac9c 9eb76fac 45af8e51"+
+		"30c81c46 a35ce411 e5fbc119 1a0a52ef f69f2445 df4f9b17 ad2b417b e66c3710")
+	for _, tc := range []struct {
+		length int
+		mac    string
+	}{
+		{0, "bb1d6929 e9593728 7fa37d12 9b756746"},
+		{16, "070a16b4 6b4d4144 f79bdd9d d04a287c"},
+		{40, "dfa66747 de9ae630 30ca3261 1497c827"},
+		{64, "51f0bebf 7e3b9d92 fc497417 79363cfe"},
+	} {
+		got := cmac(block, k1, k2, msg[:tc.length])
+		if want := unhex(t, tc.mac); !bytes.Equal(got[:], want) {
+			t.Errorf("CMAC of %d bytes = %x, want %x", tc.length, got, want)
+		}
+	}
+}
+
+// TestSIV_RFC5297 checks sivSeal and sivOpen against the examples of RFC 5297,
+// appendix A: A.1 is deterministic, A.2 is nonce-based with two AD components and the
+// nonce as the last component.
+func TestSIV_RFC5297(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		key       string
+		ad        []string
+		plaintext string
+		output    string
+	}{
+		{
+			name:      "A.1",
+			key:       "fffefdfc fbfaf9f8 f7f6f5f4 f3f2f1f0 f0f1f2f3 f4f5f6f7 f8f9fafb fcfdfeff",
+			ad:        []string{"10111213 14151617 18191a1b 1c1d1e1f 20212223 24252627"},
+			plaintext: "11223344 55667788 99aabbcc ddee",
+			output:    "85632d07 c6e8f37f 950acd32 0a2ecc93 40c02b96 90c4dc04 daef7f6a fe5c",
+		},
+		{
+			name: "A.2",
+			key:  "7f7e7d7c 7b7a7978 77767574 73727170 40414243 44454647 48494a4b 4c4d4e4f",
+			ad: []string{
+				"00112233 44556677 8899aabb ccddeeff deaddada deaddada ffeeddcc bbaa9988 77665544 33221100",
+				"10203040 50607080 90a0",
+				"09f91102 9d74e35b d84156c5 635688c0",
+			},
+			plaintext: "74686973 20697320 736f6d65 20706c61 696e7465 78742074 6f20656e 63727970 74207573 696e6720 5349562d 414553",
+			output: "7bdb6e3b 432667eb 06f4d14b ff2fbd0f cb900f2f ddbe4043 26601965 c889bf17" +
+				"dba77ceb 094fa663 b7a3f748 ba8af829 ea64ad54 4a272e9c 485b62a3 fd5c0d",
+		},
+	} {
+		key := unhex(t, tc.key)
+		var ad [][]byte
+		for _, a := range tc.ad {
+			ad = append(ad, unhex(t, a))
+		}
+		plaintext, want := unhex(t, tc.plaintext), unhex(t, tc.output)
+
+		got, err := sivSeal(key, plaintext, ad...)
+		if err != nil {
+			t.Fatalf("%s: sivSeal() error: %v", tc.name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: sivSeal() = %x, want %x", tc.name, got, want)
+		}
+		opened, err := sivOpen(key, want, ad...)
+		if err != nil || !bytes.Equal(opened, plaintext) {
+			t.Errorf("%s: sivOpen() = %x, %v", tc.name, opened, err)
+		}
+		tampered := append([]byte(nil), want...)
+		tampered[len(tampered)-1] ^= 1
+		if _, err := sivOpen(key, tampered, ad...); err != errSIVAuth {
+			t.Errorf("%s: sivOpen(tampered) error = %v, want errSIVAuth", tc.name, err)
+		}
+		if _, err := sivOpen(key, want, ad[:len(ad)-1]...); err != errSIVAuth {
+			t.Errorf("%s: sivOpen(missing AD) error = %v, want errSIVAuth", tc.name, err)
+		}
+	}
+}
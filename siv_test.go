@@ -0,0 +1,149 @@
+// siv_test.go: Test cases for deterministic AES-SIV encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptSearchable_Deterministic(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := []byte("alice@example.com")
+
+	first, err := crypto.EncryptSearchable(plaintext, key)
+	if err != nil {
+		t.Fatalf("EncryptSearchable() error: %v", err)
+	}
+	second, err := crypto.EncryptSearchable(plaintext, key)
+	if err != nil {
+		t.Fatalf("EncryptSearchable() error: %v", err)
+	}
+	if first != second {
+		t.Error("Expected equal ciphertexts for equal plaintexts")
+	}
+
+	other, _ := crypto.EncryptSearchable([]byte("bob@example.com"), key)
+	if other == first {
+		t.Error("Expected different ciphertexts for different plaintexts")
+	}
+
+	otherKey, _ := crypto.GenerateKey()
+	withOtherKey, _ := crypto.EncryptSearchable(plaintext, otherKey)
+	if withOtherKey == first {
+		t.Error("Expected different ciphertexts under different keys")
+	}
+}
+
+func TestEncryptSearchable_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	testCases := [][]byte{
+		{},
+		[]byte("a"),
+		[]byte("exactly16bytes!!"),
+		bytes.Repeat([]byte("long plaintext "), 100),
+	}
+	for _, plaintext := range testCases {
+		ciphertext, err := crypto.EncryptSearchable(plaintext, key)
+		if err != nil {
+			t.Fatalf("EncryptSearchable() error for %d bytes: %v", len(plaintext), err)
+		}
+		decrypted, err := crypto.DecryptSearchable(ciphertext, key)
+		if err != nil {
+			t.Fatalf("DecryptSearchable() error for %d bytes: %v", len(plaintext), err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Round-trip mismatch for %d bytes", len(plaintext))
+		}
+	}
+}
+
+func TestDecryptSearchable_Tampering(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptSearchable([]byte("alice@example.com"), key)
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+
+	for i := range raw {
+		tampered := make([]byte, len(raw))
+		copy(tampered, raw)
+		tampered[i] ^= 0x01
+		_, err := crypto.DecryptSearchable(base64.StdEncoding.EncodeToString(tampered), key)
+		if !errors.Is(err, crypto.ErrDecrypt) {
+			t.Fatalf("Expected ErrDecrypt for tampered byte %d, got %v", i, err)
+		}
+	}
+
+	wrongKey, _ := crypto.GenerateKey()
+	if _, err := crypto.DecryptSearchable(ciphertext, wrongKey); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for wrong key, got %v", err)
+	}
+}
+
+func TestSearchable_InvalidInputs(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	if _, err := crypto.EncryptSearchable([]byte("data"), make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	if _, err := crypto.DecryptSearchable("AAAA", make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	if _, err := crypto.DecryptSearchable("", key); !errors.Is(err, crypto.ErrEmptyPlaintext) {
+		t.Errorf("Expected ErrEmptyPlaintext, got %v", err)
+	}
+	if _, err := crypto.DecryptSearchable("not-base64!!", key); !errors.Is(err, crypto.ErrBase64Decode) {
+		t.Errorf("Expected ErrBase64Decode, got %v", err)
+	}
+	short := base64.StdEncoding.EncodeToString(make([]byte, 8))
+	if _, err := crypto.DecryptSearchable(short, key); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort, got %v", err)
+	}
+}
+
+func TestEncryptWithPasswordDeterministic(t *testing.T) {
+	params := &crypto.KDFParams{Time: 1, Memory: 8, Threads: 1}
+	password := []byte("correct horse battery staple")
+	plaintext := []byte("order-42")
+
+	first, err := crypto.EncryptWithPasswordDeterministic(plaintext, password, params)
+	if err != nil {
+		t.Fatalf("EncryptWithPasswordDeterministic() error: %v", err)
+	}
+	second, _ := crypto.EncryptWithPasswordDeterministic(plaintext, password, params)
+	if first != second {
+		t.Error("Expected equal ciphertexts for equal password and plaintext")
+	}
+	if other, _ := crypto.EncryptWithPasswordDeterministic([]byte("order-43"), password, params); other == first {
+		t.Error("Expected different ciphertexts for different plaintexts")
+	}
+	if other, _ := crypto.EncryptWithPasswordDeterministic(plaintext, []byte("another password"), params); other == first {
+		t.Error("Expected different ciphertexts under different passwords")
+	}
+
+	decrypted, err := crypto.DecryptWithPasswordDeterministic(first, password, params)
+	if err != nil {
+		t.Fatalf("DecryptWithPasswordDeterministic() error: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("Round-trip mismatch")
+	}
+
+	if _, err := crypto.DecryptWithPasswordDeterministic(first, []byte("wrong"), params); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for wrong password, got %v", err)
+	}
+	otherParams := &crypto.KDFParams{Time: 2, Memory: 8, Threads: 1}
+	if _, err := crypto.DecryptWithPasswordDeterministic(first, password, otherParams); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for different parameters, got %v", err)
+	}
+	if _, err := crypto.EncryptWithPasswordDeterministic(plaintext, nil, params); err == nil {
+		t.Error("Expected error for empty password")
+	}
+}
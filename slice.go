@@ -0,0 +1,81 @@
+// slice.go: Element-wise encryption of string slices.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// EncryptSlice encrypts each element of items separately, preserving the slice structure.
+//
+// Every element gets its own random nonce and is individually decryptable with Decrypt
+// or DecryptBytes, so a list of tags, for example, can be stored as a list of
+// ciphertexts. The key schedule is computed once for the whole slice. Each ciphertext
+// reveals the length of its element, and the output reveals the number of elements;
+// elements are not bound to their positions, so they can be reordered, removed or
+// copied between slices undetected. Use EncryptGroup when that matters.
+//
+// Parameters:
+//   - items: The strings to encrypt (elements can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - One base64-encoded ciphertext per element, at the same index
+//   - An error if the key is invalid or encryption fails
+//
+// Example:
+//
+//	encryptedTags, err := crypto.EncryptSlice([]string{"vip", "beta"}, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptSlice(items []string, key []byte) ([]string, error) {
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		if out[i], err = c.Encrypt([]byte(item)); err != nil {
+			return nil, goerrors.Wrap(err, "ENCRYPT_ERROR", fmt.Sprintf("failed to encrypt element %d", i))
+		}
+	}
+	return out, nil
+}
+
+// DecryptSlice decrypts each element of a slice produced by EncryptSlice.
+//
+// Parameters:
+//   - ciphertexts: The base64-encoded ciphertexts
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - One plaintext per element, at the same index
+//   - An error naming the first element that fails, wrapping the underlying error
+//     (e.g. ErrDecrypt)
+//
+// Example:
+//
+//	tags, err := crypto.DecryptSlice(encryptedTags, key)
+func DecryptSlice(ciphertexts []string, key []byte) ([]string, error) {
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(ciphertexts))
+	for i, ciphertext := range ciphertexts {
+		plaintext, err := c.Decrypt(ciphertext)
+		if err != nil {
+			return nil, goerrors.Wrap(err, "DECRYPT_ERROR", fmt.Sprintf("failed to decrypt element %d", i))
+		}
+		out[i] = string(plaintext)
+		Zeroize(plaintext)
+	}
+	return out, nil
+}
@@ -0,0 +1,85 @@
+// slice_test.go: Test cases for element-wise slice encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptSlice_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	items := []string{"vip", "", "beta", "vip"}
+	encrypted, err := crypto.EncryptSlice(items, key)
+	if err != nil {
+		t.Fatalf("EncryptSlice() error: %v", err)
+	}
+	if len(encrypted) != len(items) {
+		t.Fatalf("EncryptSlice() returned %d elements, want %d", len(encrypted), len(items))
+	}
+	if encrypted[0] == encrypted[3] {
+		t.Error("equal elements produced equal ciphertexts; nonces must differ")
+	}
+
+	// Each element is individually decryptable.
+	if got, err := crypto.Decrypt(encrypted[2], key); err != nil || got != "beta" {
+		t.Errorf("Decrypt(element 2) = %q, %v", got, err)
+	}
+
+	decrypted, err := crypto.DecryptSlice(encrypted, key)
+	if err != nil {
+		t.Fatalf("DecryptSlice() error: %v", err)
+	}
+	for i := range items {
+		if decrypted[i] != items[i] {
+			t.Errorf("element %d = %q, want %q", i, decrypted[i], items[i])
+		}
+	}
+}
+
+func TestEncryptSlice_Empty(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	encrypted, err := crypto.EncryptSlice(nil, key)
+	if err != nil || len(encrypted) != 0 {
+		t.Fatalf("EncryptSlice(nil) = %v, %v", encrypted, err)
+	}
+	decrypted, err := crypto.DecryptSlice(nil, key)
+	if err != nil || len(decrypted) != 0 {
+		t.Fatalf("DecryptSlice(nil) = %v, %v", decrypted, err)
+	}
+}
+
+func TestDecryptSlice_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	encrypted, _ := crypto.EncryptSlice([]string{"a", "b"}, key)
+	foreign, _ := crypto.Encrypt("c", other)
+
+	_, err := crypto.DecryptSlice(append(encrypted, foreign), key)
+	if !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("foreign element: got %v, want ErrDecrypt", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "element 2") {
+		t.Errorf("expected element index in error, got %v", err)
+	}
+	if code := crypto.ErrorCode(err); code != crypto.ErrCodeDecrypt {
+		t.Errorf("foreign element: ErrorCode = %q, want %q", code, crypto.ErrCodeDecrypt)
+	}
+	_, err = crypto.DecryptSlice([]string{encrypted[0], "not base64!"}, key)
+	if code := crypto.ErrorCode(err); code != crypto.ErrCodeBase64Decode {
+		t.Errorf("malformed element: ErrorCode = %q, want %q", code, crypto.ErrCodeBase64Decode)
+	}
+	if _, err := crypto.EncryptSlice([]string{"a"}, make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+	if _, err := crypto.DecryptSlice(encrypted, nil); !errors.Is(err, crypto.ErrNilKey) {
+		t.Errorf("nil key: got %v, want ErrNilKey", err)
+	}
+}
@@ -0,0 +1,97 @@
+// split.go: Encryption with the nonce and tag stored apart from the ciphertext body.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"fmt"
+	"io"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// SplitHeaderSize is the size of the header returned by EncryptSplit: the 12-byte
+// nonce followed by the 16-byte authentication tag.
+const SplitHeaderSize = gcmNonceSize + gcmTagSize
+
+// EncryptSplit encrypts a plaintext with AES-256-GCM and returns the header and body separately.
+//
+// The header holds the nonce and the authentication tag (SplitHeaderSize bytes); the
+// body holds the raw ciphertext, exactly as long as the plaintext. This lets small
+// headers live in a fast key-value store while bulk bodies go to object storage. Both
+// parts are required for decryption, and neither reveals anything about the plaintext
+// beyond its length. Concatenated as nonce || body || tag, the parts form the same
+// message that EncryptBytes encodes in base64.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The header (nonce || tag)
+//   - The body (raw ciphertext)
+//   - An error if encryption fails
+//
+// Example:
+//
+//	header, body, err := crypto.EncryptSplit(document, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	kv.Put(id, header)
+//	bucket.Put(id, body)
+func EncryptSplit(plaintext, key []byte) (header, body []byte, err error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	header = make([]byte, SplitHeaderSize)
+	nonce := header[:gcmNonceSize]
+	if _, err := io.ReadFull(nonceReader(), nonce); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate nonce")
+		return nil, nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	sealed := gcm.Seal(nil, nonce, plaintext, nil)
+	body = sealed[:len(plaintext):len(plaintext)]
+	copy(header[gcmNonceSize:], sealed[len(plaintext):])
+	return header, body, nil
+}
+
+// DecryptSplit reassembles a header and body produced by EncryptSplit and decrypts them.
+//
+// The tag in the header authenticates the body, so a body paired with the wrong header,
+// or modified in storage, is rejected with ErrDecrypt.
+//
+// Parameters:
+//   - header: The SplitHeaderSize-byte header returned by EncryptSplit
+//   - body: The raw ciphertext returned by EncryptSplit
+//   - key: The 32-byte key used for encryption (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if the header has the wrong size or authentication fails
+//
+// Example:
+//
+//	plaintext, err := crypto.DecryptSplit(kv.Get(id), bucket.Get(id), key)
+func DecryptSplit(header, body, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(header) != SplitHeaderSize {
+		richErr := goerrors.New(ErrCodeCipherShort, fmt.Sprintf("split header must be %d bytes (got %d)", SplitHeaderSize, len(header)))
+		return nil, inputError(ErrCiphertextShort, richErr)
+	}
+	sealed := make([]byte, 0, len(body)+gcmTagSize)
+	sealed = append(append(sealed, body...), header[gcmNonceSize:]...)
+	plaintext, err := gcm.Open(sealed[:0], header[:gcmNonceSize], sealed, nil)
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeDecrypt, "failed to decrypt")
+		return nil, inputError(ErrDecrypt, richErr)
+	}
+	return plaintext, nil
+}
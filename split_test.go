@@ -0,0 +1,80 @@
+// split_test.go: Test cases for split header/body encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptSplit_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, size := range []int{0, 1, 100, 4096} {
+		plaintext := bytes.Repeat([]byte("s"), size)
+		header, body, err := crypto.EncryptSplit(plaintext, key)
+		if err != nil {
+			t.Fatalf("EncryptSplit() error for %d bytes: %v", size, err)
+		}
+		if len(header) != crypto.SplitHeaderSize {
+			t.Errorf("Expected %d-byte header, got %d", crypto.SplitHeaderSize, len(header))
+		}
+		if len(body) != size {
+			t.Errorf("Expected %d-byte body, got %d", size, len(body))
+		}
+		decrypted, err := crypto.DecryptSplit(header, body, key)
+		if err != nil {
+			t.Fatalf("DecryptSplit() error for %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Round-trip mismatch for %d bytes", size)
+		}
+	}
+}
+
+func TestEncryptSplit_CompatibleWithDecryptBytes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := []byte("bulk object")
+	header, body, _ := crypto.EncryptSplit(plaintext, key)
+
+	joined := append(append(append([]byte{}, header[:12]...), body...), header[12:]...)
+	decrypted, err := crypto.DecryptBytes(base64.StdEncoding.EncodeToString(joined), key)
+	if err != nil {
+		t.Fatalf("DecryptBytes() error: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("Reassembled message mismatch")
+	}
+}
+
+func TestDecryptSplit_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	header, body, _ := crypto.EncryptSplit([]byte("payload"), key)
+	otherHeader, _, _ := crypto.EncryptSplit([]byte("payload"), key)
+
+	tamperedBody := append([]byte{}, body...)
+	tamperedBody[0] ^= 0x01
+	if _, err := crypto.DecryptSplit(header, tamperedBody, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for tampered body, got %v", err)
+	}
+	if _, err := crypto.DecryptSplit(otherHeader, body, key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for mismatched header, got %v", err)
+	}
+	wrongKey, _ := crypto.GenerateKey()
+	if _, err := crypto.DecryptSplit(header, body, wrongKey); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for wrong key, got %v", err)
+	}
+	if _, err := crypto.DecryptSplit(header[:20], body, key); !errors.Is(err, crypto.ErrCiphertextShort) {
+		t.Errorf("Expected ErrCiphertextShort for short header, got %v", err)
+	}
+	if _, _, err := crypto.EncryptSplit([]byte("x"), make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
@@ -0,0 +1,693 @@
+// stream.go: Chunked streaming encryption and decryption using AES-256-GCM.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// Streaming format parameters.
+//
+// A stream starts with a 16-byte header followed by a sequence of chunks:
+//
+//	header = magic "AGCS" || version (1 byte) || chunk size (uint32, big-endian) || nonce prefix (7 bytes)
+//	chunk  = AES-256-GCM(plaintext chunk) || tag (16 bytes)
+//
+// Every chunk except the last carries exactly chunkSize bytes of plaintext; the last
+// chunk carries fewer (possibly zero). Each chunk nonce is the nonce prefix followed by
+// a 32-bit big-endian chunk counter and a final-chunk flag byte, and the header is
+// authenticated as additional data of every chunk. Reordering, duplicating, dropping or
+// truncating chunks is therefore detected.
+const (
+	// DefaultChunkSize is the plaintext size of each chunk used by EncryptStream (64 KiB).
+	DefaultChunkSize = 64 * 1024
+
+	// MaxChunkSize is the largest chunk size accepted when reading a stream (16 MiB).
+	// It bounds the memory a malicious header can make the decryptor allocate.
+	MaxChunkSize = 16 * 1024 * 1024
+
+	// DefaultAtomicStreamLimit is the plaintext size cap used by DecryptStreamAtomic (32 MiB).
+	DefaultAtomicStreamLimit = 32 * 1024 * 1024
+
+	streamHeaderSize      = 16
+	streamNoncePrefixSize = 7
+	streamVersion         = 1
+	streamTagSize         = 16
+)
+
+// streamMagic identifies the streaming format.
+var streamMagic = [4]byte{'A', 'G', 'C', 'S'}
+
+var (
+	// ErrInvalidStream is returned when a stream header is malformed or the stream is truncated.
+	ErrInvalidStream = errors.New("crypto: invalid or truncated stream")
+
+	// ErrStreamTooLarge is returned when an atomic stream decryption exceeds its size limit.
+	ErrStreamTooLarge = errors.New("crypto: stream exceeds size limit")
+)
+
+// Error codes for streaming failures.
+const (
+	ErrCodeInvalidStream  = "CRYPTO_INVALID_STREAM"
+	ErrCodeStreamTooLarge = "CRYPTO_STREAM_TOO_LARGE"
+)
+
+// EncryptStream encrypts everything read from src and writes the encrypted stream to dst.
+//
+// The data is processed in chunks of DefaultChunkSize bytes, so memory usage is constant
+// regardless of the input size. Each chunk is authenticated individually, and the final
+// chunk is marked so that truncation is detected on decryption.
+//
+// Parameters:
+//   - dst: The writer receiving the encrypted stream
+//   - src: The reader providing the plaintext
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - An error if encryption, reading or writing fails
+//
+// Example:
+//
+//	in, _ := os.Open("backup.tar")
+//	out, _ := os.Create("backup.tar.enc")
+//	if err := crypto.EncryptStream(out, in, key); err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	return EncryptStreamWithChunkSize(dst, src, key, DefaultChunkSize)
+}
+
+// EncryptStreamWithChunkSize is like EncryptStream but uses the given plaintext chunk size.
+//
+// Larger chunks reduce the per-chunk overhead (16 bytes) at the cost of memory;
+// chunkSize must be between 1 and MaxChunkSize.
+//
+// Example:
+//
+//	err := crypto.EncryptStreamWithChunkSize(out, in, key, 1<<20) // 1 MiB chunks
+func EncryptStreamWithChunkSize(dst io.Writer, src io.Reader, key []byte, chunkSize int) error {
+	w, err := newStreamWriter(dst, key, chunkSize)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		w.zeroize()
+		return err
+	}
+	return w.Close()
+}
+
+// StreamCiphertextLen returns the exact size of the stream EncryptStreamWithChunkSize
+// produces for plaintextLen bytes with chunkSize-byte chunks.
+//
+// The stream consists of the header and one chunk per chunkSize bytes of plaintext,
+// plus a final chunk holding the remainder, which is empty when plaintextLen is a
+// multiple of chunkSize; every chunk carries a tag. The size depends only on the two
+// lengths, so it can be sent as Content-Length before encryption starts.
+//
+// Parameters:
+//   - plaintextLen: The plaintext length in bytes (must not be negative)
+//   - chunkSize: The chunk size (DefaultChunkSize for EncryptStream; 1 to MaxChunkSize)
+//
+// Returns:
+//   - The stream size in bytes, or -1 if an argument is out of range or the plaintext
+//     needs more chunks than a stream can hold
+//
+// Example:
+//
+//	info, _ := f.Stat()
+//	w.Header().Set("Content-Length", strconv.FormatInt(crypto.StreamCiphertextLen(int(info.Size()), crypto.DefaultChunkSize), 10))
+//	err := crypto.EncryptStream(w, f, key)
+func StreamCiphertextLen(plaintextLen, chunkSize int) int64 {
+	if plaintextLen < 0 || chunkSize < 1 || chunkSize > MaxChunkSize {
+		return -1
+	}
+	chunks := int64(plaintextLen)/int64(chunkSize) + 1
+	if chunks > 1<<32 {
+		return -1 // more chunks than the 32-bit chunk counter allows
+	}
+	return streamHeaderSize + int64(plaintextLen) + chunks*streamTagSize
+}
+
+// DecryptStream decrypts an encrypted stream read from src and writes the plaintext to dst.
+//
+// Each chunk is written to dst as soon as it has been authenticated. If a later chunk
+// fails authentication, or the stream is truncated, an error is returned but the
+// plaintext of the preceding chunks has already been written. Use DecryptStreamAtomic
+// when consumers must not observe any data from a stream that turns out to be corrupt.
+//
+// Parameters:
+//   - dst: The writer receiving the plaintext
+//   - src: The reader providing the encrypted stream
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - An error if the stream is malformed (ErrInvalidStream), fails authentication
+//     (ErrDecrypt), or reading or writing fails
+//
+// Example:
+//
+//	in, _ := os.Open("backup.tar.enc")
+//	out, _ := os.Create("backup.tar")
+//	if err := crypto.DecryptStream(out, in, key); err != nil {
+//		log.Fatal(err)
+//	}
+func DecryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	return decryptStream(src, key, func(_ int, plaintext []byte) error {
+		_, err := dst.Write(plaintext)
+		return err
+	})
+}
+
+// DecryptStreamAtomic decrypts an encrypted stream with all-or-nothing semantics.
+//
+// The whole stream is decrypted and authenticated into memory first; dst receives the
+// plaintext only if every chunk authenticated and the final-chunk marker was found.
+// On failure nothing is written to dst and the buffered plaintext is wiped.
+// The plaintext size is capped at DefaultAtomicStreamLimit; use
+// DecryptStreamAtomicLimit to configure the cap.
+//
+// Example:
+//
+//	var config bytes.Buffer
+//	if err := crypto.DecryptStreamAtomic(&config, encryptedFile, key); err != nil {
+//		log.Fatal(err) // config is untouched
+//	}
+func DecryptStreamAtomic(dst io.Writer, src io.Reader, key []byte) error {
+	return DecryptStreamAtomicLimit(dst, src, key, DefaultAtomicStreamLimit)
+}
+
+// DecryptStreamAtomicLimit is like DecryptStreamAtomic with a caller-supplied cap on
+// the plaintext size. If the stream holds more than maxSize bytes of plaintext the
+// function stops reading and returns an error wrapping ErrStreamTooLarge.
+//
+// Example:
+//
+//	err := crypto.DecryptStreamAtomicLimit(dst, src, key, 1<<20) // at most 1 MiB
+func DecryptStreamAtomicLimit(dst io.Writer, src io.Reader, key []byte, maxSize int64) error {
+	var buf wipingBuffer
+	defer func() { Zeroize(buf.Bytes()) }() // also if dst.Write panics
+	err := decryptStream(src, key, func(_ int, plaintext []byte) error {
+		if int64(buf.Len())+int64(len(plaintext)) > maxSize {
+			richErr := goerrors.New(ErrCodeStreamTooLarge, fmt.Sprintf("stream plaintext exceeds limit of %d bytes", maxSize))
+			return fmt.Errorf("%w: %w", ErrStreamTooLarge, richErr)
+		}
+		buf.Write(plaintext)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(buf.Bytes())
+	return err
+}
+
+// ValidateStream checks the integrity of an encrypted stream without producing its plaintext.
+//
+// Every chunk is authenticated in turn, including its position in the stream, and the
+// stream must end with a chunk carrying the final-chunk marker, so corruption,
+// reordering and truncation are all detected. Authentication requires decrypting each
+// chunk, but the plaintext is kept in a single chunk-sized buffer that is wiped before
+// returning; memory usage is constant regardless of the stream size. Use it for
+// periodic integrity scrubs of archived encrypted data.
+//
+// The error of a failed check names the index of the first bad chunk, and chunks is
+// the number of chunks that authenticated before it, which equals that index.
+//
+// Parameters:
+//   - r: The reader providing the encrypted stream
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The number of chunks in the stream, including the final chunk
+//   - An error if the stream is malformed or truncated (ErrInvalidStream), a chunk fails
+//     authentication (ErrDecrypt), or reading fails
+//
+// Example:
+//
+//	f, _ := os.Open("backup.tar.enc")
+//	defer f.Close()
+//	chunks, err := crypto.ValidateStream(bufio.NewReader(f), key)
+//	if err != nil {
+//		log.Printf("backup.tar.enc corrupt after %d good chunks: %v", chunks, err)
+//	}
+func ValidateStream(r io.Reader, key []byte) (chunks int, err error) {
+	err = decryptStream(r, key, func(index int, _ []byte) error {
+		chunks = index + 1
+		return nil
+	})
+	return chunks, err
+}
+
+// RechunkStream re-encrypts an encrypted stream with a different chunk size.
+//
+// The source stream is decrypted chunk by chunk and its plaintext fed straight into a
+// new stream with newChunkSize-byte chunks under the same key, so memory usage is
+// bounded by the two chunk sizes regardless of the stream size, and no plaintext
+// touches the disk. Every source chunk is authenticated before its plaintext is
+// re-encrypted. If the source turns out to be corrupt or truncated, an error is
+// returned and the output written so far lacks its final chunk, so it is itself
+// rejected as truncated by DecryptStream. The new stream gets a fresh header and nonce
+// prefix.
+//
+// Parameters:
+//   - dst: The writer receiving the re-chunked stream
+//   - src: The reader providing the encrypted stream
+//   - key: The 32-byte key of the stream (must be exactly KeySize bytes)
+//   - newChunkSize: The plaintext chunk size of the new stream (1 to MaxChunkSize)
+//
+// Returns:
+//   - An error if newChunkSize is invalid, the source is malformed (ErrInvalidStream)
+//     or fails authentication (ErrDecrypt), or reading or writing fails
+//
+// Example:
+//
+//	in, _ := os.Open("backup.tar.enc")     // 64 KiB chunks
+//	out, _ := os.Create("backup.tar.enc2") // 1 MiB chunks for object storage
+//	if err := crypto.RechunkStream(out, in, key, 1<<20); err != nil {
+//		log.Fatal(err)
+//	}
+func RechunkStream(dst io.Writer, src io.Reader, key []byte, newChunkSize int) error {
+	w, err := newStreamWriter(dst, key, newChunkSize)
+	if err != nil {
+		return err
+	}
+	defer w.zeroize() // also if dst.Write panics
+	err = decryptStream(src, key, func(_ int, plaintext []byte) error {
+		_, err := w.Write(plaintext)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// EncryptSharded encrypts plaintext in the streaming format and cuts the encrypted
+// stream into shards of shardSize bytes, for spreading one ciphertext over several
+// objects or disks.
+//
+// Every shard except the last holds exactly shardSize bytes. The shards are slices of a
+// single stream, not independent ciphertexts: they decrypt only together, in order,
+// with DecryptSharded, and losing any one of them loses the data (there is no erasure
+// coding). Because the stream authenticates every chunk's position and marks the final
+// chunk, missing, reordered or duplicated shards are detected.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - shardSize: The size of each shard in bytes (at least 1)
+//
+// Returns:
+//   - The shards, in order; they share one backing array
+//   - An error if shardSize is invalid or encryption fails
+//
+// Example:
+//
+//	shards, err := crypto.EncryptSharded(backup, key, 64<<20)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for i, shard := range shards {
+//		upload(disks[i%len(disks)], i, shard)
+//	}
+func EncryptSharded(plaintext, key []byte, shardSize int) ([][]byte, error) {
+	if shardSize < 1 {
+		return nil, goerrors.New("INVALID_SHARD_SIZE", fmt.Sprintf("shard size must be positive (got %d)", shardSize))
+	}
+	var buf bytes.Buffer
+	if err := EncryptStream(&buf, bytes.NewReader(plaintext), key); err != nil {
+		return nil, err
+	}
+	stream := buf.Bytes()
+	shards := make([][]byte, 0, (len(stream)+shardSize-1)/shardSize)
+	for len(stream) > shardSize {
+		shards = append(shards, stream[:shardSize:shardSize])
+		stream = stream[shardSize:]
+	}
+	return append(shards, stream), nil
+}
+
+// DecryptSharded reassembles shards produced by EncryptSharded and decrypts them.
+//
+// The shards must be passed complete and in their original order. Decryption is all or
+// nothing: the plaintext is returned only if the whole stream authenticated, and is
+// wiped otherwise.
+//
+// Parameters:
+//   - shards: The shards, in order
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The decrypted plaintext
+//   - An error wrapping ErrInvalidStream if shards are missing or have inconsistent
+//     sizes, or ErrDecrypt if the shards are reordered, corrupted or the key is wrong
+//
+// Example:
+//
+//	backup, err := crypto.DecryptSharded(shards, key)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func DecryptSharded(shards [][]byte, key []byte) ([]byte, error) {
+	if len(shards) == 0 {
+		richErr := goerrors.New(ErrCodeInvalidStream, "no shards")
+		return nil, fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+	}
+	readers := make([]io.Reader, len(shards))
+	for i, shard := range shards {
+		last := i == len(shards)-1
+		if len(shard) == 0 || (!last && len(shard) != len(shards[0])) || len(shard) > len(shards[0]) {
+			richErr := goerrors.New(ErrCodeInvalidStream, fmt.Sprintf("shard %d has %d bytes, expected %d", i, len(shard), len(shards[0])))
+			return nil, fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+		}
+		readers[i] = bytes.NewReader(shard)
+	}
+	var buf wipingBuffer
+	err := decryptStream(io.MultiReader(readers...), key, func(_ int, plaintext []byte) error {
+		buf.Write(plaintext)
+		return nil
+	})
+	if err != nil {
+		Zeroize(buf.Bytes())
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecryptRange decrypts only the plaintext bytes [offset, offset+length) of an encrypted stream.
+//
+// Because every chunk except the last holds exactly chunkSize bytes of plaintext, the
+// chunks covering the range can be located directly. Only those chunks are read from
+// ra, authenticated and decrypted, so random access into large encrypted files costs
+// at most one chunk of overhead at each end of the range.
+//
+// Each chunk read is authenticated, including its position in the stream, but chunks
+// outside the range are not examined: truncation of the stream after the range is not
+// detected. Use DecryptStream to verify a stream in full. If the range extends past the
+// end of the plaintext, the available bytes are written and an error wrapping
+// ErrInvalidStream is returned.
+//
+// Parameters:
+//   - ra: The encrypted stream, e.g. an *os.File
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//   - offset: The plaintext offset of the first byte to return
+//   - length: The number of plaintext bytes to return
+//   - dst: The writer receiving the plaintext range
+//
+// Returns:
+//   - An error if the arguments are invalid, the stream is malformed or ends before the
+//     range, authentication fails (ErrDecrypt), or reading or writing fails
+//
+// Example:
+//
+//	f, _ := os.Open("app.log.enc")
+//	defer f.Close()
+//	err := crypto.DecryptRange(f, key, 10<<20, 4096, os.Stdout)
+func DecryptRange(ra io.ReaderAt, key []byte, offset, length int64, dst io.Writer) error {
+	if offset < 0 || length < 0 || offset > math.MaxInt64-length {
+		return goerrors.New("INVALID_RANGE", fmt.Sprintf("invalid range: offset %d, length %d", offset, length))
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	h, err := readStreamHeader(io.NewSectionReader(ra, 0, streamHeaderSize))
+	if err != nil {
+		return err
+	}
+	if length == 0 {
+		return nil
+	}
+
+	chunkSize, sealedSize := int64(h.chunkSize), int64(h.sealedChunkSize())
+	end := offset + length
+	first, last := offset/chunkSize, (end-1)/chunkSize
+	if last > math.MaxUint32 || last > (math.MaxInt64-streamHeaderSize)/sealedSize {
+		return rangeBeyondStream(end)
+	}
+	chunk := make([]byte, sealedSize)
+	plaintext := make([]byte, 0, h.chunkSize)
+	defer Zeroize(plaintext[:cap(plaintext)])
+
+	for index := first; index <= last; index++ {
+		n, err := ra.ReadAt(chunk, streamHeaderSize+index*sealedSize)
+		final := false
+		if n < len(chunk) {
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			if n < streamTagSize {
+				richErr := goerrors.New(ErrCodeInvalidStream, fmt.Sprintf("stream truncated at chunk %d", index))
+				return fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+			}
+			final = true
+		}
+		plaintext, err = h.openChunk(gcm, plaintext[:0], chunk[:n], uint32(index), final)
+		if err != nil {
+			return err
+		}
+
+		chunkStart := index * chunkSize
+		from := min(max(offset-chunkStart, 0), int64(len(plaintext)))
+		to := min(end-chunkStart, int64(len(plaintext)))
+		if _, err := dst.Write(plaintext[from:to]); err != nil {
+			return err
+		}
+		if final && chunkStart+int64(len(plaintext)) < end {
+			return rangeBeyondStream(end)
+		}
+	}
+	return nil
+}
+
+// rangeBeyondStream reports a DecryptRange request ending past the stream's plaintext.
+func rangeBeyondStream(end int64) error {
+	richErr := goerrors.New(ErrCodeInvalidStream, fmt.Sprintf("requested range ends at %d, past the end of the stream", end))
+	return fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+}
+
+// streamHeader is the parsed form of a stream header.
+type streamHeader struct {
+	raw       [streamHeaderSize]byte
+	chunkSize int
+}
+
+// newStreamHeader creates a header with a fresh random nonce prefix.
+func newStreamHeader(chunkSize int) (*streamHeader, error) {
+	if chunkSize <= 0 || chunkSize > MaxChunkSize {
+		richErr := goerrors.New(ErrCodeInvalidStream, fmt.Sprintf("chunk size must be between 1 and %d (got %d)", MaxChunkSize, chunkSize))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+	}
+	h := &streamHeader{chunkSize: chunkSize}
+	copy(h.raw[:4], streamMagic[:])
+	h.raw[4] = streamVersion
+	binary.BigEndian.PutUint32(h.raw[5:9], uint32(chunkSize))
+	if _, err := io.ReadFull(nonceReader(), h.raw[9:]); err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeNonceGen, "failed to generate nonce")
+		return nil, fmt.Errorf("%w: %w", ErrNonceGen, richErr)
+	}
+	return h, nil
+}
+
+// parseStreamHeader validates and parses a raw stream header.
+func parseStreamHeader(raw []byte) (*streamHeader, error) {
+	if len(raw) != streamHeaderSize || !bytes.Equal(raw[:4], streamMagic[:]) || raw[4] != streamVersion {
+		richErr := goerrors.New(ErrCodeInvalidStream, "invalid stream header")
+		return nil, fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+	}
+	chunkSize := binary.BigEndian.Uint32(raw[5:9])
+	if chunkSize == 0 || chunkSize > MaxChunkSize {
+		richErr := goerrors.New(ErrCodeInvalidStream, fmt.Sprintf("invalid stream chunk size %d", chunkSize))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+	}
+	h := &streamHeader{chunkSize: int(chunkSize)}
+	copy(h.raw[:], raw)
+	return h, nil
+}
+
+// readStreamHeader reads and parses the header at the start of src.
+func readStreamHeader(src io.Reader) (*streamHeader, error) {
+	var raw [streamHeaderSize]byte
+	if _, err := io.ReadFull(src, raw[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			richErr := goerrors.Wrap(err, ErrCodeInvalidStream, "stream too short for header")
+			return nil, fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+		}
+		return nil, err
+	}
+	return parseStreamHeader(raw[:])
+}
+
+// nonce returns the nonce of chunk number counter.
+func (h *streamHeader) nonce(counter uint32, final bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, h.raw[9:])
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefixSize:], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// sealedChunkSize is the size of an encrypted chunk carrying a full chunk of plaintext.
+func (h *streamHeader) sealedChunkSize() int {
+	return h.chunkSize + streamTagSize
+}
+
+// openChunk authenticates and decrypts chunk number index.
+func (h *streamHeader) openChunk(gcm cipher.AEAD, dst, chunk []byte, index uint32, final bool) ([]byte, error) {
+	plaintext, err := gcm.Open(dst, h.nonce(index, final), chunk, h.raw[:])
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeDecrypt, fmt.Sprintf("failed to decrypt stream chunk %d", index))
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, richErr)
+	}
+	return plaintext, nil
+}
+
+// decryptStream reads an encrypted stream from src and passes the plaintext of each
+// authenticated chunk, with its index, to emit. The plaintext slice is reused between
+// calls and wiped afterwards, so emit must copy what it needs to keep.
+func decryptStream(src io.Reader, key []byte, emit func(index int, plaintext []byte) error) error {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	h, err := readStreamHeader(src)
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, h.sealedChunkSize())
+	plaintext := make([]byte, 0, h.chunkSize)
+	defer Zeroize(plaintext[:cap(plaintext)])
+
+	for index := uint32(0); ; index++ {
+		n, err := io.ReadFull(src, chunk)
+		final := false
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			if n < streamTagSize {
+				richErr := goerrors.New(ErrCodeInvalidStream, fmt.Sprintf("stream truncated at chunk %d", index))
+				return fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+			}
+			final = true
+		default:
+			return err
+		}
+		plaintext, err = h.openChunk(gcm, plaintext[:0], chunk[:n], index, final)
+		if err != nil {
+			return err
+		}
+		if err := emit(int(index), plaintext); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+		if index == ^uint32(0) {
+			richErr := goerrors.New(ErrCodeInvalidStream, "stream has too many chunks")
+			return fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+		}
+	}
+}
+
+// streamWriter encrypts data written to it into the streaming format.
+type streamWriter struct {
+	dst     io.Writer
+	gcm     cipher.AEAD
+	header  *streamHeader
+	buf     []byte
+	out     []byte
+	counter uint32
+	started bool
+	closed  bool
+}
+
+// newStreamWriter returns a writer that encrypts into dst with the given chunk size.
+// Close must be called to write the final chunk.
+func newStreamWriter(dst io.Writer, key []byte, chunkSize int) (*streamWriter, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	h, err := newStreamHeader(chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	return &streamWriter{
+		dst:    dst,
+		gcm:    gcm,
+		header: h,
+		buf:    make([]byte, 0, chunkSize),
+		out:    make([]byte, 0, chunkSize+streamTagSize),
+	}, nil
+}
+
+// Write buffers p and emits every completed chunk.
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	written := 0
+	for len(p) > 0 {
+		n := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+		if len(w.buf) == cap(w.buf) {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close writes the final chunk. It does not close the underlying writer.
+func (w *streamWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	err := w.flush(true)
+	w.closed = true
+	w.zeroize()
+	return err
+}
+
+// flush seals the buffered plaintext as the next chunk.
+func (w *streamWriter) flush(final bool) error {
+	if !w.started {
+		if _, err := w.dst.Write(w.header.raw[:]); err != nil {
+			return err
+		}
+		w.started = true
+	}
+	if !final && w.counter == ^uint32(0) {
+		richErr := goerrors.New(ErrCodeInvalidStream, "stream has too many chunks")
+		return fmt.Errorf("%w: %w", ErrInvalidStream, richErr)
+	}
+	w.out = w.gcm.Seal(w.out[:0], w.header.nonce(w.counter, final), w.buf, w.header.raw[:])
+	Zeroize(w.buf)
+	w.buf = w.buf[:0]
+	w.counter++
+	_, err := w.dst.Write(w.out)
+	return err
+}
+
+// zeroize wipes the buffered plaintext.
+func (w *streamWriter) zeroize() {
+	Zeroize(w.buf[:cap(w.buf)])
+}
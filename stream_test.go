@@ -0,0 +1,446 @@
+// stream_test.go: Test cases for chunked streaming encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func encryptTestStream(t *testing.T, plaintext, key []byte, chunkSize int) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := crypto.EncryptStreamWithChunkSize(&out, bytes.NewReader(plaintext), key, chunkSize); err != nil {
+		t.Fatalf("EncryptStreamWithChunkSize() error: %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestEncryptStream_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, size := range []int{0, 1, 63, 64, 65, 128, 1000} {
+		plaintext := make([]byte, size)
+		_, _ = rand.Read(plaintext)
+		stream := encryptTestStream(t, plaintext, key, 64)
+
+		var out bytes.Buffer
+		if err := crypto.DecryptStream(&out, bytes.NewReader(stream), key); err != nil {
+			t.Fatalf("DecryptStream() error for %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(out.Bytes(), plaintext) {
+			t.Errorf("Round-trip mismatch for %d bytes", size)
+		}
+	}
+}
+
+func TestEncryptStream_DefaultChunkSize(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("x"), 3*crypto.DefaultChunkSize+17)
+
+	var stream bytes.Buffer
+	if err := crypto.EncryptStream(&stream, bytes.NewReader(plaintext), key); err != nil {
+		t.Fatalf("EncryptStream() error: %v", err)
+	}
+	expected := 16 + len(plaintext) + 4*16
+	if stream.Len() != expected {
+		t.Errorf("Expected stream length %d, got %d", expected, stream.Len())
+	}
+	var out bytes.Buffer
+	if err := crypto.DecryptStream(&out, &stream, key); err != nil {
+		t.Fatalf("DecryptStream() error: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), plaintext) {
+		t.Error("Round-trip mismatch")
+	}
+}
+
+func TestDecryptStream_Truncation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("a"), 128)
+	stream := encryptTestStream(t, plaintext, key, 64)
+	// header(16) + 2 full chunks (80 each) + empty final chunk (16)
+
+	testCases := map[string][]byte{
+		"header only":        stream[:16],
+		"short header":       stream[:10],
+		"final chunk gone":   stream[:16+2*80],
+		"partial last chunk": stream[:len(stream)-1],
+		"mid chunk":          stream[:16+100],
+	}
+	for name, truncated := range testCases {
+		err := crypto.DecryptStream(&bytes.Buffer{}, bytes.NewReader(truncated), key)
+		if err == nil {
+			t.Errorf("%s: expected error for truncated stream", name)
+		}
+		if !errors.Is(err, crypto.ErrInvalidStream) && !errors.Is(err, crypto.ErrDecrypt) {
+			t.Errorf("%s: unexpected error type: %v", name, err)
+		}
+	}
+}
+
+func TestDecryptStream_Tampering(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("b"), 200)
+	stream := encryptTestStream(t, plaintext, key, 64)
+
+	// Swap the first two chunks.
+	swapped := append([]byte{}, stream...)
+	copy(swapped[16:96], stream[96:176])
+	copy(swapped[96:176], stream[16:96])
+	if err := crypto.DecryptStream(&bytes.Buffer{}, bytes.NewReader(swapped), key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for reordered chunks, got %v", err)
+	}
+
+	// Modify the nonce prefix in the header.
+	modified := append([]byte{}, stream...)
+	modified[12] ^= 0xff
+	if err := crypto.DecryptStream(&bytes.Buffer{}, bytes.NewReader(modified), key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for modified header, got %v", err)
+	}
+
+	// Corrupt the magic.
+	badMagic := append([]byte{}, stream...)
+	badMagic[0] = 'X'
+	if err := crypto.DecryptStream(&bytes.Buffer{}, bytes.NewReader(badMagic), key); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for bad magic, got %v", err)
+	}
+
+	wrongKey, _ := crypto.GenerateKey()
+	if err := crypto.DecryptStream(&bytes.Buffer{}, bytes.NewReader(stream), wrongKey); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for wrong key, got %v", err)
+	}
+}
+
+func TestDecryptStreamAtomic(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("c"), 300)
+	stream := encryptTestStream(t, plaintext, key, 64)
+
+	var out bytes.Buffer
+	if err := crypto.DecryptStreamAtomic(&out, bytes.NewReader(stream), key); err != nil {
+		t.Fatalf("DecryptStreamAtomic() error: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), plaintext) {
+		t.Error("Round-trip mismatch")
+	}
+
+	corrupted := append([]byte{}, stream...)
+	corrupted[len(corrupted)-1] ^= 0x01
+	var partial bytes.Buffer
+	if err := crypto.DecryptStreamAtomic(&partial, bytes.NewReader(corrupted), key); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt, got %v", err)
+	}
+	if partial.Len() != 0 {
+		t.Errorf("Expected no output for corrupt stream, got %d bytes", partial.Len())
+	}
+	if err := crypto.DecryptStream(&partial, bytes.NewReader(corrupted), key); err == nil {
+		t.Error("Expected error from DecryptStream for corrupt stream")
+	}
+	if partial.Len() == 0 {
+		t.Error("Expected DecryptStream to emit the chunks preceding the corruption")
+	}
+}
+
+func TestDecryptStreamAtomicLimit(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("d"), 300)
+	stream := encryptTestStream(t, plaintext, key, 64)
+
+	var out bytes.Buffer
+	if err := crypto.DecryptStreamAtomicLimit(&out, bytes.NewReader(stream), key, 299); !errors.Is(err, crypto.ErrStreamTooLarge) {
+		t.Errorf("Expected ErrStreamTooLarge, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Error("Expected no output when the limit is exceeded")
+	}
+	if err := crypto.DecryptStreamAtomicLimit(&out, bytes.NewReader(stream), key, 300); err != nil {
+		t.Errorf("Expected success at exact limit, got %v", err)
+	}
+}
+
+func TestValidateStream(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("v"), 200)
+	stream := encryptTestStream(t, plaintext, key, 64)
+	// header(16) + 3 full chunks (80 each) + final chunk with 8 bytes (24)
+
+	chunks, err := crypto.ValidateStream(bytes.NewReader(stream), key)
+	if err != nil {
+		t.Fatalf("ValidateStream() error: %v", err)
+	}
+	if chunks != 4 {
+		t.Errorf("Expected 4 chunks, got %d", chunks)
+	}
+
+	corrupt := append([]byte{}, stream...)
+	corrupt[16+2*80+5] ^= 0x01
+	chunks, err = crypto.ValidateStream(bytes.NewReader(corrupt), key)
+	if !errors.Is(err, crypto.ErrDecrypt) || !strings.Contains(err.Error(), "chunk 2") {
+		t.Errorf("Expected ErrDecrypt naming chunk 2, got %v", err)
+	}
+	if chunks != 2 {
+		t.Errorf("Expected 2 good chunks before the corrupt one, got %d", chunks)
+	}
+
+	// Dropping the final chunk leaves a stream without the final-chunk marker.
+	chunks, err = crypto.ValidateStream(bytes.NewReader(stream[:16+3*80]), key)
+	if err == nil {
+		t.Error("Expected error for stream missing its final chunk")
+	}
+	if chunks != 3 {
+		t.Errorf("Expected 3 good chunks before truncation, got %d", chunks)
+	}
+
+	if _, err := crypto.ValidateStream(bytes.NewReader(stream[:10]), key); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for short header, got %v", err)
+	}
+	wrongKey, _ := crypto.GenerateKey()
+	if chunks, err := crypto.ValidateStream(bytes.NewReader(stream), wrongKey); !errors.Is(err, crypto.ErrDecrypt) || chunks != 0 {
+		t.Errorf("Expected ErrDecrypt and 0 chunks for wrong key, got %d, %v", chunks, err)
+	}
+}
+
+func TestStream_InvalidInputs(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	if err := crypto.EncryptStream(&bytes.Buffer{}, bytes.NewReader(nil), make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	if err := crypto.DecryptStream(&bytes.Buffer{}, bytes.NewReader(nil), make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	for _, size := range []int{0, -1, crypto.MaxChunkSize + 1} {
+		if err := crypto.EncryptStreamWithChunkSize(&bytes.Buffer{}, bytes.NewReader(nil), key, size); !errors.Is(err, crypto.ErrInvalidStream) {
+			t.Errorf("Expected ErrInvalidStream for chunk size %d, got %v", size, err)
+		}
+	}
+}
+
+func TestDecryptRange(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := make([]byte, 1000)
+	for i := range plaintext {
+		plaintext[i] = byte(i * 7)
+	}
+	stream := encryptTestStream(t, plaintext, key, 64)
+	ra := bytes.NewReader(stream)
+
+	ranges := [][2]int64{{0, 0}, {0, 1}, {0, 1000}, {63, 2}, {64, 64}, {100, 300}, {999, 1}, {960, 40}}
+	for _, r := range ranges {
+		var out bytes.Buffer
+		if err := crypto.DecryptRange(ra, key, r[0], r[1], &out); err != nil {
+			t.Fatalf("DecryptRange(%d, %d) error: %v", r[0], r[1], err)
+		}
+		if !bytes.Equal(out.Bytes(), plaintext[r[0]:r[0]+r[1]]) {
+			t.Errorf("DecryptRange(%d, %d) returned wrong bytes", r[0], r[1])
+		}
+	}
+
+	// Ranges past the end return what is available and an error.
+	var out bytes.Buffer
+	if err := crypto.DecryptRange(ra, key, 990, 20, &out); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for range past the end, got %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), plaintext[990:]) {
+		t.Errorf("Expected the available tail to be written, got %d bytes", out.Len())
+	}
+	if err := crypto.DecryptRange(ra, key, 5000, 1, &bytes.Buffer{}); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for range after the end, got %v", err)
+	}
+}
+
+func TestDecryptRange_Errors(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	stream := encryptTestStream(t, bytes.Repeat([]byte("r"), 300), key, 64)
+
+	// Chunks touched by the range are authenticated.
+	corrupted := append([]byte{}, stream...)
+	corrupted[16+80+5] ^= 1
+	if err := crypto.DecryptRange(bytes.NewReader(corrupted), key, 70, 10, &bytes.Buffer{}); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for corrupted chunk, got %v", err)
+	}
+	if err := crypto.DecryptRange(bytes.NewReader(corrupted), key, 0, 64, &bytes.Buffer{}); err != nil {
+		t.Errorf("Expected untouched chunks to decrypt, got %v", err)
+	}
+
+	// Swapped chunks fail because the chunk index is authenticated.
+	swapped := append([]byte{}, stream...)
+	copy(swapped[16:96], stream[96:176])
+	if err := crypto.DecryptRange(bytes.NewReader(swapped), key, 0, 10, &bytes.Buffer{}); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for moved chunk, got %v", err)
+	}
+
+	if err := crypto.DecryptRange(bytes.NewReader(stream), key, -1, 10, &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for negative offset")
+	}
+	if err := crypto.DecryptRange(bytes.NewReader(stream[:8]), key, 0, 10, &bytes.Buffer{}); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for short header, got %v", err)
+	}
+	if err := crypto.DecryptRange(bytes.NewReader(stream), make([]byte, 16), 0, 10, &bytes.Buffer{}); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
+
+func TestRechunkStream(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("rechunk "), 5000)
+	var src bytes.Buffer
+	if err := crypto.EncryptStreamWithChunkSize(&src, bytes.NewReader(plaintext), key, 1000); err != nil {
+		t.Fatalf("EncryptStreamWithChunkSize() error: %v", err)
+	}
+	encrypted := src.Bytes()
+
+	var rechunked bytes.Buffer
+	if err := crypto.RechunkStream(&rechunked, bytes.NewReader(encrypted), key, 16*1024); err != nil {
+		t.Fatalf("RechunkStream() error: %v", err)
+	}
+	chunks, err := crypto.ValidateStream(bytes.NewReader(rechunked.Bytes()), key)
+	if err != nil || chunks != 3 {
+		t.Fatalf("ValidateStream() = %d, %v, want 3 chunks", chunks, err)
+	}
+	var out bytes.Buffer
+	if err := crypto.DecryptStream(&out, &rechunked, key); err != nil || !bytes.Equal(out.Bytes(), plaintext) {
+		t.Fatalf("DecryptStream() of rechunked stream: %v", err)
+	}
+
+	// A truncated source fails, and the partial output does not validate.
+	var partial bytes.Buffer
+	err = crypto.RechunkStream(&partial, bytes.NewReader(encrypted[:len(encrypted)-2000]), key, 100)
+	if !errors.Is(err, crypto.ErrInvalidStream) && !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected an error for a truncated source, got %v", err)
+	}
+	if _, err := crypto.ValidateStream(&partial, key); err == nil {
+		t.Error("Expected the partial output of a failed rechunk not to validate")
+	}
+
+	if err := crypto.RechunkStream(&bytes.Buffer{}, bytes.NewReader(encrypted), key, 0); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for chunk size 0, got %v", err)
+	}
+	other, _ := crypto.GenerateKey()
+	if err := crypto.RechunkStream(&bytes.Buffer{}, bytes.NewReader(encrypted), other, 100); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for the wrong key, got %v", err)
+	}
+}
+
+func TestEncryptSharded_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := make([]byte, 3*crypto.DefaultChunkSize+100)
+	_, _ = rand.Read(plaintext)
+	for _, shardSize := range []int{1000, 50000, 1 << 20} {
+		shards, err := crypto.EncryptSharded(plaintext, key, shardSize)
+		if err != nil {
+			t.Fatalf("EncryptSharded(%d) error: %v", shardSize, err)
+		}
+		for i, shard := range shards[:len(shards)-1] {
+			if len(shard) != shardSize {
+				t.Fatalf("Shard %d has %d bytes, want %d", i, len(shard), shardSize)
+			}
+		}
+		decrypted, err := crypto.DecryptSharded(shards, key)
+		if err != nil {
+			t.Fatalf("DecryptSharded(%d) error: %v", shardSize, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("Round-trip mismatch for shard size %d", shardSize)
+		}
+	}
+	if shards, err := crypto.EncryptSharded(nil, key, 8); err != nil {
+		t.Fatalf("EncryptSharded(nil) error: %v", err)
+	} else if decrypted, err := crypto.DecryptSharded(shards, key); err != nil || len(decrypted) != 0 {
+		t.Errorf("DecryptSharded() = %d bytes, %v", len(decrypted), err)
+	}
+}
+
+func TestDecryptSharded_Tampering(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := make([]byte, 3*crypto.DefaultChunkSize)
+	shards, _ := crypto.EncryptSharded(plaintext, key, 20000)
+	decrypt := func(shards ...[]byte) error {
+		_, err := crypto.DecryptSharded(shards, key)
+		return err
+	}
+	n := len(shards)
+
+	if err := decrypt(); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for no shards, got %v", err)
+	}
+	if err := decrypt(shards[:n-1]...); !errors.Is(err, crypto.ErrInvalidStream) && !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected an error for a missing last shard, got %v", err)
+	}
+	missing := append(append([][]byte{}, shards[:2]...), shards[3:]...)
+	if err := decrypt(missing...); err == nil {
+		t.Error("Expected an error for a missing middle shard")
+	}
+	swapped := append([][]byte{}, shards...)
+	swapped[2], swapped[3] = swapped[3], swapped[2]
+	if err := decrypt(swapped...); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for reordered shards, got %v", err)
+	}
+	short := append([][]byte{}, shards...)
+	short[1] = short[1][:100]
+	if err := decrypt(short...); !errors.Is(err, crypto.ErrInvalidStream) {
+		t.Errorf("Expected ErrInvalidStream for a short shard, got %v", err)
+	}
+	wrongKey, _ := crypto.GenerateKey()
+	if _, err := crypto.DecryptSharded(shards, wrongKey); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for the wrong key, got %v", err)
+	}
+	if _, err := crypto.EncryptSharded(plaintext, key, 0); err == nil {
+		t.Error("Expected an error for shard size 0")
+	}
+}
+
+func TestStreamCiphertextLen(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, tc := range []struct{ size, chunkSize int }{
+		{0, 16}, {1, 16}, {15, 16}, {16, 16}, {17, 16}, {64, 16}, {1000, 1}, {100000, crypto.DefaultChunkSize},
+	} {
+		stream := encryptTestStream(t, make([]byte, tc.size), key, tc.chunkSize)
+		if got := crypto.StreamCiphertextLen(tc.size, tc.chunkSize); got != int64(len(stream)) {
+			t.Errorf("StreamCiphertextLen(%d, %d) = %d, want %d", tc.size, tc.chunkSize, got, len(stream))
+		}
+	}
+	for _, tc := range []struct{ size, chunkSize int }{{-1, 16}, {10, 0}, {10, crypto.MaxChunkSize + 1}} {
+		if got := crypto.StreamCiphertextLen(tc.size, tc.chunkSize); got != -1 {
+			t.Errorf("StreamCiphertextLen(%d, %d) = %d, want -1", tc.size, tc.chunkSize, got)
+		}
+	}
+}
+
+// panicWriter retains the slice passed to Write and panics.
+type panicWriter struct{ got []byte }
+
+func (w *panicWriter) Write(p []byte) (int, error) {
+	w.got = p
+	panic("write failed")
+}
+
+func TestDecryptStreamAtomic_WipesOnPanic(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := bytes.Repeat([]byte("secret"), 1000)
+	stream := encryptTestStream(t, plaintext, key, 256)
+
+	w := &panicWriter{}
+	func() {
+		defer func() {
+			if r := recover(); r != "write failed" {
+				t.Errorf("Expected the writer panic to propagate, got %v", r)
+			}
+		}()
+		_ = crypto.DecryptStreamAtomic(w, bytes.NewReader(stream), key)
+	}()
+	if len(w.got) != len(plaintext) {
+		t.Fatalf("Writer received %d bytes, want %d", len(w.got), len(plaintext))
+	}
+	if !bytes.Equal(w.got, make([]byte, len(plaintext))) {
+		t.Error("The buffered plaintext should be zeroized when the writer panics")
+	}
+}
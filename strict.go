@@ -0,0 +1,57 @@
+// strict.go: Opaque decryption errors for oracle-resistant deployments.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// strictErrors reports whether SetStrictErrors has enabled opaque decryption errors.
+var strictErrors atomic.Bool
+
+// SetStrictErrors enables or disables strict error mode for decryption.
+//
+// By default decryption failures are descriptive: a malformed base64 string wraps
+// ErrBase64Decode, a truncated input wraps ErrCiphertextShort, and a failed
+// authentication wraps ErrDecrypt. This helps debugging, but a networked service that
+// relays these errors to clients hands out an oracle, telling an attacker exactly
+// which stage rejected each probe. In strict mode every failure caused by the
+// ciphertext itself wraps only ErrDecrypt with the same message, "decryption failed",
+// so the error reveals nothing beyond the fact that decryption failed. Errors caused
+// by the caller's configuration, such as an invalid key, stay descriptive.
+//
+// Strict mode covers the single-message formats (EncryptBytes and its variants,
+// Cipher, EncryptWith, SafeEncrypt, EncryptSearchable, key hints, groups, chains and
+// CipherData). Streaming formats keep ErrInvalidStream, since truncating a stream is
+// visible to the attacker anyway.
+//
+// Timing: authentication always processes the whole ciphertext and compares tags in
+// constant time. Base64 and length checks run before the key is used, so their faster
+// rejection depends only on public input and reveals nothing about the key or the
+// plaintext. SetStrictErrors is safe to call concurrently with decryption.
+//
+// Example:
+//
+//	crypto.SetStrictErrors(true)
+//	_, err := crypto.DecryptBytes(untrusted, key)
+//	// err wraps only ErrDecrypt, whatever was wrong with untrusted
+func SetStrictErrors(enabled bool) {
+	strictErrors.Store(enabled)
+}
+
+// inputError returns the error for a decryption failure caused by the ciphertext:
+// the descriptive sentinel and rich error normally, an opaque ErrDecrypt in strict mode.
+func inputError(sentinel error, richErr error) error {
+	if strictErrors.Load() {
+		richErr = goerrors.New(ErrCodeDecrypt, "decryption failed")
+		sentinel = ErrDecrypt
+	}
+	return fmt.Errorf("%w: %w", sentinel, richErr)
+}
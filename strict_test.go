@@ -0,0 +1,108 @@
+// strict_test.go: Test cases for strict (opaque) decryption errors.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+// malformedInputs returns ciphertexts that fail at each stage of decryption.
+func malformedInputs(t *testing.T, key []byte) map[string]string {
+	t.Helper()
+	ciphertext, _ := crypto.EncryptBytes([]byte("payload"), key)
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+	raw[len(raw)-1] ^= 1
+	return map[string]string{
+		"empty":    "",
+		"base64":   "not base64!",
+		"short":    "AAAA",
+		"tampered": base64.StdEncoding.EncodeToString(raw),
+	}
+}
+
+func TestSetStrictErrors_Opaque(t *testing.T) {
+	crypto.SetStrictErrors(true)
+	t.Cleanup(func() { crypto.SetStrictErrors(false) })
+
+	key, _ := crypto.GenerateKey()
+	var first string
+	for name, in := range malformedInputs(t, key) {
+		for fn, decrypt := range map[string]func(string, []byte) ([]byte, error){
+			"DecryptBytes":      crypto.DecryptBytes,
+			"SafeDecrypt":       crypto.SafeDecrypt,
+			"DecryptSearchable": crypto.DecryptSearchable,
+		} {
+			_, err := decrypt(in, key)
+			if !errors.Is(err, crypto.ErrDecrypt) {
+				t.Fatalf("%s(%s): got %v, want ErrDecrypt", fn, name, err)
+			}
+			if errors.Is(err, crypto.ErrBase64Decode) || errors.Is(err, crypto.ErrCiphertextShort) || errors.Is(err, crypto.ErrEmptyPlaintext) {
+				t.Errorf("%s(%s): strict error reveals the failing stage: %v", fn, name, err)
+			}
+			if first == "" {
+				first = err.Error()
+			} else if err.Error() != first {
+				t.Errorf("%s(%s): message %q differs from %q", fn, name, err, first)
+			}
+		}
+	}
+
+	// Configuration errors stay descriptive.
+	if _, err := crypto.DecryptBytes("AAAA", make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key in strict mode: got %v, want ErrInvalidKeySize", err)
+	}
+}
+
+func TestSetStrictErrors_DefaultDescriptive(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	inputs := malformedInputs(t, key)
+	want := map[string]error{
+		"empty":    crypto.ErrEmptyPlaintext,
+		"base64":   crypto.ErrBase64Decode,
+		"short":    crypto.ErrCiphertextShort,
+		"tampered": crypto.ErrDecrypt,
+	}
+	for name, in := range inputs {
+		if _, err := crypto.DecryptBytes(in, key); !errors.Is(err, want[name]) {
+			t.Errorf("%s: got %v, want %v", name, err, want[name])
+		}
+	}
+}
+
+// TestDecryptBytes_AuthFailureNoShortCircuit checks that a tag mismatch is only
+// detected after the whole ciphertext has been processed, rather than by an early
+// exit that would make failures measurably cheaper than successes.
+func TestDecryptBytes_AuthFailureNoShortCircuit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test skipped in short mode")
+	}
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytes(bytes.Repeat([]byte{0x42}, 1<<20), key)
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+	raw[len(raw)-1] ^= 1
+	tampered := base64.StdEncoding.EncodeToString(raw)
+
+	fastest := func(in string) time.Duration {
+		best := time.Duration(1<<63 - 1)
+		for i := 0; i < 15; i++ {
+			start := time.Now()
+			_, _ = crypto.DecryptBytes(in, key)
+			best = min(best, time.Since(start))
+		}
+		return best
+	}
+	success, failure := fastest(ciphertext), fastest(tampered)
+	if failure < success/4 {
+		t.Errorf("authentication failure took %v, success %v: failure path short-circuits", failure, success)
+	}
+}
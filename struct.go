@@ -0,0 +1,245 @@
+// struct.go: In-place encryption of tagged struct fields under per-classification keys.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// structFieldLabel domain-separates struct field ciphertexts from other AAD-bound values.
+const structFieldLabel = "go-crypto/v1/struct-field"
+
+// structTagName is the struct tag holding a field's classification label.
+const structTagName = "crypto"
+
+// structVisit identifies a struct walked by walkStruct: its address and type, since a
+// struct and its first field share an address. Zero-size structs are not tracked, as
+// distinct ones may share an address and they hold no fields to encrypt.
+type structVisit struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// structField is a tagged field located by walkStruct.
+type structField struct {
+	value reflect.Value
+	label string
+	path  string
+}
+
+// EncryptStructMultiKey encrypts the tagged fields of a struct in place, each under the
+// key of its classification label.
+//
+// Fields are selected with a `crypto:"<label>"` struct tag, e.g. `crypto:"pii"` or
+// `crypto:"secret"`, and encrypted with the key registered under that label in keys, so
+// classes of data with different rotation policies are protected by separate keys.
+// Tagged fields must be exported and of type string or []byte; their value is replaced
+// by the base64 ciphertext. Untagged struct fields (and pointers to structs) are walked
+// recursively, so nested records work too; a struct reached twice, through a pointer
+// cycle or a shared pointer, is rejected rather than encrypted twice. Empty values are left as-is, which keeps
+// optional fields empty, but reveals that they are empty.
+//
+// Each field is encrypted with AES-256-GCM and bound to its label and field path as
+// additional data, so a ciphertext cannot be moved to another field, or decrypted under
+// another classification, undetected. Ciphertexts can still be swapped between two
+// records of the same type; bind the record identity with EncryptWithAAD or use
+// EncryptGroup when that matters. On error v is left unchanged.
+//
+// Parameters:
+//   - v: A non-nil pointer to a struct
+//   - keys: The 32-byte keys, keyed by classification label
+//
+// Returns:
+//   - An error if v is not a pointer to a struct, a tagged field has an unsupported type,
+//     a struct is reachable twice, a label has no key, or encryption fails
+//
+// Example:
+//
+//	type Patient struct {
+//		ID        string
+//		Name      string `crypto:"pii"`
+//		Diagnosis string `crypto:"secret"`
+//	}
+//	err := crypto.EncryptStructMultiKey(&patient, map[string][]byte{
+//		"pii":    piiKey,
+//		"secret": secretKey,
+//	})
+func EncryptStructMultiKey(v any, keys map[string][]byte) error {
+	fields, err := collectStructFields(v, keys)
+	if err != nil {
+		return err
+	}
+	ciphertexts := make([]string, len(fields))
+	for i, f := range fields {
+		plaintext := fieldBytes(f.value)
+		if len(plaintext) == 0 {
+			continue
+		}
+		ciphertexts[i], err = EncryptWithAAD(plaintext, keys[f.label], structFieldAAD(f))
+		if f.value.Kind() == reflect.String {
+			Zeroize(plaintext) // a copy of the string, not the caller's memory
+		}
+		if err != nil {
+			return goerrors.Wrap(err, "STRUCT_FIELD_ERROR", fmt.Sprintf("failed to encrypt field %s", f.path))
+		}
+	}
+	for i, f := range fields {
+		if ciphertexts[i] == "" {
+			continue
+		}
+		if f.value.Kind() == reflect.String {
+			f.value.SetString(ciphertexts[i])
+		} else {
+			f.value.SetBytes([]byte(ciphertexts[i]))
+		}
+	}
+	return nil
+}
+
+// DecryptStructMultiKey decrypts in place the tagged fields of a struct encrypted by
+// EncryptStructMultiKey.
+//
+// The struct must have the same shape as at encryption time, since every ciphertext is
+// bound to its field path and label. Decrypted []byte fields receive fresh slices; the
+// intermediate plaintext of string fields is zeroized after conversion. On error v is
+// left unchanged.
+//
+// Parameters:
+//   - v: A non-nil pointer to a struct
+//   - keys: The 32-byte keys, keyed by classification label
+//
+// Returns:
+//   - An error if v is not a pointer to a struct, a tagged field has an unsupported type,
+//     a label has no key, or a field fails to decrypt (wrapping ErrDecrypt on
+//     authentication failure)
+//
+// Example:
+//
+//	if err := crypto.DecryptStructMultiKey(&patient, keys); err != nil {
+//		log.Fatal(err)
+//	}
+func DecryptStructMultiKey(v any, keys map[string][]byte) error {
+	fields, err := collectStructFields(v, keys)
+	if err != nil {
+		return err
+	}
+	plaintexts := make([][]byte, len(fields))
+	defer func() {
+		for _, p := range plaintexts {
+			Zeroize(p)
+		}
+	}()
+	for i, f := range fields {
+		ciphertext := fieldBytes(f.value)
+		if len(ciphertext) == 0 {
+			continue
+		}
+		plaintexts[i], err = DecryptWithAAD(string(ciphertext), keys[f.label], structFieldAAD(f))
+		if err != nil {
+			return goerrors.Wrap(err, "STRUCT_FIELD_ERROR", fmt.Sprintf("failed to decrypt field %s", f.path))
+		}
+	}
+	for i, f := range fields {
+		if plaintexts[i] == nil {
+			continue
+		}
+		if f.value.Kind() == reflect.String {
+			f.value.SetString(string(plaintexts[i]))
+		} else {
+			f.value.SetBytes(append([]byte(nil), plaintexts[i]...))
+		}
+	}
+	return nil
+}
+
+// collectStructFields validates v and returns its tagged fields, checking that every
+// label used has a key.
+func collectStructFields(v any, keys map[string][]byte) ([]structField, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, goerrors.New("INVALID_STRUCT", fmt.Sprintf("expected a non-nil pointer to a struct, got %T", v))
+	}
+	var fields []structField
+	if err := walkStruct(rv.Elem(), "", map[structVisit]struct{}{}, &fields); err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if _, ok := keys[f.label]; !ok {
+			return nil, goerrors.New("MISSING_FIELD_KEY", fmt.Sprintf("no key for label %q of field %s", f.label, f.path))
+		}
+	}
+	return fields, nil
+}
+
+// walkStruct appends the tagged fields of the struct rv to fields, descending into
+// untagged struct and non-nil struct pointer fields. prefix is the dotted path of rv.
+// visited holds the structs already walked, so that a struct reachable twice, through a
+// pointer cycle or a shared pointer, is reported instead of being walked forever or
+// collected twice.
+func walkStruct(rv reflect.Value, prefix string, visited map[structVisit]struct{}, fields *[]structField) error {
+	rt := rv.Type()
+	if rt.Size() > 0 {
+		visit := structVisit{addr: rv.UnsafeAddr(), typ: rt}
+		if _, seen := visited[visit]; seen {
+			return goerrors.New("INVALID_STRUCT", fmt.Sprintf("field %s refers to a struct already visited (pointer cycle or shared pointer)", strings.TrimSuffix(prefix, ".")))
+		}
+		visited[visit] = struct{}{}
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		fv := rv.Field(i)
+		path := prefix + sf.Name
+		label, tagged := sf.Tag.Lookup(structTagName)
+		if !tagged {
+			if !sf.IsExported() {
+				continue
+			}
+			switch {
+			case fv.Kind() == reflect.Struct:
+				if err := walkStruct(fv, path+".", visited, fields); err != nil {
+					return err
+				}
+			case fv.Kind() == reflect.Pointer && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct:
+				if err := walkStruct(fv.Elem(), path+".", visited, fields); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if label == "" {
+			return goerrors.New("INVALID_STRUCT", fmt.Sprintf("field %s has an empty %s tag", path, structTagName))
+		}
+		if !sf.IsExported() {
+			return goerrors.New("INVALID_STRUCT", fmt.Sprintf("tagged field %s is not exported", path))
+		}
+		isBytes := fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8
+		if fv.Kind() != reflect.String && !isBytes {
+			return goerrors.New("UNSUPPORTED_FIELD_TYPE", fmt.Sprintf("tagged field %s has type %s; only string and []byte are supported", path, fv.Type()))
+		}
+		*fields = append(*fields, structField{value: fv, label: label, path: path})
+	}
+	return nil
+}
+
+// fieldBytes returns the contents of a string or []byte field.
+func fieldBytes(v reflect.Value) []byte {
+	if v.Kind() == reflect.String {
+		return []byte(v.String())
+	}
+	return v.Bytes()
+}
+
+// structFieldAAD binds a field ciphertext to its label and field path.
+func structFieldAAD(f structField) []byte {
+	aad := append([]byte(structFieldLabel), 0)
+	aad = appendLengthPrefixed(aad, f.label)
+	return append(aad, f.path...)
+}
@@ -0,0 +1,184 @@
+// struct_test.go: Test cases for tagged struct field encryption.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+type testAddress struct {
+	Street string `crypto:"pii"`
+	City   string
+}
+
+type testPatient struct {
+	ID        string
+	Name      string `crypto:"pii"`
+	Diagnosis []byte `crypto:"secret"`
+	Notes     string `crypto:"secret"`
+	Address   testAddress
+	Billing   *testAddress
+}
+
+func testStructKeys(t *testing.T) map[string][]byte {
+	t.Helper()
+	pii, _ := crypto.GenerateKey()
+	secret, _ := crypto.GenerateKey()
+	return map[string][]byte{"pii": pii, "secret": secret}
+}
+
+func TestEncryptStructMultiKey_RoundTrip(t *testing.T) {
+	keys := testStructKeys(t)
+	original := testPatient{
+		ID:        "p-1",
+		Name:      "Alice",
+		Diagnosis: []byte("J45.909"),
+		Address:   testAddress{Street: "1 Main St", City: "Springfield"},
+		Billing:   &testAddress{Street: "PO Box 7", City: "Shelbyville"},
+	}
+	p := original
+	p.Billing = &testAddress{Street: original.Billing.Street, City: original.Billing.City}
+
+	if err := crypto.EncryptStructMultiKey(&p, keys); err != nil {
+		t.Fatalf("EncryptStructMultiKey() error: %v", err)
+	}
+	if p.ID != "p-1" || p.Address.City != "Springfield" {
+		t.Error("Untagged fields must not change")
+	}
+	if p.Name == "Alice" || bytes.Equal(p.Diagnosis, original.Diagnosis) || p.Address.Street == "1 Main St" || p.Billing.Street == "PO Box 7" {
+		t.Error("Tagged fields must be encrypted")
+	}
+	if p.Notes != "" {
+		t.Error("Empty tagged fields must stay empty")
+	}
+	if _, err := crypto.DecryptWithAAD(p.Name, keys["secret"], nil); err == nil {
+		t.Error("Expected a pii field not to decrypt under the secret key")
+	}
+
+	if err := crypto.DecryptStructMultiKey(&p, keys); err != nil {
+		t.Fatalf("DecryptStructMultiKey() error: %v", err)
+	}
+	if p.Name != original.Name || !bytes.Equal(p.Diagnosis, original.Diagnosis) ||
+		p.Address != original.Address || *p.Billing != *original.Billing {
+		t.Errorf("Round-trip mismatch: %+v", p)
+	}
+}
+
+func TestDecryptStructMultiKey_SwappedFields(t *testing.T) {
+	keys := testStructKeys(t)
+	p := testPatient{Name: "Alice", Address: testAddress{Street: "1 Main St"}}
+	_ = crypto.EncryptStructMultiKey(&p, keys)
+
+	// Both fields use the pii key, but each ciphertext is bound to its field path.
+	p.Name, p.Address.Street = p.Address.Street, p.Name
+	encrypted := p
+	if err := crypto.DecryptStructMultiKey(&p, keys); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt for swapped fields, got %v", err)
+	}
+	if p.Name != encrypted.Name || p.Address != encrypted.Address {
+		t.Error("Expected struct to be unchanged after a failed decryption")
+	}
+}
+
+func TestEncryptStructMultiKey_Errors(t *testing.T) {
+	keys := testStructKeys(t)
+
+	p := testPatient{Name: "Alice"}
+	if err := crypto.EncryptStructMultiKey(p, keys); err == nil {
+		t.Error("Expected error for non-pointer argument")
+	}
+	if err := crypto.EncryptStructMultiKey((*testPatient)(nil), keys); err == nil {
+		t.Error("Expected error for nil pointer")
+	}
+	if err := crypto.EncryptStructMultiKey(&p, map[string][]byte{"pii": keys["pii"]}); err == nil {
+		t.Error("Expected error for missing label key")
+	}
+	if p.Name != "Alice" {
+		t.Error("Expected struct to be unchanged after an error")
+	}
+
+	type badType struct {
+		Age int `crypto:"pii"`
+	}
+	if err := crypto.EncryptStructMultiKey(&badType{Age: 3}, keys); err == nil {
+		t.Error("Expected error for unsupported field type")
+	}
+	type unexported struct {
+		name string `crypto:"pii"`
+	}
+	if err := crypto.EncryptStructMultiKey(&unexported{name: "x"}, keys); err == nil {
+		t.Error("Expected error for unexported tagged field")
+	}
+	shortKeys := map[string][]byte{"pii": make([]byte, 16), "secret": keys["secret"]}
+	if err := crypto.EncryptStructMultiKey(&p, shortKeys); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+}
+
+func TestEncryptStructMultiKey_Cycle(t *testing.T) {
+	type node struct {
+		Name string `crypto:"pii"`
+		Next *node
+	}
+	keys := testStructKeys(t)
+	n := &node{Name: "Alice"}
+	n.Next = n
+	if err := crypto.EncryptStructMultiKey(n, keys); err == nil {
+		t.Fatal("Expected error for a pointer cycle")
+	}
+	if n.Name != "Alice" {
+		t.Error("Expected struct to be unchanged after an error")
+	}
+	if err := crypto.DecryptStructMultiKey(n, keys); err == nil {
+		t.Error("Expected error for a pointer cycle on decryption")
+	}
+
+	a := &node{Name: "a"}
+	a.Next = &node{Name: "b", Next: a}
+	if err := crypto.EncryptStructMultiKey(a, keys); err == nil {
+		t.Error("Expected error for a two-node cycle")
+	}
+}
+
+func TestEncryptStructMultiKey_SharedPointer(t *testing.T) {
+	keys := testStructKeys(t)
+	shared := &testAddress{Street: "1 Main St"}
+	p := testPatient{Name: "Alice", Billing: shared}
+	type household struct {
+		Home    *testAddress
+		Patient testPatient
+	}
+	h := household{Home: shared, Patient: p}
+	if err := crypto.EncryptStructMultiKey(&h, keys); err == nil {
+		t.Fatal("Expected error for a struct reachable through two pointers")
+	}
+	if shared.Street != "1 Main St" || h.Patient.Name != "Alice" {
+		t.Error("Expected struct to be unchanged after an error")
+	}
+
+	// A pointer to a struct that is also walked inline is the same struct twice.
+	p.Billing = &p.Address
+	if err := crypto.EncryptStructMultiKey(&p, keys); err == nil {
+		t.Error("Expected error for a pointer to an inline field")
+	}
+
+	// Distinct structs with equal contents are fine.
+	p.Billing = &testAddress{Street: "1 Main St"}
+	if err := crypto.EncryptStructMultiKey(&p, keys); err != nil {
+		t.Fatalf("EncryptStructMultiKey() error: %v", err)
+	}
+	if err := crypto.DecryptStructMultiKey(&p, keys); err != nil {
+		t.Fatalf("DecryptStructMultiKey() error: %v", err)
+	}
+	if p.Billing.Street != "1 Main St" {
+		t.Errorf("Billing.Street = %q", p.Billing.Street)
+	}
+}
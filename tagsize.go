@@ -0,0 +1,101 @@
+// tagsize.go: AES-256-GCM with truncated authentication tags for constrained protocols.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// Bounds of the GCM tag length accepted by EncryptBytesTagLen and DecryptBytesTagLen.
+const (
+	// MinTagSize is the shortest supported GCM tag. Shorter tags, which NIST SP 800-38D
+	// allows only under strict usage limits, are not supported.
+	MinTagSize = 12
+
+	// MaxTagSize is the full GCM tag length used by EncryptBytes.
+	MaxTagSize = gcmTagSize
+)
+
+// ErrInvalidTagSize is returned when a GCM tag length is outside the supported range.
+var ErrInvalidTagSize = errors.New("crypto: invalid GCM tag size")
+
+// ErrCodeInvalidTagSize is the error code for unsupported GCM tag lengths.
+const ErrCodeInvalidTagSize = "CRYPTO_INVALID_TAG_SIZE"
+
+// EncryptBytesTagLen encrypts a plaintext byte slice using AES-256-GCM with a tagLen-byte
+// authentication tag.
+//
+// Security tradeoff: a t-byte tag lets an attacker forge a message with probability
+// about 2^-(8t) per attempt, and for GCM the forgery probability grows further with the
+// length of the messages. Each byte removed from the tag saves one byte per message at
+// the cost of a 256-fold easier forgery. Use 16-byte tags (or EncryptBytes) unless a
+// protocol dictates a shorter tag. Tags shorter than MinTagSize are rejected: the Go
+// standard library does not implement them, and NIST restricts them to applications
+// that strictly limit message length and the number of failed decryptions.
+//
+// The output is nonce || ciphertext || tag, base64-encoded. With tagLen 16 it is
+// identical in format to EncryptBytes.
+//
+// Parameters:
+//   - plaintext: The byte slice to encrypt (can be empty)
+//   - key: The 32-byte encryption key (must be exactly KeySize bytes)
+//   - tagLen: The tag length in bytes, between MinTagSize (12) and MaxTagSize (16)
+//
+// Returns:
+//   - A base64-encoded string containing the nonce, ciphertext and tag
+//   - An error wrapping ErrInvalidTagSize if tagLen is out of range, or another error if encryption fails
+//
+// Example:
+//
+//	ciphertext, err := crypto.EncryptBytesTagLen(reading, deviceKey, 12)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func EncryptBytesTagLen(plaintext, key []byte, tagLen int) (string, error) {
+	gcm, err := newTruncatedGCM(key, tagLen)
+	if err != nil {
+		return "", err
+	}
+	return EncryptWith(gcm, plaintext, nil)
+}
+
+// DecryptBytesTagLen decrypts a ciphertext produced by EncryptBytesTagLen with the same tag length.
+//
+// The tag length is not stored in the ciphertext, so it must be known from the protocol.
+//
+// Parameters:
+//   - encryptedText: The base64-encoded encrypted string (cannot be empty)
+//   - key: The 32-byte decryption key (must be exactly KeySize bytes)
+//   - tagLen: The tag length used for encryption
+//
+// Returns:
+//   - The decrypted plaintext as a byte slice
+//   - An error if tagLen is out of range or decryption fails
+//
+// Example:
+//
+//	reading, err := crypto.DecryptBytesTagLen(ciphertext, deviceKey, 12)
+func DecryptBytesTagLen(encryptedText string, key []byte, tagLen int) ([]byte, error) {
+	gcm, err := newTruncatedGCM(key, tagLen)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptWith(gcm, encryptedText, nil)
+}
+
+// newTruncatedGCM validates tagLen and returns an AES-256-GCM AEAD with that tag size.
+func newTruncatedGCM(key []byte, tagLen int) (cipher.AEAD, error) {
+	if tagLen < MinTagSize || tagLen > MaxTagSize {
+		richErr := goerrors.New(ErrCodeInvalidTagSize, fmt.Sprintf("tag size must be between %d and %d bytes (got %d)", MinTagSize, MaxTagSize, tagLen))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTagSize, richErr)
+	}
+	return newGCMWithTagSize(key, tagLen)
+}
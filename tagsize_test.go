@@ -0,0 +1,70 @@
+// tagsize_test.go: Test cases for truncated GCM tags.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestEncryptBytesTagLen_RoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	plaintext := []byte("sensor reading 21.5C")
+	for tagLen := crypto.MinTagSize; tagLen <= crypto.MaxTagSize; tagLen++ {
+		ciphertext, err := crypto.EncryptBytesTagLen(plaintext, key, tagLen)
+		if err != nil {
+			t.Fatalf("EncryptBytesTagLen(%d) error: %v", tagLen, err)
+		}
+		raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+		if want := 12 + len(plaintext) + tagLen; len(raw) != want {
+			t.Errorf("tag %d: ciphertext is %d bytes, want %d", tagLen, len(raw), want)
+		}
+		got, err := crypto.DecryptBytesTagLen(ciphertext, key, tagLen)
+		if err != nil || string(got) != string(plaintext) {
+			t.Errorf("DecryptBytesTagLen(%d) = %q, %v", tagLen, got, err)
+		}
+	}
+}
+
+func TestEncryptBytesTagLen_FullTagInteroperates(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytesTagLen([]byte("x"), key, 16)
+	if got, err := crypto.DecryptBytes(ciphertext, key); err != nil || string(got) != "x" {
+		t.Errorf("DecryptBytes() of 16-byte-tag output = %q, %v", got, err)
+	}
+}
+
+func TestDecryptBytesTagLen_Mismatch(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ciphertext, _ := crypto.EncryptBytesTagLen([]byte("x"), key, 12)
+	if _, err := crypto.DecryptBytesTagLen(ciphertext, key, 16); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("decrypt with wrong tag length: got %v, want ErrDecrypt", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+	raw[len(raw)-1] ^= 1
+	if _, err := crypto.DecryptBytesTagLen(base64.StdEncoding.EncodeToString(raw), key, 12); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("decrypt tampered tag: got %v, want ErrDecrypt", err)
+	}
+}
+
+func TestEncryptBytesTagLen_InvalidTagLen(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, tagLen := range []int{0, 4, 8, 11, 17} {
+		if _, err := crypto.EncryptBytesTagLen([]byte("x"), key, tagLen); !errors.Is(err, crypto.ErrInvalidTagSize) {
+			t.Errorf("EncryptBytesTagLen(%d): got %v, want ErrInvalidTagSize", tagLen, err)
+		}
+		if _, err := crypto.DecryptBytesTagLen("AAAA", key, tagLen); !errors.Is(err, crypto.ErrInvalidTagSize) {
+			t.Errorf("DecryptBytesTagLen(%d): got %v, want ErrInvalidTagSize", tagLen, err)
+		}
+	}
+	if _, err := crypto.EncryptBytesTagLen([]byte("x"), make([]byte, 16), 12); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short key: got %v, want ErrInvalidKeySize", err)
+	}
+}
@@ -0,0 +1,122 @@
+// tenant.go: Per-tenant, versioned key hierarchy derived from a master key.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"encoding/binary"
+	"strconv"
+	"sync"
+	"time"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// tenantKeyInfo is the HKDF info prefix of tenant keys; the length-prefixed tenant ID
+// and the big-endian key version follow it.
+const tenantKeyInfo = "go-crypto/v1/tenant-key"
+
+// TenantKeyProvider derives one key per tenant and key version from a master key.
+//
+// Each key is HKDF-SHA256(masterKey, info = label || tenant ID || version), so tenants
+// are cryptographically isolated from each other, and rotating a tenant to a new
+// version yields an unrelated key while older versions stay derivable for decrypting
+// existing data. Derived keys are cached in a bounded KeyCache with a TTL, and cached
+// keys are zeroized on expiry or eviction. A TenantKeyProvider is safe for concurrent use.
+type TenantKeyProvider struct {
+	mu     sync.RWMutex
+	master []byte
+	cache  *KeyCache
+	ttl    time.Duration
+}
+
+// NewTenantKeyProvider creates a provider deriving tenant keys from masterKey.
+//
+// The master key is copied; the caller should zeroize its own copy.
+//
+// Parameters:
+//   - masterKey: The 32-byte master key (must be exactly KeySize bytes)
+//   - maxEntries: The maximum number of cached tenant keys (DefaultKeyCacheSize if not positive)
+//   - ttl: How long a derived key stays cached (keys are not cached if not positive)
+//
+// Returns:
+//   - A new TenantKeyProvider
+//   - An error if the key size is invalid
+//
+// Example:
+//
+//	provider, err := crypto.NewTenantKeyProvider(masterKey, 10000, 10*time.Minute)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	key, err := provider.KeyForTenant(tenantID, currentVersion)
+func NewTenantKeyProvider(masterKey []byte, maxEntries int, ttl time.Duration) (*TenantKeyProvider, error) {
+	if err := checkKey(masterKey); err != nil {
+		return nil, err
+	}
+	return &TenantKeyProvider{
+		master: append([]byte(nil), masterKey...),
+		cache:  NewKeyCache(maxEntries),
+		ttl:    ttl,
+	}, nil
+}
+
+// KeyForTenant returns the key of a tenant at a key version.
+//
+// The returned key is the caller's own copy, which they should zeroize when done.
+//
+// Parameters:
+//   - tenantID: The tenant identifier (cannot be empty)
+//   - version: The key version (must be positive), e.g. the tenant's rotation counter
+//
+// Returns:
+//   - The 32-byte tenant key
+//   - An error if the arguments are invalid, the provider has been destroyed, or key
+//     derivation fails
+func (p *TenantKeyProvider) KeyForTenant(tenantID string, version int) ([]byte, error) {
+	if tenantID == "" {
+		return nil, goerrors.New("EMPTY_TENANT", "tenant ID cannot be empty")
+	}
+	if version <= 0 || uint64(version) > 1<<32-1 {
+		return nil, goerrors.New("INVALID_KEY_VERSION", "key version must be between 1 and 2^32-1")
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.master == nil {
+		return nil, goerrors.New("PROVIDER_DESTROYED", "tenant key provider has been destroyed")
+	}
+	return p.cache.GetOrDerive(tenantCacheID(tenantID, version), func() ([]byte, error) {
+		info := appendLengthPrefixed([]byte(tenantKeyInfo), tenantID)
+		info = binary.BigEndian.AppendUint32(info, uint32(version))
+		key, err := deriveSubkey(p.master, nil, string(info), KeySize)
+		if err != nil {
+			return nil, goerrors.Wrap(err, "KEY_DERIVE_ERROR", "failed to derive tenant key")
+		}
+		return key, nil
+	}, p.ttl)
+}
+
+// Invalidate removes and zeroizes the cached key of a tenant at a key version, e.g.
+// once all data under a retired version has been re-encrypted.
+func (p *TenantKeyProvider) Invalidate(tenantID string, version int) {
+	p.cache.Invalidate(tenantCacheID(tenantID, version))
+}
+
+// Destroy zeroizes the master key and every cached tenant key. Subsequent calls to
+// KeyForTenant fail.
+func (p *TenantKeyProvider) Destroy() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	Zeroize(p.master)
+	p.master = nil
+	p.cache.Clear()
+}
+
+// tenantCacheID is the KeyCache identifier of a tenant key. The version, being
+// numeric, cannot contain the separator, so the mapping is unambiguous.
+func tenantCacheID(tenantID string, version int) string {
+	return strconv.Itoa(version) + ":" + tenantID
+}
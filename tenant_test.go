@@ -0,0 +1,110 @@
+// tenant_test.go: Test cases for per-tenant key derivation.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestTenantKeyProvider_Derivation(t *testing.T) {
+	master, _ := crypto.GenerateKey()
+	provider, err := crypto.NewTenantKeyProvider(master, 16, time.Minute)
+	if err != nil {
+		t.Fatalf("NewTenantKeyProvider() error: %v", err)
+	}
+	a1, err := provider.KeyForTenant("acme", 1)
+	if err != nil {
+		t.Fatalf("KeyForTenant() error: %v", err)
+	}
+	if len(a1) != crypto.KeySize {
+		t.Errorf("Expected %d-byte key, got %d", crypto.KeySize, len(a1))
+	}
+	again, _ := provider.KeyForTenant("acme", 1)
+	if !bytes.Equal(a1, again) {
+		t.Error("Expected the same key for the same tenant and version")
+	}
+	a2, _ := provider.KeyForTenant("acme", 2)
+	b1, _ := provider.KeyForTenant("globex", 1)
+	if bytes.Equal(a1, a2) || bytes.Equal(a1, b1) {
+		t.Error("Expected distinct keys per tenant and version")
+	}
+
+	// A fresh provider over the same master key derives the same keys (no cached state).
+	other, _ := crypto.NewTenantKeyProvider(master, 0, 0)
+	if fresh, _ := other.KeyForTenant("acme", 1); !bytes.Equal(fresh, a1) {
+		t.Error("Expected derivation to be deterministic across providers")
+	}
+	otherMaster, _ := crypto.GenerateKey()
+	foreign, _ := crypto.NewTenantKeyProvider(otherMaster, 0, time.Minute)
+	if k, _ := foreign.KeyForTenant("acme", 1); bytes.Equal(k, a1) {
+		t.Error("Expected different keys under a different master key")
+	}
+}
+
+func TestTenantKeyProvider_CallerOwnsCopy(t *testing.T) {
+	master, _ := crypto.GenerateKey()
+	provider, _ := crypto.NewTenantKeyProvider(master, 16, time.Minute)
+	key, _ := provider.KeyForTenant("acme", 1)
+	expected := append([]byte(nil), key...)
+	crypto.Zeroize(key)
+	if again, _ := provider.KeyForTenant("acme", 1); !bytes.Equal(again, expected) {
+		t.Error("Zeroizing a returned key must not affect the cached key")
+	}
+	provider.Invalidate("acme", 1)
+	if again, _ := provider.KeyForTenant("acme", 1); !bytes.Equal(again, expected) {
+		t.Error("Expected the same key after invalidation")
+	}
+}
+
+func TestTenantKeyProvider_Concurrent(t *testing.T) {
+	master, _ := crypto.GenerateKey()
+	provider, _ := crypto.NewTenantKeyProvider(master, 4, time.Minute)
+	expected, _ := provider.KeyForTenant("acme", 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tenant := []string{"acme", "globex", "initech", "umbrella", "hooli"}[i%5]
+			key, err := provider.KeyForTenant(tenant, 1)
+			if err != nil {
+				t.Errorf("KeyForTenant() error: %v", err)
+			}
+			if tenant == "acme" && !bytes.Equal(key, expected) {
+				t.Error("Concurrent lookup returned a different key")
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestTenantKeyProvider_Errors(t *testing.T) {
+	if _, err := crypto.NewTenantKeyProvider(make([]byte, 16), 0, time.Minute); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("Expected ErrInvalidKeySize, got %v", err)
+	}
+	master, _ := crypto.GenerateKey()
+	provider, _ := crypto.NewTenantKeyProvider(master, 0, time.Minute)
+	if _, err := provider.KeyForTenant("", 1); err == nil {
+		t.Error("Expected error for empty tenant ID")
+	}
+	for _, version := range []int{0, -1} {
+		if _, err := provider.KeyForTenant("acme", version); err == nil {
+			t.Errorf("Expected error for version %d", version)
+		}
+	}
+	provider.Destroy()
+	if _, err := provider.KeyForTenant("acme", 1); err == nil {
+		t.Error("Expected error after Destroy")
+	}
+}
@@ -0,0 +1,166 @@
+// vectors.go: Exported known-answer vectors pinning the library's byte-level behavior.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// TestVector is a known-answer vector for one of the package's public functions.
+//
+// Unlike SelfTest, which checks the underlying primitives against published references,
+// these vectors pin the exact output of this library's own API (envelope format, key
+// derivation parameters, deterministic encryption), so that any change in behavior
+// across library versions is detected.
+type TestVector struct {
+	// Name identifies the vector and the function it exercises, e.g. "DecryptBytes/AES-256-GCM".
+	Name string
+
+	// Key is the key passed to the function, or the password for key derivation vectors.
+	Key []byte
+
+	// Input is the function input: plaintext, ciphertext text or salt, depending on the vector.
+	Input []byte
+
+	// Expected is the exact expected output. Functions returning strings are compared
+	// against the string's bytes.
+	Expected []byte
+
+	run func(v TestVector) ([]byte, error)
+}
+
+// TestVectors returns the known-answer vectors checked by VerifyAgainstVectors.
+//
+// The returned slice is a fresh copy and may be modified freely. The vectors are:
+//   - DecryptBytes/AES-256-GCM: opens a fixed EncryptBytes envelope
+//   - DecryptWithAAD/AES-256-GCM: opens a fixed envelope bound to the AAD "go-crypto"
+//   - DeriveKey/Argon2id: t=1, m=1 MB, p=1, 32-byte output
+//   - DeriveKeyPBKDF2/SHA-256: 4096 iterations, 32-byte output
+//   - EncryptSearchable/AES-SIV: deterministic encryption of a fixed plaintext
+//   - GetKeyFingerprint/SHA-256: fingerprint of a fixed key
+//
+// Returns:
+//   - The list of vectors
+//
+// Example:
+//
+//	for _, v := range crypto.TestVectors() {
+//		fmt.Printf("%s: %x\n", v.Name, v.Expected)
+//	}
+func TestVectors() []TestVector {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	vectors := []TestVector{
+		{
+			Name:     "DecryptBytes/AES-256-GCM",
+			Key:      key,
+			Input:    []byte("Z28tY3J5cHRvLXYxRf0DsjFPOpK/zwx2z5km5uxoYs6lWgxOSGHC50VEicbOChWxqcE="),
+			Expected: []byte("go-crypto known answer"),
+			run: func(v TestVector) ([]byte, error) {
+				return DecryptBytes(string(v.Input), v.Key)
+			},
+		},
+		{
+			Name:     "DecryptWithAAD/AES-256-GCM",
+			Key:      key,
+			Input:    []byte("Z28tY3J5cHRvLXYyBa1BgX8fvcFp2R7FC6RTc+6NNXRpRhCq8UnLqLMf00k="),
+			Expected: []byte("bound to context"),
+			run: func(v TestVector) ([]byte, error) {
+				return DecryptWithAAD(string(v.Input), v.Key, []byte("go-crypto"))
+			},
+		},
+		{
+			Name:     "DeriveKey/Argon2id",
+			Key:      []byte("password"),
+			Input:    []byte("somesalt"),
+			Expected: mustHex("c8e9aedc956f6a7dff0a4d42940df628623f328ea1235005abac933c57093e23"),
+			run: func(v TestVector) ([]byte, error) {
+				return DeriveKey(v.Key, v.Input, KeySize, &KDFParams{Time: 1, Memory: 1, Threads: 1})
+			},
+		},
+		{
+			Name:     "DeriveKeyPBKDF2/SHA-256",
+			Key:      []byte("password"),
+			Input:    []byte("salt"),
+			Expected: mustHex("c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"),
+			run: func(v TestVector) ([]byte, error) {
+				return DeriveKeyPBKDF2(v.Key, v.Input, 4096, KeySize)
+			},
+		},
+		{
+			Name:     "EncryptSearchable/AES-SIV",
+			Key:      key,
+			Input:    []byte("alice@example.com"),
+			Expected: []byte("yDl9TeNW8fcNuWC8QpkMpZtgOOsRuH2kKK7rK52UttDm"),
+			run: func(v TestVector) ([]byte, error) {
+				out, err := EncryptSearchable(v.Input, v.Key)
+				return []byte(out), err
+			},
+		},
+		{
+			Name:     "GetKeyFingerprint/SHA-256",
+			Key:      key,
+			Expected: []byte("630dcd2966c43366"),
+			run: func(v TestVector) ([]byte, error) {
+				return []byte(GetKeyFingerprint(v.Key)), nil
+			},
+		},
+	}
+	return vectors
+}
+
+// VerifyAgainstVectors runs every vector from TestVectors through the public API and
+// compares the output byte for byte.
+//
+// Run it in CI (or at startup, next to SelfTest) to confirm that a library upgrade
+// did not change the bytes produced for existing data.
+//
+// Returns:
+//   - nil if every vector matches
+//   - An error wrapping ErrSelfTest that names the first failing vector otherwise
+//
+// Example:
+//
+//	func TestCryptoStability(t *testing.T) {
+//		if err := crypto.VerifyAgainstVectors(); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func VerifyAgainstVectors() error {
+	for _, v := range TestVectors() {
+		if err := v.Verify(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify runs a single vector and compares its output with Expected.
+//
+// Returns:
+//   - nil if the output matches
+//   - An error wrapping ErrSelfTest otherwise
+func (v TestVector) Verify() error {
+	if v.run == nil {
+		richErr := goerrors.New(ErrCodeSelfTest, fmt.Sprintf("vector %q is not a library vector", v.Name))
+		return fmt.Errorf("%w: %w", ErrSelfTest, richErr)
+	}
+	out, err := v.run(v)
+	if err == nil && !bytes.Equal(out, v.Expected) {
+		err = errKATMismatch
+	}
+	if err != nil {
+		richErr := goerrors.Wrap(err, ErrCodeSelfTest, fmt.Sprintf("vector %q failed", v.Name))
+		return fmt.Errorf("%w: %w", ErrSelfTest, richErr)
+	}
+	return nil
+}
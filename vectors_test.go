@@ -0,0 +1,49 @@
+// vectors_test.go: Test cases for the exported known-answer vectors.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestVerifyAgainstVectors(t *testing.T) {
+	if err := crypto.VerifyAgainstVectors(); err != nil {
+		t.Fatalf("VerifyAgainstVectors() error: %v", err)
+	}
+	vectors := crypto.TestVectors()
+	if len(vectors) == 0 {
+		t.Fatal("Expected at least one vector")
+	}
+	for _, v := range vectors {
+		if err := v.Verify(); err != nil {
+			t.Errorf("%s: %v", v.Name, err)
+		}
+	}
+}
+
+func TestTestVectors_DetectChanges(t *testing.T) {
+	vectors := crypto.TestVectors()
+	v := vectors[0]
+	v.Expected = append([]byte{}, v.Expected...)
+	v.Expected[0] ^= 1
+	if err := v.Verify(); !errors.Is(err, crypto.ErrSelfTest) {
+		t.Errorf("Expected ErrSelfTest for modified vector, got %v", err)
+	}
+
+	// The returned vectors are copies.
+	vectors[0].Expected[0] ^= 1
+	if err := crypto.VerifyAgainstVectors(); err != nil {
+		t.Errorf("Expected modifying returned vectors not to affect verification, got %v", err)
+	}
+
+	if err := (crypto.TestVector{Name: "custom"}).Verify(); !errors.Is(err, crypto.ErrSelfTest) {
+		t.Errorf("Expected ErrSelfTest for a vector without a runner, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+// wrap.go: Wrapping data encryption keys under key encryption keys.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	goerrors "github.com/agilira/go-errors"
+)
+
+// keyWrapLabel domain-separates wrapped keys from other envelopes, so a wrapped key
+// cannot be passed off as ordinary ciphertext under the same KEK, or vice versa.
+const keyWrapLabel = "go-crypto/v1/key-wrap"
+
+// WrapKey encrypts a data encryption key (DEK) under a key encryption key (KEK).
+//
+// The wrapped form is an AES-256-GCM envelope bound to a key-wrapping label, safe to
+// store next to the data it protects. Only holders of the KEK can recover the DEK.
+//
+// Parameters:
+//   - dek: The 32-byte key to wrap (must be exactly KeySize bytes)
+//   - kek: The 32-byte key encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - The base64-encoded wrapped key
+//   - An error if either key is invalid or encryption fails
+//
+// Example:
+//
+//	wrapped, err := crypto.WrapKey(dek, kek)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func WrapKey(dek, kek []byte) (string, error) {
+	if err := checkKey(dek); err != nil {
+		return "", err
+	}
+	out, err := sealWithHeader(kek, keyWrapLabel, nil, dek)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// UnwrapKey decrypts a key wrapped by WrapKey.
+//
+// Parameters:
+//   - wrapped: The base64-encoded wrapped key
+//   - kek: The 32-byte key encryption key used for wrapping
+//
+// Returns:
+//   - The 32-byte DEK; the caller should Zeroize it when done
+//   - An error if decryption fails or the wrapped value is not a KeySize key
+//
+// Example:
+//
+//	dek, err := crypto.UnwrapKey(record.WrappedKey, kek)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer crypto.Zeroize(dek)
+func UnwrapKey(wrapped string, kek []byte) ([]byte, error) {
+	data, err := decodeCiphertext(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	_, dek, err := openWithHeader(kek, keyWrapLabel, data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(dek) != KeySize {
+		Zeroize(dek)
+		richErr := goerrors.New(ErrCodeInvalidKey, fmt.Sprintf("unwrapped key must be %d bytes (got %d)", KeySize, len(dek)))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeySize, richErr)
+	}
+	return dek, nil
+}
+
+// GenerateWrappedKey generates a fresh DEK and wraps it under kek in one step.
+//
+// It returns both forms: the DEK for immediate use and the wrapped key for storage.
+// Store only wrapped; keep dek in memory for as long as it is needed and Zeroize it
+// afterwards. Later, recover the DEK with UnwrapKey. If wrapping fails, the DEK is
+// zeroized and not returned, so a key that was never persisted in wrapped form can
+// not end up encrypting data.
+//
+// Parameters:
+//   - kek: The 32-byte key encryption key (must be exactly KeySize bytes)
+//
+// Returns:
+//   - dek: The new 32-byte data encryption key
+//   - wrapped: The base64-encoded wrapped DEK, for storage
+//   - err: An error if the KEK is invalid or key generation or wrapping fails
+//
+// Example:
+//
+//	dek, wrapped, err := crypto.GenerateWrappedKey(kek)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer crypto.Zeroize(dek)
+//	record.WrappedKey = wrapped
+//	record.Data, err = crypto.EncryptBytes(data, dek)
+func GenerateWrappedKey(kek []byte) (dek []byte, wrapped string, err error) {
+	if err := checkKey(kek); err != nil {
+		return nil, "", err
+	}
+	dek, err = GenerateKey()
+	if err != nil {
+		return nil, "", err
+	}
+	wrapped, err = WrapKey(dek, kek)
+	if err != nil {
+		Zeroize(dek)
+		return nil, "", err
+	}
+	return dek, wrapped, nil
+}
+
+// RewrapKeys re-wraps keys wrapped by WrapKey from oldKEK to newKEK, for KEK rotation.
+//
+// Each DEK is unwrapped and immediately wrapped again under newKEK with a fresh nonce,
+// and zeroized before the next entry is processed, so no DEK leaves the function. The
+// result holds the new wrapped forms in the same order as wrapped. The operation is all
+// or nothing: on the first failure no result is returned and the error names the
+// failing entry, so the old wrapped keys stay authoritative until every entry succeeds.
+//
+// Parameters:
+//   - wrapped: The base64-encoded wrapped keys
+//   - oldKEK: The current 32-byte key encryption key
+//   - newKEK: The 32-byte key encryption key to wrap under
+//
+// Returns:
+//   - The wrapped keys under newKEK, in order
+//   - An error naming the 0-based index of the failing entry, wrapping the underlying
+//     error (e.g. ErrDecrypt), or an error if either KEK is invalid
+//
+// Example:
+//
+//	rewrapped, err := crypto.RewrapKeys(storedKeys, previousKEK, currentKEK)
+//	if err != nil {
+//		log.Fatal(err) // storedKeys are still valid under previousKEK
+//	}
+func RewrapKeys(wrapped []string, oldKEK, newKEK []byte) ([]string, error) {
+	if err := checkKey(oldKEK); err != nil {
+		return nil, err
+	}
+	if err := checkKey(newKEK); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(wrapped))
+	for i, w := range wrapped {
+		dek, err := UnwrapKey(w, oldKEK)
+		if err != nil {
+			return nil, goerrors.Wrap(err, "REWRAP_ERROR", fmt.Sprintf("failed to unwrap key %d", i))
+		}
+		out[i], err = WrapKey(dek, newKEK)
+		Zeroize(dek)
+		if err != nil {
+			return nil, goerrors.Wrap(err, "REWRAP_ERROR", fmt.Sprintf("failed to wrap key %d", i))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,108 @@
+// wrap_test.go: Test cases for key wrapping.
+//
+// Copyright (c) 2025 AGILira
+// Series: an AGLIra library
+// SPDX-License-Identifier: MPL-2.0
+
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agilira/go-crypto"
+)
+
+func TestGenerateWrappedKey(t *testing.T) {
+	kek, _ := crypto.GenerateKey()
+	dek, wrapped, err := crypto.GenerateWrappedKey(kek)
+	if err != nil {
+		t.Fatalf("GenerateWrappedKey() error: %v", err)
+	}
+	if len(dek) != crypto.KeySize {
+		t.Fatalf("DEK is %d bytes, want %d", len(dek), crypto.KeySize)
+	}
+	unwrapped, err := crypto.UnwrapKey(wrapped, kek)
+	if err != nil || !bytes.Equal(unwrapped, dek) {
+		t.Fatalf("UnwrapKey() = %x, %v, want %x", unwrapped, err, dek)
+	}
+
+	dek2, _, _ := crypto.GenerateWrappedKey(kek)
+	if bytes.Equal(dek, dek2) {
+		t.Error("GenerateWrappedKey() returned the same DEK twice")
+	}
+	if _, _, err := crypto.GenerateWrappedKey(make([]byte, 16)); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short KEK: got %v, want ErrInvalidKeySize", err)
+	}
+}
+
+func TestUnwrapKey_Errors(t *testing.T) {
+	kek, _ := crypto.GenerateKey()
+	dek, _ := crypto.GenerateKey()
+	wrapped, err := crypto.WrapKey(dek, kek)
+	if err != nil {
+		t.Fatalf("WrapKey() error: %v", err)
+	}
+
+	other, _ := crypto.GenerateKey()
+	if _, err := crypto.UnwrapKey(wrapped, other); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("wrong KEK: got %v, want ErrDecrypt", err)
+	}
+	// An ordinary ciphertext under the KEK is not a wrapped key.
+	plain, _ := crypto.EncryptBytes(dek, kek)
+	if _, err := crypto.UnwrapKey(plain, kek); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("plain envelope: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.DecryptBytes(wrapped, kek); !errors.Is(err, crypto.ErrDecrypt) {
+		t.Errorf("DecryptBytes() of wrapped key: got %v, want ErrDecrypt", err)
+	}
+	if _, err := crypto.WrapKey(make([]byte, 16), kek); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short DEK: got %v, want ErrInvalidKeySize", err)
+	}
+}
+
+func TestRewrapKeys(t *testing.T) {
+	oldKEK, _ := crypto.GenerateKey()
+	newKEK, _ := crypto.GenerateKey()
+	var deks [][]byte
+	var wrapped []string
+	for i := 0; i < 3; i++ {
+		dek, w, _ := crypto.GenerateWrappedKey(oldKEK)
+		deks = append(deks, dek)
+		wrapped = append(wrapped, w)
+	}
+
+	rewrapped, err := crypto.RewrapKeys(wrapped, oldKEK, newKEK)
+	if err != nil {
+		t.Fatalf("RewrapKeys() error: %v", err)
+	}
+	if len(rewrapped) != len(wrapped) {
+		t.Fatalf("RewrapKeys() returned %d keys, want %d", len(rewrapped), len(wrapped))
+	}
+	for i, w := range rewrapped {
+		dek, err := crypto.UnwrapKey(w, newKEK)
+		if err != nil || !bytes.Equal(dek, deks[i]) {
+			t.Errorf("entry %d: UnwrapKey() = %x, %v, want %x", i, dek, err, deks[i])
+		}
+		if _, err := crypto.UnwrapKey(w, oldKEK); err == nil {
+			t.Errorf("entry %d: expected the old KEK to no longer unwrap", i)
+		}
+	}
+
+	wrapped[1] = rewrapped[1] // already under the new KEK
+	rewrapped, err = crypto.RewrapKeys(wrapped, oldKEK, newKEK)
+	if !errors.Is(err, crypto.ErrDecrypt) || rewrapped != nil {
+		t.Fatalf("Expected ErrDecrypt and no result, got %v, %v", rewrapped, err)
+	}
+	if !strings.Contains(err.Error(), "key 1") {
+		t.Errorf("Expected the error to name the failing entry, got %v", err)
+	}
+	if _, err := crypto.RewrapKeys(wrapped, oldKEK, newKEK[:16]); !errors.Is(err, crypto.ErrInvalidKeySize) {
+		t.Errorf("short KEK: got %v, want ErrInvalidKeySize", err)
+	}
+	if out, err := crypto.RewrapKeys(nil, oldKEK, newKEK); err != nil || len(out) != 0 {
+		t.Errorf("RewrapKeys(nil) = %v, %v", out, err)
+	}
+}